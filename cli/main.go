@@ -2,14 +2,19 @@ package main
 
 import (
 	"bytes"
+	"demo/internal/chains"
+	"demo/internal/config"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
+
+	"github.com/zeromicro/go-zero/core/conf"
 )
 
 const (
@@ -95,7 +100,8 @@ func handleSendTransaction(args []string) {
 	to := fs.String("to", "", "接收地址 (必填)")
 	chain := fs.String("chain", "BSC", "区块链网络")
 	token := fs.String("token", "BNB", "代币符号 (BNB/USDT/ETH等)")
-	amount := fs.String("amount", "", "转账金额 (必填)")
+	amount := fs.String("amount", "", "转账金额，默认按代币精度换算的小数，如 0.1 (必填)")
+	unit := fs.String("unit", "display", "金额单位: display（小数）/ base（最小单位）")
 
 	fs.Parse(args)
 
@@ -115,7 +121,8 @@ func handleSendTransaction(args []string) {
 		"chain":        *chain,
 		"from_token":   fromToken,
 		"to_token":     toToken,
-		"amount":       convertAmountToWei(*amount, *token),
+		"amount":       *amount,
+		"amount_unit":  *unit,
 	}
 
 	fmt.Printf("=== 发送 %s %s (链: %s) ===\n", *amount, *token, *chain)
@@ -130,7 +137,8 @@ func handleSwapTransaction(args []string) {
 	chain := fs.String("chain", "BSC", "区块链网络")
 	fromToken := fs.String("from-token", "", "源代币 (必填)")
 	toToken := fs.String("to-token", "", "目标代币 (必填)")
-	amount := fs.String("amount", "", "交换金额 (必填)")
+	amount := fs.String("amount", "", "交换金额，默认按代币精度换算的小数，如 0.1 (必填)")
+	unit := fs.String("unit", "display", "金额单位: display（小数）/ base（最小单位）")
 
 	fs.Parse(args)
 
@@ -156,7 +164,8 @@ func handleSwapTransaction(args []string) {
 		"chain":        *chain,
 		"from_token":   fromTokenAddr,
 		"to_token":     toTokenAddr,
-		"amount":       convertAmountToWei(*amount, *fromToken),
+		"amount":       *amount,
+		"amount_unit":  *unit,
 	}
 
 	fmt.Printf("=== 交换 %s %s -> %s (链: %s) ===\n", *amount, *fromToken, *toToken, *chain)
@@ -171,7 +180,9 @@ func handleBridgeTransaction(args []string) {
 	fromChain := fs.String("from-chain", "BSC", "源链")
 	toChain := fs.String("to-chain", "ETH", "目标链")
 	token := fs.String("token", "USDT", "代币符号")
-	amount := fs.String("amount", "", "跨链金额 (必填)")
+	amount := fs.String("amount", "", "跨链金额，默认按代币精度换算的小数，如 0.1 (必填)")
+	unit := fs.String("unit", "display", "金额单位: display（小数）/ base（最小单位）")
+	configFile := fs.String("config", "etc/demo.yaml", "服务配置文件 (读取链注册表)")
 
 	fs.Parse(args)
 
@@ -182,8 +193,9 @@ func handleBridgeTransaction(args []string) {
 	}
 
 	// 获取链ID
-	fromChainId := getChainId(*fromChain)
-	toChainId := getChainId(*toChain)
+	registry := loadChainRegistry(*configFile)
+	fromChainId := getChainId(registry, *fromChain)
+	toChainId := getChainId(registry, *toChain)
 
 	// 标准化代币地址
 	fromToken := normalizeTokenAddress(*token, *fromChain)
@@ -196,7 +208,8 @@ func handleBridgeTransaction(args []string) {
 		"to_chain":     toChainId,
 		"from_token":   fromToken,
 		"to_token":     toToken,
-		"amount":       convertAmountToWei(*amount, *token),
+		"amount":       *amount,
+		"amount_unit":  *unit,
 	}
 
 	fmt.Printf("=== 跨链转账 %s %s: %s -> %s ===\n", *amount, *token, *fromChain, *toChain)
@@ -208,7 +221,7 @@ func handleApproveToken(args []string) {
 	fs := flag.NewFlagSet("approve", flag.ExitOnError)
 	owner := fs.String("owner", "", "代币持有者地址 (必填)")
 	spender := fs.String("spender", "", "被授权地址 (必填)")
-	token := fs.String("token", "", "代币地址 (必填)")
+	token := fs.String("token", "", "代币符号或地址 (必填)")
 	chain := fs.String("chain", "BSC", "区块链网络")
 	amount := fs.String("amount", "max", "授权金额 (默认为无限授权)")
 
@@ -221,7 +234,7 @@ func handleApproveToken(args []string) {
 	}
 
 	requestData := map[string]interface{}{
-		"token_address":   *token,
+		"token_address":   normalizeTokenAddress(*token, *chain),
 		"spender_address": *spender,
 		"owner_address":   *owner,
 		"chain":           *chain,
@@ -237,7 +250,7 @@ func handleRevokeApproval(args []string) {
 	fs := flag.NewFlagSet("revoke", flag.ExitOnError)
 	owner := fs.String("owner", "", "代币持有者地址 (必填)")
 	spender := fs.String("spender", "", "被授权地址 (必填)")
-	token := fs.String("token", "", "代币地址 (必填)")
+	token := fs.String("token", "", "代币符号或地址 (必填)")
 	chain := fs.String("chain", "BSC", "区块链网络")
 
 	fs.Parse(args)
@@ -249,7 +262,7 @@ func handleRevokeApproval(args []string) {
 	}
 
 	requestData := map[string]interface{}{
-		"token_address":   *token,
+		"token_address":   normalizeTokenAddress(*token, *chain),
 		"spender_address": *spender,
 		"owner_address":   *owner,
 		"chain":           *chain,
@@ -307,63 +320,66 @@ func makeAPIRequest(method, endpoint string, data interface{}) {
 	}
 }
 
-// 标准化代币地址
-func normalizeTokenAddress(token, chain string) string {
-	token = strings.ToUpper(token)
-
-	switch chain {
-	case "BSC":
-		switch token {
-		case "BNB":
-			return "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE" // BSC原生代币
-		case "USDT":
-			return "0x55d398326f99059fF775485246999027B3197955" // BSC USDT
-		case "USDC":
-			return "0x8AC76a51cc950d9822D68b83fE1Ad97B32Cd580d" // BSC USDC
-		case "BUSD":
-			return "0xe9e7CEA3DedcA5984780Bafc599bD69ADd087D56" // BSC BUSD
-		}
-	case "ETH":
-		switch token {
-		case "ETH":
-			return "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE" // ETH原生代币
-		case "USDT":
-			return "0xdAC17F958D2ee523a2206206994597C13D831ec7" // ETH USDT
-		case "USDC":
-			return "0xA0b86a33E6441b8044EB1E5CfE9F4C4e3A3A6e1D" // ETH USDC
-		}
+// tokenInfo /token/resolve 返回的代币元数据
+type tokenInfo struct {
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+	Native   bool   `json:"native"`
+}
+
+// resolveToken 通过服务端代币注册表将代币符号（BNB / USDT 等）或地址解析为代币地址和精度
+func resolveToken(token, chain string) tokenInfo {
+	params := url.Values{}
+	params.Set("chain", chain)
+	params.Set("token", token)
+
+	resp, err := http.Get(BaseURL + "/token/resolve?" + params.Encode())
+	if err != nil {
+		log.Fatalf("错误: 解析代币 %s 失败: %v", token, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatalf("错误: 读取响应失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("错误: 无法解析链 %s 上的代币 %s: %s", chain, token, strings.TrimSpace(string(body)))
 	}
 
-	// 如果不是已知的代币符号，假设它已经是地址格式
-	return token
+	var info tokenInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		log.Fatalf("错误: 解析响应失败: %v", err)
+	}
+	return info
 }
 
-// 获取链ID
-func getChainId(chain string) int {
-	switch strings.ToUpper(chain) {
-	case "BSC":
-		return 56
-	case "ETH", "ETHEREUM":
-		return 1
-	case "POLYGON":
-		return 137
-	case "ARBITRUM":
-		return 42161
-	case "OPTIMISM":
-		return 10
-	case "BASE":
-		return 8453
-	default:
-		return 56 // 默认BSC
+// 标准化代币地址（代币符号经服务端解析为合约地址 / mint）
+func normalizeTokenAddress(token, chain string) string {
+	return resolveToken(token, chain).Address
+}
+
+// loadChainRegistry 从服务配置文件加载链注册表，保证 CLI 与服务端使用同一份链配置
+func loadChainRegistry(file string) *chains.Registry {
+	var c struct {
+		Chains map[string]config.ChainConf
+	}
+	if err := conf.Load(file, &c); err != nil {
+		log.Fatalf("加载配置文件 %s 失败: %v", file, err)
 	}
+	registry, err := chains.NewRegistry(c.Chains)
+	if err != nil {
+		log.Fatalf("链配置无效: %v", err)
+	}
+	return registry
 }
 
-// 将金额转换为最小单位 (wei)
-func convertAmountToWei(amount, token string) string {
-	// 这里简化处理，实际应该根据代币精度进行转换
-	// 对于演示，直接返回输入的金额
-	// 在生产环境中，需要：
-	// 1. 查询代币的 decimals
-	// 2. 将 human-readable 金额转换为 wei
-	return amount
+// 获取链ID（LI.FI 使用的链 ID）
+func getChainId(registry *chains.Registry, chain string) int64 {
+	c, ok := registry.Get(chain)
+	if !ok {
+		log.Fatalf("不支持的链: %s", chain)
+	}
+	return c.LifiId()
 }
@@ -0,0 +1,227 @@
+package abiregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var bigIntType = reflect.TypeOf(&big.Int{})
+
+// PackArgs 按方法参数名（未命名参数用位置 "0"、"1"…）读取 JSON 参数并编码调用数据（含选择器）
+func PackArgs(m abi.Method, args map[string]interface{}) ([]byte, error) {
+	values := make([]interface{}, len(m.Inputs))
+	accepted := make(map[string]bool)
+	for i, input := range m.Inputs {
+		name := argName(input, i)
+		accepted[input.Name] = true
+		accepted[strconv.Itoa(i)] = true
+
+		v, ok := args[input.Name]
+		if !ok || input.Name == "" {
+			v, ok = args[strconv.Itoa(i)]
+		}
+		if !ok {
+			return nil, fmt.Errorf("missing argument %s (%s)", name, input.Type)
+		}
+		converted, err := convert(input.Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %w", name, err)
+		}
+		values[i] = converted
+	}
+
+	var unknown []string
+	for k := range args {
+		if !accepted[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown arguments for %s: %s", m.Sig, strings.Join(unknown, ", "))
+	}
+
+	packed, err := m.Inputs.Pack(values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", m.Sig, err)
+	}
+	return append(append([]byte{}, m.ID...), packed...), nil
+}
+
+func argName(arg abi.Argument, i int) string {
+	if arg.Name != "" {
+		return arg.Name
+	}
+	return strconv.Itoa(i)
+}
+
+// convert 把 JSON 值转换为 accounts/abi 编码需要的 Go 类型
+func convert(t abi.Type, v interface{}) (interface{}, error) {
+	switch t.T {
+	case abi.AddressTy:
+		s, ok := v.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("invalid address %v", v)
+		}
+		return common.HexToAddress(s), nil
+
+	case abi.BoolTy:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			if parsed, err := strconv.ParseBool(b); err == nil {
+				return parsed, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid bool %v", v)
+
+	case abi.StringTy:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid string %v", v)
+		}
+		return s, nil
+
+	case abi.IntTy, abi.UintTy:
+		n, err := toBigInt(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkIntRange(t, n); err != nil {
+			return nil, err
+		}
+		goType := t.GetType()
+		if goType == bigIntType {
+			return n, nil
+		}
+		rv := reflect.New(goType).Elem()
+		if t.T == abi.UintTy {
+			rv.SetUint(n.Uint64())
+		} else {
+			rv.SetInt(n.Int64())
+		}
+		return rv.Interface(), nil
+
+	case abi.BytesTy:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid bytes %v, expected 0x hex", v)
+		}
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bytes %q: %v", s, err)
+		}
+		return b, nil
+
+	case abi.FixedBytesTy:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %v, expected 0x hex", t, v)
+		}
+		b, err := hexutil.Decode(s)
+		if err != nil || len(b) != t.Size {
+			return nil, fmt.Errorf("invalid %s %q, expected %d bytes of 0x hex", t, s, t.Size)
+		}
+		rv := reflect.New(t.GetType()).Elem()
+		reflect.Copy(rv, reflect.ValueOf(b))
+		return rv.Interface(), nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %v, expected an array", t, v)
+		}
+		var rv reflect.Value
+		if t.T == abi.SliceTy {
+			rv = reflect.MakeSlice(t.GetType(), len(items), len(items))
+		} else {
+			if len(items) != t.Size {
+				return nil, fmt.Errorf("invalid %s, expected %d items, got %d", t, t.Size, len(items))
+			}
+			rv = reflect.New(t.GetType()).Elem()
+		}
+		for i, item := range items {
+			converted, err := convert(*t.Elem, item)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			rv.Index(i).Set(reflect.ValueOf(converted))
+		}
+		return rv.Interface(), nil
+
+	case abi.TupleTy:
+		rv := reflect.New(t.GetType()).Elem()
+		for i, elem := range t.TupleElems {
+			name := t.TupleRawNames[i]
+			var item interface{}
+			switch val := v.(type) {
+			case map[string]interface{}:
+				var ok bool
+				if item, ok = val[name]; !ok {
+					return nil, fmt.Errorf("missing tuple field %s", name)
+				}
+			case []interface{}:
+				if len(val) != len(t.TupleElems) {
+					return nil, fmt.Errorf("invalid tuple, expected %d items, got %d", len(t.TupleElems), len(val))
+				}
+				item = val[i]
+			default:
+				return nil, fmt.Errorf("invalid tuple %v, expected an object or array", v)
+			}
+			converted, err := convert(*elem, item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			rv.Field(i).Set(reflect.ValueOf(converted))
+		}
+		return rv.Interface(), nil
+	}
+	return nil, fmt.Errorf("unsupported abi type %s", t)
+}
+
+// toBigInt 整数接受 JSON 数字、十进制字符串和 0x 十六进制字符串
+func toBigInt(v interface{}) (*big.Int, error) {
+	var s string
+	switch n := v.(type) {
+	case json.Number:
+		s = n.String()
+	case string:
+		s = n
+	case float64:
+		if n != float64(int64(n)) {
+			return nil, fmt.Errorf("invalid integer %v", v)
+		}
+		return big.NewInt(int64(n)), nil
+	default:
+		return nil, fmt.Errorf("invalid integer %v", v)
+	}
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return n, nil
+}
+
+func checkIntRange(t abi.Type, n *big.Int) error {
+	if t.T == abi.UintTy {
+		if n.Sign() < 0 || n.BitLen() > t.Size {
+			return fmt.Errorf("%s out of range for %s", n, t)
+		}
+		return nil
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+	if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+		return fmt.Errorf("%s out of range for %s", n, t)
+	}
+	return nil
+}
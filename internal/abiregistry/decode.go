@@ -0,0 +1,108 @@
+package abiregistry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Value 解码后的返回值
+type Value struct {
+	Name  string
+	Type  string
+	Value interface{}
+}
+
+// DecodeOutputs 解码方法返回值：整数为十进制字符串，bytes 为 hex，tuple 为以字段名为键的对象
+func DecodeOutputs(m abi.Method, data []byte) ([]Value, error) {
+	if len(m.Outputs) == 0 {
+		return nil, nil
+	}
+	values, err := m.Outputs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s output: %w", m.Sig, err)
+	}
+	out := make([]Value, len(values))
+	for i, v := range values {
+		arg := m.Outputs[i]
+		out[i] = Value{Name: argName(arg, i), Type: arg.Type.String(), Value: jsonValue(arg.Type, reflect.ValueOf(v))}
+	}
+	return out, nil
+}
+
+func jsonValue(t abi.Type, v reflect.Value) interface{} {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		if n, ok := v.Interface().(*big.Int); ok {
+			return n.String()
+		}
+		return fmt.Sprint(v.Interface())
+	case abi.AddressTy:
+		return v.Interface().(common.Address).Hex()
+	case abi.BoolTy, abi.StringTy:
+		return v.Interface()
+	case abi.BytesTy:
+		return hexutil.Encode(v.Bytes())
+	case abi.FixedBytesTy, abi.FunctionTy:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return hexutil.Encode(b)
+	case abi.SliceTy, abi.ArrayTy:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = jsonValue(*t.Elem, v.Index(i))
+		}
+		return items
+	case abi.TupleTy:
+		obj := make(map[string]interface{}, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			name := t.TupleRawNames[i]
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			obj[name] = jsonValue(*elem, v.Field(i))
+		}
+		return obj
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// RevertReason 从 eth_call / eth_estimateGas 的错误中解析 revert 原因：
+// Error(string)、Panic(uint256)，或 ABI 中声明的自定义错误
+func RevertReason(contractABI *abi.ABI, err error) string {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err.Error()
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err.Error()
+	}
+	data, decErr := hexutil.Decode(hexData)
+	if decErr != nil || len(data) < 4 {
+		return err.Error()
+	}
+	if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+		return reason
+	}
+	if contractABI != nil {
+		for _, e := range contractABI.Errors {
+			if !bytes.Equal(e.ID[:4], data[:4]) {
+				continue
+			}
+			if args, unpackErr := e.Unpack(data); unpackErr == nil {
+				return fmt.Sprintf("%s%v", e.Name, args)
+			}
+			return e.Name
+		}
+	}
+	return fmt.Sprintf("%s (revert data %s)", err.Error(), hexData)
+}
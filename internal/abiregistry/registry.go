@@ -0,0 +1,118 @@
+package abiregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"demo/internal/config"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Registry 按名称登记的合约 ABI，内置 erc20 / erc721 / erc1155
+type Registry struct {
+	abis map[string]abi.ABI
+}
+
+// NewRegistry 加载内置 ABI 和配置中登记的 ABI，同名时配置覆盖内置
+func NewRegistry(confs []config.AbiConf) (*Registry, error) {
+	r := &Registry{abis: make(map[string]abi.ABI)}
+	for name, raw := range builtin {
+		parsed, err := Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("builtin abi %s: %w", name, err)
+		}
+		r.abis[name] = parsed
+	}
+
+	for _, c := range confs {
+		raw := c.Abi
+		if c.Path != "" {
+			content, err := os.ReadFile(c.Path)
+			if err != nil {
+				return nil, fmt.Errorf("abi %s: %w", c.Name, err)
+			}
+			raw = string(content)
+		}
+		if raw == "" {
+			return nil, fmt.Errorf("abi %s: Path or Abi is required", c.Name)
+		}
+		parsed, err := Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("abi %s: %w", c.Name, err)
+		}
+		r.abis[strings.ToLower(c.Name)] = parsed
+	}
+	return r, nil
+}
+
+// Get 按名称（不区分大小写）查找 ABI
+func (r *Registry) Get(name string) (abi.ABI, bool) {
+	a, ok := r.abis[strings.ToLower(name)]
+	return a, ok
+}
+
+// Parse 解析 JSON ABI，接受数组、单个片段对象，以及带 "abi" 字段的 Hardhat / Foundry 编译产物
+func Parse(raw string) (abi.ABI, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "{") {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(raw), &obj); err != nil {
+			return abi.ABI{}, fmt.Errorf("invalid abi json: %w", err)
+		}
+		if artifact, ok := obj["abi"]; ok {
+			raw = string(artifact)
+		} else {
+			raw = "[" + raw + "]"
+		}
+	}
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		return abi.ABI{}, fmt.Errorf("invalid abi json: %w", err)
+	}
+	return parsed, nil
+}
+
+// FindMethod 按方法名、完整签名（如 transfer(address,uint256)，用于重载）或 4 字节选择器查找方法
+func FindMethod(a abi.ABI, method string) (abi.Method, error) {
+	if strings.HasPrefix(method, "0x") {
+		selector, err := hexutil.Decode(method)
+		if err == nil && len(selector) == 4 {
+			if m, err := a.MethodById(selector); err == nil {
+				return *m, nil
+			}
+		}
+		return abi.Method{}, fmt.Errorf("method %s not found in abi", method)
+	}
+	if strings.Contains(method, "(") {
+		sig := strings.ReplaceAll(method, " ", "")
+		for _, m := range a.Methods {
+			if m.Sig == sig {
+				return m, nil
+			}
+		}
+		return abi.Method{}, fmt.Errorf("method %s not found in abi", method)
+	}
+
+	// 重载方法在 go-ethereum 中被重命名为 name0、name1…，按原始名称匹配时要求唯一
+	var found []abi.Method
+	for _, m := range a.Methods {
+		if m.RawName == method {
+			found = append(found, m)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return abi.Method{}, fmt.Errorf("method %s not found in abi", method)
+	case 1:
+		return found[0], nil
+	}
+	sigs := make([]string, len(found))
+	for i, m := range found {
+		sigs[i] = m.Sig
+	}
+	return abi.Method{}, fmt.Errorf("method %s is overloaded, use the full signature: %s", method, strings.Join(sigs, ", "))
+}
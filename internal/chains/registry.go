@@ -0,0 +1,192 @@
+// Package chains is the chain registry built from the Chains section of the
+// config. Every chain-specific lookup (chain IDs, RPC endpoints, explorer
+// links, native assets, DEX addresses) goes through it instead of
+// hard-coded maps.
+package chains
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"demo/internal/config"
+	"demo/internal/constant"
+)
+
+// Family is the chain family; it decides which wallet type and which
+// transaction implementation a chain uses.
+type Family string
+
+const (
+	FamilyEVM    Family = "evm"
+	FamilyBTC    Family = "btc"
+	FamilySolana Family = "solana"
+)
+
+// Chain is a configured chain together with its registry key.
+type Chain struct {
+	Key string
+	config.ChainConf
+}
+
+// Family returns the chain family, defaulting to evm.
+func (c *Chain) Family() Family {
+	if c.ChainConf.Family == "" {
+		return FamilyEVM
+	}
+	return Family(strings.ToLower(c.ChainConf.Family))
+}
+
+func (c *Chain) IsEVM() bool    { return c.Family() == FamilyEVM }
+func (c *Chain) IsBTC() bool    { return c.Family() == FamilyBTC }
+func (c *Chain) IsSolana() bool { return c.Family() == FamilySolana }
+
+// WalletType returns the wallets.chain_type used by this chain's family.
+func (c *Chain) WalletType() constant.Chain {
+	switch c.Family() {
+	case FamilyBTC:
+		return constant.ChainBTC
+	case FamilySolana:
+		return constant.ChainSOLANA
+	default:
+		return constant.ChainEVM
+	}
+}
+
+// LifiId returns the chain ID used by the LI.FI API.
+func (c *Chain) LifiId() int64 {
+	if c.LifiChainId != 0 {
+		return c.LifiChainId
+	}
+	return c.ChainId
+}
+
+// DisplayName returns the human readable chain name.
+func (c *Chain) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Key
+}
+
+// ExplorerTx returns the explorer link for a transaction, or "" if the chain
+// has no explorer configured.
+func (c *Chain) ExplorerTx(txHash string) string {
+	return expand(c.ExplorerTxUrl, txHash)
+}
+
+// ExplorerAddress returns the explorer link for an address.
+func (c *Chain) ExplorerAddress(address string) string {
+	return expand(c.ExplorerAddressUrl, address)
+}
+
+// expand fills a template that contains either %s or {}.
+func expand(template, value string) string {
+	switch {
+	case template == "":
+		return ""
+	case strings.Contains(template, "%s"):
+		return fmt.Sprintf(template, value)
+	case strings.Contains(template, "{}"):
+		return strings.ReplaceAll(template, "{}", value)
+	default:
+		return strings.TrimRight(template, "/") + "/" + value
+	}
+}
+
+// Registry indexes the configured chains by key, alias and chain ID.
+type Registry struct {
+	chains   []*Chain
+	byName   map[string]*Chain
+	byId     map[int64]*Chain
+	byLifiId map[int64]*Chain
+}
+
+// NewRegistry builds a registry from config. Keys and aliases are matched
+// case-insensitively and must be unique, as must chain IDs.
+func NewRegistry(confs map[string]config.ChainConf) (*Registry, error) {
+	r := &Registry{
+		byName:   make(map[string]*Chain),
+		byId:     make(map[int64]*Chain),
+		byLifiId: make(map[int64]*Chain),
+	}
+
+	keys := make([]string, 0, len(confs))
+	for key := range confs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		c := &Chain{Key: key, ChainConf: confs[key]}
+		switch c.Family() {
+		case FamilyEVM, FamilyBTC, FamilySolana:
+		default:
+			return nil, fmt.Errorf("chain %s: unknown family %q", key, c.ChainConf.Family)
+		}
+		if c.GasStation.Wallet != "" && !c.IsEVM() {
+			return nil, fmt.Errorf("chain %s: GasStation is only supported on EVM chains", key)
+		}
+
+		for _, name := range append([]string{key}, c.Aliases...) {
+			lower := strings.ToLower(name)
+			if other, dup := r.byName[lower]; dup {
+				return nil, fmt.Errorf("chain %s: name %q is already used by %s", key, name, other.Key)
+			}
+			r.byName[lower] = c
+		}
+		if other, dup := r.byId[c.ChainId]; dup {
+			return nil, fmt.Errorf("chain %s: chain id %d is already used by %s", key, c.ChainId, other.Key)
+		}
+		r.byId[c.ChainId] = c
+		if other, dup := r.byLifiId[c.LifiId()]; dup {
+			return nil, fmt.Errorf("chain %s: lifi chain id %d is already used by %s", key, c.LifiId(), other.Key)
+		}
+		r.byLifiId[c.LifiId()] = c
+		r.chains = append(r.chains, c)
+	}
+	return r, nil
+}
+
+// Get finds a chain by key, alias or decimal chain ID.
+func (r *Registry) Get(name string) (*Chain, bool) {
+	if c, ok := r.byName[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return c, true
+	}
+	if id, err := strconv.ParseInt(name, 10, 64); err == nil {
+		return r.ByChainId(id)
+	}
+	return nil, false
+}
+
+// ByChainId finds a chain by its native chain ID.
+func (r *Registry) ByChainId(id int64) (*Chain, bool) {
+	c, ok := r.byId[id]
+	return c, ok
+}
+
+// ByLifiChainId finds a chain by its LI.FI chain ID, falling back to the
+// native chain ID.
+func (r *Registry) ByLifiChainId(id int64) (*Chain, bool) {
+	if c, ok := r.byLifiId[id]; ok {
+		return c, true
+	}
+	return r.ByChainId(id)
+}
+
+// All returns every chain sorted by key.
+func (r *Registry) All() []*Chain {
+	return r.chains
+}
+
+// ByFamily returns the chains of one family sorted by key.
+func (r *Registry) ByFamily(f Family) []*Chain {
+	var result []*Chain
+	for _, c := range r.chains {
+		if c.Family() == f {
+			result = append(result, c)
+		}
+	}
+	return result
+}
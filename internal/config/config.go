@@ -2,20 +2,289 @@ package config
 
 import "github.com/zeromicro/go-zero/rest"
 
+// ChainConf describes one chain in the registry (see internal/chains).
+// Explorer templates contain %s (or {}) for the tx hash / address.
 type ChainConf struct {
-	Name    string `json:"Name"`
-	RpcUrl  string `json:"RpcUrl"`
-	ChainId int64  `json:"ChainId"`
+	Name   string `json:"Name"`
+	Family string `json:"Family,default=evm,options=evm|btc|solana"`
+	// Aliases are extra names accepted for this chain (e.g. "SOL" for Solana).
+	Aliases []string `json:"Aliases,optional"`
+	ChainId int64    `json:"ChainId"`
+	// LifiChainId is the chain ID used by LI.FI when it differs from ChainId.
+	LifiChainId int64  `json:"LifiChainId,optional"`
+	RpcUrl      string `json:"RpcUrl"`
+	// RpcUrls are backup endpoints; the RPC pool fails over to them.
+	RpcUrls            []string `json:"RpcUrls,optional"`
+	WsUrl              string   `json:"WsUrl,optional"` // 配置后启动该链的区块监控
+	ExplorerTxUrl      string   `json:"ExplorerTxUrl,optional"`
+	ExplorerAddressUrl string   `json:"ExplorerAddressUrl,optional"`
+	NativeSymbol       string   `json:"NativeSymbol"`
+	NativeDecimals     int      `json:"NativeDecimals,default=18"`
+	// WrappedNative and DexRouter are used by the native (non LI.FI) swap on testnets.
+	WrappedNative string `json:"WrappedNative,optional"`
+	DexRouter     string `json:"DexRouter,optional"`
+	// Disperse is a disperse.app compatible contract used by /transaction/batch_send;
+	// without it batch payments are sent one transaction per item.
+	Disperse string `json:"Disperse,optional"`
+	// Permit2Proxy is the LI.FI Permit2Proxy contract; when set, ERC20 swaps and bridges
+	// through LI.FI use an EIP-2612 or Permit2 signature instead of an approve transaction.
+	Permit2Proxy string `json:"Permit2Proxy,optional"`
+	// Multicall is the Multicall3 contract used to batch contract reads; empty means the
+	// canonical deployment at 0xcA11bde05977b3631167028862bE2a173976CA11.
+	Multicall string `json:"Multicall,optional"`
+	// SafeMultiSend is the Safe MultiSendCallOnly contract used to batch Safe transactions;
+	// empty means the canonical v1.3.0 deployment at 0x40A2aCCbd92BCA938b02010E17A5b8929b49130D.
+	SafeMultiSend string `json:"SafeMultiSend,optional"`
+	Testnet       bool   `json:"Testnet,optional"`
+	// Confirmations is how many blocks deep a block must be before the monitor emits its
+	// events and matches payments; 1 processes a block as soon as it arrives.
+	Confirmations int `json:"Confirmations,default=1"`
+	// Legacy forces legacy (type 0) transactions even if the chain reports a base fee.
+	Legacy bool `json:"Legacy,optional"`
+	// MaxFeeGwei caps the fee per gas paid on this chain; 0 disables the cap.
+	MaxFeeGwei float64 `json:"MaxFeeGwei,optional"`
+	// FeeCapPolicy decides what happens when the market fee is above MaxFeeGwei:
+	// reject fails the request, queue waits up to GasOracle.QueueTimeout for fees to drop.
+	FeeCapPolicy string `json:"FeeCapPolicy,default=reject,options=reject|queue"`
+	// Tokens are the tracked tokens whose balances are reported by /wallet/balances.
+	Tokens []TokenConf `json:"Tokens,optional"`
+	// Nfts are the tracked NFT collections whose holdings are reported by /wallet/balances.
+	Nfts []NftConf `json:"Nfts,optional"`
+	// SmartAccount enables ERC-4337 smart accounts on this chain when BundlerUrl is set.
+	SmartAccount SmartAccountConf `json:"SmartAccount,optional"`
+	// GasStation tops up native gas for wallets that hold tokens but cannot pay the fee (EVM only).
+	GasStation GasStationConf `json:"GasStation,optional"`
+}
+
+// GasStationConf configures the gas wallet that sends native currency to managed wallets
+// lacking gas before an ERC20 send, approve, swap, bridge or sweep.
+type GasStationConf struct {
+	// Wallet is a managed wallet funded with native currency; empty disables the gas station.
+	Wallet string `json:"Wallet,optional"`
+	// DailyLimit caps the native currency sent to one wallet per UTC day, in display units
+	// (e.g. "0.01"); empty means no limit.
+	DailyLimit     string `json:"DailyLimit,optional"`
+	MarginPercent  int64  `json:"MarginPercent,default=20"`   // 在估算手续费基础上多补的比例，避免广播前手续费上涨
+	ConfirmTimeout int64  `json:"ConfirmTimeout,default=120"` // 等待补充交易确认的最长时间（秒）
+}
+
+// SmartAccountConf configures ERC-4337 (EntryPoint v0.7) SimpleAccount smart accounts.
+type SmartAccountConf struct {
+	BundlerUrl string `json:"BundlerUrl,optional"`
+	// EntryPoint and Factory default to the canonical EntryPoint v0.7 and SimpleAccountFactory deployments.
+	EntryPoint string `json:"EntryPoint,optional"`
+	Factory    string `json:"Factory,optional"`
+	// PaymasterUrl is an ERC-7677 paymaster service used when a request asks for gas sponsorship;
+	// PaymasterContext is passed to it as is (e.g. a sponsorship policy id).
+	PaymasterUrl     string            `json:"PaymasterUrl,optional"`
+	PaymasterContext map[string]string `json:"PaymasterContext,optional"`
+}
+
+// TokenConf describes a tracked token (ERC20 contract or SPL mint).
+type TokenConf struct {
+	Symbol   string `json:"Symbol"`
+	Address  string `json:"Address"`
+	Decimals int    `json:"Decimals"`
+}
+
+// NftConf describes a tracked ERC-721 / ERC-1155 collection.
+type NftConf struct {
+	Name     string `json:"Name,optional"`
+	Address  string `json:"Address"`
+	Standard string `json:"Standard,options=erc721|erc1155"`
+	// TokenIds are the ERC-1155 ids to report; ERC-721 holdings are enumerated on chain
+	// when the collection supports ERC721Enumerable, otherwise only the count is reported.
+	TokenIds []string `json:"TokenIds,optional"`
+}
+
+// OutboxConf configures the TokenEvent outbox relay.
+type OutboxConf struct {
+	Sink         string `json:",default=log,options=log|webhook"` // log: 模拟 Kafka 打印; webhook: HTTP POST
+	WebhookUrl   string `json:",optional"`
+	BatchSize    int    `json:",default=100"`
+	PollInterval int64  `json:",default=2"`  // 秒
+	MaxAttempts  int    `json:",default=10"` // 超过后标记为 dead
+}
+
+// GasOracleConf configures the background fee sampler of EVM chains.
+type GasOracleConf struct {
+	Interval      int64 `json:",default=12"` // 采样间隔（秒）
+	HistoryBlocks int   `json:",default=20"` // eth_feeHistory 采样的区块数
+	MaxAge        int64 `json:",default=60"` // 缓存超过该时长视为过期，使用时同步刷新（秒）
+	QueueTimeout  int64 `json:",default=20"` // FeeCapPolicy=queue 时最长等待（秒），需小于路由超时
+}
+
+// MulticallConf configures how EVM contract reads are batched through Multicall3.
+type MulticallConf struct {
+	MaxCalls int    `json:",default=300"`      // 单次 aggregate3 的最大调用数
+	MaxGas   uint64 `json:",default=30000000"` // 单次 aggregate3 的 gas 估算上限，需低于节点的 eth_call gas 上限
+	Parallel int    `json:",default=4"`        // 同时执行的分块数
+}
+
+// PolicyConf configures the checks applied before the service signs anything on behalf of a wallet.
+type PolicyConf struct {
+	MaxMessageBytes int `json:",default=8192"` // /wallet/sign_message 单条消息的最大字节数
+	// AllowBlindSign allows personal_sign of a bare 32-byte hash, whose meaning cannot be reviewed.
+	AllowBlindSign bool `json:",optional"`
+	// DeniedTypedData lists EIP-712 primary types that are never signed, e.g. Permit or PermitSingle.
+	DeniedTypedData []string `json:",optional"`
+	// TypedDataContracts restricts the EIP-712 verifyingContract; empty allows any contract.
+	TypedDataContracts []string `json:",optional"`
+	// Contracts allowlists /transaction/contract_call targets; empty denies every contract call.
+	Contracts []ContractRule `json:",optional"`
+	// Scheduled is checked before every execution of a scheduled transfer or swap.
+	Scheduled ScheduledPolicy `json:",optional"`
+	// GasTopUp is checked before the gas station sends native currency to a wallet.
+	GasTopUp GasTopUpPolicy `json:",optional"`
+}
+
+// GasTopUpPolicy limits what a single gas station top-up may send.
+type GasTopUpPolicy struct {
+	// Limits caps one top-up per chain, with Token set to the native symbol; chains without a limit are not capped.
+	Limits []AmountLimit `json:",optional"`
+}
+
+// ScheduledPolicy limits what one execution of a scheduled transfer or swap may do.
+type ScheduledPolicy struct {
+	// Recipients allowlists the to_address of scheduled executions; empty allows any recipient.
+	Recipients []string `json:",optional"`
+	// Limits caps the amount of a single execution per token; tokens without a limit are not capped.
+	Limits []AmountLimit `json:",optional"`
+	// ApprovalUrl receives every execution as a JSON POST before it is broadcast; only a 2xx
+	// response with {"approved": true} lets it proceed. Empty skips the approval step.
+	ApprovalUrl     string `json:",optional"`
+	ApprovalTimeout int64  `json:",default=10"` // 等待审批接口响应的超时（秒）
+}
+
+// AmountLimit caps the amount of one token moved by a single request.
+type AmountLimit struct {
+	Chain string `json:",optional"` // 为空时匹配所有链
+	// Token is a symbol or address; the native currency is matched by its symbol (e.g. BNB).
+	Token     string
+	MaxAmount string // 按代币精度的金额，如 "1000"
+}
+
+// ContractRule allows calls to one contract, optionally limited to a chain and a set of methods.
+type ContractRule struct {
+	Chain   string `json:",optional"` // 为空时匹配所有链
+	Address string
+	// Methods lists method names, full signatures or 0x selectors; empty allows every method.
+	Methods []string `json:",optional"`
+}
+
+// AbiConf registers a contract ABI under a name usable as abi_name in requests.
+// Path points to a JSON ABI or a Hardhat/Foundry artifact; Abi holds the JSON inline.
+type AbiConf struct {
+	Name string
+	Path string `json:",optional"`
+	Abi  string `json:",optional"`
+}
+
+// TokenRegistryConf configures the token metadata registry (internal/tokens).
+type TokenRegistryConf struct {
+	// SeedFromLifi imports the LI.FI token list of the configured mainnets at startup,
+	// making their symbols resolvable (e.g. "USDC" on ETH) without listing them in Chains.Tokens.
+	SeedFromLifi bool `json:",default=true"`
+}
+
+// SweepConf configures treasury sweeps that consolidate the balances of managed
+// (deposit) wallets into treasury addresses.
+type SweepConf struct {
+	CheckInterval int64       `json:",default=60"` // 调度检查间隔（秒）
+	Rules         []SweepRule `json:",optional"`
+}
+
+// SweepRule sweeps one token on one chain from every managed wallet of that chain into Destination.
+type SweepRule struct {
+	Name        string
+	Chain       string
+	Token       string `json:",optional"` // 代币符号或地址，为空表示原生币
+	Destination string
+	// MinAmount is the threshold in display units (e.g. "10" for 10 USDT); smaller balances stay in place.
+	MinAmount string `json:",default=0"`
+	// Interval schedules the rule every Interval seconds; 0 means it only runs through /sweep/run.
+	Interval int64 `json:",optional"`
+	// FeeLevel applies to the sweep transfers. Token holders without enough native currency
+	// for the fee are topped up by the chain's GasStation, or skipped when it has none.
+	FeeLevel string `json:",optional,options=slow|normal|fast"`
+	// NativePrice is the value of one native coin in Token (e.g. "600" USDT per BNB). When set, token
+	// sweeps whose transfer fee plus gas top-up fee is worth at least the balance are skipped as dust;
+	// without it MinAmount is the only guard for tokens.
+	NativePrice string `json:",optional"`
+}
+
+// ScheduleConf configures the scheduler that executes stored transfer intents
+// (one-off at a given time or recurring on a cron expression).
+type ScheduleConf struct {
+	CheckInterval int64 `json:",default=30"`  // 调度检查间隔（秒）
+	MisfireGrace  int64 `json:",default=300"` // 超过预定时间该秒数仍未执行视为错过，按 catch_up 策略处理
+	MaxCatchUp    int   `json:",default=10"`  // catch_up=all 时一次最多补执行的次数，其余记为跳过
+	// MaxFailures pauses a schedule after that many consecutive failed executions; 0 never pauses.
+	MaxFailures int   `json:",default=3"`
+	ExecTimeout int64 `json:",default=120"` // 单次执行（含 gas 补充与 swap 报价）的超时（秒）
+}
+
+// RpcPoolConf configures the per-chain RPC client pool.
+type RpcPoolConf struct {
+	HealthInterval int64 `json:",default=15"` // 健康检查间隔（秒）
+	MaxLag         int64 `json:",default=5"`  // 落后最高节点超过该区块（slot）数视为不健康
+	MaxConcurrent  int   `json:",default=32"` // 单个节点的最大并发请求数
+	Timeout        int64 `json:",default=15"` // 单次请求等待响应头的超时（秒）
+	MaxFailures    int   `json:",default=3"`  // 连续失败达到该次数后摘除，等待健康检查恢复
 }
 
 type Config struct {
 	rest.RestConf
-	Postgres struct {
-		DSN string
+	// Database selects the storage backend. Driver is "postgres" or "sqlite";
+	// for sqlite the DSN is a file path such as "data/demo.db".
+	Database struct {
+		Driver      string `json:",default=postgres,options=postgres|sqlite"`
+		DSN         string `json:",optional"`
+		AutoMigrate bool   `json:",optional"` // 启动时自动执行数据库迁移
 	}
+	// Postgres is kept for existing config files; Database.DSN takes precedence.
+	Postgres struct {
+		DSN string `json:",optional"`
+	} `json:",optional"`
 	Lifi struct {
 		ApiUrl string
 	}
+	// Idempotency controls how long Idempotency-Key results are kept (seconds)
+	// and how long a duplicate request waits for the first one to finish.
+	// A running request refreshes its key every Lease/3 seconds; a key not refreshed
+	// for Lease seconds (e.g. the process crashed) is taken over by the next request.
+	Idempotency struct {
+		Ttl         int64 `json:",default=86400"`
+		WaitTimeout int64 `json:",default=25"`
+		Lease       int64 `json:",default=120"`
+	}
+	// Outbox configures the relay that publishes monitored TokenEvents.
+	Outbox OutboxConf
+	// RpcPool configures failover and health checks of chain RPC endpoints.
+	RpcPool RpcPoolConf
+	// GasOracle configures cached fee estimates and fee caps of EVM chains.
+	GasOracle GasOracleConf
+	// Multicall configures batched contract reads (allowances, balances, token metadata).
+	Multicall MulticallConf
+	// TokenRegistry configures token metadata lookups and symbol resolution.
+	TokenRegistry TokenRegistryConf
+	// Policy configures the checks and audit logging of signing requests.
+	Policy PolicyConf
+	// Sweep configures scheduled consolidation of deposit wallet balances into treasury addresses.
+	Sweep SweepConf
+	// Schedule configures scheduled and recurring transfers created through /schedule/create.
+	Schedule ScheduleConf
+	// Abis registers contract ABIs in addition to the builtin erc20, erc721 and erc1155.
+	Abis []AbiConf `json:",optional"`
 	// Chains maps a chain name (e.g., "BSC") to its configuration.
 	Chains map[string]ChainConf
 }
+
+// DatabaseDSN returns the DSN for the configured driver, falling back to the
+// legacy Postgres.DSN setting.
+func (c Config) DatabaseDSN() string {
+	if c.Database.DSN != "" {
+		return c.Database.DSN
+	}
+	return c.Postgres.DSN
+}
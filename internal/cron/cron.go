@@ -0,0 +1,245 @@
+// Package cron 解析标准 5 段 cron 表达式（分 时 日 月 周）并计算下一次触发时间
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 解析后的 cron 表达式，每段用位图表示允许的取值
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// 日和周中有一段为 * 时两者取交集，否则任一满足即可（与 vixie cron 一致）
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minutes = bounds{0, 59, nil}
+	hours   = bounds{0, 23, nil}
+	doms    = bounds{1, 31, nil}
+	months  = bounds{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 周日可以写作 0 或 7
+	dows = bounds{0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxSearchYears Next 最多向后查找的年数，用于识别永远不会触发的表达式（如 2 月 30 日）
+const maxSearchYears = 5
+
+// Parse 解析 cron 表达式，支持 *、列表、范围、步长、月份和星期的英文缩写以及 @daily 等描述符
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cron descriptor: %s", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression needs 5 fields (minute hour day-of-month month day-of-week), got %d: %q", len(fields), spec)
+	}
+
+	s := &Schedule{
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	for i, target := range []struct {
+		bits *uint64
+		b    bounds
+		name string
+	}{
+		{&s.minute, minutes, "minute"},
+		{&s.hour, hours, "hour"},
+		{&s.dom, doms, "day-of-month"},
+		{&s.month, months, "month"},
+		{&s.dow, dows, "day-of-week"},
+	} {
+		if *target.bits, err = parseField(fields[i], target.b); err != nil {
+			return nil, fmt.Errorf("invalid %s field %q: %w", target.name, fields[i], err)
+		}
+	}
+	// 7 与 0 同为周日
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	return s, nil
+}
+
+// parseField 解析一段，逗号分隔的每一项为 *、n、a-b，均可带 /step
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		var lo, hi int
+		switch {
+		case rangePart == "*":
+			lo, hi = b.min, b.max
+		case strings.Contains(rangePart, "-"):
+			ends := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = parseValue(ends[0], b); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(ends[1], b); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("range %q is reversed", rangePart)
+			}
+		default:
+			v, err := parseValue(rangePart, b)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			// a/n 表示从 a 开始到最大值每隔 n
+			if step > 1 {
+				hi = b.max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, b bounds) (int, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, b.min, b.max)
+	}
+	return v, nil
+}
+
+// Next 返回严格晚于 t 的下一次触发时间（按 t 所在时区计算，精确到分钟）；
+// 5 年内都不会触发时返回零值。夏令时结束时重复出现的本地时间只触发第一次
+func (s *Schedule) Next(t time.Time) time.Time {
+	for {
+		t = s.next(t)
+		if t.IsZero() || !repeated(t) {
+			return t
+		}
+	}
+}
+
+func (s *Schedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + maxSearchYears
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		t = forward(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !s.dayMatches(t) {
+		t = forward(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		prev := t
+		t = forward(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+		if s.skippedHourMatches(prev, t) {
+			return t
+		}
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		prev := t
+		t = t.Add(time.Minute)
+		if s.skippedHourMatches(prev, t) {
+			return t
+		}
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	return t
+}
+
+// skippedHourMatches 从 prev 推进到 t 时跳过了夏令时开始的整点，且被跳过的小时在表达式中；
+// 与 vixie cron 一致，这类任务在时钟拨快后立即执行而不是错过
+func (s *Schedule) skippedHourMatches(prev, t time.Time) bool {
+	if prev.Day() != t.Day() {
+		return false
+	}
+	for h := prev.Hour() + 1; h < t.Hour(); h++ {
+		if s.hour&(1<<uint(h)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// repeated t 的本地时间在一小时前已经出现过（夏令时结束回拨）
+func repeated(t time.Time) bool {
+	prev := t.Add(-time.Hour)
+	return prev.Hour() == t.Hour() && prev.Minute() == t.Minute() && prev.Day() == t.Day()
+}
+
+// forward 夏令时跳过的本地时间会被 time.Date 换算到更早的时刻，此时改为向后推进一小时，保证时间单调递增
+func forward(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(time.Hour)
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,156 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+	utc := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	ny := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04 MST", s, newYork)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "weekdays at 9 skip the weekend",
+			spec: "0 9 * * 1-5",
+			from: utc("2026-10-16 10:00"), // 周五
+			want: []time.Time{utc("2026-10-19 09:00"), utc("2026-10-20 09:00"), utc("2026-10-21 09:00")},
+		},
+		{
+			name: "weekday names",
+			spec: "0 9 * * mon-fri",
+			from: utc("2026-10-18 10:07"),
+			want: []time.Time{utc("2026-10-19 09:00"), utc("2026-10-20 09:00")},
+		},
+		{
+			name: "every 15 minutes",
+			spec: "*/15 * * * *",
+			from: utc("2026-10-18 10:07"),
+			want: []time.Time{utc("2026-10-18 10:15"), utc("2026-10-18 10:30"), utc("2026-10-18 10:45"), utc("2026-10-18 11:00")},
+		},
+		{
+			name: "strictly after a matching time",
+			spec: "*/15 * * * *",
+			from: utc("2026-10-18 10:15"),
+			want: []time.Time{utc("2026-10-18 10:30")},
+		},
+		{
+			name: "monthly from the 31st",
+			spec: "@monthly",
+			from: utc("2026-01-31 00:00"),
+			want: []time.Time{utc("2026-02-01 00:00"), utc("2026-03-01 00:00"), utc("2026-04-01 00:00")},
+		},
+		{
+			name: "the 31st skips shorter months",
+			spec: "0 0 31 * *",
+			from: utc("2026-01-31 00:00"),
+			want: []time.Time{utc("2026-03-31 00:00"), utc("2026-05-31 00:00"), utc("2026-07-31 00:00")},
+		},
+		{
+			name: "monthly across the year end",
+			spec: "0 0 1 * *",
+			from: utc("2026-10-18 10:07"),
+			want: []time.Time{utc("2026-11-01 00:00"), utc("2026-12-01 00:00"), utc("2027-01-01 00:00")},
+		},
+		{
+			name: "leap day",
+			spec: "0 0 29 2 *",
+			from: utc("2026-10-18 10:07"),
+			want: []time.Time{utc("2028-02-29 00:00")},
+		},
+		{
+			name: "never fires",
+			spec: "0 0 30 2 *",
+			from: utc("2026-10-18 10:07"),
+			want: []time.Time{{}},
+		},
+		{
+			name: "step from an offset with month names",
+			spec: "5/20 8-10 * jan,oct *",
+			from: utc("2026-10-18 10:07"),
+			want: []time.Time{utc("2026-10-18 10:25"), utc("2026-10-18 10:45"), utc("2026-10-19 08:05")},
+		},
+		{
+			name: "spring forward runs a skipped time right after the gap",
+			spec: "30 2 * * *",
+			from: ny("2026-03-07 23:10 EST"),
+			want: []time.Time{ny("2026-03-08 03:00 EDT"), ny("2026-03-09 02:30 EDT")},
+		},
+		{
+			name: "hourly across spring forward",
+			spec: "0 * * * *",
+			from: ny("2026-03-07 23:10 EST"),
+			want: []time.Time{ny("2026-03-08 00:00 EST"), ny("2026-03-08 01:00 EST"), ny("2026-03-08 03:00 EDT")},
+		},
+		{
+			name: "fall back fires a repeated time once",
+			spec: "30 1 * * *",
+			from: ny("2026-10-31 23:10 EDT"),
+			want: []time.Time{ny("2026-11-01 01:30 EDT"), ny("2026-11-02 01:30 EST")},
+		},
+		{
+			name: "hourly across fall back",
+			spec: "0 * * * *",
+			from: ny("2026-10-31 23:10 EDT"),
+			want: []time.Time{ny("2026-11-01 00:00 EDT"), ny("2026-11-01 01:00 EDT"), ny("2026-11-01 02:00 EST")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			at := tt.from
+			for i, want := range tt.want {
+				at = s.Next(at)
+				if !at.Equal(want) {
+					t.Fatalf("run %d: got %s, want %s", i, at, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@every5m",
+	}
+	for _, spec := range tests {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q): expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package erc4337
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SimpleAccountFactoryV07 eth-infinitism SimpleAccountFactory（EntryPoint v0.7）的部署地址
+const SimpleAccountFactoryV07 = "0x91E60e0613810449d098b0b5Ec8b51A0FE8c8985"
+
+var (
+	factoryABI = mustParseABI(`[
+		{"name":"createAccount","type":"function","stateMutability":"nonpayable","inputs":[{"name":"owner","type":"address"},{"name":"salt","type":"uint256"}],"outputs":[{"name":"","type":"address"}]},
+		{"name":"getAddress","type":"function","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"salt","type":"uint256"}],"outputs":[{"name":"","type":"address"}]}
+	]`)
+	accountABI = mustParseABI(`[
+		{"name":"execute","type":"function","stateMutability":"nonpayable","inputs":[{"name":"dest","type":"address"},{"name":"value","type":"uint256"},{"name":"func","type":"bytes"}],"outputs":[]},
+		{"name":"executeBatch","type":"function","stateMutability":"nonpayable","inputs":[{"name":"dest","type":"address[]"},{"name":"value","type":"uint256[]"},{"name":"func","type":"bytes[]"}],"outputs":[]}
+	]`)
+	entryPointABI = mustParseABI(`[
+		{"name":"getNonce","type":"function","stateMutability":"view","inputs":[{"name":"sender","type":"address"},{"name":"key","type":"uint192"}],"outputs":[{"name":"nonce","type":"uint256"}]}
+	]`)
+)
+
+func mustParseABI(raw string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Call 智能账户执行的一个调用
+type Call struct {
+	To    common.Address
+	Value *big.Int
+	Data  []byte
+}
+
+// ExecuteCallData 单个调用编码为 execute，多个调用编码为 executeBatch（在一个 UserOperation 中原子执行）
+func ExecuteCallData(calls []Call) ([]byte, error) {
+	switch len(calls) {
+	case 0:
+		return nil, errors.New("no calls to execute")
+	case 1:
+		value := calls[0].Value
+		if value == nil {
+			value = new(big.Int)
+		}
+		return accountABI.Pack("execute", calls[0].To, value, calls[0].Data)
+	}
+	dest := make([]common.Address, len(calls))
+	values := make([]*big.Int, len(calls))
+	data := make([][]byte, len(calls))
+	for i, c := range calls {
+		dest[i] = c.To
+		values[i] = c.Value
+		if values[i] == nil {
+			values[i] = new(big.Int)
+		}
+		data[i] = c.Data
+		if data[i] == nil {
+			data[i] = []byte{}
+		}
+	}
+	return accountABI.Pack("executeBatch", dest, values, data)
+}
+
+// FactoryData 部署账户的 createAccount(owner, salt) 调用数据，作为首个 UserOperation 的 factoryData
+func FactoryData(owner common.Address, salt *big.Int) ([]byte, error) {
+	return factoryABI.Pack("createAccount", owner, salt)
+}
+
+// CounterfactualAddress 通过工厂合约的 getAddress(owner, salt) 计算账户地址，无需部署
+func CounterfactualAddress(ctx context.Context, caller ethereum.ContractCaller, factory, owner common.Address, salt *big.Int) (common.Address, error) {
+	data, err := factoryABI.Pack("getAddress", owner, salt)
+	if err != nil {
+		return common.Address{}, err
+	}
+	ret, err := caller.CallContract(ctx, ethereum.CallMsg{To: &factory, Data: data}, nil)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("factory getAddress failed: %w", err)
+	}
+	values, err := factoryABI.Unpack("getAddress", ret)
+	if err != nil || len(values) != 1 {
+		return common.Address{}, fmt.Errorf("factory %s returned no address, is it deployed?", factory.Hex())
+	}
+	address := values[0].(common.Address)
+	if address == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("factory %s returned the zero address", factory.Hex())
+	}
+	return address, nil
+}
+
+// GetNonce 读取 EntryPoint 中账户的 nonce（key 0）
+func GetNonce(ctx context.Context, caller ethereum.ContractCaller, entryPoint, sender common.Address) (*big.Int, error) {
+	data, err := entryPointABI.Pack("getNonce", sender, new(big.Int))
+	if err != nil {
+		return nil, err
+	}
+	ret, err := caller.CallContract(ctx, ethereum.CallMsg{To: &entryPoint, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("entry point getNonce failed: %w", err)
+	}
+	values, err := entryPointABI.Unpack("getNonce", ret)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("entry point %s returned no nonce, is it deployed?", entryPoint.Hex())
+	}
+	return values[0].(*big.Int), nil
+}
@@ -0,0 +1,107 @@
+package erc4337
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Bundler ERC-4337 bundler 的 JSON-RPC 客户端（eth_sendUserOperation 等）
+type Bundler struct {
+	url    string
+	client *rpc.Client
+}
+
+// NewBundler 创建 bundler 客户端，HTTP 连接在首次调用时建立
+func NewBundler(url string) (*Bundler, error) {
+	client, err := rpc.DialOptions(context.Background(), url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundler url %q: %w", url, err)
+	}
+	return &Bundler{url: url, client: client}, nil
+}
+
+// GasEstimate eth_estimateUserOperationGas 的结果
+type GasEstimate struct {
+	PreVerificationGas            *hexutil.Big `json:"preVerificationGas"`
+	VerificationGasLimit          *hexutil.Big `json:"verificationGasLimit"`
+	CallGasLimit                  *hexutil.Big `json:"callGasLimit"`
+	PaymasterVerificationGasLimit *hexutil.Big `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *hexutil.Big `json:"paymasterPostOpGasLimit,omitempty"`
+}
+
+// Receipt eth_getUserOperationReceipt 的结果，Receipt 为打包该操作的链上交易
+type Receipt struct {
+	UserOpHash    common.Hash    `json:"userOpHash"`
+	Sender        common.Address `json:"sender"`
+	Nonce         *hexutil.Big   `json:"nonce"`
+	Success       bool           `json:"success"`
+	Reason        string         `json:"reason,omitempty"`
+	ActualGasCost *hexutil.Big   `json:"actualGasCost"`
+	ActualGasUsed *hexutil.Big   `json:"actualGasUsed"`
+	Receipt       struct {
+		TransactionHash common.Hash  `json:"transactionHash"`
+		BlockNumber     *hexutil.Big `json:"blockNumber"`
+	} `json:"receipt"`
+}
+
+// SupportedEntryPoints 返回 bundler 支持的 EntryPoint 地址
+func (b *Bundler) SupportedEntryPoints(ctx context.Context) ([]common.Address, error) {
+	var result []common.Address
+	if err := b.client.CallContext(ctx, &result, "eth_supportedEntryPoints"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EstimateGas 估算 UserOperation 的各项 gas，签名字段需为占位签名
+func (b *Bundler) EstimateGas(ctx context.Context, op *UserOperation, entryPoint common.Address) (*GasEstimate, error) {
+	var result GasEstimate
+	if err := b.client.CallContext(ctx, &result, "eth_estimateUserOperationGas", op, entryPoint); err != nil {
+		return nil, err
+	}
+	if result.PreVerificationGas == nil || result.VerificationGasLimit == nil || result.CallGasLimit == nil {
+		return nil, fmt.Errorf("incomplete gas estimate from bundler %s", b.url)
+	}
+	return &result, nil
+}
+
+// Send 提交已签名的 UserOperation，返回 userOpHash
+func (b *Bundler) Send(ctx context.Context, op *UserOperation, entryPoint common.Address) (common.Hash, error) {
+	var result common.Hash
+	if err := b.client.CallContext(ctx, &result, "eth_sendUserOperation", op, entryPoint); err != nil {
+		return common.Hash{}, err
+	}
+	return result, nil
+}
+
+// GetReceipt 查询 UserOperation 的执行结果，尚未打包时返回 nil
+func (b *Bundler) GetReceipt(ctx context.Context, userOpHash common.Hash) (*Receipt, error) {
+	var result *Receipt
+	if err := b.client.CallContext(ctx, &result, "eth_getUserOperationReceipt", userOpHash); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ApplyGasEstimate 写入估算结果；调用和验证 gas 上浮 20%，preVerificationGas 按原值
+func (op *UserOperation) ApplyGasEstimate(est *GasEstimate) {
+	op.PreVerificationGas = est.PreVerificationGas.ToInt()
+	op.VerificationGasLimit = withBuffer(est.VerificationGasLimit.ToInt())
+	op.CallGasLimit = withBuffer(est.CallGasLimit.ToInt())
+	if op.Paymaster != nil && est.PaymasterVerificationGasLimit != nil {
+		op.PaymasterVerificationGasLimit = withBuffer(est.PaymasterVerificationGasLimit.ToInt())
+	}
+	if op.Paymaster != nil && est.PaymasterPostOpGasLimit != nil {
+		op.PaymasterPostOpGasLimit = est.PaymasterPostOpGasLimit.ToInt()
+	}
+}
+
+func withBuffer(n *big.Int) *big.Int {
+	out := new(big.Int).Mul(n, big.NewInt(120))
+	return out.Div(out, big.NewInt(100))
+}
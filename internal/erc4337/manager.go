@@ -0,0 +1,89 @@
+package erc4337
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"demo/internal/chains"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Network 一条链的 ERC-4337 配置
+type Network struct {
+	ChainId    *big.Int
+	EntryPoint common.Address
+	Factory    common.Address
+	Bundler    *Bundler
+	Paymaster  Paymaster // 未配置时为 nil，不支持代付
+}
+
+// Manager 各 EVM 链的 bundler 与 paymaster，配置了 SmartAccount.BundlerUrl 的链才启用智能账户
+type Manager struct {
+	mu       sync.RWMutex
+	networks map[string]*Network
+}
+
+// NewManager 校验注册表中 EVM 链的 SmartAccount 配置
+func NewManager(registry *chains.Registry) (*Manager, error) {
+	m := &Manager{networks: make(map[string]*Network)}
+	for _, c := range registry.ByFamily(chains.FamilyEVM) {
+		conf := c.SmartAccount
+		if conf.BundlerUrl == "" {
+			continue
+		}
+		entryPoint, factory := conf.EntryPoint, conf.Factory
+		if entryPoint == "" {
+			entryPoint = EntryPointV07
+		}
+		if factory == "" {
+			factory = SimpleAccountFactoryV07
+		}
+		if !common.IsHexAddress(entryPoint) || !common.IsHexAddress(factory) {
+			return nil, fmt.Errorf("chain %s: invalid SmartAccount EntryPoint or Factory", c.Key)
+		}
+		bundler, err := NewBundler(conf.BundlerUrl)
+		if err != nil {
+			return nil, fmt.Errorf("chain %s: %w", c.Key, err)
+		}
+		n := &Network{
+			ChainId:    big.NewInt(c.ChainId),
+			EntryPoint: common.HexToAddress(entryPoint),
+			Factory:    common.HexToAddress(factory),
+			Bundler:    bundler,
+		}
+		if conf.PaymasterUrl != "" {
+			if n.Paymaster, err = NewRPCPaymaster(conf.PaymasterUrl, conf.PaymasterContext); err != nil {
+				return nil, fmt.Errorf("chain %s: %w", c.Key, err)
+			}
+		}
+		m.networks[c.Key] = n
+	}
+	return m, nil
+}
+
+// Network 返回链的 ERC-4337 配置
+func (m *Manager) Network(chain *chains.Chain) (*Network, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n, ok := m.networks[chain.Key]
+	if !ok {
+		return nil, fmt.Errorf("smart accounts are not enabled on chain %s", chain.Key)
+	}
+	return n, nil
+}
+
+// SetPaymaster 替换链的 paymaster，用于接入非 ERC-7677 的代付服务
+func (m *Manager) SetPaymaster(chain string, p Paymaster) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, ok := m.networks[chain]
+	if !ok {
+		return fmt.Errorf("smart accounts are not enabled on chain %s", chain)
+	}
+	updated := *n
+	updated.Paymaster = p
+	m.networks[chain] = &updated
+	return nil
+}
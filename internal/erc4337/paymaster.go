@@ -0,0 +1,82 @@
+package erc4337
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Paymaster gas 代付的扩展点：GetStubData 在 gas 估算前提供占位数据，
+// GetData 在估算完成、签名之前返回最终的 paymaster 字段
+type Paymaster interface {
+	GetStubData(ctx context.Context, op *UserOperation, entryPoint common.Address, chainId *big.Int) (*PaymasterData, error)
+	GetData(ctx context.Context, op *UserOperation, entryPoint common.Address, chainId *big.Int) (*PaymasterData, error)
+}
+
+// PaymasterData paymaster 返回的字段，gas 上限为空时沿用估算值
+type PaymasterData struct {
+	Paymaster                     common.Address `json:"paymaster"`
+	PaymasterData                 hexutil.Bytes  `json:"paymasterData"`
+	PaymasterVerificationGasLimit *hexutil.Big   `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *hexutil.Big   `json:"paymasterPostOpGasLimit,omitempty"`
+	Sponsor                       *struct {
+		Name string `json:"name"`
+	} `json:"sponsor,omitempty"`
+	// IsFinal 为 true 时占位数据即最终数据，无需再调用 GetData
+	IsFinal bool `json:"isFinal,omitempty"`
+}
+
+// ApplyPaymaster 写入 paymaster 字段
+func (op *UserOperation) ApplyPaymaster(d *PaymasterData) {
+	paymaster := d.Paymaster
+	op.Paymaster = &paymaster
+	op.PaymasterData = d.PaymasterData
+	if d.PaymasterVerificationGasLimit != nil {
+		op.PaymasterVerificationGasLimit = d.PaymasterVerificationGasLimit.ToInt()
+	}
+	if d.PaymasterPostOpGasLimit != nil {
+		op.PaymasterPostOpGasLimit = d.PaymasterPostOpGasLimit.ToInt()
+	}
+}
+
+// rpcPaymaster ERC-7677 paymaster 服务（pm_getPaymasterStubData / pm_getPaymasterData）
+type rpcPaymaster struct {
+	url     string
+	client  *rpc.Client
+	context map[string]string
+}
+
+// NewRPCPaymaster 创建 ERC-7677 paymaster 客户端，pmContext 原样传给服务（如赞助策略 ID）
+func NewRPCPaymaster(url string, pmContext map[string]string) (Paymaster, error) {
+	client, err := rpc.DialOptions(context.Background(), url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid paymaster url %q: %w", url, err)
+	}
+	if pmContext == nil {
+		pmContext = map[string]string{}
+	}
+	return &rpcPaymaster{url: url, client: client, context: pmContext}, nil
+}
+
+func (p *rpcPaymaster) GetStubData(ctx context.Context, op *UserOperation, entryPoint common.Address, chainId *big.Int) (*PaymasterData, error) {
+	return p.call(ctx, "pm_getPaymasterStubData", op, entryPoint, chainId)
+}
+
+func (p *rpcPaymaster) GetData(ctx context.Context, op *UserOperation, entryPoint common.Address, chainId *big.Int) (*PaymasterData, error) {
+	return p.call(ctx, "pm_getPaymasterData", op, entryPoint, chainId)
+}
+
+func (p *rpcPaymaster) call(ctx context.Context, method string, op *UserOperation, entryPoint common.Address, chainId *big.Int) (*PaymasterData, error) {
+	var result PaymasterData
+	if err := p.client.CallContext(ctx, &result, method, op, entryPoint, (*hexutil.Big)(chainId), p.context); err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	if result.Paymaster == (common.Address{}) {
+		return nil, fmt.Errorf("%s: paymaster %s returned no paymaster address", method, p.url)
+	}
+	return &result, nil
+}
@@ -0,0 +1,153 @@
+// Package erc4337 builds, hashes and submits ERC-4337 (EntryPoint v0.7) UserOperations
+// for SimpleAccount smart accounts, through a bundler and an optional paymaster.
+package erc4337
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EntryPointV07 EntryPoint v0.7 在各链上的统一部署地址
+const EntryPointV07 = "0x0000000071727De22E5E9d8BAf0edAc6f37da032"
+
+// DummySignature gas 估算阶段使用的占位签名，能通过 ECDSA.recover 而不 revert
+var DummySignature = hexutil.MustDecode("0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1c")
+
+// UserOperation v0.7 的未打包形式（bundler RPC 使用），账户未部署时 Factory 非空
+type UserOperation struct {
+	Sender                        common.Address
+	Nonce                         *big.Int
+	Factory                       *common.Address
+	FactoryData                   []byte
+	CallData                      []byte
+	CallGasLimit                  *big.Int
+	VerificationGasLimit          *big.Int
+	PreVerificationGas            *big.Int
+	MaxFeePerGas                  *big.Int
+	MaxPriorityFeePerGas          *big.Int
+	Paymaster                     *common.Address
+	PaymasterVerificationGasLimit *big.Int
+	PaymasterPostOpGasLimit       *big.Int
+	PaymasterData                 []byte
+	Signature                     []byte
+}
+
+type userOperationJSON struct {
+	Sender                        common.Address  `json:"sender"`
+	Nonce                         *hexutil.Big    `json:"nonce"`
+	Factory                       *common.Address `json:"factory,omitempty"`
+	FactoryData                   hexutil.Bytes   `json:"factoryData,omitempty"`
+	CallData                      hexutil.Bytes   `json:"callData"`
+	CallGasLimit                  *hexutil.Big    `json:"callGasLimit"`
+	VerificationGasLimit          *hexutil.Big    `json:"verificationGasLimit"`
+	PreVerificationGas            *hexutil.Big    `json:"preVerificationGas"`
+	MaxFeePerGas                  *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas          *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Paymaster                     *common.Address `json:"paymaster,omitempty"`
+	PaymasterVerificationGasLimit *hexutil.Big    `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *hexutil.Big    `json:"paymasterPostOpGasLimit,omitempty"`
+	PaymasterData                 hexutil.Bytes   `json:"paymasterData,omitempty"`
+	Signature                     hexutil.Bytes   `json:"signature"`
+}
+
+// MarshalJSON 按 bundler RPC 的格式输出，数值为 0x 十六进制，未设置的 gas 字段按 0 处理
+func (op *UserOperation) MarshalJSON() ([]byte, error) {
+	out := userOperationJSON{
+		Sender:               op.Sender,
+		Nonce:                hexBig(op.Nonce),
+		CallData:             op.CallData,
+		CallGasLimit:         hexBig(op.CallGasLimit),
+		VerificationGasLimit: hexBig(op.VerificationGasLimit),
+		PreVerificationGas:   hexBig(op.PreVerificationGas),
+		MaxFeePerGas:         hexBig(op.MaxFeePerGas),
+		MaxPriorityFeePerGas: hexBig(op.MaxPriorityFeePerGas),
+		Signature:            op.Signature,
+	}
+	if out.CallData == nil {
+		out.CallData = hexutil.Bytes{}
+	}
+	if out.Signature == nil {
+		out.Signature = hexutil.Bytes{}
+	}
+	if op.Factory != nil {
+		out.Factory = op.Factory
+		out.FactoryData = op.FactoryData
+	}
+	if op.Paymaster != nil {
+		out.Paymaster = op.Paymaster
+		out.PaymasterVerificationGasLimit = hexBig(op.PaymasterVerificationGasLimit)
+		out.PaymasterPostOpGasLimit = hexBig(op.PaymasterPostOpGasLimit)
+		out.PaymasterData = op.PaymasterData
+		if out.PaymasterData == nil {
+			out.PaymasterData = hexutil.Bytes{}
+		}
+	}
+	return json.Marshal(out)
+}
+
+func hexBig(n *big.Int) *hexutil.Big {
+	if n == nil {
+		return (*hexutil.Big)(new(big.Int))
+	}
+	return (*hexutil.Big)(n)
+}
+
+// InitCode factory 地址 + factoryData，账户已部署时为空
+func (op *UserOperation) InitCode() []byte {
+	if op.Factory == nil {
+		return nil
+	}
+	return append(op.Factory.Bytes(), op.FactoryData...)
+}
+
+// PaymasterAndData paymaster 地址 + 验证 gas（16 字节）+ postOp gas（16 字节）+ paymasterData
+func (op *UserOperation) PaymasterAndData() []byte {
+	if op.Paymaster == nil {
+		return nil
+	}
+	out := op.Paymaster.Bytes()
+	out = append(out, uint128(op.PaymasterVerificationGasLimit)...)
+	out = append(out, uint128(op.PaymasterPostOpGasLimit)...)
+	return append(out, op.PaymasterData...)
+}
+
+// Hash EntryPoint.getUserOpHash：keccak256(abi.encode(keccak256(pack(op)), entryPoint, chainId))
+func (op *UserOperation) Hash(entryPoint common.Address, chainId *big.Int) common.Hash {
+	packed := make([]byte, 0, 32*8)
+	packed = append(packed, common.LeftPadBytes(op.Sender.Bytes(), 32)...)
+	packed = append(packed, word(op.Nonce)...)
+	packed = append(packed, crypto.Keccak256(op.InitCode())...)
+	packed = append(packed, crypto.Keccak256(op.CallData)...)
+	// accountGasLimits = verificationGasLimit << 128 | callGasLimit
+	packed = append(packed, uint128(op.VerificationGasLimit)...)
+	packed = append(packed, uint128(op.CallGasLimit)...)
+	packed = append(packed, word(op.PreVerificationGas)...)
+	// gasFees = maxPriorityFeePerGas << 128 | maxFeePerGas
+	packed = append(packed, uint128(op.MaxPriorityFeePerGas)...)
+	packed = append(packed, uint128(op.MaxFeePerGas)...)
+	packed = append(packed, crypto.Keccak256(op.PaymasterAndData())...)
+
+	encoded := make([]byte, 0, 32*3)
+	encoded = append(encoded, crypto.Keccak256(packed)...)
+	encoded = append(encoded, common.LeftPadBytes(entryPoint.Bytes(), 32)...)
+	encoded = append(encoded, word(chainId)...)
+	return crypto.Keccak256Hash(encoded)
+}
+
+func word(n *big.Int) []byte {
+	if n == nil {
+		return make([]byte, 32)
+	}
+	return common.LeftPadBytes(n.Bytes(), 32)
+}
+
+func uint128(n *big.Int) []byte {
+	if n == nil {
+		return make([]byte, 16)
+	}
+	return common.LeftPadBytes(n.Bytes(), 16)
+}
@@ -0,0 +1,102 @@
+package gasoracle
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// 手续费档位
+const (
+	LevelSlow   = "slow"
+	LevelNormal = "normal"
+	LevelFast   = "fast"
+)
+
+// Levels 按从低到高排列的档位
+var Levels = []string{LevelSlow, LevelNormal, LevelFast}
+
+// levelParams 每个档位取的小费百分位、maxFee 相对 baseFee 的倍数（百分比）、legacy gasPrice 的倍数（百分比）
+var levelParams = map[string]struct {
+	percentile      float64
+	baseFeePercent  int64
+	gasPricePercent int64
+}{
+	LevelSlow:   {percentile: 10, baseFeePercent: 125, gasPricePercent: 90},
+	LevelNormal: {percentile: 50, baseFeePercent: 200, gasPricePercent: 100},
+	LevelFast:   {percentile: 90, baseFeePercent: 300, gasPricePercent: 125},
+}
+
+// rewardPercentiles eth_feeHistory 请求的百分位，顺序与 Levels 一致
+var rewardPercentiles = []float64{
+	levelParams[LevelSlow].percentile,
+	levelParams[LevelNormal].percentile,
+	levelParams[LevelFast].percentile,
+}
+
+// Fees 构建交易使用的手续费；Dynamic 为 false 时只使用 GasPrice
+type Fees struct {
+	Dynamic   bool
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+	// BaseFee 采样时下一个区块的 baseFee，legacy 或自定义手续费时为 nil
+	BaseFee *big.Int
+}
+
+// MaxGasPrice 返回每单位 gas 最多支付的价格
+func (f *Fees) MaxGasPrice() *big.Int {
+	if f.Dynamic {
+		return f.GasFeeCap
+	}
+	return f.GasPrice
+}
+
+// Required 返回交易按当前行情被打包至少需要的单价：EIP-1559 为 baseFee + 小费，legacy 为 gasPrice
+func (f *Fees) Required() *big.Int {
+	if f.Dynamic && f.BaseFee != nil {
+		return new(big.Int).Add(f.BaseFee, f.GasTipCap)
+	}
+	return f.MaxGasPrice()
+}
+
+func (f *Fees) String() string {
+	if f.Dynamic {
+		return fmt.Sprintf("EIP-1559 maxFee=%s maxPriorityFee=%s", f.GasFeeCap, f.GasTipCap)
+	}
+	return fmt.Sprintf("legacy gasPrice=%s", f.GasPrice)
+}
+
+// Snapshot 一次手续费采样结果
+type Snapshot struct {
+	Chain   string
+	Dynamic bool
+	Block   uint64
+	// BaseFee 下一个区块的 baseFee（仅 EIP-1559）
+	BaseFee *big.Int
+	// Tips 各档位的小费，取采样区块对应百分位的中位数（仅 EIP-1559）
+	Tips map[string]*big.Int
+	// GasPrice 节点建议的 gasPrice（仅 legacy）
+	GasPrice  *big.Int
+	UpdatedAt time.Time
+}
+
+// Fees 按档位计算手续费（未应用链的手续费上限）
+func (s *Snapshot) Fees(level string) (*Fees, error) {
+	params, ok := levelParams[level]
+	if !ok {
+		return nil, fmt.Errorf("invalid fee level: %s", level)
+	}
+
+	if !s.Dynamic {
+		gasPrice := new(big.Int).Mul(s.GasPrice, big.NewInt(params.gasPricePercent))
+		gasPrice.Div(gasPrice, big.NewInt(100))
+		return &Fees{GasPrice: gasPrice}, nil
+	}
+
+	tip := new(big.Int).Set(s.Tips[level])
+	feeCap := new(big.Int).Mul(s.BaseFee, big.NewInt(params.baseFeePercent))
+	feeCap.Div(feeCap, big.NewInt(100))
+	feeCap.Add(feeCap, tip)
+	return &Fees{Dynamic: true, GasTipCap: tip, GasFeeCap: feeCap, BaseFee: new(big.Int).Set(s.BaseFee)}, nil
+}
@@ -0,0 +1,69 @@
+package gasoracle
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"demo/internal/chains"
+	"demo/internal/config"
+	"demo/internal/rpcpool"
+)
+
+// Manager 持有所有 EVM 链的手续费预言机
+type Manager struct {
+	conf    config.GasOracleConf
+	oracles map[string]*Oracle
+	order   []*Oracle
+}
+
+// NewManager 为注册表中的每条 EVM 链创建预言机
+func NewManager(registry *chains.Registry, rpc *rpcpool.Manager, conf config.GasOracleConf) (*Manager, error) {
+	m := &Manager{conf: conf, oracles: make(map[string]*Oracle)}
+	for _, c := range registry.ByFamily(chains.FamilyEVM) {
+		o, err := newOracle(c, rpc, conf)
+		if err != nil {
+			return nil, err
+		}
+		m.oracles[c.Key] = o
+		m.order = append(m.order, o)
+	}
+	return m, nil
+}
+
+// Oracle 返回链对应的预言机
+func (m *Manager) Oracle(chain *chains.Chain) (*Oracle, error) {
+	o, ok := m.oracles[chain.Key]
+	if !ok {
+		return nil, fmt.Errorf("no gas oracle for chain %s", chain.Key)
+	}
+	return o, nil
+}
+
+// Oracles 按注册表顺序返回所有预言机
+func (m *Manager) Oracles() []*Oracle {
+	return m.order
+}
+
+// Start 启动后台采样，ctx 取消后退出
+func (m *Manager) Start(ctx context.Context) {
+	interval := time.Duration(m.conf.Interval) * time.Second
+	for _, o := range m.order {
+		go func(o *Oracle) {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				if _, err := o.Refresh(ctx); err != nil && ctx.Err() == nil {
+					log.Printf("⚠️  %s 手续费采样失败: %v", o.chain.Key, err)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(o)
+	}
+	log.Printf("⛽ 手续费预言机已启动，共 %d 条 EVM 链，间隔 %s", len(m.order), interval)
+}
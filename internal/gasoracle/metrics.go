@@ -0,0 +1,21 @@
+package gasoracle
+
+import "github.com/zeromicro/go-zero/core/metric"
+
+// 手续费预言机指标，开启 go-zero Prometheus 后通过 /metrics 暴露
+var (
+	metricBaseFee = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: "gas_oracle",
+		Subsystem: "chain",
+		Name:      "base_fee_gwei",
+		Help:      "next block base fee (or legacy gas price) sampled by the gas oracle.",
+		Labels:    []string{"chain"},
+	})
+	metricCapRejected = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "gas_oracle",
+		Subsystem: "chain",
+		Name:      "cap_rejected_total",
+		Help:      "transactions rejected because the fee exceeded the chain cap.",
+		Labels:    []string{"chain"},
+	})
+)
@@ -0,0 +1,271 @@
+// Package gasoracle 在后台按链采样 EVM 手续费（eth_feeHistory 的 baseFee 和小费百分位，
+// 不支持 EIP-1559 的链为节点建议的 gasPrice），交易逻辑和 /gas/estimate 共用缓存结果，
+// 并按链配置的 MaxFeeGwei 拒绝或排队超过上限的交易。
+package gasoracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"demo/internal/chains"
+	"demo/internal/config"
+	"demo/internal/rpcpool"
+	"demo/internal/units"
+)
+
+// 超过上限时的处理策略
+const (
+	PolicyReject = "reject"
+	PolicyQueue  = "queue"
+)
+
+// sampleTimeout 单次采样的超时
+const sampleTimeout = 10 * time.Second
+
+// ErrFeeCapExceeded 手续费超过链配置的上限
+var ErrFeeCapExceeded = errors.New("gas fee exceeds chain cap")
+
+// Oracle 一条 EVM 链的手续费预言机
+type Oracle struct {
+	chain  *chains.Chain
+	rpc    *rpcpool.Manager
+	conf   config.GasOracleConf
+	maxFee *big.Int // nil 表示不限制
+
+	refreshMu sync.Mutex // 同一时刻只有一个采样请求
+
+	mu      sync.RWMutex
+	snap    *Snapshot
+	lastErr error
+	updated chan struct{} // 每次采样成功后关闭并替换，唤醒排队的交易
+}
+
+func newOracle(chain *chains.Chain, rpc *rpcpool.Manager, conf config.GasOracleConf) (*Oracle, error) {
+	o := &Oracle{chain: chain, rpc: rpc, conf: conf, updated: make(chan struct{})}
+	if chain.MaxFeeGwei < 0 {
+		return nil, fmt.Errorf("chain %s: MaxFeeGwei must not be negative", chain.Key)
+	}
+	if chain.MaxFeeGwei > 0 {
+		o.maxFee, _ = new(big.Float).Mul(big.NewFloat(chain.MaxFeeGwei), big.NewFloat(1e9)).Int(nil)
+	}
+	return o, nil
+}
+
+// Chain 返回预言机对应的链
+func (o *Oracle) Chain() *chains.Chain {
+	return o.chain
+}
+
+// MaxFee 返回链的手续费上限（wei），nil 表示不限制
+func (o *Oracle) MaxFee() *big.Int {
+	return o.maxFee
+}
+
+// Policy 返回超过上限时的处理策略
+func (o *Oracle) Policy() string {
+	if o.chain.FeeCapPolicy == "" {
+		return PolicyReject
+	}
+	return o.chain.FeeCapPolicy
+}
+
+// Refresh 立即采样一次并更新缓存
+func (o *Oracle) Refresh(ctx context.Context) (*Snapshot, error) {
+	o.refreshMu.Lock()
+	defer o.refreshMu.Unlock()
+
+	snap, err := o.sample(ctx)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.lastErr = err
+	if err != nil {
+		return nil, err
+	}
+	o.snap = snap
+	close(o.updated)
+	o.updated = make(chan struct{})
+
+	if snap.Dynamic {
+		metricBaseFee.Set(gwei(snap.BaseFee), o.chain.Key)
+	} else {
+		metricBaseFee.Set(gwei(snap.GasPrice), o.chain.Key)
+	}
+	return snap, nil
+}
+
+// Snapshot 返回缓存的采样结果，没有缓存或缓存过期时同步采样
+func (o *Oracle) Snapshot(ctx context.Context) (*Snapshot, error) {
+	o.mu.RLock()
+	snap := o.snap
+	o.mu.RUnlock()
+
+	if snap != nil && time.Since(snap.UpdatedAt) <= time.Duration(o.conf.MaxAge)*time.Second {
+		return snap, nil
+	}
+	return o.Refresh(ctx)
+}
+
+// Estimate 返回档位的手续费；超过上限时 ok 为 false，返回的仍是行情价
+func (o *Oracle) Estimate(ctx context.Context, level string) (fees *Fees, ok bool, err error) {
+	snap, err := o.Snapshot(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	fees, err = snap.Fees(level)
+	if err != nil {
+		return nil, false, err
+	}
+	capped, ok := o.applyCap(fees)
+	if !ok {
+		return fees, false, nil
+	}
+	return capped, true, nil
+}
+
+// Suggest 返回档位的手续费并应用上限：
+// 行情低于上限时 maxFee 截断到上限；高于上限时按策略直接拒绝，或等待手续费回落直到 QueueTimeout
+func (o *Oracle) Suggest(ctx context.Context, level string) (*Fees, error) {
+	deadline := time.Now().Add(time.Duration(o.conf.QueueTimeout) * time.Second)
+	for {
+		o.mu.RLock()
+		updated := o.updated
+		o.mu.RUnlock()
+
+		fees, ok, err := o.Estimate(ctx, level)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return fees, nil
+		}
+
+		capErr := fmt.Errorf("%w: %s needs %s gwei, cap is %s gwei",
+			ErrFeeCapExceeded, o.chain.Key, units.FormatUnits(fees.Required(), 9), units.FormatUnits(o.maxFee, 9))
+		wait := time.Until(deadline)
+		if o.Policy() != PolicyQueue || wait <= 0 {
+			metricCapRejected.Inc(o.chain.Key)
+			return nil, capErr
+		}
+
+		log.Printf("⏳ %s 手续费 %s gwei 超过上限 %s gwei，等待回落（剩余 %s）",
+			o.chain.Key, units.FormatUnits(fees.Required(), 9), units.FormatUnits(o.maxFee, 9), wait.Round(time.Second))
+		// 后台采样更新时唤醒；没有后台采样时按采样间隔自行刷新
+		interval := time.Duration(o.conf.Interval) * time.Second
+		if wait > interval {
+			wait = interval
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-updated:
+			timer.Stop()
+		case <-timer.C:
+			if _, err := o.Refresh(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// CheckCap 校验请求指定的手续费是否超过上限
+func (o *Oracle) CheckCap(fees *Fees) error {
+	if o.maxFee != nil && fees.MaxGasPrice().Cmp(o.maxFee) > 0 {
+		metricCapRejected.Inc(o.chain.Key)
+		return fmt.Errorf("%w: %s gwei requested on %s, cap is %s gwei",
+			ErrFeeCapExceeded, units.FormatUnits(fees.MaxGasPrice(), 9), o.chain.Key, units.FormatUnits(o.maxFee, 9))
+	}
+	return nil
+}
+
+// applyCap 行情在上限内时把 maxFee 截断到上限（baseFee 上涨的余量变小，但不会超付）
+func (o *Oracle) applyCap(fees *Fees) (*Fees, bool) {
+	if o.maxFee == nil {
+		return fees, true
+	}
+	if fees.Required().Cmp(o.maxFee) > 0 {
+		return nil, false
+	}
+	if fees.Dynamic && fees.GasFeeCap.Cmp(o.maxFee) > 0 {
+		capped := *fees
+		capped.GasFeeCap = new(big.Int).Set(o.maxFee)
+		return &capped, true
+	}
+	return fees, true
+}
+
+// sample 链配置强制 legacy 或最新区块没有 baseFee 时采样 gasPrice，
+// 否则用 eth_feeHistory 采样下一个区块的 baseFee 和各档位小费
+func (o *Oracle) sample(ctx context.Context) (*Snapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, sampleTimeout)
+	defer cancel()
+
+	client, err := o.rpc.EthClient(o.chain)
+	if err != nil {
+		return nil, err
+	}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest header: %v", err)
+	}
+	snap := &Snapshot{Chain: o.chain.Key, Block: header.Number.Uint64(), UpdatedAt: time.Now()}
+
+	if o.chain.Legacy || header.BaseFee == nil {
+		if snap.GasPrice, err = client.SuggestGasPrice(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get gas price: %v", err)
+		}
+		return snap, nil
+	}
+
+	history, err := client.FeeHistory(ctx, uint64(o.conf.HistoryBlocks), nil, rewardPercentiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %v", err)
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, errors.New("empty fee history")
+	}
+	snap.Dynamic = true
+	// BaseFee 的最后一个元素是下一个区块的 baseFee
+	snap.BaseFee = history.BaseFee[len(history.BaseFee)-1]
+	snap.Tips = make(map[string]*big.Int, len(Levels))
+
+	var fallback *big.Int
+	for i, level := range Levels {
+		var rewards []*big.Int
+		for _, r := range history.Reward {
+			if len(r) > i && r[i] != nil {
+				rewards = append(rewards, r[i])
+			}
+		}
+		if len(rewards) > 0 {
+			sort.Slice(rewards, func(a, b int) bool { return rewards[a].Cmp(rewards[b]) < 0 })
+			snap.Tips[level] = rewards[len(rewards)/2]
+			continue
+		}
+		// 节点没有返回 reward（空块或不支持），各档位都使用节点建议的小费
+		if fallback == nil {
+			if fallback, err = client.SuggestGasTipCap(ctx); err != nil {
+				return nil, fmt.Errorf("failed to get priority fee: %v", err)
+			}
+		}
+		snap.Tips[level] = fallback
+	}
+	return snap, nil
+}
+
+// gwei 指标使用的 gwei 浮点值
+func gwei(wei *big.Int) float64 {
+	if wei == nil {
+		return 0
+	}
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9)).Float64()
+	return f
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"demo/internal/logic/transaction"
+	"demo/internal/svc"
+	"demo/internal/types"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// ContractCallHandler 按 ABI 编码的任意合约调用
+func ContractCallHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logx.WithContext(r.Context()).Infof("ContractCallHandler")
+		var req types.ContractCallReq
+		if err := httpx.Parse(r, &req); err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to parse request body: %v", err)
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := transaction.NewTransactionLogic(r.Context(), svcCtx)
+		resp, err := l.WrapContractCall(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+// ContractReadHandler 只读合约调用并解码返回值
+func ContractReadHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ContractReadReq
+		if err := httpx.Parse(r, &req); err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to parse request body: %v", err)
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := transaction.NewTransactionLogic(r.Context(), svcCtx)
+		resp, err := l.ReadContract(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"demo/internal/logic/gas"
+	"demo/internal/svc"
+	"demo/internal/types"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// GasEstimateHandler 查询各 EVM 链 slow / normal / fast 档位的手续费估算
+func GasEstimateHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GasEstimateReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := gas.NewEstimateLogic(r.Context(), svcCtx)
+		resp, err := l.Estimate(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+// GasTopUpsHandler 查询 gas 站补充记录和代付 gas 汇总
+func GasTopUpsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GasTopUpsReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := gas.NewTopUpLogic(r.Context(), svcCtx)
+		resp, err := l.TopUps(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"demo/internal/logic/transaction"
+	"demo/internal/svc"
+	"demo/internal/types"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// NftTransferHandler ERC-721 / ERC-1155 转账
+func NftTransferHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logx.WithContext(r.Context()).Infof("NftTransferHandler")
+		var req types.NftTransferReq
+		if err := httpx.Parse(r, &req); err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to parse request body: %v", err)
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := transaction.NewTransactionLogic(r.Context(), svcCtx)
+		resp, err := l.WrapNftTransfer(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -11,12 +11,143 @@ import (
 
 func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 	// authMiddleware := mid.NewAuthMiddleware() // Temporarily commented out for debugging
+	// 有副作用的 POST 接口（签名、广播、写库）统一挂载幂等中间件，
+	// 客户端超时重试时携带相同的 Idempotency-Key 不会重复发送交易
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.Idempotency},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/wallet_init",
+					Handler: WalletInitHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/wallet/smart_account",
+					Handler: SmartAccountHandler(serverCtx),
+				},
+				// --- Transaction Routes ---
+				{
+					Method:  http.MethodPost,
+					Path:    "/transaction/send",
+					Handler: SendHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/transaction/batch_send",
+					Handler: BatchSendHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/transaction/approve",
+					Handler: ApproveHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/transaction/revoke",
+					Handler: RevokeHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/transaction/swap",
+					Handler: SwapHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/transaction/contract_call",
+					Handler: ContractCallHandler(serverCtx),
+				},
+				{
+					// 指定 amount 时创建收款请求
+					Method:  http.MethodPost,
+					Path:    "/transaction/receive",
+					Handler: ReceiveHandler(serverCtx),
+				},
+				// --- Safe Routes ---
+				{
+					Method:  http.MethodPost,
+					Path:    "/safe/register",
+					Handler: SafeRegisterHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/safe/propose",
+					Handler: SafeProposeHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/safe/confirm",
+					Handler: SafeConfirmHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/safe/execute",
+					Handler: SafeExecuteHandler(serverCtx),
+				},
+				// --- NFT Routes ---
+				{
+					Method:  http.MethodPost,
+					Path:    "/nft/transfer",
+					Handler: NftTransferHandler(serverCtx),
+				},
+				// --- Bridge Routes ---
+				{
+					Method:  http.MethodPost,
+					Path:    "/bridge/execute",
+					Handler: BridgeHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/bridge/wrap",
+					Handler: WrapBridgeHandler(serverCtx),
+				},
+				// --- Sweep Routes ---
+				{
+					Method:  http.MethodPost,
+					Path:    "/sweep/run",
+					Handler: SweepRunHandler(serverCtx),
+				},
+				// --- Schedule Routes ---
+				{
+					Method:  http.MethodPost,
+					Path:    "/schedule/create",
+					Handler: ScheduleCreateHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/schedule/pause",
+					Handler: SchedulePauseHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/schedule/resume",
+					Handler: ScheduleResumeHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/schedule/cancel",
+					Handler: ScheduleCancelHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api/"),
+		rest.WithTimeout(30000*time.Millisecond),
+	)
+
+	// 只读接口
 	server.AddRoutes(
 		[]rest.Route{
 			{
 				Method:  http.MethodPost,
-				Path:    "/wallet_init",
-				Handler: WalletInitHandler(serverCtx),
+				Path:    "/wallet/balances",
+				Handler: WalletBalancesHandler(serverCtx),
+			},
+			{
+				// 链下消息签名不产生链上副作用，签名结果经审计日志记录
+				Method:  http.MethodPost,
+				Path:    "/wallet/sign_message",
+				Handler: SignMessageHandler(serverCtx),
 			},
 			// --- Transaction Routes ---
 			{
@@ -26,38 +157,58 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 			},
 			{
 				Method:  http.MethodPost,
-				Path:    "/transaction/send",
-				Handler: SendHandler(serverCtx),
+				Path:    "/transaction/check_allowance",
+				Handler: CheckAllowanceHandler(serverCtx),
 			},
 			{
 				Method:  http.MethodPost,
-				Path:    "/transaction/approve",
-				Handler: ApproveHandler(serverCtx),
+				Path:    "/transaction/user_approvals",
+				Handler: GetUserApprovalsHandler(serverCtx),
 			},
 			{
 				Method:  http.MethodPost,
-				Path:    "/transaction/revoke",
-				Handler: RevokeHandler(serverCtx),
+				Path:    "/transaction/history",
+				Handler: TransactionHistoryHandler(serverCtx),
 			},
 			{
 				Method:  http.MethodPost,
-				Path:    "/transaction/check_allowance",
-				Handler: CheckAllowanceHandler(serverCtx),
+				Path:    "/transaction/user_operation",
+				Handler: UserOperationStatusHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/transaction/receive/status",
+				Handler: ReceiveStatusHandler(serverCtx),
 			},
 			{
 				Method:  http.MethodPost,
-				Path:    "/transaction/user_approvals",
-				Handler: GetUserApprovalsHandler(serverCtx),
+				Path:    "/contract/read",
+				Handler: ContractReadHandler(serverCtx),
 			},
 			{
-				Method:  http.MethodGet, // Receive is typically a GET request to fetch address/info
-				Path:    "/transaction/receive",
-				Handler: NotImplementedHandler(),
+				Method:  http.MethodPost,
+				Path:    "/safe/transactions",
+				Handler: SafeTransactionsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/rpc/status",
+				Handler: RpcStatusHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/gas/estimate",
+				Handler: GasEstimateHandler(serverCtx),
 			},
 			{
 				Method:  http.MethodPost,
-				Path:    "/transaction/swap",
-				Handler: SwapHandler(serverCtx),
+				Path:    "/gas/topups",
+				Handler: GasTopUpsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/token/resolve",
+				Handler: TokenResolveHandler(serverCtx),
 			},
 			// --- Bridge Routes ---
 			{
@@ -67,18 +218,30 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 			},
 			{
 				Method:  http.MethodPost,
-				Path:    "/bridge/execute",
-				Handler: BridgeHandler(serverCtx),
+				Path:    "/bridge/status",
+				Handler: BridgeStatusHandler(serverCtx),
 			},
+			// --- Sweep Routes ---
 			{
 				Method:  http.MethodPost,
-				Path:    "/bridge/wrap",
-				Handler: WrapBridgeHandler(serverCtx),
+				Path:    "/sweep/runs",
+				Handler: SweepRunsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/sweep/report",
+				Handler: SweepReportHandler(serverCtx),
 			},
+			// --- Schedule Routes ---
 			{
 				Method:  http.MethodPost,
-				Path:    "/bridge/status",
-				Handler: BridgeStatusHandler(serverCtx),
+				Path:    "/schedule/list",
+				Handler: ScheduleListHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/schedule/runs",
+				Handler: ScheduleRunsHandler(serverCtx),
 			},
 		},
 		rest.WithPrefix("/api/"),
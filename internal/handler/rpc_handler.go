@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"demo/internal/svc"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// RpcStatusHandler 查询各链 RPC 节点池的健康状态和请求计数
+func RpcStatusHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		httpx.OkJsonCtx(r.Context(), w, map[string]interface{}{
+			"chains": svcCtx.RPC.Stats(),
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"demo/internal/logic/transaction"
+	"demo/internal/svc"
+	"demo/internal/types"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// SafeRegisterHandler 登记 Safe 多签钱包
+func SafeRegisterHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logx.WithContext(r.Context()).Infof("SafeRegisterHandler")
+		var req types.SafeRegisterReq
+		if err := httpx.Parse(r, &req); err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to parse request body: %v", err)
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := transaction.NewTransactionLogic(r.Context(), svcCtx)
+		resp, err := l.RegisterSafe(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+// SafeProposeHandler 创建 Safe 交易并由 proposer 签名
+func SafeProposeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logx.WithContext(r.Context()).Infof("SafeProposeHandler")
+		var req types.SafeProposeReq
+		if err := httpx.Parse(r, &req); err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to parse request body: %v", err)
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := transaction.NewTransactionLogic(r.Context(), svcCtx)
+		resp, err := l.ProposeSafeTransaction(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+// SafeConfirmHandler 托管 owner 签名 Safe 交易，达到阈值时执行
+func SafeConfirmHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logx.WithContext(r.Context()).Infof("SafeConfirmHandler")
+		var req types.SafeConfirmReq
+		if err := httpx.Parse(r, &req); err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to parse request body: %v", err)
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := transaction.NewTransactionLogic(r.Context(), svcCtx)
+		resp, err := l.ConfirmSafeTransaction(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+// SafeExecuteHandler 提交签名已达阈值的 Safe 交易
+func SafeExecuteHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logx.WithContext(r.Context()).Infof("SafeExecuteHandler")
+		var req types.SafeExecuteReq
+		if err := httpx.Parse(r, &req); err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to parse request body: %v", err)
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := transaction.NewTransactionLogic(r.Context(), svcCtx)
+		resp, err := l.ExecuteSafeTransaction(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+// SafeTransactionsHandler 查询 Safe 交易及签名进度
+func SafeTransactionsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logx.WithContext(r.Context()).Infof("SafeTransactionsHandler")
+		var req types.SafeTransactionsReq
+		if err := httpx.Parse(r, &req); err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to parse request body: %v", err)
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := transaction.NewTransactionLogic(r.Context(), svcCtx)
+		resp, err := l.SafeTransactions(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
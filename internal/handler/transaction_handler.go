@@ -33,6 +33,27 @@ func SendHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	}
 }
 
+// BatchSendHandler 批量转账，按付款 ID 返回每笔结果
+func BatchSendHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logx.WithContext(r.Context()).Infof("BatchSendHandler")
+		var req types.BatchSendReq
+		if err := httpx.Parse(r, &req); err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to parse request body: %v", err)
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := transaction.NewTransactionLogic(r.Context(), svcCtx)
+		resp, err := l.WrapBatchSend(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
 func SwapHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		logx.WithContext(r.Context()).Infof("SwapHandler")
@@ -229,3 +250,44 @@ func GetUserApprovalsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		}
 	}
 }
+
+// TransactionHistoryHandler 查询交易记录
+func TransactionHistoryHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logx.WithContext(r.Context()).Infof("TransactionHistoryHandler")
+		var req types.TransactionHistoryReq
+		if err := httpx.Parse(r, &req); err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to parse request body: %v", err)
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		logx.WithContext(r.Context()).Infof("Request body parsed successfully: %+v", req)
+
+		l := transaction.NewTransactionLogic(r.Context(), svcCtx)
+		resp, err := l.GetTransactionHistory(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+// UserOperationStatusHandler 查询智能账户 UserOperation 的打包结果
+func UserOperationStatusHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UserOperationStatusReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := transaction.NewTransactionLogic(r.Context(), svcCtx)
+		resp, err := l.UserOperationStatus(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
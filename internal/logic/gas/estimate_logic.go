@@ -0,0 +1,113 @@
+package gas
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"demo/internal/gasoracle"
+	"demo/internal/svc"
+	"demo/internal/types"
+	"demo/internal/units"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// transferGas 原生币转账消耗的 gas，用于估算展示的转账手续费
+const transferGas = 21000
+
+type EstimateLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewEstimateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *EstimateLogic {
+	return &EstimateLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// Estimate 返回各 EVM 链的手续费估算（来自手续费预言机缓存）
+func (l *EstimateLogic) Estimate(req *types.GasEstimateReq) (*types.GasEstimateResp, error) {
+	oracles := l.svcCtx.Gas.Oracles()
+	if req.Chain != "" {
+		c, ok := l.svcCtx.Chains.Get(req.Chain)
+		if !ok {
+			return nil, fmt.Errorf("unsupported chain: %s", req.Chain)
+		}
+		oracle, err := l.svcCtx.Gas.Oracle(c)
+		if err != nil {
+			return nil, fmt.Errorf("gas estimates are only available for EVM chains: %s", req.Chain)
+		}
+		oracles = []*gasoracle.Oracle{oracle}
+	}
+
+	result := make([]types.ChainGasEstimate, len(oracles))
+	var wg sync.WaitGroup
+	for i, oracle := range oracles {
+		wg.Add(1)
+		go func(i int, oracle *gasoracle.Oracle) {
+			defer wg.Done()
+			result[i] = l.estimateChain(oracle)
+		}(i, oracle)
+	}
+	wg.Wait()
+
+	return &types.GasEstimateResp{Chains: result}, nil
+}
+
+// estimateChain 单条链采样失败时只在该链的 error 中返回
+func (l *EstimateLogic) estimateChain(oracle *gasoracle.Oracle) types.ChainGasEstimate {
+	c := oracle.Chain()
+	est := types.ChainGasEstimate{
+		Chain:        c.Key,
+		ChainId:      c.ChainId,
+		NativeSymbol: c.NativeSymbol,
+	}
+	if maxFee := oracle.MaxFee(); maxFee != nil {
+		est.MaxFeeCap = maxFee.String()
+		est.FeeCapPolicy = oracle.Policy()
+	}
+
+	snap, err := oracle.Snapshot(l.ctx)
+	if err != nil {
+		l.Errorf("%s 手续费采样失败: %v", c.Key, err)
+		est.Error = err.Error()
+		return est
+	}
+	est.Eip1559 = snap.Dynamic
+	est.Block = snap.Block
+	est.UpdatedAt = snap.UpdatedAt.Format(time.RFC3339)
+	if snap.BaseFee != nil {
+		est.BaseFee = snap.BaseFee.String()
+	}
+
+	est.Levels = make(map[string]types.GasLevelEstimate, len(gasoracle.Levels))
+	for _, level := range gasoracle.Levels {
+		fees, ok, err := oracle.Estimate(l.ctx, level)
+		if err != nil {
+			est.Error = err.Error()
+			return est
+		}
+
+		maxPrice := fees.MaxGasPrice()
+		item := types.GasLevelEstimate{
+			MaxFeeGwei:   units.FormatUnits(maxPrice, 9),
+			TransferCost: units.FormatUnits(new(big.Int).Mul(maxPrice, big.NewInt(transferGas)), c.NativeDecimals),
+			ExceedsCap:   !ok,
+		}
+		if fees.Dynamic {
+			item.MaxFeePerGas = fees.GasFeeCap.String()
+			item.MaxPriorityFeePerGas = fees.GasTipCap.String()
+		} else {
+			item.GasPrice = fees.GasPrice.String()
+		}
+		est.Levels[level] = item
+	}
+	return est
+}
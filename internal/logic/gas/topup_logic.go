@@ -0,0 +1,129 @@
+package gas
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"demo/internal/model"
+	"demo/internal/svc"
+	"demo/internal/types"
+	"demo/internal/units"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type TopUpLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewTopUpLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TopUpLogic {
+	return &TopUpLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// TopUps 分页返回 gas 站补充记录，并按链汇总代付的 gas
+func (l *TopUpLogic) TopUps(req *types.GasTopUpsReq) (*types.GasTopUpsResp, error) {
+	chain := ""
+	if req.Chain != "" {
+		c, ok := l.svcCtx.Chains.Get(req.Chain)
+		if !ok {
+			return nil, fmt.Errorf("unsupported chain: %s", req.Chain)
+		}
+		chain = c.Key
+	}
+	// 补充记录中的地址为 EIP-55 校验和格式
+	wallet := req.Wallet
+	if common.IsHexAddress(wallet) {
+		wallet = common.HexToAddress(wallet).Hex()
+	}
+
+	rows, total, err := l.svcCtx.GasTopUpsDao.FindPage(l.ctx, chain, wallet, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	all, err := l.svcCtx.GasTopUpsDao.FindSince(l.ctx, chain, wallet, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.GasTopUpsResp{Total: total, Summary: l.summarize(all), Items: make([]types.GasTopUpItem, 0, len(rows))}
+	for _, row := range rows {
+		item := types.GasTopUpItem{
+			Id:        row.Id,
+			Chain:     row.Chain,
+			Wallet:    row.Wallet,
+			Funder:    row.Funder,
+			Amount:    row.Amount,
+			Fee:       row.Fee,
+			Purpose:   row.Purpose,
+			TxHash:    row.TxHash,
+			Status:    row.Status,
+			Error:     row.ErrorMessage,
+			CreatedAt: row.CreatedAt.Unix(),
+		}
+		if c, ok := l.svcCtx.Chains.Get(row.Chain); ok {
+			item.AmountFormatted = units.FormatUnitsString(row.Amount, c.NativeDecimals)
+			item.ExplorerUrl = c.ExplorerTx(row.TxHash)
+		}
+		if row.ConfirmedAt != nil {
+			item.ConfirmedAt = row.ConfirmedAt.Unix()
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	return resp, nil
+}
+
+// summarize 按链累计补充金额和手续费；失败（回滚）的补充没有转出金额，但手续费照付
+func (l *TopUpLogic) summarize(rows []*model.GasTopUps) []types.GasSponsoredSummary {
+	type totals struct {
+		count       int
+		amount, fee *big.Int
+	}
+	var order []string
+	byChain := make(map[string]*totals)
+	for _, row := range rows {
+		t, ok := byChain[row.Chain]
+		if !ok {
+			t = &totals{amount: new(big.Int), fee: new(big.Int)}
+			byChain[row.Chain] = t
+			order = append(order, row.Chain)
+		}
+		t.count++
+		if v, ok := new(big.Int).SetString(row.Fee, 10); ok {
+			t.fee.Add(t.fee, v)
+		}
+		if row.Status == model.GasTopUpStatusFailed {
+			continue
+		}
+		if v, ok := new(big.Int).SetString(row.Amount, 10); ok {
+			t.amount.Add(t.amount, v)
+		}
+	}
+
+	summary := make([]types.GasSponsoredSummary, 0, len(order))
+	for _, chain := range order {
+		t := byChain[chain]
+		sponsored := new(big.Int).Add(t.amount, t.fee)
+		s := types.GasSponsoredSummary{
+			Chain:     chain,
+			Count:     t.count,
+			Amount:    t.amount.String(),
+			Fee:       t.fee.String(),
+			Sponsored: sponsored.String(),
+		}
+		if c, ok := l.svcCtx.Chains.Get(chain); ok {
+			s.Symbol = c.NativeSymbol
+			s.SponsoredFormatted = units.FormatUnits(sponsored, c.NativeDecimals)
+		}
+		summary = append(summary, s)
+	}
+	return summary
+}
@@ -1,14 +1,21 @@
 package monitor
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"math/big"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
+	"demo/internal/chains"
+	"demo/internal/tokens"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -29,8 +36,20 @@ var (
 	// DepositEventSignature & WithdrawEventSignature Deposit/Withdraw (Wrapped tokens)
 	DepositEventSignature  = crypto.Keccak256Hash([]byte("Deposit(address,uint256)"))
 	WithdrawEventSignature = crypto.Keccak256Hash([]byte("Withdrawal(address,uint256)"))
+	// TransferSingleEventSignature & TransferBatchEventSignature ERC1155
+	TransferSingleEventSignature = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	TransferBatchEventSignature  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
 )
 
+// NFTTransferEventType ERC721 Transfer / ERC1155 TransferSingle、TransferBatch 统一的事件类型
+const NFTTransferEventType = "NFTTransfer"
+
+// transferBatchArgs TransferBatch 的非 indexed 参数 (uint256[] ids, uint256[] values)
+var transferBatchArgs = func() abi.Arguments {
+	uintSlice, _ := abi.NewType("uint256[]", "", nil)
+	return abi.Arguments{{Name: "ids", Type: uintSlice}, {Name: "values", Type: uintSlice}}
+}()
+
 // 已知合约地址 (可扩展)
 var (
 	// PancakeV2Router PancakeSwap V2 Router
@@ -51,17 +70,24 @@ type LiFiResponse struct {
 	Amount          string `json:"amount"`
 }
 
+// TokenLookup 按地址查询代币元数据（由 tokens.Registry 实现），用于事件金额的格式化
+type TokenLookup interface {
+	Lookup(ctx context.Context, chain *chains.Chain, address string) (*tokens.Token, error)
+}
+
 // LogParser 日志解析器
 type LogParser struct {
 	knownContracts map[common.Address]string // 已知合约地址映射
 	lifiAPIKey     string                    // LI.FI API密钥
+	watch          *WatchList                // 监控地址，用于判断资金流向，为空时按交易发起方判断
 }
 
 // NewLogParser 创建日志解析器
-func NewLogParser() *LogParser {
+func NewLogParser(watch *WatchList) *LogParser {
 	parser := &LogParser{
 		knownContracts: make(map[common.Address]string),
 		lifiAPIKey:     "", // 可以从环境变量或配置文件读取
+		watch:          watch,
 	}
 
 	// 初始化已知合约
@@ -102,6 +128,13 @@ func (p *LogParser) ParseLogs(logs []*types.Log, tx *types.Transaction, blockNum
 
 		switch eventSig {
 		case TransferEventSignature:
+			// ERC721 的 tokenId 也是 indexed：4 个 topic、data 为空
+			if len(vLog.Topics) == 4 && len(vLog.Data) == 0 {
+				if event := p.parseERC721TransferEvent(vLog, tx, blockNumber, timestamp, chainId, fromAddr); event != nil {
+					events = append(events, event)
+				}
+				continue
+			}
 			if event := p.parseTransferEvent(vLog, tx, blockNumber, timestamp, chainId, fromAddr, toAddr); event != nil {
 				events = append(events, event)
 			}
@@ -113,6 +146,8 @@ func (p *LogParser) ParseLogs(logs []*types.Log, tx *types.Transaction, blockNum
 			if event := p.parseSwapEvent(vLog, tx, blockNumber, timestamp, chainId, fromAddr, toAddr); event != nil {
 				events = append(events, event)
 			}
+		case TransferSingleEventSignature, TransferBatchEventSignature:
+			events = append(events, p.parseERC1155TransferEvents(vLog, tx, blockNumber, timestamp, chainId, fromAddr)...)
 		case BridgeEventSignature, DepositEventSignature, WithdrawEventSignature:
 			if event := p.parseBridgeEvent(vLog, tx, blockNumber, timestamp, chainId, fromAddr, toAddr); event != nil {
 				events = append(events, event)
@@ -141,6 +176,7 @@ func (p *LogParser) ParseLogs(logs []*types.Log, tx *types.Transaction, blockNum
 			TokenAddr:   "0x0000000000000000000000000000000000000000", // 原生代币地址
 			Amount:      tx.Value().String(),
 			ChainId:     chainId,
+			LogIndex:    -1,
 		}
 		events = append(events, event)
 	}
@@ -177,6 +213,85 @@ func (p *LogParser) parseTransferEvent(vLog *types.Log, tx *types.Transaction, b
 		TokenAddr:   vLog.Address.Hex(),
 		Amount:      amount.String(),
 		ChainId:     chainId,
+		LogIndex:    int(vLog.Index),
+	}
+}
+
+// parseERC721TransferEvent 解析ERC721 Transfer(from, to, tokenId)，三个参数都是 indexed
+func (p *LogParser) parseERC721TransferEvent(vLog *types.Log, tx *types.Transaction, blockNumber uint64, timestamp int64, chainId uint64, txFromAddr string) *TokenEvent {
+	from := common.BytesToAddress(vLog.Topics[1].Bytes())
+	to := common.BytesToAddress(vLog.Topics[2].Bytes())
+	tokenId := new(big.Int).SetBytes(vLog.Topics[3].Bytes())
+
+	return p.newNFTEvent(vLog, tx, blockNumber, timestamp, chainId, txFromAddr, from, to, "ERC721", "", tokenId, big.NewInt(1))
+}
+
+// parseERC1155TransferEvents 解析 TransferSingle / TransferBatch(operator, from, to, ...)，
+// TransferBatch 按 token id 拆成多个事件，同一 id 出现多次时合并数量
+func (p *LogParser) parseERC1155TransferEvents(vLog *types.Log, tx *types.Transaction, blockNumber uint64, timestamp int64, chainId uint64, txFromAddr string) []*TokenEvent {
+	if len(vLog.Topics) != 4 {
+		return nil
+	}
+	operator := common.BytesToAddress(vLog.Topics[1].Bytes())
+	from := common.BytesToAddress(vLog.Topics[2].Bytes())
+	to := common.BytesToAddress(vLog.Topics[3].Bytes())
+
+	var ids, values []*big.Int
+	if vLog.Topics[0] == TransferSingleEventSignature {
+		if len(vLog.Data) != 64 {
+			return nil
+		}
+		ids = []*big.Int{new(big.Int).SetBytes(vLog.Data[:32])}
+		values = []*big.Int{new(big.Int).SetBytes(vLog.Data[32:])}
+	} else {
+		unpacked, err := transferBatchArgs.Unpack(vLog.Data)
+		if err != nil || len(unpacked) != 2 {
+			return nil
+		}
+		ids, _ = unpacked[0].([]*big.Int)
+		values, _ = unpacked[1].([]*big.Int)
+		if len(ids) != len(values) {
+			return nil
+		}
+	}
+
+	var events []*TokenEvent
+	index := make(map[string]*TokenEvent, len(ids))
+	for i, id := range ids {
+		if event, ok := index[id.String()]; ok {
+			amount, _ := new(big.Int).SetString(event.Amount, 10)
+			event.Amount = amount.Add(amount, values[i]).String()
+			continue
+		}
+		event := p.newNFTEvent(vLog, tx, blockNumber, timestamp, chainId, txFromAddr, from, to, "ERC1155", operator.Hex(), id, values[i])
+		index[id.String()] = event
+		events = append(events, event)
+	}
+	return events
+}
+
+// newNFTEvent 构造 NFTTransfer 事件，方向判断与 ERC20 Transfer 一致
+func (p *LogParser) newNFTEvent(vLog *types.Log, tx *types.Transaction, blockNumber uint64, timestamp int64, chainId uint64, txFromAddr string, from, to common.Address, standard, operator string, tokenId, amount *big.Int) *TokenEvent {
+	direction := "OUT"
+	if p.isReceiveEvent(from, to, txFromAddr) {
+		direction = "IN"
+	}
+
+	return &TokenEvent{
+		BlockNumber:   blockNumber,
+		TxHash:        tx.Hash().Hex(),
+		Timestamp:     timestamp,
+		EventType:     NFTTransferEventType,
+		Direction:     direction,
+		FromAddr:      from.Hex(),
+		ToAddr:        to.Hex(),
+		TokenAddr:     vLog.Address.Hex(),
+		Amount:        amount.String(),
+		ChainId:       chainId,
+		LogIndex:      int(vLog.Index),
+		TokenStandard: standard,
+		TokenId:       tokenId.String(),
+		Operator:      operator,
 	}
 }
 
@@ -204,6 +319,7 @@ func (p *LogParser) parseApprovalEvent(vLog *types.Log, tx *types.Transaction, b
 		TokenAddr:   vLog.Address.Hex(),
 		Amount:      amount.String(),
 		ChainId:     chainId,
+		LogIndex:    int(vLog.Index),
 	}
 }
 
@@ -230,6 +346,7 @@ func (p *LogParser) parseSwapEvent(vLog *types.Log, tx *types.Transaction, block
 		TokenAddr:   vLog.Address.Hex(),
 		Amount:      amount.String(),
 		ChainId:     chainId,
+		LogIndex:    int(vLog.Index),
 	}
 }
 
@@ -281,11 +398,16 @@ func (p *LogParser) parseBridgeEvent(vLog *types.Log, tx *types.Transaction, blo
 		TokenAddr:   vLog.Address.Hex(),
 		Amount:      amount.String(),
 		ChainId:     chainId,
+		LogIndex:    int(vLog.Index),
 	}
 }
 
 // isReceiveEvent 判断Transfer事件是否为接收事件
 func (p *LogParser) isReceiveEvent(from, to common.Address, txFromAddr string) bool {
+	// 有监控地址时以接收方是否为监控地址判断（别人向监控钱包转账时交易发起者是付款方）
+	if p.watch != nil {
+		return p.watch.Contains(to)
+	}
 	// 判断：如果Transfer事件的to地址是交易发起者，则为接收
 	// 这种情况通常发生在：别人向你转账，或者你从合约中提取代币
 	txFrom := common.HexToAddress(txFromAddr)
@@ -294,6 +416,9 @@ func (p *LogParser) isReceiveEvent(from, to common.Address, txFromAddr string) b
 
 // isNativeReceiveEvent 判断原生代币转账是否为接收事件
 func (p *LogParser) isNativeReceiveEvent(fromAddr, toAddr, txFromAddr string) bool {
+	if p.watch != nil && toAddr != "" {
+		return p.watch.Contains(common.HexToAddress(toAddr))
+	}
 	// 对于原生代币转账，交易发起者就是发送方
 	// 所以如果txFromAddr == fromAddr，则为发送(OUT)
 	// 这里的逻辑与Transfer事件不同，因为原生转账没有合约中介
@@ -328,45 +453,40 @@ func (p *LogParser) IsBridgeContract(address common.Address) bool {
 		strings.Contains(strings.ToLower(name), "cross")
 }
 
-// FormatTokenAmount 格式化代币金额为可读格式
-func FormatTokenAmount(amountStr string, tokenAddr string, chainId uint64) string {
+// FormatTokenAmount 格式化代币金额为可读格式，chain 为空时原生币按 18 位精度显示；
+// ERC20 的符号和精度来自代币注册表，查询失败时显示最小单位数量
+func FormatTokenAmount(amountStr string, tokenAddr string, chain *chains.Chain, lookup TokenLookup) string {
 	amount := new(big.Int)
 	amount.SetString(amountStr, 10)
 
 	// 检查是否为原生代币 (BNB/ETH等)
 	if tokenAddr == "0x0000000000000000000000000000000000000000" {
-		return formatNativeToken(amount, chainId)
+		return formatNativeToken(amount, chain)
 	}
 
-	// 对于ERC20代币，默认使用18位小数
-	return formatERC20Token(amount, tokenAddr, 18)
+	if chain != nil && lookup != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		token, err := lookup.Lookup(ctx, chain, tokenAddr)
+		if err == nil {
+			symbol := token.Symbol
+			if symbol == "" {
+				symbol = shortTokenName(tokenAddr)
+			}
+			return formatERC20Token(amount, symbol, token.Decimals)
+		}
+		log.Printf("⚠️  查询代币 %s 元数据失败: %v", tokenAddr, err)
+	}
+	return fmt.Sprintf("%s %s（最小单位）", amount.String(), shortTokenName(tokenAddr))
 }
 
-// formatNativeToken 格式化原生代币
-func formatNativeToken(amount *big.Int, chainId uint64) string {
-	var symbol string
-	var divisor *big.Int
-
-	switch chainId {
-	case 1, 5, 11155111: // Ethereum 主网, Goerli, Sepolia
-		symbol = "ETH"
-		divisor = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil) // 18 decimals
-	case 56, 97: // BSC 主网, BSC 测试网
-		symbol = "BNB"
-		divisor = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil) // 18 decimals
-	case 137, 80001: // Polygon 主网, Mumbai
-		symbol = "MATIC"
-		divisor = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil) // 18 decimals
-	case 20000000000001: // Bitcoin 主网
-		symbol = "BTC"
-		divisor = new(big.Int).Exp(big.NewInt(10), big.NewInt(8), nil) // 8 decimals
-	case 20000000000002: // Bitcoin 测试网
-		symbol = "tBTC"
-		divisor = new(big.Int).Exp(big.NewInt(10), big.NewInt(8), nil) // 8 decimals
-	default:
-		symbol = "Native"
-		divisor = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil) // 默认18位小数
+// formatNativeToken 格式化原生代币，符号和精度来自链注册表
+func formatNativeToken(amount *big.Int, chain *chains.Chain) string {
+	symbol, decimals := "Native", 18
+	if chain != nil {
+		symbol, decimals = chain.NativeSymbol, chain.NativeDecimals
 	}
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
 
 	// 格式化金额
 	eth := new(big.Float).SetInt(amount)
@@ -383,20 +503,13 @@ func formatNativeToken(amount *big.Int, chainId uint64) string {
 	}
 }
 
-// formatERC20Token 格式化ERC20代币
-func formatERC20Token(amount *big.Int, tokenAddr string, decimals int) string {
-	if decimals == 0 {
-		decimals = 18 // 默认18位小数
-	}
-
+// formatERC20Token 按代币精度格式化ERC20代币
+func formatERC20Token(amount *big.Int, symbol string, decimals int) string {
 	token := new(big.Float).SetInt(amount)
 	token.Quo(token, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
 
 	floatVal, _ := token.Float64()
 
-	// 获取代币符号（简化版本，实际项目中可以从合约查询）
-	symbol := getTokenSymbol(tokenAddr)
-
 	if floatVal >= 1 {
 		return fmt.Sprintf("%.6f %s", floatVal, symbol)
 	} else if floatVal >= 0.001 {
@@ -406,22 +519,8 @@ func formatERC20Token(amount *big.Int, tokenAddr string, decimals int) string {
 	}
 }
 
-// getTokenSymbol 获取代币符号（简化版本）
-func getTokenSymbol(tokenAddr string) string {
-	// 常见代币地址映射（BSC测试网）
-	knownTokens := map[string]string{
-		"0x55d398326f99059ff775485246999027b3197955": "USDT",
-		"0x8ac76a51cc950d9822d68b83fe1ad97b32cd580d": "USDC",
-		"0xe9e7cea3dedca5984780bafc599bd69add087d56": "BUSD",
-		"0x2170ed0880ac9a755fd29b2688956bd959f933f8": "ETH",
-		"0x7130d2a12b9bcbfae4f2634d864a1ee1ce3ead9c": "BTCB",
-	}
-
-	if symbol, exists := knownTokens[strings.ToLower(tokenAddr)]; exists {
-		return symbol
-	}
-
-	// 返回地址的简短形式
+// shortTokenName 没有符号的代币显示地址的简短形式
+func shortTokenName(tokenAddr string) string {
 	if len(tokenAddr) >= 10 {
 		return fmt.Sprintf("Token(%s...%s)", tokenAddr[:6], tokenAddr[len(tokenAddr)-4:])
 	}
@@ -8,6 +8,9 @@ import (
 	"math/big"
 	"time"
 
+	"demo/internal/chains"
+
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -18,68 +21,90 @@ type TokenEvent struct {
 	BlockNumber uint64 `json:"blockNumber"`
 	TxHash      string `json:"txHash"`
 	Timestamp   int64  `json:"timestamp"`
-	EventType   string `json:"eventType"` // ABI标准事件名称: Transfer/Approval/Swap/Bridge/Deposit/Withdrawal/NativeTransfer
+	EventType   string `json:"eventType"` // ABI标准事件名称: Transfer/Approval/Swap/Bridge/Deposit/Withdrawal/NativeTransfer/NFTTransfer
 	Direction   string `json:"direction"` // IN/OUT/NONE - 资金流向标记
 	FromAddr    string `json:"fromAddr"`
 	ToAddr      string `json:"toAddr"`
 	TokenAddr   string `json:"tokenAddr"` // 代币合约地址
 	Amount      string `json:"amount"`    // 使用string存储以避免精度问题
 	ChainId     uint64 `json:"chainId"`   // 支持跨链场景
+	LogIndex    int    `json:"logIndex"`  // 区块内日志序号，原生转账为 -1
+	// NFT 转账（EventType=NFTTransfer）：标准 ERC721 / ERC1155、token id，Amount 为数量（ERC721 固定为 1）
+	TokenStandard string `json:"tokenStandard,omitempty"`
+	TokenId       string `json:"tokenId,omitempty"`
+	// ERC1155 事件中的 operator
+	Operator string `json:"operator,omitempty"`
+}
+
+// DedupKey 事件唯一键，下游消费者据此去重（至少一次投递）
+func (e *TokenEvent) DedupKey() string {
+	// TransferBatch 一条日志拆成多个事件，按 token id 区分
+	if e.TokenId != "" {
+		return fmt.Sprintf("%d:%s:%d:%s:%s", e.ChainId, e.TxHash, e.LogIndex, e.EventType, e.TokenId)
+	}
+	return fmt.Sprintf("%d:%s:%d:%s", e.ChainId, e.TxHash, e.LogIndex, e.EventType)
 }
 
 // BSCMonitor BSC监控器
 type BSCMonitor struct {
 	client         *ethclient.Client
-	watchAddresses map[common.Address]bool // 监控的地址列表
+	watchAddresses *WatchList // 监控的地址列表
 	eventHandlers  []func(*TokenEvent)
 	chainId        uint64
+	chain          *chains.Chain
 	logParser      *LogParser
+	store          EventStore // 非空时事件写入发件箱，由 OutboxRelay 投递
 }
 
+// catchUpBatch 每收到一个新区块头最多补扫的区块数；落后更多时分批补扫，游标不会越过未处理的区块
+const catchUpBatch = 500
+
 // BTCMonitor Bitcoin测试网监控器
 type BTCMonitor struct {
 	rpcURL         string
 	watchAddresses []string // Bitcoin地址列表
 	eventHandlers  []func(*TokenEvent)
-	chainId        uint64 // Bitcoin Testnet Chain ID: 20000000000002
+	chainId        uint64
 }
 
-// NewBSCMonitor 创建BSC监控器
-func NewBSCMonitor(wsURL string, watchAddresses []string) (*BSCMonitor, error) {
-	client, err := ethclient.Dial(wsURL)
+// NewBSCMonitor 创建 EVM 链监控器（通过链注册表中的 WsUrl 订阅新区块）
+func NewBSCMonitor(chain *chains.Chain, watchAddresses *WatchList, store EventStore) (*BSCMonitor, error) {
+	client, err := ethclient.Dial(chain.WsUrl)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to BSC WebSocket: %w", err)
+		return nil, fmt.Errorf("failed to connect to %s WebSocket: %w", chain.Key, err)
 	}
 
-	// 获取链ID
+	// 获取链ID，并与注册表核对，避免 WsUrl 配错链导致游标和事件错乱
 	chainID, err := client.ChainID(context.Background())
 	if err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to get chain ID: %w", err)
 	}
+	if chainID.Int64() != chain.ChainId {
+		client.Close()
+		return nil, fmt.Errorf("%s WsUrl returned chain id %d, expected %d", chain.Key, chainID.Int64(), chain.ChainId)
+	}
 
 	monitor := &BSCMonitor{
 		client:         client,
-		watchAddresses: make(map[common.Address]bool),
+		watchAddresses: watchAddresses,
 		eventHandlers:  make([]func(*TokenEvent), 0),
 		chainId:        chainID.Uint64(),
-		logParser:      NewLogParser(),
-	}
-
-	// 添加监控地址
-	for _, addr := range watchAddresses {
-		monitor.watchAddresses[common.HexToAddress(addr)] = true
+		chain:          chain,
+		logParser:      NewLogParser(watchAddresses),
+		store:          store,
 	}
 
 	return monitor, nil
 }
 
-// NewBTCMonitor 创建Bitcoin测试网监控器
-func NewBTCMonitor(rpcURL string, watchAddresses []string) (*BTCMonitor, error) {
+// NewBTCMonitor 创建Bitcoin监控器
+func NewBTCMonitor(chain *chains.Chain, watchAddresses []string) (*BTCMonitor, error) {
 	monitor := &BTCMonitor{
-		rpcURL:         rpcURL,
+		rpcURL:         chain.RpcUrl,
 		watchAddresses: watchAddresses,
 		eventHandlers:  make([]func(*TokenEvent), 0),
-		chainId:        20000000000002, // Bitcoin Testnet Chain ID
+		chainId:        uint64(chain.ChainId),
 	}
 
 	return monitor, nil
@@ -113,7 +138,25 @@ func (m *BSCMonitor) Start(ctx context.Context) error {
 			log.Printf("WebSocket订阅错误: %v", err)
 			return err
 		case header := <-headers:
-			// 处理新区块
+			// 只处理达到确认数的区块，避免重组后已投递的事件（含收款匹配）被回滚
+			confirmed, ok := m.confirmedHead(header.Number.Uint64())
+			if !ok {
+				continue
+			}
+			if m.store != nil {
+				// 从游标处补扫到已确认的最新区块，失败的区块下次收到新区块头时重试
+				if err := m.catchUp(ctx, confirmed); err != nil {
+					log.Printf("处理区块失败: %v", err)
+				}
+				continue
+			}
+			// 处理刚达到确认数的区块
+			if confirmed != header.Number.Uint64() {
+				if header, err = m.client.HeaderByNumber(ctx, new(big.Int).SetUint64(confirmed)); err != nil {
+					log.Printf("获取区块头 %d 失败: %v", confirmed, err)
+					continue
+				}
+			}
 			if err := m.processBlock(ctx, header); err != nil {
 				log.Printf("处理区块 %d 失败: %v", header.Number.Uint64(), err)
 			}
@@ -124,6 +167,55 @@ func (m *BSCMonitor) Start(ctx context.Context) error {
 	}
 }
 
+// confirmedHead 返回达到链配置确认数（Confirmations，1 表示区块一上链即处理）的最新区块号，链还不够长时返回 false
+func (m *BSCMonitor) confirmedHead(head uint64) (uint64, bool) {
+	depth := uint64(1)
+	if m.chain.Confirmations > 1 {
+		depth = uint64(m.chain.Confirmations)
+	}
+	if head+1 < depth {
+		return 0, false
+	}
+	return head + 1 - depth, true
+}
+
+// cursorName 监控游标名称
+func (m *BSCMonitor) cursorName() string {
+	return fmt.Sprintf("evm:%d", m.chainId)
+}
+
+// catchUp 从上次处理的区块依次向 head（已确认的最新区块）处理，每次最多 catchUpBatch 个区块，剩余的在收到下一个区块头时继续；
+// 每个区块的事件和游标在同一事务中提交。没有游标时（首次启动）从 head 开始
+func (m *BSCMonitor) catchUp(ctx context.Context, head uint64) error {
+	last, ok, err := m.store.LastBlock(ctx, m.cursorName())
+	if err != nil {
+		return fmt.Errorf("读取监控游标失败: %w", err)
+	}
+
+	from, to := head, head
+	if ok {
+		if last >= head {
+			return nil
+		}
+		from = last + 1
+		if head-last > catchUpBatch {
+			to = last + catchUpBatch
+			log.Printf("⏩ 游标落后 %d 个区块，本次补扫区块 %d - %d", head-last, from, to)
+		}
+	}
+
+	for number := from; number <= to; number++ {
+		header, err := m.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return fmt.Errorf("获取区块头 %d 失败: %w", number, err)
+		}
+		if err := m.processBlock(ctx, header); err != nil {
+			return fmt.Errorf("区块 %d: %w", number, err)
+		}
+	}
+	return nil
+}
+
 // processBlock 处理区块
 func (m *BSCMonitor) processBlock(ctx context.Context, header *types.Header) error {
 	blockNumber := header.Number.Uint64()
@@ -135,11 +227,21 @@ func (m *BSCMonitor) processBlock(ctx context.Context, header *types.Header) err
 		return fmt.Errorf("获取区块失败: %w", err)
 	}
 
+	// 代币和 NFT 转入时交易的 to 是代币合约（或市场合约），需要按转账日志的接收方找出相关交易
+	tokenTxs, err := m.incomingTokenTxs(ctx, block.Hash())
+	if err != nil {
+		if m.store != nil {
+			return fmt.Errorf("查询区块 %d 的转入日志失败: %w", blockNumber, err)
+		}
+		log.Printf("查询区块 %d 的转入日志失败: %v", blockNumber, err)
+	}
+
 	// 处理区块中的每个交易
 	var relevantTxCount int
+	var blockEvents []*TokenEvent
 	for _, tx := range block.Transactions() {
 		// 检查是否为监控的交易
-		if m.isWatchedTransaction(tx) {
+		if tokenTxs[tx.Hash()] || m.isWatchedTransaction(tx) {
 			// 第一个相关交易时输出区块日志
 			if relevantTxCount == 0 {
 				log.Printf("🔍 发现相关区块 %d (包含监控地址的交易)", blockNumber)
@@ -147,8 +249,29 @@ func (m *BSCMonitor) processBlock(ctx context.Context, header *types.Header) err
 			relevantTxCount++
 
 			// 处理相关交易
-			if err := m.processTx(ctx, tx, blockNumber, timestamp); err != nil {
+			events, err := m.processTx(ctx, tx, blockNumber, timestamp)
+			if err != nil {
+				if m.store != nil {
+					// 整个区块稍后重试，避免游标越过丢失事件
+					return fmt.Errorf("处理交易 %s 失败: %w", tx.Hash().Hex(), err)
+				}
 				log.Printf("处理交易 %s 失败: %v", tx.Hash().Hex(), err)
+				continue
+			}
+			blockEvents = append(blockEvents, events...)
+		}
+	}
+
+	if m.store != nil {
+		// 事件与游标同一事务提交：要么都写入，要么整个区块重新处理
+		if err := m.store.SaveBlock(ctx, m.cursorName(), blockNumber, blockEvents); err != nil {
+			return fmt.Errorf("保存区块事件失败: %w", err)
+		}
+	} else {
+		// 未配置发件箱时直接触发事件处理器
+		for _, event := range blockEvents {
+			for _, handler := range m.eventHandlers {
+				handler(event)
 			}
 		}
 	}
@@ -161,55 +284,71 @@ func (m *BSCMonitor) processBlock(ctx context.Context, header *types.Header) err
 	return nil
 }
 
-// processTx 处理单个交易（调用前已确认是监控的交易）
-func (m *BSCMonitor) processTx(ctx context.Context, tx *types.Transaction, blockNumber uint64, timestamp int64) error {
+// processTx 处理单个交易（调用前已确认是监控的交易），返回解析出的事件
+func (m *BSCMonitor) processTx(ctx context.Context, tx *types.Transaction, blockNumber uint64, timestamp int64) ([]*TokenEvent, error) {
 	// 获取交易回执
 	receipt, err := m.client.TransactionReceipt(ctx, tx.Hash())
 	if err != nil {
-		return fmt.Errorf("获取交易回执失败: %w", err)
+		return nil, fmt.Errorf("获取交易回执失败: %w", err)
 	}
 
 	// 检查交易状态
 	if receipt.Status != types.ReceiptStatusSuccessful {
 		log.Printf("⚠️  跳过失败交易: %s", tx.Hash().Hex())
-		return nil
+		return nil, nil
 	}
 
 	// 解析交易日志，生成TokenEvent
-	events := m.logParser.ParseLogs(receipt.Logs, tx, blockNumber, timestamp, m.chainId)
+	return m.logParser.ParseLogs(receipt.Logs, tx, blockNumber, timestamp, m.chainId), nil
+}
 
-	// 触发事件处理器
-	for _, event := range events {
-		for _, handler := range m.eventHandlers {
-			handler(event)
-		}
+// incomingTokenTxs 通过 eth_getLogs 查询区块内接收方为监控地址的转账日志，返回所属交易：
+// Transfer（ERC20 / ERC721）的 to 在 topics[2]，ERC1155 TransferSingle / TransferBatch 的 to 在 topics[3]
+func (m *BSCMonitor) incomingTokenTxs(ctx context.Context, blockHash common.Hash) (map[common.Hash]bool, error) {
+	// 空的 topic 列表表示不过滤，没有监控地址时直接返回
+	watched := m.watchAddresses.Topics()
+	if len(watched) == 0 {
+		return nil, nil
 	}
 
-	return nil
+	txs := make(map[common.Hash]bool)
+	for _, topics := range [][][]common.Hash{
+		{{TransferEventSignature}, nil, watched},
+		{{TransferSingleEventSignature, TransferBatchEventSignature}, nil, nil, watched},
+	} {
+		logs, err := m.client.FilterLogs(ctx, ethereum.FilterQuery{BlockHash: &blockHash, Topics: topics})
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range logs {
+			txs[l.TxHash] = true
+		}
+	}
+	return txs, nil
 }
 
-// isWatchedTransaction 检查是否为监控的交易
+// isWatchedTransaction 检查是否为监控的交易（发送方或接收方是监控地址）
 func (m *BSCMonitor) isWatchedTransaction(tx *types.Transaction) bool {
 	// 如果没有设置监控地址，不监控任何交易
-	if len(m.watchAddresses) == 0 {
+	if m.watchAddresses.Len() == 0 {
 		return false
 	}
 
 	// 检查发送方地址
-	// 修复ChainID为0的问题，BSC链ID为56
+	// 修复ChainID为0的问题（EIP-155 之前的交易），使用监控链的链ID
 	txChainID := tx.ChainId()
 	if txChainID == nil || txChainID.Uint64() == 0 {
-		txChainID = big.NewInt(56) // BSC Chain ID
+		txChainID = new(big.Int).SetUint64(m.chainId)
 	}
 	signer := types.LatestSignerForChainID(txChainID)
 	if from, err := signer.Sender(tx); err == nil {
-		if m.watchAddresses[from] {
+		if m.watchAddresses.Contains(from) {
 			return true
 		}
 	}
 
 	// 检查接收方地址
-	if tx.To() != nil && m.watchAddresses[*tx.To()] {
+	if tx.To() != nil && m.watchAddresses.Contains(*tx.To()) {
 		return true
 	}
 
@@ -225,14 +364,44 @@ func MockKafkaProducer(event *TokenEvent) {
 	// producer.Send("token-events", eventJSON)
 }
 
+// logTokenEvent 以可读格式打印事件，chain 用于原生币的符号和精度，lookup 用于代币的符号和精度（均可为空）
+func logTokenEvent(event *TokenEvent, chain *chains.Chain, lookup TokenLookup) {
+	formattedAmount := FormatTokenAmount(event.Amount, event.TokenAddr, chain, lookup)
+	if event.EventType == NFTTransferEventType {
+		formattedAmount = fmt.Sprintf("%s #%s x%s (%s)", event.TokenStandard, event.TokenId, event.Amount, event.TokenAddr)
+	}
+
+	// 为不同方向的事件添加不同的emoji
+	var emoji string
+	switch event.Direction {
+	case "IN":
+		emoji = "📥" // 接收
+	case "OUT":
+		emoji = "📤" // 发送
+	default:
+		emoji = "🔔" // 其他事件
+	}
+
+	// 构建方向标记
+	var directionTag string
+	if event.Direction != "NONE" && event.Direction != "" {
+		directionTag = fmt.Sprintf("-%s", event.Direction)
+	}
+
+	log.Printf("%s EVM事件: %s%s | 金额: %s | 哈希: %s",
+		emoji, event.EventType, directionTag, formattedAmount, event.TxHash[:10]+"...")
+}
+
 // StartBSCMonitoring 启动BSC监控 (对外接口)
-func StartBSCMonitoring(ctx context.Context, wsURL string, watchAddresses []string) error {
+// store 非空时事件写入发件箱，由 OutboxRelay 投递；为空时直接打印，lookup 用于打印代币金额。
+// watchAddresses 可在运行中通过 Set 更新，每个区块使用最新的地址列表
+func StartBSCMonitoring(ctx context.Context, chain *chains.Chain, watchAddresses *WatchList, store EventStore, lookup TokenLookup) error {
 	// 带重连机制的监控启动
-	return StartBSCMonitoringWithReconnect(ctx, wsURL, watchAddresses)
+	return StartBSCMonitoringWithReconnect(ctx, chain, watchAddresses, store, lookup)
 }
 
 // StartBSCMonitoringWithReconnect 带自动重连的BSC监控
-func StartBSCMonitoringWithReconnect(ctx context.Context, wsURL string, watchAddresses []string) error {
+func StartBSCMonitoringWithReconnect(ctx context.Context, chain *chains.Chain, watchAddresses *WatchList, store EventStore, lookup TokenLookup) error {
 	for {
 		select {
 		case <-ctx.Done():
@@ -241,7 +410,7 @@ func StartBSCMonitoringWithReconnect(ctx context.Context, wsURL string, watchAdd
 		default:
 			log.Println("🔄 尝试连接BSC监控...")
 
-			monitor, err := NewBSCMonitor(wsURL, watchAddresses)
+			monitor, err := NewBSCMonitor(chain, watchAddresses, store)
 			if err != nil {
 				log.Printf("❌ 创建BSC监控失败: %v, 5秒后重试...", err)
 				select {
@@ -256,29 +425,7 @@ func StartBSCMonitoringWithReconnect(ctx context.Context, wsURL string, watchAdd
 			monitor.AddEventHandler(MockKafkaProducer)
 
 			// 添加日志事件处理器
-			monitor.AddEventHandler(func(event *TokenEvent) {
-				formattedAmount := FormatTokenAmount(event.Amount, event.TokenAddr, event.ChainId)
-
-				// 为不同方向的事件添加不同的emoji
-				var emoji string
-				switch event.Direction {
-				case "IN":
-					emoji = "📥" // 接收
-				case "OUT":
-					emoji = "📤" // 发送
-				default:
-					emoji = "🔔" // 其他事件
-				}
-
-				// 构建方向标记
-				var directionTag string
-				if event.Direction != "NONE" && event.Direction != "" {
-					directionTag = fmt.Sprintf("-%s", event.Direction)
-				}
-
-				log.Printf("%s EVM事件: %s%s | 金额: %s | 哈希: %s",
-					emoji, event.EventType, directionTag, formattedAmount, event.TxHash[:10]+"...")
-			})
+			monitor.AddEventHandler(func(event *TokenEvent) { logTokenEvent(event, chain, lookup) })
 
 			// 启动监控
 			err = monitor.Start(ctx)
@@ -341,12 +488,12 @@ func (m *BTCMonitor) checkBTCTransactions(ctx context.Context) error {
 }
 
 // StartBTCMonitoring 启动BTC监控
-func StartBTCMonitoring(ctx context.Context, rpcURL string, watchAddresses []string) error {
-	return StartBTCMonitoringWithReconnect(ctx, rpcURL, watchAddresses)
+func StartBTCMonitoring(ctx context.Context, chain *chains.Chain, watchAddresses []string) error {
+	return StartBTCMonitoringWithReconnect(ctx, chain, watchAddresses)
 }
 
 // StartBTCMonitoringWithReconnect 带自动重连的BTC监控
-func StartBTCMonitoringWithReconnect(ctx context.Context, rpcURL string, watchAddresses []string) error {
+func StartBTCMonitoringWithReconnect(ctx context.Context, chain *chains.Chain, watchAddresses []string) error {
 	for {
 		select {
 		case <-ctx.Done():
@@ -355,7 +502,7 @@ func StartBTCMonitoringWithReconnect(ctx context.Context, rpcURL string, watchAd
 		default:
 			log.Println("🔄 尝试连接Bitcoin测试网监控...")
 
-			monitor, err := NewBTCMonitor(rpcURL, watchAddresses)
+			monitor, err := NewBTCMonitor(chain, watchAddresses)
 			if err != nil {
 				log.Printf("❌ 创建Bitcoin监控失败: %v, 5秒后重试...", err)
 				select {
@@ -0,0 +1,30 @@
+package monitor
+
+import (
+	"testing"
+
+	"demo/internal/chains"
+	"demo/internal/config"
+)
+
+func TestConfirmedHead(t *testing.T) {
+	tests := []struct {
+		confirmations int
+		head          uint64
+		want          uint64
+		ok            bool
+	}{
+		{confirmations: 0, head: 100, want: 100, ok: true},
+		{confirmations: 1, head: 100, want: 100, ok: true},
+		{confirmations: 15, head: 100, want: 86, ok: true},
+		{confirmations: 15, head: 14, want: 0, ok: true},
+		{confirmations: 15, head: 13, ok: false},
+	}
+	for _, tt := range tests {
+		m := &BSCMonitor{chain: &chains.Chain{Key: "BSC", ChainConf: config.ChainConf{Confirmations: tt.confirmations}}}
+		got, ok := m.confirmedHead(tt.head)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("confirmations=%d head=%d: got %d %v, want %d %v", tt.confirmations, tt.head, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"demo/internal/chains"
+	"demo/internal/config"
+	"demo/internal/model"
+)
+
+// EventStore 持久化区块事件与监控游标
+type EventStore interface {
+	// LastBlock 返回最后一个已完整处理的区块，ok=false 表示尚无记录
+	LastBlock(ctx context.Context, cursor string) (block uint64, ok bool, err error)
+	// SaveBlock 在同一事务内写入区块事件并推进游标
+	SaveBlock(ctx context.Context, cursor string, block uint64, events []*TokenEvent) error
+}
+
+type outboxStore struct {
+	dao model.EventOutboxDao
+}
+
+// NewOutboxStore 基于发件箱表的 EventStore
+func NewOutboxStore(dao model.EventOutboxDao) EventStore {
+	return &outboxStore{dao: dao}
+}
+
+func (s *outboxStore) LastBlock(ctx context.Context, cursor string) (uint64, bool, error) {
+	block, err := s.dao.FindCursor(ctx, cursor)
+	if errors.Is(err, model.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return block, true, nil
+}
+
+func (s *outboxStore) SaveBlock(ctx context.Context, cursor string, block uint64, events []*TokenEvent) error {
+	rows := make([]*model.TokenEventOutbox, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("marshal event %s: %w", event.DedupKey(), err)
+		}
+		rows = append(rows, &model.TokenEventOutbox{
+			DedupKey:    event.DedupKey(),
+			ChainId:     event.ChainId,
+			BlockNumber: event.BlockNumber,
+			TxHash:      event.TxHash,
+			EventType:   event.EventType,
+			Payload:     string(payload),
+		})
+	}
+	return s.dao.SaveBlock(ctx, cursor, block, rows)
+}
+
+// EventSink 事件投递目标（Kafka / Webhook 等）。
+// 同一事件可能被投递多次，消费者需按 dedupKey 去重。
+type EventSink interface {
+	Publish(ctx context.Context, dedupKey string, event *TokenEvent) error
+}
+
+// NewEventSink 根据配置创建投递目标，lookup 用于日志中代币金额的格式化
+func NewEventSink(c config.OutboxConf, registry *chains.Registry, lookup TokenLookup) (EventSink, error) {
+	switch c.Sink {
+	case "", "log":
+		return LogSink{chains: registry, tokens: lookup}, nil
+	case "webhook":
+		if c.WebhookUrl == "" {
+			return nil, errors.New("outbox webhook sink requires WebhookUrl")
+		}
+		return &WebhookSink{url: c.WebhookUrl, client: &http.Client{Timeout: 10 * time.Second}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported outbox sink: %s", c.Sink)
+	}
+}
+
+// LogSink 打印事件，替代尚未接入的 Kafka
+type LogSink struct {
+	chains *chains.Registry
+	tokens TokenLookup
+}
+
+func (s LogSink) Publish(_ context.Context, dedupKey string, event *TokenEvent) error {
+	log.Printf("🔑 dedupKey: %s", dedupKey)
+	MockKafkaProducer(event)
+	var chain *chains.Chain
+	if s.chains != nil {
+		chain, _ = s.chains.ByChainId(int64(event.ChainId))
+	}
+	logTokenEvent(event, chain, s.tokens)
+	return nil
+}
+
+// WebhookSink 以 HTTP POST 投递事件，dedupKey 放在 Idempotency-Key 头中
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, dedupKey string, event *TokenEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", dedupKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %d: %s", resp.StatusCode, string(msg))
+	}
+	return nil
+}
+
+// OutboxRelay 轮询发件箱，把待投递事件发布到 sink，失败按指数退避重试
+type OutboxRelay struct {
+	dao          model.EventOutboxDao
+	sink         EventSink
+	batchSize    int
+	pollInterval time.Duration
+	maxAttempts  int
+}
+
+// NewOutboxRelay 创建发件箱投递器
+func NewOutboxRelay(dao model.EventOutboxDao, sink EventSink, c config.OutboxConf) *OutboxRelay {
+	relay := &OutboxRelay{
+		dao:          dao,
+		sink:         sink,
+		batchSize:    c.BatchSize,
+		pollInterval: time.Duration(c.PollInterval) * time.Second,
+		maxAttempts:  c.MaxAttempts,
+	}
+	if relay.batchSize <= 0 {
+		relay.batchSize = 100
+	}
+	if relay.pollInterval <= 0 {
+		relay.pollInterval = 2 * time.Second
+	}
+	if relay.maxAttempts <= 0 {
+		relay.maxAttempts = 10
+	}
+	return relay
+}
+
+// Run 持续投递直到 ctx 取消
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	log.Println("🚚 TokenEvent 发件箱投递服务已启动")
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// 一批处理满时立即继续，否则等待下一个轮询周期
+		n, err := r.relayOnce(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("❌ 发件箱投递失败: %v", err)
+		}
+		if n == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("✅ TokenEvent 发件箱投递服务已停止")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// relayOnce 投递一批到期事件，返回处理的条数
+func (r *OutboxRelay) relayOnce(ctx context.Context) (int, error) {
+	rows, err := r.dao.FindDue(ctx, time.Now(), r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+
+		var event TokenEvent
+		if err := json.Unmarshal([]byte(row.Payload), &event); err != nil {
+			// 无法解析的事件不会因重试而变好，直接标记为 dead
+			log.Printf("❌ 发件箱事件 %s 解析失败: %v", row.DedupKey, err)
+			if err := r.dao.MarkDead(ctx, row.Id, row.Attempts+1, err.Error()); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		if err := r.sink.Publish(ctx, row.DedupKey, &event); err != nil {
+			attempts := row.Attempts + 1
+			if attempts >= r.maxAttempts {
+				log.Printf("☠️ 事件 %s 投递失败 %d 次，标记为 dead: %v", row.DedupKey, attempts, err)
+				if err := r.dao.MarkDead(ctx, row.Id, attempts, err.Error()); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			next := time.Now().Add(retryBackoff(attempts))
+			log.Printf("⚠️ 事件 %s 第 %d 次投递失败，%s 后重试: %v", row.DedupKey, attempts, time.Until(next).Round(time.Second), err)
+			if err := r.dao.MarkRetry(ctx, row.Id, attempts, next, err.Error()); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		if err := r.dao.MarkDelivered(ctx, row.Id); err != nil {
+			// 已投递但未标记成功，下次会重复投递，由 dedupKey 去重
+			return 0, err
+		}
+	}
+	return len(rows), nil
+}
+
+// retryBackoff 指数退避：2s, 4s, 8s ... 最长 5 分钟
+func retryBackoff(attempts int) time.Duration {
+	backoff := time.Second << uint(min(attempts, 9))
+	return min(backoff, 5*time.Minute)
+}
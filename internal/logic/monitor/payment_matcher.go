@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"demo/internal/model"
+)
+
+// nativeTokenAddr 监控中原生币转账使用的代币地址
+const nativeTokenAddr = "0x0000000000000000000000000000000000000000"
+
+// PaymentMatcher 将入账的 Transfer / NativeTransfer 事件与待支付的收款请求匹配。
+// 作为发件箱的一个投递目标运行，按事件键去重：重复投递同一事件不会重复匹配，
+// 同一笔交易中付给多个地址的事件（如批量转账）各自匹配。
+type PaymentMatcher struct {
+	dao model.PaymentRequestsDao
+}
+
+func NewPaymentMatcher(dao model.PaymentRequestsDao) *PaymentMatcher {
+	return &PaymentMatcher{dao: dao}
+}
+
+func (m *PaymentMatcher) Publish(ctx context.Context, dedupKey string, event *TokenEvent) error {
+	if event.Direction != "IN" {
+		return nil
+	}
+
+	var token string
+	switch event.EventType {
+	case "NativeTransfer":
+	case "Transfer":
+		token = strings.ToLower(event.TokenAddr)
+	default:
+		return nil
+	}
+	if token == nativeTokenAddr {
+		token = ""
+	}
+
+	pr, err := m.dao.MatchIncoming(ctx, int64(event.ChainId), strings.ToLower(event.ToAddr), token, event.Amount, event.TxHash, dedupKey)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("💰 收款请求 %s 已支付: tx=%s, amount=%s", pr.RequestId, pr.PaidTxHash, pr.PaidAmount)
+	return nil
+}
+
+// MultiSink 依次投递到多个目标，任一失败则整体重试（各目标需自行幂等）
+type MultiSink []EventSink
+
+func (s MultiSink) Publish(ctx context.Context, dedupKey string, event *TokenEvent) error {
+	for _, sink := range s {
+		if err := sink.Publish(ctx, dedupKey, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
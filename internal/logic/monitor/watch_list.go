@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// WatchList 监控的 EVM 地址集合，由所有链的监控器共享，可在运行中整体替换（新建的钱包无需重启即可被监控）
+type WatchList struct {
+	mu    sync.RWMutex
+	addrs map[common.Address]bool
+}
+
+func NewWatchList() *WatchList {
+	return &WatchList{addrs: make(map[common.Address]bool)}
+}
+
+// Set 替换监控地址，非 EVM 地址被忽略，返回替换后的地址数
+func (w *WatchList) Set(addresses []string) int {
+	addrs := make(map[common.Address]bool, len(addresses))
+	for _, addr := range addresses {
+		if common.IsHexAddress(addr) {
+			addrs[common.HexToAddress(addr)] = true
+		}
+	}
+	w.mu.Lock()
+	w.addrs = addrs
+	w.mu.Unlock()
+	return len(addrs)
+}
+
+// Contains 地址是否在监控列表中
+func (w *WatchList) Contains(addr common.Address) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.addrs[addr]
+}
+
+// Len 监控的地址数
+func (w *WatchList) Len() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.addrs)
+}
+
+// Topics 监控地址左补零后的 32 字节形式，用作 eth_getLogs 中 indexed address 参数的过滤条件
+func (w *WatchList) Topics() []common.Hash {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	topics := make([]common.Hash, 0, len(w.addrs))
+	for addr := range w.addrs {
+		topics = append(topics, common.BytesToHash(addr.Bytes()))
+	}
+	return topics
+}
@@ -0,0 +1,330 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"demo/internal/logic/transaction"
+	"demo/internal/model"
+	"demo/internal/svc"
+	"demo/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ScheduleLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewScheduleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ScheduleLogic {
+	return &ScheduleLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// Create 校验并保存定时转账；链、地址、发送钱包和金额在保存时检查，余额和手续费在每次执行时检查
+func (l *ScheduleLogic) Create(req *types.ScheduleCreateReq) (*types.ScheduleResp, error) {
+	adapter, err := transaction.NewChainAdapter(l.ctx, l.svcCtx, req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	c := adapter.Chain()
+	if err := adapter.ValidateAddress(req.FromAddress); err != nil {
+		return nil, fmt.Errorf("invalid from_address: %w", err)
+	}
+	if err := adapter.ValidateAddress(req.ToAddress); err != nil {
+		return nil, fmt.Errorf("invalid to_address: %w", err)
+	}
+	txLogic := transaction.NewTransactionLogic(l.ctx, l.svcCtx)
+	if err := txLogic.CheckSender(c, req.FromAddress); err != nil {
+		return nil, err
+	}
+
+	if req.ToToken == "" {
+		if req.Kind == model.ScheduleKindSwap {
+			return nil, errors.New("to_token is required for swap schedules")
+		}
+		req.ToToken = req.FromToken
+	}
+	if req.Kind == model.ScheduleKindSwap {
+		if _, ok := adapter.(transaction.SwapAdapter); !ok {
+			return nil, fmt.Errorf("swap is not supported on chain %s", c.Key)
+		}
+	}
+	if err := txLogic.ValidateAmount(c, req.FromToken, req.Amount, req.AmountUnit, req.Kind == model.ScheduleKindSwap); err != nil {
+		return nil, err
+	}
+
+	s := &model.ScheduledTransfers{
+		Name:        strings.TrimSpace(req.Name),
+		Kind:        req.Kind,
+		Chain:       c.Key,
+		FromAddress: req.FromAddress,
+		ToAddress:   req.ToAddress,
+		FromToken:   req.FromToken,
+		ToToken:     req.ToToken,
+		Amount:      strings.TrimSpace(req.Amount),
+		AmountUnit:  req.AmountUnit,
+		FeeLevel:    req.FeeLevel,
+		Cron:        strings.TrimSpace(req.Cron),
+		Timezone:    req.Timezone,
+		CatchUp:     req.CatchUp,
+		MaxRuns:     req.MaxRuns,
+		Status:      model.ScheduleStatusActive,
+	}
+	// 创建时先按 Policy.Scheduled 检查收款地址和单次限额，尽早拒绝注定无法执行的任务；每次执行前仍会重新检查
+	preq, err := policyRequest(l.ctx, l.svcCtx, s)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.svcCtx.Policy.Check(preq); err != nil {
+		return nil, err
+	}
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	if s.CatchUp == "" {
+		s.CatchUp = model.ScheduleCatchUpOnce
+	}
+	if err := l.setTiming(s, req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s.CreatedAt, s.UpdatedAt = now, now
+	if err := l.svcCtx.SchedulesDao.Insert(l.ctx, s); err != nil {
+		return nil, err
+	}
+	l.Infof("⏰ 已创建定时%s #%d: %s %s -> %s，下次执行 %s", s.Kind, s.Id, s.Chain, s.FromAddress, s.ToAddress, s.NextRunAt.Format(time.RFC3339))
+	return toScheduleResp(s), nil
+}
+
+// setTiming 校验 run_at / cron、时区、次数和结束时间，并计算第一次执行时间
+func (l *ScheduleLogic) setTiming(s *model.ScheduledTransfers, req *types.ScheduleCreateReq) error {
+	if (req.RunAt == 0) == (s.Cron == "") {
+		return errors.New("exactly one of run_at and cron is required")
+	}
+	if req.MaxRuns < 0 {
+		return errors.New("max_runs must not be negative")
+	}
+	now := time.Now()
+	if req.EndAt != 0 {
+		endAt := time.Unix(req.EndAt, 0)
+		if !endAt.After(now) {
+			return errors.New("end_at is in the past")
+		}
+		s.EndAt = &endAt
+	}
+	t, err := newTiming(s)
+	if err != nil {
+		return err
+	}
+
+	if req.RunAt != 0 {
+		runAt := time.Unix(req.RunAt, 0)
+		if !runAt.After(now) {
+			return errors.New("run_at is in the past")
+		}
+		if s.EndAt != nil && runAt.After(*s.EndAt) {
+			return errors.New("run_at is after end_at")
+		}
+		s.RunAt, s.NextRunAt = &runAt, &runAt
+		return nil
+	}
+	if s.NextRunAt = t.next(now); s.NextRunAt == nil {
+		return fmt.Errorf("cron expression %q has no run in the next years or before end_at", s.Cron)
+	}
+	return nil
+}
+
+// Pause 暂停执行中的定时转账，已在执行的那一次不受影响
+func (l *ScheduleLogic) Pause(req *types.ScheduleIdReq) (*types.ScheduleResp, error) {
+	s, err := l.find(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Status != model.ScheduleStatusActive {
+		return nil, fmt.Errorf("schedule %d is %s, only active schedules can be paused", s.Id, s.Status)
+	}
+	s.Status = model.ScheduleStatusPaused
+	if err := l.update(s); err != nil {
+		return nil, err
+	}
+	l.Infof("⏸️  定时转账 #%d 已暂停", s.Id)
+	return toScheduleResp(s), nil
+}
+
+// Resume 恢复已暂停的定时转账并清零连续失败次数；暂停期间错过的执行不会补执行，
+// 一次性任务的预定时间已过时立即执行
+func (l *ScheduleLogic) Resume(req *types.ScheduleIdReq) (*types.ScheduleResp, error) {
+	s, err := l.find(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Status != model.ScheduleStatusPaused {
+		return nil, fmt.Errorf("schedule %d is %s, only paused schedules can be resumed", s.Id, s.Status)
+	}
+	t, err := newTiming(s)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if s.Cron == "" {
+		next := now
+		if s.RunAt.After(now) {
+			next = *s.RunAt
+		}
+		s.NextRunAt = &next
+	} else if s.NextRunAt = t.next(now); s.NextRunAt == nil {
+		return nil, fmt.Errorf("schedule %d has no run left before end_at, cancel it instead", s.Id)
+	}
+	s.Status = model.ScheduleStatusActive
+	if err := l.update(s); err != nil {
+		return nil, err
+	}
+	if _, err := l.svcCtx.SchedulesDao.RecordOutcome(l.ctx, s.Id, "", 0); err != nil {
+		return nil, err
+	}
+	s.ConsecutiveFailures, s.LastError = 0, ""
+	l.Infof("▶️  定时转账 #%d 已恢复，下次执行 %s", s.Id, s.NextRunAt.Format(time.RFC3339))
+	return toScheduleResp(s), nil
+}
+
+// Cancel 取消定时转账，不能再恢复
+func (l *ScheduleLogic) Cancel(req *types.ScheduleIdReq) (*types.ScheduleResp, error) {
+	s, err := l.find(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Status != model.ScheduleStatusActive && s.Status != model.ScheduleStatusPaused {
+		return nil, fmt.Errorf("schedule %d is already %s", s.Id, s.Status)
+	}
+	s.Status, s.NextRunAt = model.ScheduleStatusCancelled, nil
+	if err := l.update(s); err != nil {
+		return nil, err
+	}
+	l.Infof("🛑 定时转账 #%d 已取消", s.Id)
+	return toScheduleResp(s), nil
+}
+
+// List 分页查询定时转账
+func (l *ScheduleLogic) List(req *types.ScheduleListReq) (*types.ScheduleListResp, error) {
+	rows, total, err := l.svcCtx.SchedulesDao.FindPage(l.ctx, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	resp := &types.ScheduleListResp{Total: total, Items: make([]types.ScheduleResp, 0, len(rows))}
+	for _, s := range rows {
+		resp.Items = append(resp.Items, *toScheduleResp(s))
+	}
+	return resp, nil
+}
+
+// Runs 分页查询执行记录，并从交易记录中带出交易状态
+func (l *ScheduleLogic) Runs(req *types.ScheduleRunsReq) (*types.ScheduleRunsResp, error) {
+	chains := make(map[int64]string)
+	if req.Id > 0 {
+		s, err := l.find(req.Id)
+		if err != nil {
+			return nil, err
+		}
+		chains[s.Id] = s.Chain
+	}
+	runs, total, err := l.svcCtx.ScheduleRunsDao.FindPage(l.ctx, req.Id, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.ScheduleRunsResp{Total: total, Items: make([]types.ScheduleRunResp, 0, len(runs))}
+	for _, run := range runs {
+		item := types.ScheduleRunResp{
+			Id:           run.Id,
+			ScheduleId:   run.ScheduleId,
+			ScheduledFor: run.ScheduledFor.Unix(),
+			CatchUp:      run.CatchUp,
+			Status:       run.Status,
+			TxHash:       run.TxHash,
+			Error:        run.ErrorMessage,
+			StartedAt:    run.StartedAt.Unix(),
+			FinishedAt:   unix(run.FinishedAt),
+		}
+		if run.TxHash != "" {
+			chain, ok := chains[run.ScheduleId]
+			if !ok {
+				if s, err := l.svcCtx.SchedulesDao.FindOne(l.ctx, run.ScheduleId); err == nil {
+					chain = s.Chain
+				}
+				chains[run.ScheduleId] = chain
+			}
+			if tx, err := l.svcCtx.TransactionsDao.FindOneByHash(l.ctx, chain, run.TxHash); err == nil {
+				item.TxStatus, item.ExplorerUrl = tx.Status, tx.ExplorerUrl
+			}
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	return resp, nil
+}
+
+func (l *ScheduleLogic) find(id int64) (*model.ScheduledTransfers, error) {
+	s, err := l.svcCtx.SchedulesDao.FindOne(l.ctx, id)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, fmt.Errorf("schedule %d not found", id)
+	}
+	return s, err
+}
+
+// update 保存状态变更；调度器同时推进了该任务时返回冲突错误，由调用方重试
+func (l *ScheduleLogic) update(s *model.ScheduledTransfers) error {
+	ok, err := l.svcCtx.SchedulesDao.Update(l.ctx, s)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("schedule %d was modified concurrently, please retry", s.Id)
+	}
+	return nil
+}
+
+func unix(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+func toScheduleResp(s *model.ScheduledTransfers) *types.ScheduleResp {
+	return &types.ScheduleResp{
+		Id:                  s.Id,
+		Name:                s.Name,
+		Kind:                s.Kind,
+		Chain:               s.Chain,
+		FromAddress:         s.FromAddress,
+		ToAddress:           s.ToAddress,
+		FromToken:           s.FromToken,
+		ToToken:             s.ToToken,
+		Amount:              s.Amount,
+		AmountUnit:          s.AmountUnit,
+		FeeLevel:            s.FeeLevel,
+		RunAt:               unix(s.RunAt),
+		Cron:                s.Cron,
+		Timezone:            s.Timezone,
+		CatchUp:             s.CatchUp,
+		MaxRuns:             s.MaxRuns,
+		EndAt:               unix(s.EndAt),
+		Status:              s.Status,
+		NextRunAt:           unix(s.NextRunAt),
+		LastRunAt:           unix(s.LastRunAt),
+		RunCount:            s.RunCount,
+		ConsecutiveFailures: s.ConsecutiveFailures,
+		LastError:           s.LastError,
+		CreatedAt:           s.CreatedAt.Unix(),
+	}
+}
@@ -0,0 +1,391 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"demo/internal/logic/transaction"
+	"demo/internal/model"
+	"demo/internal/policy"
+	"demo/internal/svc"
+	"demo/internal/types"
+)
+
+// dueBatch 每次检查最多取出的到期任务数，其余留到下一次检查
+const dueBatch = 100
+
+// running 正在处理的定时转账 id，避免执行较慢时下一次检查重复处理
+var running sync.Map
+
+// senderLocks 同一链上同一发送钱包的任务串行执行，上一笔被节点接受后才构建下一笔，避免 nonce 冲突
+var senderLocks sync.Map
+
+func lockSender(s *model.ScheduledTransfers) func() {
+	mu, _ := senderLocks.LoadOrStore(s.Chain+":"+strings.ToLower(s.FromAddress), &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// scheduleAudit 定时执行的审计详情
+type scheduleAudit struct {
+	ScheduleId   int64  `json:"schedule_id"`
+	Kind         string `json:"kind"`
+	ScheduledFor string `json:"scheduled_for"`
+	CatchUp      bool   `json:"catch_up,omitempty"`
+	ToAddress    string `json:"to_address"`
+	FromToken    string `json:"from_token"`
+	ToToken      string `json:"to_token,omitempty"`
+	Amount       string `json:"amount"`
+	AmountUnit   string `json:"amount_unit,omitempty"`
+	TxHash       string `json:"tx_hash,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// occurrence 一次要执行的预定时间
+type occurrence struct {
+	at      time.Time
+	catchUp bool
+}
+
+// plan 一个到期任务本次要执行和跳过的预定时间
+type plan struct {
+	runs         []occurrence
+	skipped      int
+	skippedFirst time.Time
+	skippedLast  time.Time
+}
+
+// StartScheduler 定时检查到期的定时转账并执行，ctx 取消时停止；阻塞运行，调用方应在独立 goroutine 中启动
+func StartScheduler(ctx context.Context, svcCtx *svc.ServiceContext) {
+	// 上次进程退出时正在执行的那一次已经推进了 next_run_at，不会重新执行，避免重复付款
+	if n, err := svcCtx.ScheduleRunsDao.FailRunning(ctx, "interrupted by restart, not retried to avoid a duplicate payment"); err != nil {
+		log.Printf("❌ 清理未完成的定时转账记录失败: %v", err)
+	} else if n > 0 {
+		log.Printf("⚠️  %d 条未完成的定时转账记录已标记为失败", n)
+	}
+
+	log.Println("⏰ 定时转账调度已启动")
+	ticker := time.NewTicker(time.Duration(svcCtx.Config.Schedule.CheckInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		dispatch(ctx, svcCtx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("✅ 定时转账调度已停止")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch 取出到期的任务，每个任务在独立的 goroutine 中处理，同一发送钱包的任务依次执行
+func dispatch(ctx context.Context, svcCtx *svc.ServiceContext) {
+	due, err := svcCtx.SchedulesDao.FindDue(ctx, time.Now(), dueBatch)
+	if err != nil {
+		log.Printf("❌ 查询到期的定时转账失败: %v", err)
+		return
+	}
+	for _, s := range due {
+		if _, loaded := running.LoadOrStore(s.Id, struct{}{}); loaded {
+			continue
+		}
+		go func(s *model.ScheduledTransfers) {
+			defer running.Delete(s.Id)
+			defer lockSender(s)()
+			NewScheduleLogic(ctx, svcCtx).process(s)
+		}(s)
+	}
+}
+
+// process 按 catch_up 策略处理一个到期任务：每次执行前先推进 next_run_at（认领），
+// 认领失败说明任务已被暂停、取消或由其他实例处理，保证同一预定时间最多执行一次
+func (l *ScheduleLogic) process(s *model.ScheduledTransfers) {
+	t, err := newTiming(s)
+	if err != nil {
+		l.Errorf("❌ 定时转账 #%d 无法计算执行时间: %v", s.Id, err)
+		return
+	}
+	conf := l.svcCtx.Config.Schedule
+	now := time.Now()
+	p := makePlan(t, now, time.Duration(conf.MisfireGrace)*time.Second, s.CatchUp, conf.MaxCatchUp)
+
+	if len(p.runs) == 0 {
+		if l.claim(s, t.next(now), false) {
+			l.recordSkipped(s, p)
+		}
+		return
+	}
+	for i, occ := range p.runs {
+		next := t.next(now)
+		if i+1 < len(p.runs) {
+			next = &p.runs[i+1].at
+		}
+		if !l.claim(s, next, true) {
+			return
+		}
+		if i == 0 {
+			l.recordSkipped(s, p)
+		}
+		l.execute(s, occ)
+	}
+}
+
+// makePlan 区分按时和错过的预定时间：距今不超过 grace 的最近一次按时执行，更早的按 catch_up 策略补执行或跳过
+func makePlan(t *timing, now time.Time, grace time.Duration, catchUp string, maxCatchUp int) *plan {
+	first, tail, count := t.missed(now, maxCatchUp+1)
+	p := &plan{}
+	if count == 0 {
+		return p
+	}
+	last := tail[len(tail)-1]
+	onTime := now.Sub(last) <= grace
+	missed := count
+	if onTime {
+		missed--
+	}
+
+	caughtUp := 0
+	switch catchUp {
+	case model.ScheduleCatchUpAll:
+		for caughtUp < missed && caughtUp < maxCatchUp {
+			p.runs = append(p.runs, occurrence{at: first[caughtUp], catchUp: true})
+			caughtUp++
+		}
+	case model.ScheduleCatchUpOnce:
+		// 按时的那一次已经覆盖错过的执行
+		if !onTime {
+			p.runs = append(p.runs, occurrence{at: last, catchUp: true})
+			caughtUp = 1
+		}
+	}
+	if onTime {
+		p.runs = append(p.runs, occurrence{at: last})
+	}
+
+	if p.skipped = missed - caughtUp; p.skipped > 0 {
+		p.skippedFirst, p.skippedLast = first[0], last
+		if catchUp == model.ScheduleCatchUpAll {
+			p.skippedFirst = first[caughtUp]
+		}
+		// 最后一次已执行时，跳过的是它之前的那些
+		if onTime || catchUp == model.ScheduleCatchUpOnce {
+			p.skippedLast = tail[0]
+		}
+	}
+	return p
+}
+
+// claim 推进 next_run_at（执行前同时增加执行次数），没有下一次或达到 max_runs 时标记为完成
+func (l *ScheduleLogic) claim(s *model.ScheduledTransfers, next *time.Time, execute bool) bool {
+	if s.Status != model.ScheduleStatusActive {
+		return false
+	}
+	prev := *s
+	if execute {
+		now := time.Now()
+		s.RunCount++
+		s.LastRunAt = &now
+	}
+	s.NextRunAt = next
+	if next == nil || (s.MaxRuns > 0 && s.RunCount >= s.MaxRuns) {
+		s.Status, s.NextRunAt = model.ScheduleStatusCompleted, nil
+	}
+
+	ok, err := l.svcCtx.SchedulesDao.Update(context.WithoutCancel(l.ctx), s)
+	if err != nil || !ok {
+		*s = prev
+		if err != nil {
+			l.Errorf("❌ 更新定时转账 #%d 失败: %v", s.Id, err)
+		} else {
+			l.Infof("定时转账 #%d 已被暂停、取消或由其他实例处理，跳过本次执行", s.Id)
+		}
+		return false
+	}
+	if s.Status == model.ScheduleStatusCompleted {
+		l.Infof("✅ 定时转账 #%d 已完成，共执行 %d 次", s.Id, s.RunCount)
+	}
+	return true
+}
+
+// recordSkipped 把按策略跳过的预定时间合并记为一条 skipped 执行记录
+func (l *ScheduleLogic) recordSkipped(s *model.ScheduledTransfers, p *plan) {
+	if p.skipped == 0 {
+		return
+	}
+	now := time.Now()
+	msg := fmt.Sprintf("missed the run at %s, skipped by catch_up=%s", p.skippedFirst.Format(time.RFC3339), s.CatchUp)
+	if p.skipped > 1 {
+		msg = fmt.Sprintf("missed %d runs between %s and %s, skipped by catch_up=%s",
+			p.skipped, p.skippedFirst.Format(time.RFC3339), p.skippedLast.Format(time.RFC3339), s.CatchUp)
+	}
+	l.Infof("⚠️  定时转账 #%d %s", s.Id, msg)
+	run := &model.ScheduledTransferRuns{
+		ScheduleId:   s.Id,
+		ScheduledFor: p.skippedFirst,
+		Status:       model.ScheduleRunStatusSkipped,
+		ErrorMessage: msg,
+		StartedAt:    now,
+		FinishedAt:   &now,
+	}
+	if err := l.svcCtx.ScheduleRunsDao.Insert(context.WithoutCancel(l.ctx), run); err != nil {
+		l.Errorf("保存定时转账跳过记录失败: %v", err)
+	}
+}
+
+// execute 执行一次已认领的预定时间，写入执行记录并更新连续失败次数
+func (l *ScheduleLogic) execute(s *model.ScheduledTransfers, occ occurrence) {
+	dbCtx := context.WithoutCancel(l.ctx)
+	run := &model.ScheduledTransferRuns{
+		ScheduleId:   s.Id,
+		ScheduledFor: occ.at,
+		CatchUp:      occ.catchUp,
+		Status:       model.ScheduleRunStatusRunning,
+		StartedAt:    time.Now(),
+	}
+	if err := l.svcCtx.ScheduleRunsDao.Insert(dbCtx, run); err != nil {
+		l.Errorf("❌ 保存定时转账执行记录失败，本次不执行 #%d: %v", s.Id, err)
+		return
+	}
+
+	l.Infof("⏰ 执行定时%s #%d（预定 %s，补执行 %v）: %s %s %s -> %s",
+		s.Kind, s.Id, occ.at.Format(time.RFC3339), occ.catchUp, s.Chain, s.Amount, s.FromToken, s.ToAddress)
+	preq, err := l.authorize(s, occ)
+	var resp *types.TransactionResp
+	if err == nil {
+		resp, err = l.submit(s)
+	}
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = model.ScheduleRunStatusSucceeded
+	errMsg := ""
+	var violation *policy.Violation
+	switch {
+	case errors.As(err, &violation):
+		run.Status, run.ErrorMessage, errMsg = model.ScheduleRunStatusDenied, err.Error(), err.Error()
+		l.Errorf("🚫 定时转账 #%d 被策略拒绝，本次不执行: %v", s.Id, err)
+	case err != nil:
+		run.Status, run.ErrorMessage, errMsg = model.ScheduleRunStatusFailed, err.Error(), err.Error()
+		l.Errorf("❌ 定时转账 #%d 执行失败: %v", s.Id, err)
+	default:
+		run.TxHash = resp.TxHash
+		l.Infof("✅ 定时转账 #%d 已提交: %s", s.Id, resp.TxHash)
+	}
+	l.audit(preq, s, occ, run)
+	if err := l.svcCtx.ScheduleRunsDao.Finish(dbCtx, run); err != nil {
+		l.Errorf("更新定时转账执行记录失败: %v", err)
+	}
+
+	paused, err := l.svcCtx.SchedulesDao.RecordOutcome(dbCtx, s.Id, errMsg, l.svcCtx.Config.Schedule.MaxFailures)
+	if err != nil {
+		l.Errorf("更新定时转账失败次数失败: %v", err)
+	}
+	if paused {
+		s.Status = model.ScheduleStatusPaused
+		l.Errorf("⏸️  定时转账 #%d 连续失败 %d 次，已自动暂停", s.Id, l.svcCtx.Config.Schedule.MaxFailures)
+	}
+}
+
+// authorize 执行前按 Policy.Scheduled 检查收款地址和单次限额，配置了审批接口时等待批准；
+// 拒绝时返回 *policy.Violation，返回的策略请求用于审计日志
+func (l *ScheduleLogic) authorize(s *model.ScheduledTransfers, occ occurrence) (*policy.Request, error) {
+	preq, err := policyRequest(l.ctx, l.svcCtx, s)
+	if err != nil {
+		return preq, err
+	}
+	if err := l.svcCtx.Policy.Check(preq); err != nil {
+		return preq, err
+	}
+	return preq, l.svcCtx.Policy.Approve(l.ctx, preq, auditDetail(s, occ, nil))
+}
+
+// policyRequest 把定时任务转换为策略请求，金额换算为最小单位；创建任务和每次执行时使用
+func policyRequest(ctx context.Context, svcCtx *svc.ServiceContext, s *model.ScheduledTransfers) (*policy.Request, error) {
+	preq := &policy.Request{
+		Action:    policy.ActionScheduledTransfer,
+		Chain:     s.Chain,
+		Address:   s.FromAddress,
+		Recipient: s.ToAddress,
+		Token:     s.FromToken,
+		Decimals:  -1,
+	}
+	c, ok := svcCtx.Chains.Get(s.Chain)
+	if !ok {
+		return preq, fmt.Errorf("unsupported chain: %s", s.Chain)
+	}
+	amount, err := transaction.NewTransactionLogic(ctx, svcCtx).ResolveAmount(c, s.FromToken, s.Amount, s.AmountUnit)
+	if err != nil {
+		return preq, err
+	}
+	preq.Amount = amount.Base
+	if t := amount.Token(); t != nil {
+		preq.Token, preq.Decimals = t.Symbol, t.Decimals
+		if !t.Native {
+			preq.TokenAddress = t.Address
+		}
+	}
+	return preq, nil
+}
+
+// auditDetail 定时执行的审计详情，run 为空时是执行前发给审批接口的内容
+func auditDetail(s *model.ScheduledTransfers, occ occurrence, run *model.ScheduledTransferRuns) *scheduleAudit {
+	detail := &scheduleAudit{
+		ScheduleId:   s.Id,
+		Kind:         s.Kind,
+		ScheduledFor: occ.at.Format(time.RFC3339),
+		CatchUp:      occ.catchUp,
+		ToAddress:    s.ToAddress,
+		FromToken:    s.FromToken,
+		Amount:       s.Amount,
+		AmountUnit:   s.AmountUnit,
+	}
+	if s.Kind == model.ScheduleKindSwap {
+		detail.ToToken = s.ToToken
+	}
+	if run != nil {
+		detail.TxHash, detail.Reason = run.TxHash, run.ErrorMessage
+	}
+	return detail
+}
+
+// audit 每次执行写入一条审计日志
+func (l *ScheduleLogic) audit(preq *policy.Request, s *model.ScheduledTransfers, occ occurrence, run *model.ScheduledTransferRuns) {
+	result := model.AuditResultSuccess
+	switch run.Status {
+	case model.ScheduleRunStatusFailed:
+		result = model.AuditResultFailure
+	case model.ScheduleRunStatusDenied:
+		result = model.AuditResultDenied
+	}
+	l.svcCtx.Policy.Audit(context.WithoutCancel(l.ctx), preq, s.ToAddress, result, auditDetail(s, occ, run))
+}
+
+// submit 按保存的请求走与 /transaction/send、/transaction/swap 相同的流程，地址与金额校验、手续费上限和 gas 站限额同样生效
+// （策略检查在 authorize 中完成）；同步广播，返回时交易已被节点接受，同一钱包的下一次执行读取到的 nonce 已包含这一笔
+func (l *ScheduleLogic) submit(s *model.ScheduledTransfers) (*types.TransactionResp, error) {
+	ctx, cancel := context.WithTimeout(l.ctx, time.Duration(l.svcCtx.Config.Schedule.ExecTimeout)*time.Second)
+	defer cancel()
+
+	req := &types.TransactionReq{
+		FromAddress: s.FromAddress,
+		ToAddress:   s.ToAddress,
+		Chain:       s.Chain,
+		FromToken:   s.FromToken,
+		ToToken:     s.ToToken,
+		Amount:      s.Amount,
+		AmountUnit:  s.AmountUnit,
+		FeeLevel:    s.FeeLevel,
+	}
+	txLogic := transaction.NewTransactionLogic(ctx, l.svcCtx).WithSyncBroadcast()
+	if s.Kind == model.ScheduleKindSwap {
+		return txLogic.WrapSwap(req)
+	}
+	return txLogic.WrapSend(req)
+}
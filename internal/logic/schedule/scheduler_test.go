@@ -0,0 +1,136 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"demo/internal/model"
+)
+
+func TestMakePlan(t *testing.T) {
+	at := func(clock string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04:05", "2026-10-18 "+clock, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	type run struct {
+		at      string
+		catchUp bool
+	}
+	const grace = time.Minute
+
+	tests := []struct {
+		name       string
+		cron       string // 为空表示一次性任务
+		next       string // next_run_at
+		now        string
+		catchUp    string
+		maxCatchUp int
+		runs       []run
+		skipped    int
+		first      string // 跳过记录的起止时间
+		last       string
+	}{
+		{
+			name: "not due", cron: "0 * * * *", next: "10:00:00", now: "09:59:59",
+			catchUp: model.ScheduleCatchUpOnce, maxCatchUp: 3,
+		},
+		{
+			name: "on time", cron: "0 * * * *", next: "10:00:00", now: "10:00:30",
+			catchUp: model.ScheduleCatchUpSkip, maxCatchUp: 3,
+			runs: []run{{"10:00:00", false}},
+		},
+		{
+			name: "on time with earlier misses, skip", cron: "0 * * * *", next: "10:00:00", now: "12:00:30",
+			catchUp: model.ScheduleCatchUpSkip, maxCatchUp: 3,
+			runs:    []run{{"12:00:00", false}},
+			skipped: 2, first: "10:00:00", last: "11:00:00",
+		},
+		{
+			name: "on time with earlier misses, once", cron: "0 * * * *", next: "10:00:00", now: "12:00:30",
+			catchUp: model.ScheduleCatchUpOnce, maxCatchUp: 3,
+			runs:    []run{{"12:00:00", false}},
+			skipped: 2, first: "10:00:00", last: "11:00:00",
+		},
+		{
+			name: "on time with earlier misses, all", cron: "0 * * * *", next: "10:00:00", now: "12:00:30",
+			catchUp: model.ScheduleCatchUpAll, maxCatchUp: 3,
+			runs: []run{{"10:00:00", true}, {"11:00:00", true}, {"12:00:00", false}},
+		},
+		{
+			name: "on time with earlier misses, all over the limit", cron: "0 * * * *", next: "10:00:00", now: "12:00:30",
+			catchUp: model.ScheduleCatchUpAll, maxCatchUp: 1,
+			runs:    []run{{"10:00:00", true}, {"12:00:00", false}},
+			skipped: 1, first: "11:00:00", last: "11:00:00",
+		},
+		{
+			name: "all missed, skip", cron: "0 * * * *", next: "10:00:00", now: "12:30:00",
+			catchUp: model.ScheduleCatchUpSkip, maxCatchUp: 3,
+			skipped: 3, first: "10:00:00", last: "12:00:00",
+		},
+		{
+			name: "all missed, once runs the latest", cron: "0 * * * *", next: "10:00:00", now: "12:30:00",
+			catchUp: model.ScheduleCatchUpOnce, maxCatchUp: 3,
+			runs:    []run{{"12:00:00", true}},
+			skipped: 2, first: "10:00:00", last: "11:00:00",
+		},
+		{
+			name: "all missed, all", cron: "0 * * * *", next: "10:00:00", now: "12:30:00",
+			catchUp: model.ScheduleCatchUpAll, maxCatchUp: 3,
+			runs: []run{{"10:00:00", true}, {"11:00:00", true}, {"12:00:00", true}},
+		},
+		{
+			name: "all missed, all over the limit", cron: "0 * * * *", next: "10:00:00", now: "12:30:00",
+			catchUp: model.ScheduleCatchUpAll, maxCatchUp: 2,
+			runs:    []run{{"10:00:00", true}, {"11:00:00", true}},
+			skipped: 1, first: "12:00:00", last: "12:00:00",
+		},
+		{
+			name: "one-shot on time", next: "10:00:00", now: "10:00:30",
+			catchUp: model.ScheduleCatchUpSkip, maxCatchUp: 3,
+			runs: []run{{"10:00:00", false}},
+		},
+		{
+			name: "one-shot missed, skip", next: "10:00:00", now: "12:00:00",
+			catchUp: model.ScheduleCatchUpSkip, maxCatchUp: 3,
+			skipped: 1, first: "10:00:00", last: "10:00:00",
+		},
+		{
+			name: "one-shot missed, once", next: "10:00:00", now: "12:00:00",
+			catchUp: model.ScheduleCatchUpOnce, maxCatchUp: 3,
+			runs: []run{{"10:00:00", true}},
+		},
+		{
+			name: "one-shot missed, all", next: "10:00:00", now: "12:00:00",
+			catchUp: model.ScheduleCatchUpAll, maxCatchUp: 3,
+			runs: []run{{"10:00:00", true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := at(tt.next)
+			tm, err := newTiming(&model.ScheduledTransfers{Cron: tt.cron, Timezone: "UTC", NextRunAt: &next})
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := makePlan(tm, at(tt.now), grace, tt.catchUp, tt.maxCatchUp)
+
+			if len(p.runs) != len(tt.runs) {
+				t.Fatalf("got %d runs %v, want %d", len(p.runs), p.runs, len(tt.runs))
+			}
+			for i, want := range tt.runs {
+				if got := p.runs[i]; !got.at.Equal(at(want.at)) || got.catchUp != want.catchUp {
+					t.Errorf("run %d: got %s catchUp=%v, want %s catchUp=%v", i, got.at.UTC(), got.catchUp, want.at, want.catchUp)
+				}
+			}
+			if p.skipped != tt.skipped {
+				t.Fatalf("skipped: got %d, want %d", p.skipped, tt.skipped)
+			}
+			if tt.skipped > 0 && (!p.skippedFirst.Equal(at(tt.first)) || !p.skippedLast.Equal(at(tt.last))) {
+				t.Errorf("skipped range: got %s - %s, want %s - %s", p.skippedFirst.UTC(), p.skippedLast.UTC(), tt.first, tt.last)
+			}
+		})
+	}
+}
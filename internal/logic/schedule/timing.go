@@ -0,0 +1,63 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"demo/internal/cron"
+	"demo/internal/model"
+)
+
+// maxEnumerate 停机很久后计算错过的执行时间时最多枚举的次数（每分钟执行的表达式约 70 天）
+const maxEnumerate = 100000
+
+// timing 解析后的执行时间规则
+type timing struct {
+	s    *model.ScheduledTransfers
+	cron *cron.Schedule // 一次性任务为 nil
+	loc  *time.Location
+}
+
+func newTiming(s *model.ScheduledTransfers) (*timing, error) {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+	}
+	t := &timing{s: s, loc: loc}
+	if s.Cron != "" {
+		if t.cron, err = cron.Parse(s.Cron); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// next 返回 after 之后的下一次执行时间，一次性任务或超过 end_at 时返回 nil。
+// 时间统一转为本地时区保存，保证数据库中的时间可以直接比较
+func (t *timing) next(after time.Time) *time.Time {
+	if t.cron == nil {
+		return nil
+	}
+	n := t.cron.Next(after.In(t.loc))
+	if n.IsZero() || (t.s.EndAt != nil && n.After(*t.s.EndAt)) {
+		return nil
+	}
+	n = n.Local()
+	return &n
+}
+
+// missed 从 next_run_at 到 now 之间应执行的时间点：最多保留前 keep 个和最后两个，另返回总数
+func (t *timing) missed(now time.Time, keep int) (first, tail []time.Time, count int) {
+	at := t.s.NextRunAt
+	for at != nil && !at.After(now) && count < maxEnumerate {
+		if len(first) < keep {
+			first = append(first, *at)
+		}
+		if tail = append(tail, *at); len(tail) > 2 {
+			tail = tail[1:]
+		}
+		count++
+		at = t.next(*at)
+	}
+	return first, tail, count
+}
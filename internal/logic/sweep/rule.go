@@ -0,0 +1,104 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"demo/internal/chains"
+	"demo/internal/config"
+	"demo/internal/logic/transaction"
+	"demo/internal/svc"
+	"demo/internal/tokens"
+	"demo/internal/units"
+)
+
+// errRunning 同一规则同一时刻只执行一次
+var errRunning = errors.New("sweep rule is already running")
+
+// running 正在执行的规则名
+var running sync.Map
+
+// rule 校验并解析后的归集规则
+type rule struct {
+	conf      config.SweepRule
+	chain     *chains.Chain
+	token     *tokens.Token
+	threshold *big.Int
+	// nativePrice 一个原生币折合的代币数量（代币最小单位），未配置 NativePrice 时为 nil
+	nativePrice *big.Int
+	adapter     transaction.ChainAdapter
+}
+
+// findRule 按名称查找配置中的归集规则
+func findRule(svcCtx *svc.ServiceContext, name string) (config.SweepRule, bool) {
+	for _, r := range svcCtx.Config.Sweep.Rules {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return config.SweepRule{}, false
+}
+
+// loadRule 解析规则的链、代币和阈值，并校验目标地址
+func loadRule(ctx context.Context, svcCtx *svc.ServiceContext, conf config.SweepRule) (*rule, error) {
+	adapter, err := transaction.NewChainAdapter(ctx, svcCtx, conf.Chain)
+	if err != nil {
+		return nil, err
+	}
+	c := adapter.Chain()
+	if err := adapter.ValidateAddress(conf.Destination); err != nil {
+		return nil, fmt.Errorf("invalid destination: %w", err)
+	}
+
+	token := conf.Token
+	if token == "" {
+		token = c.NativeSymbol
+	}
+	t, err := svcCtx.Tokens.Resolve(ctx, c, token)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Native && !c.IsEVM() {
+		return nil, fmt.Errorf("token sweeps are only supported on EVM chains, %s is %s", c.Key, c.Family())
+	}
+	threshold, err := units.ParseUnits(conf.MinAmount, t.Decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MinAmount: %w", err)
+	}
+
+	r := &rule{conf: conf, chain: c, token: t, threshold: threshold, adapter: adapter}
+	if conf.NativePrice != "" && !t.Native {
+		price, err := units.ParseUnits(conf.NativePrice, t.Decimals)
+		if err != nil || price.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid NativePrice %q", conf.NativePrice)
+		}
+		r.nativePrice = price
+	}
+	return r, nil
+}
+
+// excluded 目标地址和该链的 gas 钱包本身不参与归集
+func (r *rule) excluded(address string) bool {
+	for _, a := range []string{r.conf.Destination, r.chain.GasStation.Wallet} {
+		if a == "" {
+			continue
+		}
+		if address == a || (r.chain.IsEVM() && strings.EqualFold(address, a)) {
+			return true
+		}
+	}
+	return false
+}
+
+func tryLock(name string) bool {
+	_, loaded := running.LoadOrStore(name, struct{}{})
+	return !loaded
+}
+
+func unlock(name string) {
+	running.Delete(name)
+}
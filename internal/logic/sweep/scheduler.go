@@ -0,0 +1,57 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"demo/internal/model"
+	"demo/internal/svc"
+)
+
+// StartScheduler 按配置的 Interval 定时触发归集规则，ctx 取消时停止；阻塞运行，调用方应在独立 goroutine 中启动
+func StartScheduler(ctx context.Context, svcCtx *svc.ServiceContext) {
+	// 上次进程退出时未完成的归集不会再继续，标记为失败
+	if n, err := svcCtx.SweepRunsDao.FailRunning(ctx, "interrupted by restart"); err != nil {
+		log.Printf("❌ 清理未完成的归集记录失败: %v", err)
+	} else if n > 0 {
+		log.Printf("⚠️  %d 条未完成的归集记录已标记为失败", n)
+	}
+
+	conf := svcCtx.Config.Sweep
+	log.Printf("🧹 归集调度已启动，共 %d 条规则", len(conf.Rules))
+	ticker := time.NewTicker(time.Duration(conf.CheckInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		for _, rule := range conf.Rules {
+			if rule.Interval <= 0 || !due(ctx, svcCtx, rule.Name, rule.Interval) {
+				continue
+			}
+			if _, _, err := start(ctx, svcCtx, rule, model.SweepTriggerSchedule); err != nil && !errors.Is(err, errRunning) {
+				log.Printf("❌ 启动归集 %s 失败: %v", rule.Name, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("✅ 归集调度已停止")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// due 距离该规则最近一次执行（无论手动还是定时）已超过 interval 秒
+func due(ctx context.Context, svcCtx *svc.ServiceContext, name string, interval int64) bool {
+	latest, err := svcCtx.SweepRunsDao.FindLatestByRule(ctx, name)
+	if errors.Is(err, model.ErrNotFound) {
+		return true
+	}
+	if err != nil {
+		log.Printf("❌ 查询归集记录失败 %s: %v", name, err)
+		return false
+	}
+	return !time.Now().Before(latest.StartedAt.Add(time.Duration(interval) * time.Second))
+}
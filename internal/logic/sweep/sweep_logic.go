@@ -0,0 +1,398 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"demo/internal/config"
+	"demo/internal/logic/transaction"
+	"demo/internal/model"
+	"demo/internal/multicall"
+	"demo/internal/svc"
+	"demo/internal/tokens"
+	"demo/internal/types"
+	"demo/internal/units"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SweepLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewSweepLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SweepLogic {
+	return &SweepLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// Run 立即执行一条归集规则，返回刚登记的执行记录，报告通过 /sweep/report 查询
+func (l *SweepLogic) Run(req *types.SweepRunReq) (*types.SweepRunResp, error) {
+	conf, ok := findRule(l.svcCtx, req.Rule)
+	if !ok {
+		return nil, fmt.Errorf("unknown sweep rule: %s", req.Rule)
+	}
+	// 归集在请求返回后继续执行
+	run, r, err := start(context.WithoutCancel(l.ctx), l.svcCtx, conf, model.SweepTriggerManual)
+	if err != nil {
+		return nil, err
+	}
+	return toRunResp(run, r.token), nil
+}
+
+// Runs 分页查询归集执行记录
+func (l *SweepLogic) Runs(req *types.SweepRunsReq) (*types.SweepRunsResp, error) {
+	runs, total, err := l.svcCtx.SweepRunsDao.FindPage(l.ctx, req.Rule, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	resp := &types.SweepRunsResp{Total: total, Items: make([]types.SweepRunResp, 0, len(runs))}
+	for _, run := range runs {
+		resp.Items = append(resp.Items, *toRunResp(run, l.lookupToken(run)))
+	}
+	return resp, nil
+}
+
+// Report 返回一次归集的汇总和每个钱包的处理结果
+func (l *SweepLogic) Report(req *types.SweepReportReq) (*types.SweepReportResp, error) {
+	run, err := l.svcCtx.SweepRunsDao.FindOne(l.ctx, req.Id)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, fmt.Errorf("sweep run %d not found", req.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	items, err := l.svcCtx.SweepItemsDao.FindByRunId(l.ctx, run.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.SweepReportResp{SweepRunResp: *toRunResp(run, l.lookupToken(run)), Items: make([]types.SweepItemResp, 0, len(items))}
+	for _, it := range items {
+		resp.Items = append(resp.Items, types.SweepItemResp{
+			Wallet:      it.Wallet,
+			Balance:     it.Balance,
+			Amount:      it.Amount,
+			Status:      it.Status,
+			Reason:      it.Reason,
+			TxHash:      it.TxHash,
+			TopUpTxHash: it.TopUpTxHash,
+		})
+	}
+	return resp, nil
+}
+
+// start 解析规则并登记执行记录，在后台执行归集；同一规则正在执行时返回 errRunning
+func start(ctx context.Context, svcCtx *svc.ServiceContext, conf config.SweepRule, trigger string) (*model.SweepRuns, *rule, error) {
+	if !tryLock(conf.Name) {
+		return nil, nil, fmt.Errorf("%w: %s", errRunning, conf.Name)
+	}
+	l := NewSweepLogic(ctx, svcCtx)
+	r, err := loadRule(ctx, svcCtx, conf)
+	if err != nil {
+		unlock(conf.Name)
+		return nil, nil, fmt.Errorf("sweep rule %s: %w", conf.Name, err)
+	}
+
+	run := &model.SweepRuns{
+		Rule:        conf.Name,
+		Chain:       r.chain.Key,
+		Symbol:      r.token.Symbol,
+		Destination: conf.Destination,
+		TriggeredBy: trigger,
+		Status:      model.SweepRunStatusRunning,
+		TotalAmount: "0",
+		StartedAt:   time.Now(),
+	}
+	if !r.token.Native {
+		run.Token = r.token.Address
+	}
+	if err := svcCtx.SweepRunsDao.Insert(ctx, run); err != nil {
+		unlock(conf.Name)
+		return nil, nil, err
+	}
+
+	go func() {
+		defer unlock(conf.Name)
+		l.execute(r, run)
+	}()
+	return run, r, nil
+}
+
+// execute 扫描规则所在链的托管钱包余额并逐个归集，最后写入汇总
+func (l *SweepLogic) execute(r *rule, run *model.SweepRuns) {
+	l.Infof("🧹 开始归集 %s: %s %s -> %s", r.conf.Name, r.chain.Key, r.token.Symbol, r.conf.Destination)
+	total := new(big.Int)
+	err := l.sweepAll(r, run, total)
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.TotalAmount = total.String()
+	run.Status = model.SweepRunStatusCompleted
+	if err != nil {
+		run.Status = model.SweepRunStatusFailed
+		run.ErrorMessage = err.Error()
+		l.Errorf("❌ 归集 %s 失败: %v", r.conf.Name, err)
+	}
+	// 进程退出时请求上下文已取消，汇总仍要写入
+	if err := l.svcCtx.SweepRunsDao.Finish(context.WithoutCancel(l.ctx), run); err != nil {
+		l.Errorf("保存归集汇总失败: %v", err)
+		return
+	}
+	l.Infof("✅ 归集 %s 完成: 扫描 %d，归集 %d，跳过 %d，失败 %d，合计 %s %s",
+		r.conf.Name, run.Scanned, run.Swept, run.Skipped, run.Failed, units.FormatUnits(total, r.token.Decimals), r.token.Symbol)
+}
+
+// sweepAll 查询余额后逐个处理有余额的钱包，余额为零的钱包只计入扫描数
+func (l *SweepLogic) sweepAll(r *rule, run *model.SweepRuns, total *big.Int) error {
+	rows, err := l.svcCtx.WalletsDao.FindByChainType(l.ctx, string(r.chain.WalletType()))
+	if err != nil {
+		return fmt.Errorf("list wallets: %w", err)
+	}
+	var wallets []string
+	for _, w := range rows {
+		// 同一链族的钱包可能属于其他网络（如 BTC 主网 / 测试网地址）
+		if r.excluded(w.Address) || r.adapter.ValidateAddress(w.Address) != nil {
+			continue
+		}
+		wallets = append(wallets, w.Address)
+	}
+	run.Scanned = len(wallets)
+
+	balances, natives, err := l.balances(r, wallets)
+	if err != nil {
+		return fmt.Errorf("read balances: %w", err)
+	}
+
+	for i, wallet := range wallets {
+		if err := l.ctx.Err(); err != nil {
+			return err
+		}
+		if balances[i].Sign() == 0 {
+			continue
+		}
+		item := &model.SweepItems{RunId: run.Id, Wallet: wallet, Balance: balances[i].String(), Amount: "0"}
+		l.sweepOne(r, item, balances[i], natives[i])
+
+		switch item.Status {
+		case model.SweepItemSwept:
+			run.Swept++
+			if amount, ok := new(big.Int).SetString(item.Amount, 10); ok {
+				total.Add(total, amount)
+			}
+		case model.SweepItemSkipped:
+			run.Skipped++
+		default:
+			run.Failed++
+		}
+		item.CreatedAt = time.Now()
+		if err := l.svcCtx.SweepItemsDao.Insert(context.WithoutCancel(l.ctx), item); err != nil {
+			l.Errorf("保存归集明细失败 %s: %v", wallet, err)
+		}
+	}
+	return nil
+}
+
+// balances 返回每个钱包的待归集余额和原生币余额；EVM 链通过 Multicall3 批量查询
+func (l *SweepLogic) balances(r *rule, wallets []string) ([]*big.Int, []*big.Int, error) {
+	balances := make([]*big.Int, len(wallets))
+	natives := make([]*big.Int, len(wallets))
+	if !r.chain.IsEVM() {
+		for i, w := range wallets {
+			b, err := r.adapter.GetBalance(w, r.token.Address)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", w, err)
+			}
+			balances[i], natives[i] = b, b
+		}
+		return balances, natives, nil
+	}
+
+	caller, err := l.svcCtx.Multicall.Caller(r.chain)
+	if err != nil {
+		return nil, nil, err
+	}
+	perWallet := 1
+	if !r.token.Native {
+		perWallet = 2
+	}
+	calls := make([]multicall.Call, 0, len(wallets)*perWallet)
+	for _, w := range wallets {
+		owner := common.HexToAddress(w)
+		calls = append(calls, caller.EthBalance(owner))
+		if !r.token.Native {
+			calls = append(calls, multicall.BalanceOf(common.HexToAddress(r.token.Address), owner))
+		}
+	}
+	results, err := caller.Aggregate(l.ctx, calls)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range wallets {
+		for j := 0; j < perWallet; j++ {
+			v, ok := results[i*perWallet+j].Uint()
+			if !ok {
+				return nil, nil, fmt.Errorf("balance of %s is unavailable", wallets[i])
+			}
+			if j == 0 {
+				natives[i] = v
+			}
+			balances[i] = v
+		}
+	}
+	return balances, natives, nil
+}
+
+// sweepOne 归集一个钱包：低于阈值或手续费不低于可归集金额时跳过（代币需配置 NativePrice 才能比较），代币归集前按需补充 gas
+func (l *SweepLogic) sweepOne(r *rule, item *model.SweepItems, balance, native *big.Int) {
+	if balance.Cmp(r.threshold) < 0 {
+		item.Status = model.SweepItemSkipped
+		item.Reason = fmt.Sprintf("below threshold %s %s", r.conf.MinAmount, r.token.Symbol)
+		return
+	}
+
+	if r.token.Native {
+		// 先按全额转出构建（不签名、不广播），得到扣除手续费后的金额
+		dry := &transaction.TransferRequest{From: item.Wallet, To: r.conf.Destination, Token: r.token.Address, Max: true, Fee: transaction.FeeOptions{Level: r.conf.FeeLevel}}
+		if _, err := r.adapter.BuildTransfer(dry); err != nil {
+			item.Status, item.Reason = model.SweepItemSkipped, err.Error()
+			return
+		}
+		if fee := new(big.Int).Sub(balance, dry.Amount); fee.Cmp(dry.Amount) >= 0 {
+			item.Status = model.SweepItemSkipped
+			item.Reason = fmt.Sprintf("dust: fee %s exceeds sweepable amount %s", fee.String(), dry.Amount.String())
+			return
+		}
+	} else {
+		need, fee, err := l.tokenFee(r, item.Wallet, balance, native)
+		if err != nil {
+			item.Status, item.Reason = model.SweepItemFailed, err.Error()
+			return
+		}
+		// 配置了 NativePrice 时把手续费折成代币比较，否则只有 MinAmount 一道门槛
+		if r.nativePrice != nil {
+			feeInToken := new(big.Int).Mul(fee, r.nativePrice)
+			feeInToken.Quo(feeInToken, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(r.chain.NativeDecimals)), nil))
+			if feeInToken.Cmp(balance) >= 0 {
+				item.Status = model.SweepItemSkipped
+				item.Reason = fmt.Sprintf("dust: fee %s %s (about %s %s) exceeds sweepable amount %s %s",
+					units.FormatUnits(fee, r.chain.NativeDecimals), r.chain.NativeSymbol,
+					units.FormatUnits(feeInToken, r.token.Decimals), r.token.Symbol,
+					units.FormatUnits(balance, r.token.Decimals), r.token.Symbol)
+				return
+			}
+		}
+		topUpHash, err := l.ensureGas(r, item.Wallet, need, native)
+		item.TopUpTxHash = topUpHash
+		if errors.Is(err, transaction.ErrNoGasStation) {
+			item.Status, item.Reason = model.SweepItemSkipped, err.Error()
+			return
+		}
+		if err != nil {
+			item.Status, item.Reason = model.SweepItemFailed, err.Error()
+			return
+		}
+	}
+
+	resp, err := transaction.NewTransactionLogic(l.ctx, l.svcCtx).WrapSend(&types.TransactionReq{
+		FromAddress: item.Wallet,
+		ToAddress:   r.conf.Destination,
+		Chain:       r.chain.Key,
+		FromToken:   r.token.Address,
+		ToToken:     r.token.Address,
+		Amount:      transaction.AmountMax,
+		FeeLevel:    r.conf.FeeLevel,
+	})
+	if err != nil {
+		item.Status, item.Reason = model.SweepItemFailed, err.Error()
+		l.Errorf("归集 %s 失败: %v", item.Wallet, err)
+		return
+	}
+	item.Status, item.Amount, item.TxHash = model.SweepItemSwept, resp.Amount, resp.TxHash
+	l.Infof("归集 %s: %s %s, TxHash: %s", item.Wallet, resp.AmountFormatted, r.token.Symbol, resp.TxHash)
+}
+
+// tokenFee 估算代币转账需要的原生币 need（gasLimit × 最高单价），以及归集的总手续费：
+// need 加上原生币不足时 gas 站补充交易（按 21000 gas）的手续费
+func (l *SweepLogic) tokenFee(r *rule, wallet string, balance, native *big.Int) (*big.Int, *big.Int, error) {
+	txLogic := transaction.NewTransactionLogic(l.ctx, l.svcCtx)
+	client, err := l.svcCtx.RPC.EthClient(r.chain)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := txLogic.BuildERC20TransferData(r.conf.Destination, balance)
+	if err != nil {
+		return nil, nil, err
+	}
+	gasLimit := txLogic.EstimateERC20TransferGas(client, common.HexToAddress(wallet), common.HexToAddress(r.token.Address), data)
+	fees, err := txLogic.SuggestFees(r.chain, transaction.FeeOptions{Level: r.conf.FeeLevel})
+	if err != nil {
+		return nil, nil, err
+	}
+	need := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), fees.MaxGasPrice())
+	fee := new(big.Int).Set(need)
+	if native.Cmp(need) < 0 {
+		fee.Add(fee, new(big.Int).Mul(big.NewInt(21000), fees.MaxGasPrice()))
+	}
+	return need, fee, nil
+}
+
+// ensureGas 原生币少于 need 时由该链的 gas 站补足并等待确认，返回补充交易哈希
+func (l *SweepLogic) ensureGas(r *rule, wallet string, need, native *big.Int) (string, error) {
+	if native.Cmp(need) >= 0 {
+		return "", nil
+	}
+	topUp, err := transaction.NewTransactionLogic(l.ctx, l.svcCtx).EnsureGas(r.chain, wallet, need, model.GasTopUpPurposeSweep)
+	if topUp == nil {
+		return "", err
+	}
+	return topUp.TxHash, err
+}
+
+// lookupToken 按执行记录中的代币查询精度，用于换算合计金额
+func (l *SweepLogic) lookupToken(run *model.SweepRuns) *tokens.Token {
+	c, ok := l.svcCtx.Chains.Get(run.Chain)
+	if !ok {
+		return nil
+	}
+	t, err := l.svcCtx.Tokens.Lookup(l.ctx, c, run.Token)
+	if err != nil {
+		return nil
+	}
+	return t
+}
+
+func toRunResp(run *model.SweepRuns, token *tokens.Token) *types.SweepRunResp {
+	resp := &types.SweepRunResp{
+		Id:          run.Id,
+		Rule:        run.Rule,
+		Chain:       run.Chain,
+		Token:       run.Token,
+		Symbol:      run.Symbol,
+		Destination: run.Destination,
+		TriggeredBy: run.TriggeredBy,
+		Status:      run.Status,
+		Scanned:     run.Scanned,
+		Swept:       run.Swept,
+		Skipped:     run.Skipped,
+		Failed:      run.Failed,
+		TotalAmount: run.TotalAmount,
+		Error:       run.ErrorMessage,
+		StartedAt:   run.StartedAt.Unix(),
+	}
+	if token != nil {
+		resp.TotalAmountFormatted = units.FormatUnitsString(run.TotalAmount, token.Decimals)
+	}
+	if run.FinishedAt != nil {
+		resp.FinishedAt = run.FinishedAt.Unix()
+	}
+	return resp
+}
@@ -0,0 +1,48 @@
+package token
+
+import (
+	"context"
+	"fmt"
+
+	"demo/internal/svc"
+	"demo/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ResolveLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewResolveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ResolveLogic {
+	return &ResolveLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// Resolve 按符号或地址查询代币的地址、符号、名称和精度
+func (l *ResolveLogic) Resolve(req *types.TokenResolveReq) (*types.TokenResp, error) {
+	c, ok := l.svcCtx.Chains.Get(req.Chain)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %s", req.Chain)
+	}
+
+	t, err := l.svcCtx.Tokens.Resolve(l.ctx, c, req.Token)
+	if err != nil {
+		l.Infof("解析代币 %s (%s) 失败: %v", req.Token, c.Key, err)
+		return nil, err
+	}
+	return &types.TokenResp{
+		Chain:    t.Chain,
+		Address:  t.Address,
+		Symbol:   t.Symbol,
+		Name:     t.Name,
+		Decimals: t.Decimals,
+		Native:   t.Native,
+		Source:   t.Source,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package token
+
+import (
+	"context"
+	"fmt"
+
+	"demo/internal/logic/transaction"
+	"demo/internal/svc"
+	"demo/internal/tokens"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SeedLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewSeedLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SeedLogic {
+	return &SeedLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// SeedFromLifi 将已配置 EVM / Solana 主网的 LI.FI 代币列表写入代币注册表，
+// 之后这些链上的代币符号（如 ETH 上的 USDC）无需在配置中登记即可解析。测试网不在 LI.FI 支持范围内
+func (l *SeedLogic) SeedFromLifi() error {
+	var chainIds []int
+	for _, c := range l.svcCtx.Chains.All() {
+		if c.Testnet || c.IsBTC() {
+			continue
+		}
+		chainIds = append(chainIds, int(c.LifiId()))
+	}
+	if len(chainIds) == 0 {
+		return nil
+	}
+
+	lists, err := transaction.NewBridgeLogic(l.ctx, l.svcCtx).GetSupportedTokens(chainIds)
+	if err != nil {
+		return fmt.Errorf("fetch lifi token list: %w", err)
+	}
+
+	for lifiId, infos := range lists {
+		c, ok := l.svcCtx.Chains.ByLifiChainId(int64(lifiId))
+		if !ok {
+			continue
+		}
+		list := make([]tokens.Token, 0, len(infos))
+		for _, info := range infos {
+			list = append(list, tokens.Token{
+				Address:  info.Address,
+				Symbol:   info.Symbol,
+				Name:     info.Name,
+				Decimals: info.Decimals,
+			})
+		}
+		n, err := l.svcCtx.Tokens.Seed(l.ctx, c, list)
+		if err != nil {
+			return fmt.Errorf("seed %s tokens: %w", c.Key, err)
+		}
+		l.Infof("✅ 已从 LI.FI 导入 %s 代币 %d 个", c.Key, n)
+	}
+	return nil
+}
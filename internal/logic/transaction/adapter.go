@@ -0,0 +1,144 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"demo/internal/chains"
+	"demo/internal/svc"
+	"demo/internal/types"
+)
+
+// ChainAdapter 屏蔽不同链族的交易差异，send/swap/bridge/approve 只和适配器打交道。
+// 新增一个链族只需要实现一个适配器并在 init 中注册。
+type ChainAdapter interface {
+	// Chain 返回适配器绑定的链
+	Chain() *chains.Chain
+	// ValidateAddress 校验地址格式及所属网络
+	ValidateAddress(address string) error
+	// BuildTransfer 构建未签名的转账交易
+	BuildTransfer(req *TransferRequest) (*UnsignedTx, error)
+	// Sign 用发送地址对应的钱包私钥签名
+	Sign(tx *UnsignedTx) (*SignedTx, error)
+	// Broadcast 广播已签名交易，返回交易哈希
+	Broadcast(tx *SignedTx) (string, error)
+	// GetBalance 查询地址余额（最小单位），token 为空或原生代币地址时查询原生币
+	GetBalance(address, token string) (*big.Int, error)
+	// GetTxStatus 查询交易状态，返回 model.TxStatus*
+	GetTxStatus(txHash string) (string, error)
+	// ExplorerURL 返回交易的区块浏览器链接
+	ExplorerURL(txHash string) string
+}
+
+// SwapAdapter 支持链内兑换的适配器
+type SwapAdapter interface {
+	Swap(req *types.TransactionReq) (*types.TransactionResp, error)
+}
+
+// BridgeAdapter 支持作为跨链源链的适配器，quote 为 LI.FI 报价
+type BridgeAdapter interface {
+	Bridge(req *types.BridgeExecuteReq, quote *types.BridgeQuoteResp) (string, error)
+}
+
+// ApprovalAdapter 支持代币授权管理的适配器
+type ApprovalAdapter interface {
+	CheckAllowance(req *types.CheckAllowanceReq) (*types.CheckAllowanceResp, error)
+	Approve(req *types.ApproveTokenReq) (*types.ApproveTokenResp, error)
+	Revoke(req *types.RevokeApprovalReq) (*types.RevokeApprovalResp, error)
+	ListApprovals(req *types.GetUserApprovalsReq) (*types.GetUserApprovalsResp, error)
+}
+
+// TransferRequest 链无关的转账参数，Amount 为最小单位，Fee 对 EVM 链和 BTC 全额转出生效，Sponsor 只对 EVM 链生效
+type TransferRequest struct {
+	From   string
+	To     string
+	Token  string
+	Amount *big.Int
+	// Max 发送全部余额：适配器按余额扣除手续费计算金额并写回 Amount
+	Max     bool
+	Fee     FeeOptions
+	Sponsor bool // 智能账户交易由 paymaster 代付 gas
+}
+
+// UnsignedTx 未签名交易，Payload 的具体类型由适配器决定
+type UnsignedTx struct {
+	From    string
+	Payload interface{}
+}
+
+// SignedTx 已签名交易，Hash 在广播前即可确定
+type SignedTx struct {
+	Hash    string
+	Payload interface{}
+}
+
+// AdapterFactory 为一条链创建适配器
+type AdapterFactory func(ctx context.Context, svcCtx *svc.ServiceContext, chain *chains.Chain) ChainAdapter
+
+var (
+	adapterMu        sync.RWMutex
+	adapterFactories = make(map[chains.Family]AdapterFactory)
+)
+
+// RegisterAdapter 注册链族对应的适配器，重复注册会覆盖
+func RegisterAdapter(family chains.Family, factory AdapterFactory) {
+	adapterMu.Lock()
+	defer adapterMu.Unlock()
+	adapterFactories[family] = factory
+}
+
+// NewChainAdapter 按链名（或别名、链 ID）查找链并创建适配器
+func NewChainAdapter(ctx context.Context, svcCtx *svc.ServiceContext, chain string) (ChainAdapter, error) {
+	c, ok := svcCtx.Chains.Get(chain)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %s", chain)
+	}
+	return newChainAdapter(ctx, svcCtx, c)
+}
+
+func newChainAdapter(ctx context.Context, svcCtx *svc.ServiceContext, c *chains.Chain) (ChainAdapter, error) {
+	adapterMu.RLock()
+	factory, ok := adapterFactories[c.Family()]
+	adapterMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no adapter registered for chain family %s", c.Family())
+	}
+	return factory(ctx, svcCtx, c), nil
+}
+
+// adapterBase 各适配器共用的部分
+type adapterBase struct {
+	l     *TransactionLogic
+	chain *chains.Chain
+}
+
+func newAdapterBase(ctx context.Context, svcCtx *svc.ServiceContext, chain *chains.Chain) adapterBase {
+	return adapterBase{l: NewTransactionLogic(ctx, svcCtx), chain: chain}
+}
+
+func (a *adapterBase) Chain() *chains.Chain {
+	return a.chain
+}
+
+func (a *adapterBase) ExplorerURL(txHash string) string {
+	if url := a.chain.ExplorerTx(txHash); url != "" {
+		return url
+	}
+	return fmt.Sprintf("https://explorer.example.com/tx/%s", txHash)
+}
+
+// approveLogic 授权相关的实现仍在 ApproveLogic 上，适配器只负责路由
+func (a *adapterBase) approveLogic() *ApproveLogic {
+	return NewApproveLogic(a.l.ctx, a.l.svcCtx)
+}
+
+func (a *adapterBase) bridgeLogic() *BridgeLogic {
+	return NewBridgeLogic(a.l.ctx, a.l.svcCtx)
+}
+
+// adapterFor 创建链对应的适配器
+func (l *TransactionLogic) adapterFor(chain string) (ChainAdapter, error) {
+	return NewChainAdapter(l.ctx, l.svcCtx, chain)
+}
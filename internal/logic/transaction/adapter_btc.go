@@ -0,0 +1,382 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"demo/internal/chains"
+	"demo/internal/model"
+	"demo/internal/svc"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// btcFeeSatoshi 固定矿工费
+const btcFeeSatoshi = 1000
+
+// 全额转出时按交易大小和费率计算矿工费（P2PKH 输入，无找零输出）
+const (
+	btcTxOverheadSize = 10  // version + locktime + 输入输出数量
+	btcP2PKHInputSize = 148 // outpoint + 压缩公钥签名脚本 + sequence
+	btcDustLimit      = 546
+)
+
+// btcFeeTargets 手续费档位对应的确认目标区块数（Esplora /fee-estimates 的 key）
+var btcFeeTargets = map[string]string{
+	FeeLevelSlow:   "144",
+	FeeLevelNormal: "6",
+	FeeLevelFast:   "2",
+}
+
+// errEsploraNotFound Esplora 返回 404（地址或交易不存在）
+var errEsploraNotFound = errors.New("esplora: not found")
+
+func init() {
+	RegisterAdapter(chains.FamilyBTC, func(ctx context.Context, svcCtx *svc.ServiceContext, chain *chains.Chain) ChainAdapter {
+		return &btcAdapter{adapterBase: newAdapterBase(ctx, svcCtx, chain)}
+	})
+}
+
+// btcAdapter Bitcoin 适配器，节点为 Esplora 兼容的 REST API（Blockstream / mempool.space）
+type btcAdapter struct {
+	adapterBase
+}
+
+// btcUnsignedTx 未签名的 BTC 交易及其引用的 UTXO 锁定脚本
+type btcUnsignedTx struct {
+	tx      *wire.MsgTx
+	scripts [][]byte
+}
+
+// btcNetParams 根据链配置选择 Bitcoin 网络参数
+func btcNetParams(c *chains.Chain) *chaincfg.Params {
+	if c.Testnet {
+		return &chaincfg.TestNet3Params
+	}
+	return &chaincfg.MainNetParams
+}
+
+// BlockstreamAPIUTXO 定义了从 Blockstream API 返回的 UTXO 结构
+type BlockstreamAPIUTXO struct {
+	TxID   string `json:"txid"`
+	Vout   int    `json:"vout"`
+	Status struct {
+		Confirmed bool `json:"confirmed"`
+	} `json:"status"`
+	Value int64 `json:"value"`
+}
+
+func (a *btcAdapter) ValidateAddress(address string) error {
+	params := btcNetParams(a.chain)
+	addr, err := btcutil.DecodeAddress(address, params)
+	if err != nil || !addr.IsForNet(params) {
+		return fmt.Errorf("invalid Bitcoin address for %s: %s", a.chain.DisplayName(), address)
+	}
+	return nil
+}
+
+// BuildTransfer 选择已确认的 UTXO 构建交易，找零回到发送地址
+func (a *btcAdapter) BuildTransfer(req *TransferRequest) (*UnsignedTx, error) {
+	if !a.isNative(req.Token) {
+		return nil, fmt.Errorf("unsupported token on %s: %s", a.chain.DisplayName(), req.Token)
+	}
+	var amount int64
+	if !req.Max {
+		if !req.Amount.IsInt64() || req.Amount.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid amount: %s", req.Amount.String())
+		}
+		amount = req.Amount.Int64()
+		a.l.Infof("转账金额: %d satoshi", amount)
+	}
+
+	params := btcNetParams(a.chain)
+	sourceAddr, err := btcutil.DecodeAddress(req.From, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source address: %v", err)
+	}
+	sourceScript, err := txscript.PayToAddrScript(sourceAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pkScript: %w", err)
+	}
+	destAddr, err := btcutil.DecodeAddress(req.To, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination address: %v", err)
+	}
+	destScript, err := txscript.PayToAddrScript(destAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output script: %v", err)
+	}
+
+	utxos, err := a.getUTXOs(req.From)
+	if err != nil {
+		a.l.Errorf("获取 UTXO 失败: %v", err)
+		return nil, fmt.Errorf("failed to get UTXOs: %v", err)
+	}
+	a.l.Infof("✅ 获取到 %d 个已确认 UTXO", len(utxos))
+	if req.Max {
+		return a.buildSweep(req, utxos, sourceScript, destScript)
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	var inputSum int64
+	var scripts [][]byte
+	for _, utxo := range utxos {
+		hash, err := chainhash.NewHashFromStr(utxo.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse UTXO hash: %v", err)
+		}
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, uint32(utxo.Vout)), nil, nil))
+		scripts = append(scripts, sourceScript)
+		inputSum += utxo.Value
+		if inputSum >= amount+btcFeeSatoshi {
+			break
+		}
+	}
+	if inputSum < amount+btcFeeSatoshi {
+		a.l.Errorf("余额不足: 需要 %d satoshi，可用 %d satoshi", amount+btcFeeSatoshi, inputSum)
+		return nil, fmt.Errorf("insufficient funds: need %d, available %d", amount+btcFeeSatoshi, inputSum)
+	}
+	a.l.Infof("✅ UTXO 选择完成，选择了 %d 个 UTXO，输入总额 %d satoshi", len(tx.TxIn), inputSum)
+
+	tx.AddTxOut(wire.NewTxOut(amount, destScript))
+	if change := inputSum - amount - btcFeeSatoshi; change > 0 {
+		tx.AddTxOut(wire.NewTxOut(change, sourceScript))
+		a.l.Infof("找零输出: %d satoshi", change)
+	}
+
+	return &UnsignedTx{From: req.From, Payload: &btcUnsignedTx{tx: tx, scripts: scripts}}, nil
+}
+
+// buildSweep 全额转出：花费全部已确认 UTXO，不设找零，矿工费按所选档位的费率和交易大小计算
+func (a *btcAdapter) buildSweep(req *TransferRequest, utxos []BlockstreamAPIUTXO, sourceScript, destScript []byte) (*UnsignedTx, error) {
+	if len(utxos) == 0 {
+		return nil, errors.New("nothing to send: no confirmed UTXOs")
+	}
+	feeRate, err := a.feeRate(req.Fee.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	var inputSum int64
+	scripts := make([][]byte, 0, len(utxos))
+	for _, utxo := range utxos {
+		hash, err := chainhash.NewHashFromStr(utxo.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse UTXO hash: %v", err)
+		}
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, uint32(utxo.Vout)), nil, nil))
+		scripts = append(scripts, sourceScript)
+		inputSum += utxo.Value
+	}
+
+	// 输出 = value(8) + 脚本长度(1) + 脚本
+	size := btcTxOverheadSize + btcP2PKHInputSize*len(utxos) + 9 + len(destScript)
+	fee := int64(math.Ceil(feeRate * float64(size)))
+	amount := inputSum - fee
+	if amount < btcDustLimit {
+		return nil, fmt.Errorf("insufficient funds: %d satoshi in %d UTXOs does not cover fee %d satoshi", inputSum, len(utxos), fee)
+	}
+	tx.AddTxOut(wire.NewTxOut(amount, destScript))
+	a.l.Infof("全额转出: %d 个 UTXO 共 %d satoshi，费率 %.2f sat/vB × %d 字节 = 手续费 %d，实际转账 %d satoshi",
+		len(utxos), inputSum, feeRate, size, fee, amount)
+
+	req.Amount = big.NewInt(amount)
+	return &UnsignedTx{From: req.From, Payload: &btcUnsignedTx{tx: tx, scripts: scripts}}, nil
+}
+
+// feeRate 通过 Esplora /fee-estimates 获取手续费档位对应的费率（sat/vB），不低于最低转发费率 1 sat/vB
+func (a *btcAdapter) feeRate(level string) (float64, error) {
+	if level == "" {
+		level = FeeLevelNormal
+	}
+	target, ok := btcFeeTargets[level]
+	if !ok {
+		return 0, fmt.Errorf("fee_level %s is not supported on %s (slow|normal|fast)", level, a.chain.DisplayName())
+	}
+
+	body, err := a.esplora(http.MethodGet, "/fee-estimates", nil)
+	if err != nil {
+		a.l.Errorf("获取费率失败: %v", err)
+		return 0, fmt.Errorf("failed to get fee estimates: %w", err)
+	}
+	var estimates map[string]float64
+	if err := json.Unmarshal(body, &estimates); err != nil {
+		return 0, fmt.Errorf("failed to decode fee estimates: %w", err)
+	}
+	rate, ok := estimates[target]
+	if !ok {
+		return 0, fmt.Errorf("no fee estimate for a %s-block target", target)
+	}
+	return math.Max(rate, 1), nil
+}
+
+func (a *btcAdapter) Sign(tx *UnsignedTx) (*SignedTx, error) {
+	unsigned, ok := tx.Payload.(*btcUnsignedTx)
+	if !ok {
+		return nil, fmt.Errorf("unexpected BTC payload %T", tx.Payload)
+	}
+
+	privateKey, err := a.l.GetWalletPrivateKey(tx.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private key: %v", err)
+	}
+	privKey, _ := btcec.PrivKeyFromBytes(crypto.FromECDSA(privateKey))
+
+	for i, script := range unsigned.scripts {
+		sigScript, err := txscript.SignatureScript(unsigned.tx, i, script, txscript.SigHashAll, privKey, true)
+		if err != nil {
+			a.l.Errorf("签名输入 %d 失败: %v", i, err)
+			return nil, fmt.Errorf("failed to sign input %d: %v", i, err)
+		}
+		unsigned.tx.TxIn[i].SignatureScript = sigScript
+	}
+	a.l.Infof("✅ %d 个输入签名完成", len(unsigned.scripts))
+
+	return &SignedTx{Hash: unsigned.tx.TxHash().String(), Payload: unsigned.tx}, nil
+}
+
+// Broadcast 通过 Esplora POST /tx 广播交易
+func (a *btcAdapter) Broadcast(tx *SignedTx) (string, error) {
+	msgTx, ok := tx.Payload.(*wire.MsgTx)
+	if !ok {
+		return "", fmt.Errorf("unexpected BTC payload %T", tx.Payload)
+	}
+
+	var raw bytes.Buffer
+	if err := msgTx.Serialize(&raw); err != nil {
+		return "", fmt.Errorf("failed to serialize transaction: %v", err)
+	}
+	a.l.Infof("交易序列化成功，大小: %d 字节", raw.Len())
+
+	body, err := a.esplora(http.MethodPost, "/tx", strings.NewReader(hex.EncodeToString(raw.Bytes())))
+	if err != nil {
+		a.l.Errorf("广播交易失败: %v", err)
+		return "", fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+
+	txHash := strings.TrimSpace(string(body))
+	a.l.Infof("✅ Bitcoin 交易已成功提交: %s", txHash)
+	return txHash, nil
+}
+
+func (a *btcAdapter) GetBalance(address, token string) (*big.Int, error) {
+	if !a.isNative(token) {
+		return nil, fmt.Errorf("unsupported token on %s: %s", a.chain.DisplayName(), token)
+	}
+
+	body, err := a.esplora(http.MethodGet, "/address/"+address, nil)
+	if err != nil {
+		return nil, err
+	}
+	var info struct {
+		ChainStats struct {
+			Funded int64 `json:"funded_txo_sum"`
+			Spent  int64 `json:"spent_txo_sum"`
+		} `json:"chain_stats"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode address info: %w", err)
+	}
+	return big.NewInt(info.ChainStats.Funded - info.ChainStats.Spent), nil
+}
+
+func (a *btcAdapter) GetTxStatus(txHash string) (string, error) {
+	body, err := a.esplora(http.MethodGet, "/tx/"+txHash+"/status", nil)
+	if errors.Is(err, errEsploraNotFound) {
+		// 刚广播的交易可能还没有被节点索引
+		return model.TxStatusPending, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var status struct {
+		Confirmed bool `json:"confirmed"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return "", fmt.Errorf("failed to decode tx status: %w", err)
+	}
+	if status.Confirmed {
+		return model.TxStatusConfirmed, nil
+	}
+	return model.TxStatusPending, nil
+}
+
+// isNative BTC 只支持原生币
+func (a *btcAdapter) isNative(token string) bool {
+	return token == "" ||
+		a.l.IsNativeToken(token) ||
+		strings.EqualFold(token, "BTC") ||
+		strings.EqualFold(token, "bitcoin")
+}
+
+// getUTXOs 通过 Esplora API 获取地址已确认的 UTXO
+func (a *btcAdapter) getUTXOs(address string) ([]BlockstreamAPIUTXO, error) {
+	body, err := a.esplora(http.MethodGet, "/address/"+address+"/utxo", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiUTXOs []BlockstreamAPIUTXO
+	if err := json.Unmarshal(body, &apiUTXOs); err != nil {
+		return nil, fmt.Errorf("failed to decode api response: %w", err)
+	}
+
+	// 只使用已确认的 UTXO
+	confirmed := apiUTXOs[:0]
+	for _, utxo := range apiUTXOs {
+		if utxo.Status.Confirmed {
+			confirmed = append(confirmed, utxo)
+		}
+	}
+	return confirmed, nil
+}
+
+// esplora 经节点池请求 Esplora REST API，非 200 视为错误
+func (a *btcAdapter) esplora(method, path string, body io.Reader) ([]byte, error) {
+	pool, err := a.l.svcCtx.RPC.Pool(a.chain)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(a.l.ctx, method, pool.URL()+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+
+	resp, err := pool.HTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errEsploraNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("esplora %s %s returned status %d: %s", method, path, resp.StatusCode, string(data))
+	}
+	return data, nil
+}
@@ -0,0 +1,298 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"demo/internal/chains"
+	"demo/internal/erc4337"
+	"demo/internal/gasoracle"
+	"demo/internal/model"
+	"demo/internal/svc"
+	"demo/internal/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	evmTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func init() {
+	RegisterAdapter(chains.FamilyEVM, func(ctx context.Context, svcCtx *svc.ServiceContext, chain *chains.Chain) ChainAdapter {
+		return &evmAdapter{adapterBase: newAdapterBase(ctx, svcCtx, chain)}
+	})
+}
+
+// evmAdapter EVM 链适配器，同时支持 swap、跨链和授权管理
+type evmAdapter struct {
+	adapterBase
+}
+
+func (a *evmAdapter) ValidateAddress(address string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("invalid EVM address: %s", address)
+	}
+	return nil
+}
+
+// BuildTransfer 构建原生币或 ERC20 转账交易，发送地址是智能账户时构建 UserOperation
+func (a *evmAdapter) BuildTransfer(req *TransferRequest) (*UnsignedTx, error) {
+	if account := a.l.findSmartAccount(a.chain, req.From); account != nil {
+		return a.buildUserOperationTransfer(req, account)
+	}
+
+	client, err := a.l.svcCtx.RPC.EthClient(a.chain)
+	if err != nil {
+		a.l.Errorf("RPC 节点连接失败: %v", err)
+		return nil, errors.New("failed to connect to chain")
+	}
+
+	// 验证收款地址是否为合约（避免 OOG 问题）
+	toAddr := common.HexToAddress(req.To)
+	code, err := client.CodeAt(a.l.ctx, toAddr, nil)
+	if err != nil {
+		a.l.Errorf("检查收款地址失败: %v", err)
+		return nil, errors.New("failed to check to address")
+	}
+	if len(code) > 0 {
+		a.l.Infof("警告：收款地址是合约地址，可能存在 gas 不足风险")
+	}
+
+	fromAddr := common.HexToAddress(req.From)
+	nonce, err := client.PendingNonceAt(a.l.ctx, fromAddr)
+	if err != nil {
+		a.l.Errorf("获取 nonce 失败: %v", err)
+		return nil, errors.New("failed to get nonce")
+	}
+	a.l.Infof("获取 nonce 成功: %d", nonce)
+
+	fees, err := a.l.SuggestFees(a.chain, req.Fee)
+	if err != nil {
+		a.l.Errorf("获取手续费失败: %v", err)
+		return nil, err
+	}
+	a.l.Infof("手续费 (%s): %s", req.Fee.Level, fees)
+
+	var tx *evmTypes.Transaction
+	if a.l.IsNativeToken(req.Token) {
+		a.l.Infof("=== 执行原生代币转账 ===")
+		var gasLimit uint64
+		if req.Max {
+			// 转给普通地址固定消耗 21000 gas，不加缓冲，剩余尽量少
+			gasLimit = 21000
+			if len(code) > 0 {
+				gasLimit = a.l.EstimateNativeTransferGas(client, fromAddr, toAddr, big.NewInt(0))
+			}
+			if req.Amount, err = a.maxNativeAmount(client, fromAddr, gasLimit, fees); err != nil {
+				return nil, err
+			}
+		} else {
+			gasLimit = a.l.EstimateNativeTransferGas(client, fromAddr, toAddr, req.Amount)
+		}
+		a.l.Infof("Gas 估算结果: gasLimit=%d", gasLimit)
+		tx = NewEVMTx(a.chain.ChainId, nonce, &toAddr, req.Amount, gasLimit, nil, fees)
+	} else {
+		a.l.Infof("=== 执行 ERC20 代币转账 ===")
+		if req.Max {
+			if req.Amount, err = a.fullBalance(req.From, req.Token); err != nil {
+				return nil, err
+			}
+		}
+		data, err := a.l.BuildERC20TransferData(req.To, req.Amount)
+		if err != nil {
+			a.l.Errorf("构建 ERC20 调用数据失败: %v", err)
+			return nil, fmt.Errorf("failed to build ERC20 data: %v", err)
+		}
+
+		tokenAddr := common.HexToAddress(req.Token)
+		gasLimit := a.l.EstimateERC20TransferGas(client, fromAddr, tokenAddr, data)
+		a.l.Infof("ERC20 Gas 估算结果: gasLimit=%d", gasLimit)
+		if err := a.l.ensureTxGas(a.chain, fromAddr, gasLimit, fees, model.GasTopUpPurposeSend); err != nil {
+			return nil, err
+		}
+		tx = NewEVMTx(a.chain.ChainId, nonce, &tokenAddr, big.NewInt(0), gasLimit, data, fees)
+	}
+
+	return &UnsignedTx{From: req.From, Payload: tx}, nil
+}
+
+// buildUserOperationTransfer 智能账户转账：原生币直接 execute 转出，ERC20 execute 调用 transfer
+func (a *evmAdapter) buildUserOperationTransfer(req *TransferRequest, account *model.SmartAccounts) (*UnsignedTx, error) {
+	a.l.Infof("=== 发送地址为智能账户，构建 UserOperation (owner %s) ===", account.Owner)
+	if req.Max {
+		// 自付 gas 时 EntryPoint 从账户余额中扣除预付款，原生币全额转出只支持 paymaster 代付
+		if a.l.IsNativeToken(req.Token) && !req.Sponsor {
+			return nil, errors.New("sending the entire native balance from a smart account requires sponsor=true")
+		}
+		amount, err := a.fullBalance(req.From, req.Token)
+		if err != nil {
+			return nil, err
+		}
+		req.Amount = amount
+	}
+	call := erc4337.Call{To: common.HexToAddress(req.To), Value: req.Amount}
+	if !a.l.IsNativeToken(req.Token) {
+		data, err := a.l.BuildERC20TransferData(req.To, req.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build ERC20 data: %v", err)
+		}
+		call = erc4337.Call{To: common.HexToAddress(req.Token), Value: big.NewInt(0), Data: data}
+	}
+	uo, err := a.l.buildUserOperation(a.chain, account, []erc4337.Call{call}, req.Fee, req.Sponsor)
+	if err != nil {
+		return nil, err
+	}
+	return &UnsignedTx{From: req.From, Payload: uo}, nil
+}
+
+// maxNativeAmount 全额转出原生币：余额减去 gasLimit × maxFee（EIP-1559 为 maxFeePerGas，legacy 为 gasPrice）
+func (a *evmAdapter) maxNativeAmount(client *ethclient.Client, from common.Address, gasLimit uint64, fees *gasoracle.Fees) (*big.Int, error) {
+	balance, err := client.PendingBalanceAt(a.l.ctx, from)
+	if err != nil {
+		a.l.Errorf("获取余额失败: %v", err)
+		return nil, errors.New("failed to get balance")
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), fees.MaxGasPrice())
+	amount := new(big.Int).Sub(balance, fee)
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("insufficient balance to cover fee: balance %s, max fee %s", balance.String(), fee.String())
+	}
+	a.l.Infof("全额转出: 余额 %s - 最高手续费 %s = %s", balance.String(), fee.String(), amount.String())
+	return amount, nil
+}
+
+// fullBalance 全额转出时不需要预留手续费的情况（ERC20、paymaster 代付），直接使用全部余额
+func (a *evmAdapter) fullBalance(owner, token string) (*big.Int, error) {
+	amount, err := a.GetBalance(owner, token)
+	if err != nil {
+		a.l.Errorf("获取余额失败: %v", err)
+		return nil, fmt.Errorf("failed to get balance: %v", err)
+	}
+	if amount.Sign() <= 0 {
+		return nil, errors.New("nothing to send: balance is zero")
+	}
+	return amount, nil
+}
+
+func (a *evmAdapter) Sign(tx *UnsignedTx) (*SignedTx, error) {
+	if uo, ok := tx.Payload.(*userOperation); ok {
+		hash, err := a.l.signUserOperation(uo)
+		if err != nil {
+			return nil, err
+		}
+		return &SignedTx{Hash: hash.Hex(), Payload: uo}, nil
+	}
+
+	unsigned, ok := tx.Payload.(*evmTypes.Transaction)
+	if !ok {
+		return nil, fmt.Errorf("unexpected EVM payload %T", tx.Payload)
+	}
+
+	privateKey, err := a.l.GetWalletPrivateKey(tx.From)
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx, err := evmTypes.SignTx(unsigned, EVMSigner(a.chain.ChainId), privateKey)
+	if err != nil {
+		a.l.Errorf("交易签名失败: %v", err)
+		return nil, errors.New("failed to sign transaction")
+	}
+	return &SignedTx{Hash: signedTx.Hash().Hex(), Payload: signedTx}, nil
+}
+
+// Broadcast EVM 交易哈希在签名后即可确定，这里立即返回，
+// 实际发送在后台带重试完成，不阻塞请求（WithSyncBroadcast 时同步发送）。UserOperation 同步提交到 bundler，返回 userOpHash
+func (a *evmAdapter) Broadcast(tx *SignedTx) (string, error) {
+	if uo, ok := tx.Payload.(*userOperation); ok {
+		return a.l.sendUserOperation(uo, common.HexToHash(tx.Hash))
+	}
+
+	signedTx, ok := tx.Payload.(*evmTypes.Transaction)
+	if !ok {
+		return "", fmt.Errorf("unexpected EVM payload %T", tx.Payload)
+	}
+
+	client, err := a.l.svcCtx.RPC.EthClient(a.chain)
+	if err != nil {
+		return "", errors.New("failed to connect to chain")
+	}
+
+	if a.l.syncBroadcast() {
+		if err := a.l.sendTransaction(a.l.ctx, client, signedTx, tx.Hash); err != nil {
+			return "", fmt.Errorf("failed to broadcast transaction: %w", err)
+		}
+		return tx.Hash, nil
+	}
+	go func() {
+		asyncCtx := context.Background() // 使用独立的 context 避免请求取消影响
+		a.l.sendTransactionAsync(asyncCtx, client, signedTx, tx.Hash)
+	}()
+	return tx.Hash, nil
+}
+
+func (a *evmAdapter) GetBalance(address, token string) (*big.Int, error) {
+	client, err := a.l.svcCtx.RPC.EthClient(a.chain)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := common.HexToAddress(address)
+	if token == "" || a.l.IsNativeToken(token) {
+		return client.BalanceAt(a.l.ctx, owner, nil)
+	}
+
+	// balanceOf(address)
+	data := append([]byte{0x70, 0xa0, 0x82, 0x31}, common.LeftPadBytes(owner.Bytes(), 32)...)
+	tokenAddr := common.HexToAddress(token)
+	result, err := client.CallContract(a.l.ctx, ethereum.CallMsg{To: &tokenAddr, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("balanceOf failed: %v", err)
+	}
+	return new(big.Int).SetBytes(result), nil
+}
+
+func (a *evmAdapter) GetTxStatus(txHash string) (string, error) {
+	client, err := a.l.svcCtx.RPC.EthClient(a.chain)
+	if err != nil {
+		return "", err
+	}
+
+	receipt, err := client.TransactionReceipt(a.l.ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return model.TxStatusPending, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if receipt.Status == evmTypes.ReceiptStatusSuccessful {
+		return model.TxStatusConfirmed, nil
+	}
+	return model.TxStatusFailed, nil
+}
+
+func (a *evmAdapter) Swap(req *types.TransactionReq) (*types.TransactionResp, error) {
+	return a.l.handleEVMSwap(req)
+}
+
+func (a *evmAdapter) Bridge(req *types.BridgeExecuteReq, quote *types.BridgeQuoteResp) (string, error) {
+	return a.bridgeLogic().executeEVMBridge(a.chain, req, quote)
+}
+
+func (a *evmAdapter) CheckAllowance(req *types.CheckAllowanceReq) (*types.CheckAllowanceResp, error) {
+	return a.approveLogic().checkEVMTokenAllowance(a.chain, req)
+}
+
+func (a *evmAdapter) Approve(req *types.ApproveTokenReq) (*types.ApproveTokenResp, error) {
+	return a.approveLogic().approveEVMToken(a.chain, req)
+}
+
+func (a *evmAdapter) Revoke(req *types.RevokeApprovalReq) (*types.RevokeApprovalResp, error) {
+	return a.approveLogic().revokeEVMTokenApproval(a.chain, req)
+}
+
+func (a *evmAdapter) ListApprovals(req *types.GetUserApprovalsReq) (*types.GetUserApprovalsResp, error) {
+	return a.approveLogic().getEVMUserApprovals(a.chain, req)
+}
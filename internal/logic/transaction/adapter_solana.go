@@ -0,0 +1,249 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"demo/internal/chains"
+	"demo/internal/model"
+	"demo/internal/svc"
+	"demo/internal/types"
+
+	solanaClient "github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/system"
+	solanaRpc "github.com/blocto/solana-go-sdk/rpc"
+	solanaTypes "github.com/blocto/solana-go-sdk/types"
+	"github.com/mr-tron/base58"
+)
+
+func init() {
+	RegisterAdapter(chains.FamilySolana, func(ctx context.Context, svcCtx *svc.ServiceContext, chain *chains.Chain) ChainAdapter {
+		return &solanaAdapter{adapterBase: newAdapterBase(ctx, svcCtx, chain)}
+	})
+}
+
+// solanaAdapter Solana 适配器，目前转账只支持原生 SOL
+type solanaAdapter struct {
+	adapterBase
+}
+
+func (a *solanaAdapter) ValidateAddress(address string) error {
+	raw, err := base58.Decode(address)
+	if err != nil || len(raw) != common.PublicKeyLength {
+		return fmt.Errorf("invalid Solana address: %s", address)
+	}
+	return nil
+}
+
+// BuildTransfer 构建 SOL 转账消息，手续费由发送方支付
+func (a *solanaAdapter) BuildTransfer(req *TransferRequest) (*UnsignedTx, error) {
+	if !a.isNative(req.Token) {
+		return nil, fmt.Errorf("SPL token transfers are not supported yet: %s", req.Token)
+	}
+	if !req.Max && (!req.Amount.IsUint64() || req.Amount.Sign() <= 0) {
+		return nil, fmt.Errorf("invalid amount: %s", req.Amount.String())
+	}
+
+	c, err := a.l.svcCtx.RPC.SolanaClient(a.chain)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := c.GetLatestBlockhash(a.l.ctx)
+	if err != nil {
+		a.l.Errorf("获取区块哈希失败: %v", err)
+		return nil, fmt.Errorf("failed to get latest blockhash: %v", err)
+	}
+
+	from := common.PublicKeyFromString(req.From)
+	newMessage := func(lamports uint64) solanaTypes.Message {
+		return solanaTypes.NewMessage(solanaTypes.NewMessageParam{
+			FeePayer:        from,
+			RecentBlockhash: latest.Blockhash,
+			Instructions: []solanaTypes.Instruction{
+				system.Transfer(system.TransferParam{
+					From:   from,
+					To:     common.PublicKeyFromString(req.To),
+					Amount: lamports,
+				}),
+			},
+		})
+	}
+	if req.Max {
+		// 手续费只取决于签名数，先用占位金额构建消息查询手续费
+		amount, err := a.maxNativeAmount(c, req, newMessage(0))
+		if err != nil {
+			return nil, err
+		}
+		req.Amount = new(big.Int).SetUint64(amount)
+	}
+	message := newMessage(req.Amount.Uint64())
+	a.l.Infof("转账金额: %s lamports", req.Amount.String())
+
+	return &UnsignedTx{From: req.From, Payload: message}, nil
+}
+
+// maxNativeAmount 全额转出 SOL：余额减去交易手续费，发送账户余额归零后被回收，不违反免租要求；
+// 收款账户不存在时转入金额必须达到免租最低余额，否则交易会失败
+func (a *solanaAdapter) maxNativeAmount(c *solanaClient.Client, req *TransferRequest, message solanaTypes.Message) (uint64, error) {
+	balance, err := c.GetBalance(a.l.ctx, req.From)
+	if err != nil {
+		a.l.Errorf("获取余额失败: %v", err)
+		return 0, fmt.Errorf("failed to get balance: %v", err)
+	}
+	fee, err := c.GetFeeForMessage(a.l.ctx, message)
+	if err != nil {
+		a.l.Errorf("获取手续费失败: %v", err)
+		return 0, fmt.Errorf("failed to get fee for message: %v", err)
+	}
+	if fee == nil {
+		return 0, errors.New("failed to get fee for message: blockhash expired")
+	}
+	if balance <= *fee {
+		return 0, fmt.Errorf("insufficient balance to cover fee: balance %d, fee %d lamports", balance, *fee)
+	}
+	amount := balance - *fee
+
+	recipient, err := c.GetBalance(a.l.ctx, req.To)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get recipient balance: %v", err)
+	}
+	if recipient == 0 {
+		rentExempt, err := c.GetMinimumBalanceForRentExemption(a.l.ctx, 0)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get rent exemption minimum: %v", err)
+		}
+		if amount < rentExempt {
+			return 0, fmt.Errorf("amount %d lamports is below the rent-exempt minimum %d for new account %s", amount, rentExempt, req.To)
+		}
+	}
+	a.l.Infof("全额转出: 余额 %d - 手续费 %d = %d lamports", balance, *fee, amount)
+	return amount, nil
+}
+
+func (a *solanaAdapter) Sign(tx *UnsignedTx) (*SignedTx, error) {
+	message, ok := tx.Payload.(solanaTypes.Message)
+	if !ok {
+		return nil, fmt.Errorf("unexpected Solana payload %T", tx.Payload)
+	}
+
+	privateKeyBytes, err := a.l.GetSolanaPrivateKey(tx.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Solana private key from database: %v", err)
+	}
+	account, err := solanaTypes.AccountFromBytes(privateKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Solana account: %v", err)
+	}
+	if account.PublicKey.ToBase58() != tx.From {
+		return nil, fmt.Errorf("private key does not match address %s", tx.From)
+	}
+
+	signed, err := solanaTypes.NewTransaction(solanaTypes.NewTransactionParam{
+		Message: message,
+		Signers: []solanaTypes.Account{account},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Solana transaction: %v", err)
+	}
+
+	// Solana 交易哈希即第一个签名
+	return &SignedTx{Hash: base58.Encode(signed.Signatures[0]), Payload: signed}, nil
+}
+
+func (a *solanaAdapter) Broadcast(tx *SignedTx) (string, error) {
+	signed, ok := tx.Payload.(solanaTypes.Transaction)
+	if !ok {
+		return "", fmt.Errorf("unexpected Solana payload %T", tx.Payload)
+	}
+
+	c, err := a.l.svcCtx.RPC.SolanaClient(a.chain)
+	if err != nil {
+		return "", err
+	}
+	txHash, err := c.SendTransaction(a.l.ctx, signed)
+	if err != nil {
+		a.l.Errorf("发送 Solana 交易失败: %v", err)
+		return "", fmt.Errorf("failed to send Solana transaction: %v", err)
+	}
+
+	a.l.Infof("✅ Solana 交易已成功提交: %s", txHash)
+	return txHash, nil
+}
+
+func (a *solanaAdapter) GetBalance(address, token string) (*big.Int, error) {
+	if a.isNative(token) {
+		c, err := a.l.svcCtx.RPC.SolanaClient(a.chain)
+		if err != nil {
+			return nil, err
+		}
+		lamports, err := c.GetBalance(a.l.ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetUint64(lamports), nil
+	}
+
+	balance, err := a.approveLogic().getSolanaTokenBalance(a.chain, token, address)
+	if err != nil {
+		return nil, err
+	}
+	amount, ok := new(big.Int).SetString(balance, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid token balance: %s", balance)
+	}
+	return amount, nil
+}
+
+func (a *solanaAdapter) GetTxStatus(txHash string) (string, error) {
+	c, err := a.l.svcCtx.RPC.SolanaClient(a.chain)
+	if err != nil {
+		return "", err
+	}
+
+	status, err := c.GetSignatureStatus(a.l.ctx, txHash)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case status == nil:
+		return model.TxStatusPending, nil
+	case status.Err != nil:
+		return model.TxStatusFailed, nil
+	case status.ConfirmationStatus != nil && *status.ConfirmationStatus != solanaRpc.CommitmentProcessed:
+		return model.TxStatusConfirmed, nil
+	default:
+		return model.TxStatusPending, nil
+	}
+}
+
+// isNative 判断是否为原生 SOL
+func (a *solanaAdapter) isNative(token string) bool {
+	return token == "" || a.l.IsNativeToken(token) || a.l.isSolanaSOL(token)
+}
+
+func (a *solanaAdapter) Swap(req *types.TransactionReq) (*types.TransactionResp, error) {
+	return a.l.handleSolanaSwap(req)
+}
+
+func (a *solanaAdapter) Bridge(req *types.BridgeExecuteReq, quote *types.BridgeQuoteResp) (string, error) {
+	return a.bridgeLogic().sendSolanaTransaction(quote.TransactionRequest.Data, req.FromAddress)
+}
+
+func (a *solanaAdapter) CheckAllowance(req *types.CheckAllowanceReq) (*types.CheckAllowanceResp, error) {
+	return a.approveLogic().checkSolanaTokenAllowance(req)
+}
+
+func (a *solanaAdapter) Approve(req *types.ApproveTokenReq) (*types.ApproveTokenResp, error) {
+	return a.approveLogic().approveSolanaToken(req)
+}
+
+func (a *solanaAdapter) Revoke(req *types.RevokeApprovalReq) (*types.RevokeApprovalResp, error) {
+	return a.approveLogic().revokeSolanaTokenApproval(req)
+}
+
+func (a *solanaAdapter) ListApprovals(req *types.GetUserApprovalsReq) (*types.GetUserApprovalsResp, error) {
+	return a.approveLogic().getSolanaUserApprovals(req)
+}
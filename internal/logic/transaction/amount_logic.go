@@ -0,0 +1,109 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"demo/internal/chains"
+	"demo/internal/svc"
+	"demo/internal/tokens"
+	"demo/internal/units"
+)
+
+// AmountMax amount 取该值时发送全部余额，原生币扣除手续费（仅 /transaction/send）
+const AmountMax = "max"
+
+// Amount 请求金额的两种表示
+type Amount struct {
+	Base    *big.Int
+	Display string // 代币精度未知时为空
+	// Max 为 true 时 Base 为空，由适配器按余额计算后调用 SetBase
+	Max   bool
+	token *tokens.Token
+}
+
+// SetBase 设置最小单位金额并按代币精度换算
+func (a *Amount) SetBase(base *big.Int) {
+	a.Base = base
+	if a.token != nil {
+		a.Display = units.FormatUnits(base, a.token.Decimals)
+	}
+}
+
+// Token 金额对应的代币，注册表中没有时为 nil
+func (a *Amount) Token() *tokens.Token {
+	return a.token
+}
+
+// resolveAmount 按 amount_unit 解析请求金额：base（默认）为最小单位整数，display 为按代币精度换算的小数。
+// 精度来自代币注册表；display 金额超出代币精度时报错而不是四舍五入
+func resolveAmount(ctx context.Context, svcCtx *svc.ServiceContext, chain *chains.Chain, token, amount, unit string) (*Amount, error) {
+	amount = strings.TrimSpace(amount)
+	if token == "" {
+		token = chain.NativeSymbol
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	t, lookupErr := svcCtx.Tokens.Resolve(lookupCtx, chain, token)
+
+	result := &Amount{}
+	if lookupErr == nil {
+		result.token = t
+	}
+	if strings.EqualFold(amount, AmountMax) {
+		result.Max = true
+		return result, nil
+	}
+
+	var base *big.Int
+	switch strings.ToLower(unit) {
+	case "", units.UnitBase:
+		var ok bool
+		if base, ok = new(big.Int).SetString(amount, 10); !ok {
+			return nil, fmt.Errorf("invalid amount: %q (amount_unit=base expects an integer in the smallest unit; use amount_unit=display for decimals)", amount)
+		}
+	case units.UnitDisplay:
+		if lookupErr != nil {
+			return nil, fmt.Errorf("cannot convert display amount, token decimals unknown: %w", lookupErr)
+		}
+		var err error
+		if base, err = units.ParseUnits(amount, t.Decimals); err != nil {
+			return nil, fmt.Errorf("invalid amount for %s: %w", t.Symbol, err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid amount_unit: %s (base|display)", unit)
+	}
+	if base.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+	result.SetBase(base)
+	return result, nil
+}
+
+// requireExactAmount swap / bridge 需要确定的金额报价，不支持 max
+func requireExactAmount(amount *Amount) error {
+	if amount.Max {
+		return fmt.Errorf("amount %q is only supported by /transaction/send", AmountMax)
+	}
+	return nil
+}
+
+// ValidateAmount 校验金额和单位能否被执行时的流程接受，exact 为 true 时按 swap 不允许 max；供保存后再执行的请求提前报错
+func (l *TransactionLogic) ValidateAmount(chain *chains.Chain, token, amount, unit string, exact bool) error {
+	a, err := resolveAmount(l.ctx, l.svcCtx, chain, token, amount, unit)
+	if err != nil {
+		return err
+	}
+	if exact {
+		return requireExactAmount(a)
+	}
+	return nil
+}
+
+// ResolveAmount 按 amount_unit 解析金额，供执行前需要最小单位金额的检查（如定时转账的策略限额）使用
+func (l *TransactionLogic) ResolveAmount(chain *chains.Chain, token, amount, unit string) (*Amount, error) {
+	return resolveAmount(l.ctx, l.svcCtx, chain, token, amount, unit)
+}
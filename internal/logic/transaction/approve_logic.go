@@ -3,9 +3,14 @@ package transaction
 import (
 	"bytes"
 	"context"
+	"demo/internal/chains"
+	"demo/internal/model"
+	"demo/internal/multicall"
+	"demo/internal/rpcpool"
 	"demo/internal/svc"
 	"demo/internal/types"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
@@ -14,7 +19,7 @@ import (
 	"strings"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
@@ -38,23 +43,20 @@ func NewApproveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ApproveLo
 func (l *ApproveLogic) CheckTokenAllowance(req *types.CheckAllowanceReq) (*types.CheckAllowanceResp, error) {
 	l.Infof("检查代币授权额度: token=%s, owner=%s, spender=%s", req.TokenAddress, req.OwnerAddress, req.SpenderAddress)
 
-	// 检测是否为 Solana 链
-	if l.isSolanaChain(req.Chain) {
-		return l.checkSolanaTokenAllowance(req)
-	}
-
-	// 获取链配置
-	chainConfig, ok := l.svcCtx.Config.Chains[req.Chain]
-	if !ok {
-		return nil, fmt.Errorf("unsupported chain: %s", req.Chain)
+	approver, err := l.approvalAdapter(req.Chain)
+	if err != nil {
+		return nil, err
 	}
+	return approver.CheckAllowance(req)
+}
 
+// checkEVMTokenAllowance 检查 EVM 代币授权额度
+func (l *ApproveLogic) checkEVMTokenAllowance(chainConfig *chains.Chain, req *types.CheckAllowanceReq) (*types.CheckAllowanceResp, error) {
 	// 连接 RPC
-	client, err := ethclient.Dial(chainConfig.RpcUrl)
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to chain: %v", err)
 	}
-	defer client.Close()
 
 	// 创建 TransactionLogic 实例
 	txLogic := NewTransactionLogic(l.ctx, l.svcCtx)
@@ -76,26 +78,37 @@ func (l *ApproveLogic) CheckTokenAllowance(req *types.CheckAllowanceReq) (*types
 }
 
 // ApproveToken 授权代币
-func (l *ApproveLogic) ApproveToken(req *types.ApproveTokenReq) (*types.ApproveTokenResp, error) {
+func (l *ApproveLogic) ApproveToken(req *types.ApproveTokenReq) (resp *types.ApproveTokenResp, err error) {
 	l.Infof("开始代币授权: token=%s, spender=%s, amount=%s", req.TokenAddress, req.SpenderAddress, req.Amount)
+	defer func() {
+		if err == nil && resp != nil {
+			recordTransaction(l.ctx, l.svcCtx, &model.Transactions{
+				Chain:       req.Chain,
+				TxHash:      resp.TxHash,
+				TxType:      model.TxTypeApprove,
+				FromAddress: req.OwnerAddress,
+				ToAddress:   req.SpenderAddress,
+				Token:       req.TokenAddress,
+				Amount:      resp.Amount,
+				ExplorerUrl: resp.ExplorerUrl,
+			})
+		}
+	}()
 
-	// 检测是否为 Solana 链
-	if l.isSolanaChain(req.Chain) {
-		return l.approveSolanaToken(req)
-	}
-
-	// 获取链配置
-	chainConfig, ok := l.svcCtx.Config.Chains[req.Chain]
-	if !ok {
-		return nil, fmt.Errorf("unsupported chain: %s", req.Chain)
+	approver, err := l.approvalAdapter(req.Chain)
+	if err != nil {
+		return nil, err
 	}
+	return approver.Approve(req)
+}
 
+// approveEVMToken EVM 代币授权
+func (l *ApproveLogic) approveEVMToken(chainConfig *chains.Chain, req *types.ApproveTokenReq) (*types.ApproveTokenResp, error) {
 	// 连接 RPC
-	client, err := ethclient.Dial(chainConfig.RpcUrl)
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to chain: %v", err)
 	}
-	defer client.Close()
 
 	// 获取钱包私钥
 	txLogic := NewTransactionLogic(l.ctx, l.svcCtx)
@@ -119,7 +132,7 @@ func (l *ApproveLogic) ApproveToken(req *types.ApproveTokenReq) (*types.ApproveT
 	}
 
 	// 执行授权交易
-	txHash, err := txLogic.ExecuteApproveTransaction(client, privateKey, req.TokenAddress, req.SpenderAddress, amount, chainConfig.ChainId)
+	txHash, err := txLogic.ExecuteApproveTransaction(client, privateKey, req.TokenAddress, req.SpenderAddress, amount, chainConfig, FeeOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("approve transaction failed: %v", err)
 	}
@@ -141,26 +154,37 @@ func (l *ApproveLogic) ApproveToken(req *types.ApproveTokenReq) (*types.ApproveT
 }
 
 // RevokeTokenApproval 取消代币授权
-func (l *ApproveLogic) RevokeTokenApproval(req *types.RevokeApprovalReq) (*types.RevokeApprovalResp, error) {
+func (l *ApproveLogic) RevokeTokenApproval(req *types.RevokeApprovalReq) (resp *types.RevokeApprovalResp, err error) {
 	l.Infof("开始取消代币授权: token=%s, spender=%s", req.TokenAddress, req.SpenderAddress)
+	defer func() {
+		if err == nil && resp != nil {
+			recordTransaction(l.ctx, l.svcCtx, &model.Transactions{
+				Chain:       req.Chain,
+				TxHash:      resp.TxHash,
+				TxType:      model.TxTypeRevoke,
+				FromAddress: req.OwnerAddress,
+				ToAddress:   req.SpenderAddress,
+				Token:       req.TokenAddress,
+				Amount:      "0",
+				ExplorerUrl: resp.ExplorerUrl,
+			})
+		}
+	}()
 
-	// 检测是否为 Solana 链
-	if l.isSolanaChain(req.Chain) {
-		return l.revokeSolanaTokenApproval(req)
-	}
-
-	// 获取链配置
-	chainConfig, ok := l.svcCtx.Config.Chains[req.Chain]
-	if !ok {
-		return nil, fmt.Errorf("unsupported chain: %s", req.Chain)
+	approver, err := l.approvalAdapter(req.Chain)
+	if err != nil {
+		return nil, err
 	}
+	return approver.Revoke(req)
+}
 
+// revokeEVMTokenApproval 取消 EVM 代币授权
+func (l *ApproveLogic) revokeEVMTokenApproval(chainConfig *chains.Chain, req *types.RevokeApprovalReq) (*types.RevokeApprovalResp, error) {
 	// 连接 RPC
-	client, err := ethclient.Dial(chainConfig.RpcUrl)
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to chain: %v", err)
 	}
-	defer client.Close()
 
 	// 获取钱包私钥
 	txLogic := NewTransactionLogic(l.ctx, l.svcCtx)
@@ -173,7 +197,7 @@ func (l *ApproveLogic) RevokeTokenApproval(req *types.RevokeApprovalReq) (*types
 	zeroAmount := big.NewInt(0)
 
 	// 执行取消授权交易
-	txHash, err := txLogic.ExecuteApproveTransaction(client, privateKey, req.TokenAddress, req.SpenderAddress, zeroAmount, chainConfig.ChainId)
+	txHash, err := txLogic.ExecuteApproveTransaction(client, privateKey, req.TokenAddress, req.SpenderAddress, zeroAmount, chainConfig, FeeOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("revoke approval transaction failed: %v", err)
 	}
@@ -197,28 +221,19 @@ func (l *ApproveLogic) RevokeTokenApproval(req *types.RevokeApprovalReq) (*types
 func (l *ApproveLogic) GetUserApprovals(req *types.GetUserApprovalsReq) (*types.GetUserApprovalsResp, error) {
 	l.Infof("获取用户授权记录: address=%s, chain=%s", req.UserAddress, req.Chain)
 
-	// 检测是否为 Solana 链
-	if l.isSolanaChain(req.Chain) {
-		return l.getSolanaUserApprovals(req)
-	}
-
-	// 获取链配置
-	chainConfig, ok := l.svcCtx.Config.Chains[req.Chain]
-	if !ok {
-		return nil, fmt.Errorf("unsupported chain: %s", req.Chain)
+	approver, err := l.approvalAdapter(req.Chain)
+	if err != nil {
+		return nil, err
 	}
+	return approver.ListApprovals(req)
+}
 
-	// 连接 RPC
-	client, err := ethclient.Dial(chainConfig.RpcUrl)
+// getEVMUserApprovals 获取 EVM 链上的授权记录：代币 × spender 通过 Multicall3 批量读取
+func (l *ApproveLogic) getEVMUserApprovals(chainConfig *chains.Chain, req *types.GetUserApprovalsReq) (*types.GetUserApprovalsResp, error) {
+	caller, err := l.svcCtx.Multicall.Caller(chainConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to chain: %v", err)
 	}
-	defer client.Close()
-
-	// 创建 TransactionLogic 实例
-	txLogic := NewTransactionLogic(l.ctx, l.svcCtx)
-
-	var approvals []types.ApprovalInfo
 
 	// 检查常见的 spender 地址（LI.FI 相关合约）
 	commonSpenders := []types.SpenderInfo{
@@ -228,12 +243,27 @@ func (l *ApproveLogic) GetUserApprovals(req *types.GetUserApprovalsReq) (*types.
 		// 可以根据需要添加更多常见的 spender
 	}
 
-	// 检查指定的代币列表
+	owner := common.HexToAddress(req.UserAddress)
+	calls := make([]multicall.Call, 0, len(req.TokenAddresses)*len(commonSpenders))
 	for _, tokenAddr := range req.TokenAddresses {
 		for _, spender := range commonSpenders {
-			allowance, err := txLogic.CheckAllowance(client, tokenAddr, req.UserAddress, spender.Address)
-			if err != nil {
-				l.Errorf("检查授权失败: %v", err)
+			calls = append(calls, multicall.Allowance(common.HexToAddress(tokenAddr), owner, common.HexToAddress(spender.Address)))
+		}
+	}
+	results, err := caller.Aggregate(l.ctx, calls)
+	if err != nil {
+		l.Errorf("批量查询授权失败: %v", err)
+		return nil, errors.New("failed to query allowances")
+	}
+
+	var approvals []types.ApprovalInfo
+	failed := 0
+	for i, tokenAddr := range req.TokenAddresses {
+		for j, spender := range commonSpenders {
+			allowance, ok := results[i*len(commonSpenders)+j].Uint()
+			if !ok {
+				l.Errorf("检查授权失败: token=%s, spender=%s", tokenAddr, spender.Address)
+				failed++
 				continue
 			}
 
@@ -249,11 +279,15 @@ func (l *ApproveLogic) GetUserApprovals(req *types.GetUserApprovalsReq) (*types.
 		}
 	}
 
+	message := fmt.Sprintf("找到 %d 个有效授权", len(approvals))
+	if failed > 0 {
+		message += fmt.Sprintf("，%d 个查询失败", failed)
+	}
 	return &types.GetUserApprovalsResp{
 		UserAddress: req.UserAddress,
 		Chain:       req.Chain,
 		Approvals:   approvals,
-		Message:     fmt.Sprintf("找到 %d 个有效授权", len(approvals)),
+		Message:     message,
 	}, nil
 }
 
@@ -270,39 +304,40 @@ func (l *ApproveLogic) isUnlimitedApproval(allowance *big.Int) bool {
 	return allowance.Cmp(threshold) > 0
 }
 
-// buildExplorerUrl 构建区块链浏览器链接
-func (l *ApproveLogic) buildExplorerUrl(chain, txHash string) string {
-	explorerMap := map[string]string{
-		"ETH":      "https://etherscan.io/tx/%s",
-		"BSC":      "https://bscscan.com/tx/%s",
-		"Polygon":  "https://polygonscan.com/tx/%s",
-		"Arbitrum": "https://arbiscan.io/tx/%s",
-		"Optimism": "https://optimistic.etherscan.io/tx/%s",
-		"Base":     "https://basescan.org/tx/%s",
-		// 添加 Solana 支持
-		"Solana": "https://solscan.io/tx/%s",
-		"SOL":    "https://solscan.io/tx/%s",
+// getChain 从链注册表获取链配置
+func (l *ApproveLogic) getChain(chain string) (*chains.Chain, error) {
+	c, ok := l.svcCtx.Chains.Get(chain)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %s", chain)
 	}
+	return c, nil
+}
 
-	if template, ok := explorerMap[chain]; ok {
-		return fmt.Sprintf(template, txHash)
+// buildExplorerUrl 构建区块链浏览器链接
+func (l *ApproveLogic) buildExplorerUrl(chain, txHash string) string {
+	if c, ok := l.svcCtx.Chains.Get(chain); ok {
+		if url := c.ExplorerTx(txHash); url != "" {
+			return url
+		}
 	}
 	return fmt.Sprintf("https://explorer.example.com/tx/%s", txHash)
 }
 
-// ========== Solana Approve 支持函数 ==========
-
-// isSolanaChain 检测是否为 Solana 链
-func (l *ApproveLogic) isSolanaChain(chain string) bool {
-	solanaChains := []string{"Solana", "SOL", "solana", "sol"}
-	for _, solChain := range solanaChains {
-		if strings.EqualFold(chain, solChain) {
-			return true
-		}
+// approvalAdapter 返回支持授权管理的链适配器
+func (l *ApproveLogic) approvalAdapter(chain string) (ApprovalAdapter, error) {
+	adapter, err := NewChainAdapter(l.ctx, l.svcCtx, chain)
+	if err != nil {
+		return nil, err
+	}
+	approver, ok := adapter.(ApprovalAdapter)
+	if !ok {
+		return nil, fmt.Errorf("token approvals are not supported on chain %s", chain)
 	}
-	return false
+	return approver, nil
 }
 
+// ========== Solana Approve 支持函数 ==========
+
 // checkSolanaTokenAllowance 检查 Solana 代币授权
 func (l *ApproveLogic) checkSolanaTokenAllowance(req *types.CheckAllowanceReq) (*types.CheckAllowanceResp, error) {
 	l.Infof("=== 检查 Solana 代币授权状态 ===")
@@ -313,8 +348,13 @@ func (l *ApproveLogic) checkSolanaTokenAllowance(req *types.CheckAllowanceReq) (
 	// 2. 账户是否有足够的代币余额
 	// 3. 是否有相关的 PDA 授权
 
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+
 	// 示例：返回账户余额作为"可用额度"
-	balance, err := l.getSolanaTokenBalance(req.TokenAddress, req.OwnerAddress)
+	balance, err := l.getSolanaTokenBalance(chainConfig, req.TokenAddress, req.OwnerAddress)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Solana token balance: %v", err)
 	}
@@ -338,8 +378,13 @@ func (l *ApproveLogic) approveSolanaToken(req *types.ApproveTokenReq) (*types.Ap
 	// 2. 设置账户委托权限
 	// 3. 或者直接返回成功（因为 Solana 很多操作不需要预授权）
 
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+
 	// 方案1: 创建 ATA 交易
-	txHash, err := l.createSolanaATA(req.TokenAddress, req.OwnerAddress)
+	txHash, err := l.createSolanaATA(chainConfig, req.TokenAddress, req.OwnerAddress)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Solana ATA: %v", err)
 	}
@@ -350,7 +395,7 @@ func (l *ApproveLogic) approveSolanaToken(req *types.ApproveTokenReq) (*types.Ap
 		SpenderAddress: req.SpenderAddress,
 		Amount:         req.Amount,
 		Chain:          req.Chain,
-		ExplorerUrl:    l.buildSolanaExplorerUrl(txHash),
+		ExplorerUrl:    l.buildExplorerUrl(req.Chain, txHash),
 		Message:        "✅ Solana 代币账户已准备就绪！(Solana 无需传统授权)",
 		Status:         "pending",
 	}, nil
@@ -374,7 +419,7 @@ func (l *ApproveLogic) revokeSolanaTokenApproval(req *types.RevokeApprovalReq) (
 		TokenAddress:   req.TokenAddress,
 		SpenderAddress: req.SpenderAddress,
 		Chain:          req.Chain,
-		ExplorerUrl:    l.buildSolanaExplorerUrl(txHash),
+		ExplorerUrl:    l.buildExplorerUrl(req.Chain, txHash),
 		Message:        "✅ Solana 代币授权已撤销！(模拟操作)",
 		Status:         "pending",
 	}, nil
@@ -389,11 +434,16 @@ func (l *ApproveLogic) getSolanaUserApprovals(req *types.GetUserApprovalsReq) (*
 	// 2. 检查委托权限
 	// 3. 显示账户余额信息
 
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+
 	var approvals []types.ApprovalInfo
 
 	// 示例：检查用户的代币账户
 	for _, tokenAddr := range req.TokenAddresses {
-		balance, err := l.getSolanaTokenBalance(tokenAddr, req.UserAddress)
+		balance, err := l.getSolanaTokenBalance(chainConfig, tokenAddr, req.UserAddress)
 		if err != nil {
 			l.Errorf("获取 Solana 代币余额失败: %v", err)
 			continue
@@ -419,26 +469,25 @@ func (l *ApproveLogic) getSolanaUserApprovals(req *types.GetUserApprovalsReq) (*
 }
 
 // getSolanaTokenBalance 获取 Solana 代币余额
-func (l *ApproveLogic) getSolanaTokenBalance(tokenAddress, ownerAddress string) (string, error) {
+func (l *ApproveLogic) getSolanaTokenBalance(chainConfig *chains.Chain, tokenAddress, ownerAddress string) (string, error) {
 	l.Infof("获取 Solana 代币余额: token=%s, owner=%s", tokenAddress, ownerAddress)
 
-	// 获取 Solana RPC URL
-	chainConfig, ok := l.svcCtx.Config.Chains["Solana"]
-	if !ok {
-		return "0", fmt.Errorf("Solana chain config not found")
+	pool, err := l.svcCtx.RPC.Pool(chainConfig)
+	if err != nil {
+		return "0", err
 	}
 
 	// 如果是 SOL 原生代币，直接查询账户余额
 	if tokenAddress == "11111111111111111111111111111111" {
-		return l.getSolanaAccountBalance(ownerAddress, chainConfig.RpcUrl)
+		return l.getSolanaAccountBalance(ownerAddress, pool)
 	}
 
 	// 对于 SPL 代币，查询 Token Account 余额
-	return l.getSPLTokenBalance(tokenAddress, ownerAddress, chainConfig.RpcUrl)
+	return l.getSPLTokenBalance(tokenAddress, ownerAddress, pool)
 }
 
 // getSolanaAccountBalance 获取 SOL 原生代币余额
-func (l *ApproveLogic) getSolanaAccountBalance(ownerAddress, rpcUrl string) (string, error) {
+func (l *ApproveLogic) getSolanaAccountBalance(ownerAddress string, pool *rpcpool.Pool) (string, error) {
 	// 构建 Solana RPC 请求
 	requestBody := map[string]interface{}{
 		"jsonrpc": "2.0",
@@ -453,7 +502,7 @@ func (l *ApproveLogic) getSolanaAccountBalance(ownerAddress, rpcUrl string) (str
 	}
 
 	// 发送 HTTP 请求
-	resp, err := http.Post(rpcUrl, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := pool.HTTPClient().Post(pool.URL(), "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "0", fmt.Errorf("failed to call Solana RPC: %v", err)
 	}
@@ -490,7 +539,7 @@ func (l *ApproveLogic) getSolanaAccountBalance(ownerAddress, rpcUrl string) (str
 }
 
 // getSPLTokenBalance 获取 SPL 代币余额
-func (l *ApproveLogic) getSPLTokenBalance(tokenAddress, ownerAddress, rpcUrl string) (string, error) {
+func (l *ApproveLogic) getSPLTokenBalance(tokenAddress, ownerAddress string, pool *rpcpool.Pool) (string, error) {
 	// 构建 getTokenAccountsByOwner 请求
 	requestBody := map[string]interface{}{
 		"jsonrpc": "2.0",
@@ -509,7 +558,7 @@ func (l *ApproveLogic) getSPLTokenBalance(tokenAddress, ownerAddress, rpcUrl str
 	}
 
 	// 发送 HTTP 请求
-	resp, err := http.Post(rpcUrl, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := pool.HTTPClient().Post(pool.URL(), "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "0", fmt.Errorf("failed to call Solana RPC: %v", err)
 	}
@@ -564,11 +613,11 @@ func (l *ApproveLogic) getSPLTokenBalance(tokenAddress, ownerAddress, rpcUrl str
 }
 
 // createSolanaATA 创建 Associated Token Account
-func (l *ApproveLogic) createSolanaATA(tokenAddress, ownerAddress string) (string, error) {
+func (l *ApproveLogic) createSolanaATA(chainConfig *chains.Chain, tokenAddress, ownerAddress string) (string, error) {
 	l.Infof("创建 Solana ATA: token=%s, owner=%s", tokenAddress, ownerAddress)
 
 	// 1. 首先检查 ATA 是否已存在
-	exists, err := l.checkATAExists(tokenAddress, ownerAddress)
+	exists, err := l.checkATAExists(chainConfig, tokenAddress, ownerAddress)
 	if err != nil {
 		l.Errorf("检查 ATA 失败: %v", err)
 		// 即使检查失败，也继续尝试创建
@@ -581,7 +630,7 @@ func (l *ApproveLogic) createSolanaATA(tokenAddress, ownerAddress string) (strin
 
 	// 2. 使用 LI.FI API 来处理 Solana ATA 创建
 	// 这是最可靠的方式，因为 LI.FI 已经处理了所有 Solana 复杂性
-	txHash, err := l.createATAViaLiFi(tokenAddress, ownerAddress)
+	txHash, err := l.createATAViaLiFi(chainConfig, tokenAddress, ownerAddress)
 	if err != nil {
 		l.Errorf("通过 LI.FI 创建 ATA 失败: %v", err)
 		// 如果 LI.FI 失败，返回一个表示需要 ATA 的状态
@@ -593,15 +642,13 @@ func (l *ApproveLogic) createSolanaATA(tokenAddress, ownerAddress string) (strin
 }
 
 // checkATAExists 检查 Associated Token Account 是否存在
-func (l *ApproveLogic) checkATAExists(tokenAddress, ownerAddress string) (bool, error) {
-	// 获取 Solana RPC URL
-	chainConfig, ok := l.svcCtx.Config.Chains["Solana"]
-	if !ok {
-		return false, fmt.Errorf("Solana chain config not found")
-	}
-
+func (l *ApproveLogic) checkATAExists(chainConfig *chains.Chain, tokenAddress, ownerAddress string) (bool, error) {
 	// 尝试获取代币余额，如果能获取到就说明 ATA 存在
-	_, err := l.getSPLTokenBalance(tokenAddress, ownerAddress, chainConfig.RpcUrl)
+	pool, err := l.svcCtx.RPC.Pool(chainConfig)
+	if err != nil {
+		return false, err
+	}
+	_, err = l.getSPLTokenBalance(tokenAddress, ownerAddress, pool)
 	if err != nil {
 		// 如果是因为找不到账户而失败，说明 ATA 不存在
 		if strings.Contains(err.Error(), "未找到 SPL 代币账户") {
@@ -615,14 +662,14 @@ func (l *ApproveLogic) checkATAExists(tokenAddress, ownerAddress string) (bool,
 }
 
 // createATAViaLiFi 通过 LI.FI API 创建 ATA
-func (l *ApproveLogic) createATAViaLiFi(tokenAddress, ownerAddress string) (string, error) {
+func (l *ApproveLogic) createATAViaLiFi(chainConfig *chains.Chain, tokenAddress, ownerAddress string) (string, error) {
 	l.Infof("通过 LI.FI 创建 Solana ATA")
 
 	// 构建一个最小的 Solana 同链转账来触发 ATA 创建
 	// LI.FI 会自动处理 ATA 创建
 	requestBody := map[string]interface{}{
-		"fromChain":   1151111081099710, // Solana Chain ID
-		"toChain":     1151111081099710, // 同链
+		"fromChain":   chainConfig.LifiId(),
+		"toChain":     chainConfig.LifiId(), // 同链
 		"fromToken":   tokenAddress,
 		"toToken":     tokenAddress,
 		"fromAmount":  "0", // 0 金额，主要是为了创建 ATA
@@ -693,8 +740,3 @@ func (l *ApproveLogic) createATAViaLiFi(tokenAddress, ownerAddress string) (stri
 	// 如果没有交易数据，可能 ATA 已经存在
 	return "ata_already_exists", nil
 }
-
-// buildSolanaExplorerUrl 构建 Solana 浏览器链接
-func (l *ApproveLogic) buildSolanaExplorerUrl(txHash string) string {
-	return fmt.Sprintf("https://solscan.io/tx/%s", txHash)
-}
@@ -0,0 +1,529 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"demo/internal/chains"
+	"demo/internal/gasoracle"
+	"demo/internal/model"
+	"demo/internal/multicall"
+	"demo/internal/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	evmTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// batchMaxItems 单次批量转账的最大付款数
+	batchMaxItems = 1000
+	// disperseChunkSize 单笔 disperse 交易的最大收款数，避免超过区块 gas 上限
+	disperseChunkSize = 200
+	// disperse 交易的 gas 估算：固定开销 + 每个收款的开销。
+	// approve 上链前无法对 disperseToken 做 eth_estimateGas，按新地址（首次写入余额）的开销估算
+	disperseBaseGas      = 60000
+	disperseEtherItemGas = 40000
+	disperseTokenItemGas = 60000
+
+	batchModeDisperse   = "disperse"
+	batchModeSequential = "sequential"
+
+	batchTxApprove  = "approve"
+	batchTxDisperse = "disperse"
+	batchTxSend     = "send"
+
+	batchItemSubmitted = "submitted"
+	batchItemFailed    = "failed"
+)
+
+// disperseABI disperse.app 合约及 ERC20 approve
+var disperseABI = mustParseABI(`[
+	{"name":"disperseEther","type":"function","stateMutability":"payable","inputs":[{"name":"recipients","type":"address[]"},{"name":"values","type":"uint256[]"}],"outputs":[]},
+	{"name":"disperseToken","type":"function","stateMutability":"nonpayable","inputs":[{"name":"token","type":"address"},{"name":"recipients","type":"address[]"},{"name":"values","type":"uint256[]"}],"outputs":[]},
+	{"name":"approve","type":"function","stateMutability":"nonpayable","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}
+]`)
+
+func mustParseABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// batchItem 校验后的付款
+type batchItem struct {
+	to     common.Address
+	token  string // 空表示原生币，否则为 checksum 合约地址
+	amount *big.Int
+	result *types.BatchSendResult
+}
+
+// batchGroup 同一代币的付款
+type batchGroup struct {
+	token string
+	items []*batchItem
+	total *big.Int
+}
+
+// batchTx 计划发送的一笔交易
+type batchTx struct {
+	kind   string
+	token  string
+	to     common.Address
+	value  *big.Int
+	data   []byte
+	gas    uint64
+	amount *big.Int // 写入交易记录的金额
+	// approve 授权的合约
+	spender common.Address
+	// 交易覆盖的付款；approve 覆盖该代币的全部付款，失败时这些付款不再发送
+	items []*batchItem
+}
+
+// WrapBatchSend 批量转账：按代币分组，配置了 Disperse 合约的链每组拆成若干笔 disperse 交易，
+// 否则逐笔发送。发送前检查余额（含手续费），结果按付款 ID 返回
+func (l *TransactionLogic) WrapBatchSend(req *types.BatchSendReq) (*types.BatchSendResp, error) {
+	l.Infof("--- 开始处理 /transaction/batch_send 请求 for address %s, chain %s, %d 笔付款 ---", req.FromAddress, req.Chain, len(req.Items))
+
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	if !chainConfig.IsEVM() {
+		return nil, fmt.Errorf("batch_send is only supported on EVM chains: %s", req.Chain)
+	}
+	if !common.IsHexAddress(req.FromAddress) {
+		return nil, fmt.Errorf("invalid from_address: %s", req.FromAddress)
+	}
+	feeOpts, err := parseFeeOptions(req.FeeLevel, req.MaxFeePerGas, req.MaxPriorityFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 校验付款并按代币分组
+	items, err := l.parseBatchItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+	groups := groupBatchItems(items)
+
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
+	if err != nil {
+		l.Errorf("RPC 节点连接失败: %v", err)
+		return nil, errors.New("failed to connect to chain")
+	}
+	fees, err := l.SuggestFees(chainConfig, feeOpts)
+	if err != nil {
+		return nil, err
+	}
+	l.Infof("手续费 (%s): %s", feeOpts.Level, fees)
+
+	// 2. 规划交易
+	from := common.HexToAddress(req.FromAddress)
+	mode := batchModeSequential
+	var plan []*batchTx
+	if chainConfig.Disperse != "" {
+		mode = batchModeDisperse
+		plan, err = l.planDisperse(client, chainConfig, from, groups)
+	} else {
+		plan, err = l.planSequential(client, from, groups)
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.Infof("批量转账规划完成: 模式 %s，%d 个代币，%d 笔交易", mode, len(groups), len(plan))
+
+	// 3. 检查余额（原生币需同时覆盖手续费）
+	if err := l.checkBatchBalances(chainConfig, req, groups, plan, fees); err != nil {
+		return nil, err
+	}
+
+	// 4. 依次签名并发送，nonce 在本地递增
+	privateKey, err := l.GetWalletPrivateKey(req.FromAddress)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := client.PendingNonceAt(l.ctx, from)
+	if err != nil {
+		l.Errorf("获取 nonce 失败: %v", err)
+		return nil, errors.New("failed to get nonce")
+	}
+
+	resp := &types.BatchSendResp{Chain: req.Chain, Mode: mode}
+	for _, tx := range plan {
+		if failed := firstFailed(tx.items); failed != nil {
+			// approve 失败后该代币的付款不再发送
+			markBatchItems(tx.items, "", failed.result.Error)
+			continue
+		}
+
+		signed, err := evmTypes.SignTx(NewEVMTx(chainConfig.ChainId, nonce, &tx.to, tx.value, tx.gas, tx.data, fees), EVMSigner(chainConfig.ChainId), privateKey)
+		if err == nil {
+			err = client.SendTransaction(l.ctx, signed)
+		}
+		if err != nil {
+			l.Errorf("批量转账交易发送失败 (%s, %d 笔付款): %v", tx.kind, len(tx.items), err)
+			msg := fmt.Sprintf("%s transaction failed: %v", tx.kind, err)
+			markBatchItems(tx.items, "", msg)
+			continue
+		}
+		// 发送失败的 nonce 没有被占用，只有成功时递增
+		nonce++
+
+		txHash := signed.Hash().Hex()
+		explorerUrl := l.BuildExplorerUrl(req.Chain, txHash)
+		l.Infof("✅ 批量转账交易已发送 (%s, %d 笔付款): %s", tx.kind, len(tx.items), txHash)
+		if tx.kind != batchTxApprove {
+			markBatchItems(tx.items, txHash, "")
+		}
+
+		resp.Transactions = append(resp.Transactions, types.BatchSendTx{
+			TxHash:      txHash,
+			Kind:        tx.kind,
+			Token:       tx.token,
+			ItemIds:     batchItemIds(tx.items),
+			ExplorerUrl: explorerUrl,
+		})
+		recordTransaction(l.ctx, l.svcCtx, &model.Transactions{
+			Chain:       req.Chain,
+			TxHash:      txHash,
+			TxType:      batchRecordType(tx.kind),
+			FromAddress: req.FromAddress,
+			ToAddress:   batchRecordTo(tx),
+			Token:       tx.token,
+			Amount:      tx.amount.String(),
+			ExplorerUrl: explorerUrl,
+		})
+	}
+
+	for _, item := range items {
+		if item.result.Status == batchItemSubmitted {
+			resp.Submitted++
+		} else {
+			resp.Failed++
+		}
+		resp.Results = append(resp.Results, *item.result)
+	}
+	switch {
+	case resp.Failed == 0:
+		resp.Status = model.TxStatusPending
+	case resp.Submitted == 0:
+		resp.Status = model.TxStatusFailed
+	default:
+		resp.Status = "partial"
+	}
+
+	l.Infof("批量转账完成: 提交 %d 笔，失败 %d 笔", resp.Submitted, resp.Failed)
+	return resp, nil
+}
+
+// parseBatchItems 校验全部付款，任何一笔无效时整批拒绝
+func (l *TransactionLogic) parseBatchItems(reqItems []types.BatchSendItem) ([]*batchItem, error) {
+	if len(reqItems) == 0 {
+		return nil, errors.New("items must not be empty")
+	}
+	if len(reqItems) > batchMaxItems {
+		return nil, fmt.Errorf("too many items: %d (max %d)", len(reqItems), batchMaxItems)
+	}
+
+	seen := make(map[string]bool, len(reqItems))
+	items := make([]*batchItem, 0, len(reqItems))
+	var problems []string
+	for i, it := range reqItems {
+		id := it.Id
+		if id == "" {
+			id = strconv.Itoa(i)
+		}
+		if seen[id] {
+			problems = append(problems, fmt.Sprintf("%s: duplicate id", id))
+			continue
+		}
+		seen[id] = true
+
+		if !common.IsHexAddress(it.To) {
+			problems = append(problems, fmt.Sprintf("%s: invalid to address %q", id, it.To))
+			continue
+		}
+		amount, ok := new(big.Int).SetString(it.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			problems = append(problems, fmt.Sprintf("%s: invalid amount %q", id, it.Amount))
+			continue
+		}
+		token := ""
+		if it.Token != "" && !l.isNativeTokenFold(it.Token) {
+			if !common.IsHexAddress(it.Token) {
+				problems = append(problems, fmt.Sprintf("%s: invalid token %q", id, it.Token))
+				continue
+			}
+			token = common.HexToAddress(it.Token).Hex()
+		}
+
+		to := common.HexToAddress(it.To)
+		items = append(items, &batchItem{
+			to:     to,
+			token:  token,
+			amount: amount,
+			result: &types.BatchSendResult{Id: id, To: to.Hex(), Token: token, Amount: amount.String(), Status: batchItemFailed},
+		})
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid batch items: %s", strings.Join(problems, "; "))
+	}
+	return items, nil
+}
+
+// isNativeTokenFold 不区分大小写地判断原生币地址
+func (l *TransactionLogic) isNativeTokenFold(token string) bool {
+	return l.IsNativeToken(token) ||
+		strings.EqualFold(token, "0x0000000000000000000000000000000000000000") ||
+		strings.EqualFold(token, "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+}
+
+// groupBatchItems 按代币分组，保持代币首次出现的顺序
+func groupBatchItems(items []*batchItem) []*batchGroup {
+	var groups []*batchGroup
+	index := make(map[string]*batchGroup)
+	for _, item := range items {
+		g, ok := index[item.token]
+		if !ok {
+			g = &batchGroup{token: item.token, total: new(big.Int)}
+			index[item.token] = g
+			groups = append(groups, g)
+		}
+		g.items = append(g.items, item)
+		g.total.Add(g.total, item.amount)
+	}
+	return groups
+}
+
+// planDisperse 每个代币拆成若干笔 disperse 交易；ERC20 授权不足时先 approve 本组总额
+func (l *TransactionLogic) planDisperse(client *ethclient.Client, chainConfig *chains.Chain, from common.Address, groups []*batchGroup) ([]*batchTx, error) {
+	if !common.IsHexAddress(chainConfig.Disperse) {
+		return nil, fmt.Errorf("invalid Disperse contract on chain %s: %s", chainConfig.Key, chainConfig.Disperse)
+	}
+	disperse := common.HexToAddress(chainConfig.Disperse)
+
+	var plan []*batchTx
+	for _, g := range groups {
+		if g.token != "" {
+			allowance, err := l.CheckAllowance(client, g.token, from.Hex(), disperse.Hex())
+			if err != nil {
+				return nil, fmt.Errorf("failed to check allowance of %s: %v", g.token, err)
+			}
+			if allowance.Cmp(g.total) < 0 {
+				// 授权额度与本组总额一致，disperse 执行后额度归零
+				data, err := disperseABI.Pack("approve", disperse, g.total)
+				if err != nil {
+					return nil, err
+				}
+				tokenAddr := common.HexToAddress(g.token)
+				plan = append(plan, &batchTx{
+					kind:    batchTxApprove,
+					token:   g.token,
+					to:      tokenAddr,
+					value:   big.NewInt(0),
+					data:    data,
+					gas:     l.EstimateERC20TransferGas(client, from, tokenAddr, data),
+					amount:  g.total,
+					spender: disperse,
+					items:   g.items,
+				})
+			}
+		}
+
+		for start := 0; start < len(g.items); start += disperseChunkSize {
+			chunk := g.items[start:min(start+disperseChunkSize, len(g.items))]
+			recipients := make([]common.Address, len(chunk))
+			values := make([]*big.Int, len(chunk))
+			total := new(big.Int)
+			for i, item := range chunk {
+				recipients[i] = item.to
+				values[i] = item.amount
+				total.Add(total, item.amount)
+			}
+
+			tx := &batchTx{kind: batchTxDisperse, token: g.token, to: disperse, amount: total, items: chunk}
+			var err error
+			if g.token == "" {
+				tx.value = total
+				tx.data, err = disperseABI.Pack("disperseEther", recipients, values)
+				tx.gas = l.estimateBatchGas(client, from, tx, disperseBaseGas+uint64(len(chunk))*disperseEtherItemGas)
+			} else {
+				tx.value = big.NewInt(0)
+				tx.data, err = disperseABI.Pack("disperseToken", common.HexToAddress(g.token), recipients, values)
+				tx.gas = disperseBaseGas + uint64(len(chunk))*disperseTokenItemGas
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to pack disperse calldata: %v", err)
+			}
+			plan = append(plan, tx)
+		}
+	}
+	return plan, nil
+}
+
+// planSequential 没有 Disperse 合约时每笔付款一笔交易，同一代币只估算一次 gas
+func (l *TransactionLogic) planSequential(client *ethclient.Client, from common.Address, groups []*batchGroup) ([]*batchTx, error) {
+	var plan []*batchTx
+	for _, g := range groups {
+		var gas uint64
+		for _, item := range g.items {
+			tx := &batchTx{kind: batchTxSend, token: g.token, amount: item.amount, items: []*batchItem{item}}
+			if g.token == "" {
+				tx.to = item.to
+				tx.value = item.amount
+				if gas == 0 {
+					gas = l.EstimateNativeTransferGas(client, from, item.to, item.amount)
+				}
+			} else {
+				data, err := l.BuildERC20TransferData(item.to.Hex(), item.amount)
+				if err != nil {
+					return nil, err
+				}
+				tx.to = common.HexToAddress(g.token)
+				tx.value = big.NewInt(0)
+				tx.data = data
+				if gas == 0 {
+					gas = l.EstimateERC20TransferGas(client, from, tx.to, data)
+				}
+			}
+			tx.gas = gas
+			plan = append(plan, tx)
+		}
+	}
+	return plan, nil
+}
+
+// estimateBatchGas eth_estimateGas 加 20% 缓冲，失败时使用按收款数估算的默认值
+func (l *TransactionLogic) estimateBatchGas(client *ethclient.Client, from common.Address, tx *batchTx, fallback uint64) uint64 {
+	gas, err := client.EstimateGas(l.ctx, ethereum.CallMsg{From: from, To: &tx.to, Value: tx.value, Data: tx.data})
+	if err != nil {
+		l.Infof("disperse Gas 估算失败，使用默认值 %d: %v", fallback, err)
+		return fallback
+	}
+	return gas * 120 / 100
+}
+
+// checkBatchBalances 发送前检查每个代币的余额，原生币需覆盖转账总额和全部交易的最高手续费
+func (l *TransactionLogic) checkBatchBalances(chainConfig *chains.Chain, req *types.BatchSendReq, groups []*batchGroup, plan []*batchTx, fees *gasoracle.Fees) error {
+	caller, err := l.svcCtx.Multicall.Caller(chainConfig)
+	if err != nil {
+		return err
+	}
+
+	var totalGas uint64
+	for _, tx := range plan {
+		totalGas += tx.gas
+	}
+	required := map[string]*big.Int{
+		"": new(big.Int).Mul(fees.MaxGasPrice(), new(big.Int).SetUint64(totalGas)),
+	}
+	tokens := []string{""}
+	for _, g := range groups {
+		if g.token == "" {
+			required[""].Add(required[""], g.total)
+			continue
+		}
+		required[g.token] = g.total
+		tokens = append(tokens, g.token)
+	}
+
+	// 所有余额在同一批次中读取
+	owner := common.HexToAddress(req.FromAddress)
+	calls := make([]multicall.Call, len(tokens))
+	for i, token := range tokens {
+		if token == "" {
+			calls[i] = caller.EthBalance(owner)
+		} else {
+			calls[i] = multicall.BalanceOf(common.HexToAddress(token), owner)
+		}
+	}
+	results, err := caller.Aggregate(l.ctx, calls)
+	if err != nil {
+		l.Errorf("批量查询余额失败: %v", err)
+		return errors.New("failed to query balances")
+	}
+
+	var shortfalls []string
+	for i, token := range tokens {
+		balance, ok := results[i].Uint()
+		if !ok {
+			return fmt.Errorf("failed to get balance of %s", batchTokenName(token))
+		}
+		if balance.Cmp(required[token]) < 0 {
+			shortfalls = append(shortfalls, fmt.Sprintf("%s: need %s, have %s", batchTokenName(token), required[token], balance))
+		}
+	}
+	if len(shortfalls) > 0 {
+		l.Errorf("批量转账余额不足: %s", strings.Join(shortfalls, "; "))
+		return fmt.Errorf("insufficient balance: %s", strings.Join(shortfalls, "; "))
+	}
+	return nil
+}
+
+func batchTokenName(token string) string {
+	if token == "" {
+		return "native (incl. gas)"
+	}
+	return token
+}
+
+func firstFailed(items []*batchItem) *batchItem {
+	for _, item := range items {
+		if item.result.Error != "" {
+			return item
+		}
+	}
+	return nil
+}
+
+// markBatchItems 写入付款结果，errMsg 为空表示已提交
+func markBatchItems(items []*batchItem, txHash, errMsg string) {
+	for _, item := range items {
+		if errMsg != "" {
+			item.result.Status = batchItemFailed
+			item.result.Error = errMsg
+			continue
+		}
+		item.result.Status = batchItemSubmitted
+		item.result.TxHash = txHash
+	}
+}
+
+func batchItemIds(items []*batchItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.result.Id
+	}
+	return ids
+}
+
+func batchRecordType(kind string) string {
+	switch kind {
+	case batchTxApprove:
+		return model.TxTypeApprove
+	case batchTxDisperse:
+		return model.TxTypeBatchSend
+	default:
+		return model.TxTypeSend
+	}
+}
+
+// batchRecordTo 交易记录的对方地址：approve 为授权的 disperse 合约，逐笔 ERC20 转账为收款地址
+func batchRecordTo(tx *batchTx) string {
+	switch {
+	case tx.kind == batchTxApprove:
+		return tx.spender.Hex()
+	case tx.kind == batchTxSend && tx.token != "":
+		return tx.items[0].to.Hex()
+	default:
+		return tx.to.Hex()
+	}
+}
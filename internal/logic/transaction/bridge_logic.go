@@ -3,8 +3,12 @@ package transaction
 import (
 	"context"
 	"crypto/ecdsa"
+	"demo/internal/chains"
+	"demo/internal/model"
 	"demo/internal/svc"
+	"demo/internal/tokens"
 	"demo/internal/types"
+	"demo/internal/units"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -54,8 +58,8 @@ func (l *BridgeLogic) GetBridgeQuote(req *types.BridgeQuoteReq) (*types.BridgeQu
 	params := url.Values{}
 	params.Set("fromChain", strconv.Itoa(req.FromChain))
 	params.Set("toChain", strconv.Itoa(req.ToChain))
-	params.Set("fromToken", l.normalizeTokenAddress(req.FromToken))
-	params.Set("toToken", l.normalizeTokenAddress(req.ToToken))
+	params.Set("fromToken", l.normalizeTokenAddress(req.FromChain, req.FromToken))
+	params.Set("toToken", l.normalizeTokenAddress(req.ToChain, req.ToToken))
 	params.Set("fromAmount", req.FromAmount)
 	params.Set("fromAddress", req.FromAddress)
 	params.Set("toAddress", req.ToAddress)
@@ -152,10 +156,28 @@ func (l *BridgeLogic) GetBridgeQuote(req *types.BridgeQuoteReq) (*types.BridgeQu
 }
 
 // ExecuteBridge 执行跨链转账
-func (l *BridgeLogic) ExecuteBridge(req *types.BridgeExecuteReq) (*types.BridgeExecuteResp, error) {
+func (l *BridgeLogic) ExecuteBridge(req *types.BridgeExecuteReq) (resp *types.BridgeExecuteResp, err error) {
 	l.Infof("--- 开始执行跨链转账 fromChain=%d toChain=%d ---", req.FromChain, req.ToChain)
+	defer func() {
+		if err == nil && resp != nil {
+			recordTransaction(l.ctx, l.svcCtx, &model.Transactions{
+				Chain:       l.getChainNameByID(req.FromChain),
+				TxHash:      resp.TxHash,
+				TxType:      model.TxTypeBridge,
+				FromAddress: req.FromAddress,
+				ToAddress:   req.ToAddress,
+				Token:       req.FromToken,
+				Amount:      req.Amount,
+				ExplorerUrl: resp.ExplorerUrl,
+			})
+		}
+	}()
 
-	// 1. 先获取报价
+	// 1. 先获取报价（金额统一换算为最小单位）
+	amount, err := l.resolveBridgeAmount(req)
+	if err != nil {
+		return nil, err
+	}
 	quoteReq := &types.BridgeQuoteReq{
 		FromChain:   req.FromChain,
 		ToChain:     req.ToChain,
@@ -175,20 +197,18 @@ func (l *BridgeLogic) ExecuteBridge(req *types.BridgeExecuteReq) (*types.BridgeE
 	}
 
 	// 2. 获取源链配置
-	chainName := l.getChainNameByID(req.FromChain)
-	chainConfig, ok := l.svcCtx.Config.Chains[chainName]
+	chainConfig, ok := l.svcCtx.Chains.ByLifiChainId(int64(req.FromChain))
 	if !ok {
 		l.Errorf("不支持的源链: %d", req.FromChain)
 		return nil, fmt.Errorf("unsupported from chain: %d", req.FromChain)
 	}
 
 	// 3. 连接源链 RPC
-	client, err := ethclient.Dial(chainConfig.RpcUrl)
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
 	if err != nil {
 		l.Errorf("连接源链 RPC 失败: %v", err)
 		return nil, errors.New("failed to connect to source chain")
 	}
-	defer client.Close()
 
 	// 4. 获取钱包私钥
 	txLogic := NewTransactionLogic(l.ctx, l.svcCtx)
@@ -200,7 +220,7 @@ func (l *BridgeLogic) ExecuteBridge(req *types.BridgeExecuteReq) (*types.BridgeE
 	// 5. 检查是否需要 approve（ERC20 代币）
 	if !txLogic.IsNativeToken(req.FromToken) && quoteResp.Estimate.ApprovalAddress != "" {
 		l.Infof("需要先执行 approve 操作")
-		err := l.executeApprove(client, req, quoteResp.Estimate.ApprovalAddress, privateKey, chainConfig.ChainId)
+		err := l.executeApprove(client, req, quoteResp.Estimate.ApprovalAddress, privateKey, chainConfig)
 		if err != nil {
 			l.Errorf("approve 操作失败: %v", err)
 			return nil, fmt.Errorf("approve failed: %v", err)
@@ -208,7 +228,7 @@ func (l *BridgeLogic) ExecuteBridge(req *types.BridgeExecuteReq) (*types.BridgeE
 	}
 
 	// 6. 构建并发送跨链交易
-	txHash, err := l.sendBridgeTransaction(client, quoteResp.TransactionRequest, privateKey, chainConfig.ChainId)
+	txHash, err := l.sendBridgeTransaction(client, quoteResp.TransactionRequest, privateKey, chainConfig)
 	if err != nil {
 		l.Errorf("发送跨链交易失败: %v", err)
 		return nil, fmt.Errorf("failed to send bridge transaction: %v", err)
@@ -219,12 +239,14 @@ func (l *BridgeLogic) ExecuteBridge(req *types.BridgeExecuteReq) (*types.BridgeE
 	message := fmt.Sprintf("✅ 跨链转账已提交！从链 %d 到链 %d，交易哈希: %s", req.FromChain, req.ToChain, txHash)
 
 	return &types.BridgeExecuteResp{
-		TxHash:      txHash,
-		Message:     message,
-		ExplorerUrl: explorerUrl,
-		FromChain:   req.FromChain,
-		ToChain:     req.ToChain,
-		Status:      "pending",
+		TxHash:          txHash,
+		Message:         message,
+		ExplorerUrl:     explorerUrl,
+		FromChain:       req.FromChain,
+		ToChain:         req.ToChain,
+		Status:          "pending",
+		Amount:          req.Amount,
+		AmountFormatted: amount.Display,
 	}, nil
 }
 
@@ -307,7 +329,7 @@ func (l *BridgeLogic) GetBridgeStatus(req *types.BridgeStatusReq) (*types.Bridge
 }
 
 // executeApprove 执行 ERC20 approve 操作（按照 LI.FI 最佳实践）
-func (l *BridgeLogic) executeApprove(client *ethclient.Client, req *types.BridgeExecuteReq, approvalAddress string, privateKey *ecdsa.PrivateKey, chainId int64) error {
+func (l *BridgeLogic) executeApprove(client *ethclient.Client, req *types.BridgeExecuteReq, approvalAddress string, privateKey *ecdsa.PrivateKey, chainConfig *chains.Chain) error {
 	l.Infof("执行 ERC20 approve 操作，approvalAddress: %s", approvalAddress)
 
 	// 构建 approve 调用数据
@@ -332,9 +354,9 @@ func (l *BridgeLogic) executeApprove(client *ethclient.Client, req *types.Bridge
 		return fmt.Errorf("failed to get nonce: %v", err)
 	}
 
-	gasPrice, err := client.SuggestGasPrice(l.ctx)
+	fees, err := NewTransactionLogic(l.ctx, l.svcCtx).SuggestFees(chainConfig, FeeOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get gas price: %v", err)
+		return err
 	}
 
 	// 估算 gas limit
@@ -352,18 +374,15 @@ func (l *BridgeLogic) executeApprove(client *ethclient.Client, req *types.Bridge
 	// 增加 gas limit 缓冲（ERC20 approve 可能消耗更多 gas）
 	gasLimit = gasLimit * 120 / 100 // 增加 20% 缓冲
 
+	if err := NewTransactionLogic(l.ctx, l.svcCtx).ensureTxGas(chainConfig, fromAddr, gasLimit, fees, model.GasTopUpPurposeApprove); err != nil {
+		return err
+	}
+
 	// 构建 approve 交易
-	tx := evmTypes.NewTx(&evmTypes.LegacyTx{
-		Nonce:    nonce,
-		To:       &tokenAddr,
-		Value:    big.NewInt(0),
-		Gas:      gasLimit,
-		GasPrice: gasPrice,
-		Data:     data,
-	})
+	tx := NewEVMTx(chainConfig.ChainId, nonce, &tokenAddr, big.NewInt(0), gasLimit, data, fees)
 
 	// 签名并发送
-	signedTx, err := evmTypes.SignTx(tx, evmTypes.NewEIP155Signer(big.NewInt(chainId)), privateKey)
+	signedTx, err := evmTypes.SignTx(tx, EVMSigner(chainConfig.ChainId), privateKey)
 	if err != nil {
 		return fmt.Errorf("failed to sign approve transaction: %v", err)
 	}
@@ -425,7 +444,7 @@ func (l *BridgeLogic) waitForTransactionReceipt(client *ethclient.Client, txHash
 }
 
 // sendBridgeTransaction 发送跨链交易（按照 LI.FI 最佳实践）
-func (l *BridgeLogic) sendBridgeTransaction(client *ethclient.Client, txReq types.BridgeTxRequest, privateKey *ecdsa.PrivateKey, chainId int64) (string, error) {
+func (l *BridgeLogic) sendBridgeTransaction(client *ethclient.Client, txReq types.BridgeTxRequest, privateKey *ecdsa.PrivateKey, chainConfig *chains.Chain) (string, error) {
 	l.Infof("发送跨链交易")
 
 	// 解析交易参数
@@ -456,18 +475,17 @@ func (l *BridgeLogic) sendBridgeTransaction(client *ethclient.Client, txReq type
 		}
 	}
 
-	gasPrice := new(big.Int)
-	if txReq.GasPrice != "" {
-		_, _ = gasPrice.SetString(txReq.GasPrice, 10)
+	txLogic := NewTransactionLogic(l.ctx, l.svcCtx)
+	fees, err := txLogic.SuggestFees(chainConfig, FeeOptions{})
+	if err != nil {
+		return "", err
 	}
 
-	// 如果 LI.FI 没有提供 gas price，使用节点建议的价格
-	if gasPrice.Sign() == 0 {
-		gp, err := client.SuggestGasPrice(l.ctx)
-		if err != nil {
-			return "", fmt.Errorf("failed to get gas price: %v", err)
+	// legacy 链优先使用 LI.FI 报价中的 gas price
+	if !fees.Dynamic && txReq.GasPrice != "" {
+		if gp, ok := new(big.Int).SetString(txReq.GasPrice, 10); ok && gp.Sign() > 0 && txLogic.withinFeeCap(chainConfig, gp) {
+			fees.GasPrice = gp
 		}
-		gasPrice = gp
 	}
 
 	// 如果 LI.FI 没有提供 gas limit，进行估算
@@ -488,20 +506,20 @@ func (l *BridgeLogic) sendBridgeTransaction(client *ethclient.Client, txReq type
 		}
 	}
 
-	l.Infof("交易参数: to=%s, value=%s, gasLimit=%d, gasPrice=%s", to.Hex(), value.String(), gasLimit, gasPrice.String())
+	l.Infof("交易参数: to=%s, value=%s, gasLimit=%d, %s", to.Hex(), value.String(), gasLimit, fees)
+
+	// 代币跨链不转出原生币，原生币不足以支付手续费时由 gas 站补充
+	if value.Sign() == 0 {
+		if err := txLogic.ensureTxGas(chainConfig, fromAddr, gasLimit, fees, model.GasTopUpPurposeBridge); err != nil {
+			return "", err
+		}
+	}
 
 	// 构建交易
-	tx := evmTypes.NewTx(&evmTypes.LegacyTx{
-		Nonce:    nonce,
-		To:       &to,
-		Value:    value,
-		Gas:      gasLimit,
-		GasPrice: gasPrice,
-		Data:     data,
-	})
+	tx := NewEVMTx(chainConfig.ChainId, nonce, &to, value, gasLimit, data, fees)
 
 	// 签名交易
-	signedTx, err := evmTypes.SignTx(tx, evmTypes.NewEIP155Signer(big.NewInt(chainId)), privateKey)
+	signedTx, err := evmTypes.SignTx(tx, EVMSigner(chainConfig.ChainId), privateKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign transaction: %v", err)
 	}
@@ -522,61 +540,48 @@ func (l *BridgeLogic) sendBridgeTransaction(client *ethclient.Client, txReq type
 	return signedTx.Hash().Hex(), nil
 }
 
-// getChainNameByID 根据链ID获取链名称
+// getChainNameByID 根据链ID（LI.FI 链 ID 或原生链 ID）获取链名称
 func (l *BridgeLogic) getChainNameByID(chainId int) string {
-	chainMap := map[int]string{
-		1:                "ETH",
-		5:                "ETH-Goerli",
-		11155111:         "ETH-Sepolia",
-		56:               "BSC",
-		97:               "BSC-TestNet",
-		137:              "Polygon",
-		80001:            "Polygon-Mumbai",
-		8453:             "Base",
-		10:               "Optimism",
-		42161:            "Arbitrum",
-		1151111081099710: "Solana", // 添加 Solana 支持
-	}
-
-	if name, ok := chainMap[chainId]; ok {
-		return name
+	if c, ok := l.svcCtx.Chains.ByLifiChainId(int64(chainId)); ok {
+		return c.Key
 	}
 	return "UNKNOWN"
 }
 
 // buildBridgeExplorerUrl 构建跨链浏览器链接
 func (l *BridgeLogic) buildBridgeExplorerUrl(chainId int, txHash string) string {
-	explorerMap := map[int]string{
-		1:                "https://etherscan.io/tx/%s",
-		5:                "https://goerli.etherscan.io/tx/%s",
-		11155111:         "https://sepolia.etherscan.io/tx/%s",
-		56:               "https://bscscan.com/tx/%s",
-		97:               "https://testnet.bscscan.com/tx/%s",
-		137:              "https://polygonscan.com/tx/%s",
-		80001:            "https://mumbai.polygonscan.com/tx/%s",
-		8453:             "https://basescan.org/tx/%s",
-		10:               "https://optimistic.etherscan.io/tx/%s",
-		42161:            "https://arbiscan.io/tx/%s",
-		1151111081099710: "https://solscan.io/tx/%s", // 添加 Solana 浏览器
-	}
-
-	if template, ok := explorerMap[chainId]; ok {
-		return fmt.Sprintf(template, txHash)
+	if c, ok := l.svcCtx.Chains.ByLifiChainId(int64(chainId)); ok {
+		if url := c.ExplorerTx(txHash); url != "" {
+			return url
+		}
 	}
 	return fmt.Sprintf("https://explorer.example.com/tx/%s", txHash)
 }
 
 // WrapBridge 完整的跨链操作流程（按照 LI.FI 最佳实践）
-func (l *BridgeLogic) WrapBridge(req *types.BridgeExecuteReq) (*types.BridgeExecuteResp, error) {
+func (l *BridgeLogic) WrapBridge(req *types.BridgeExecuteReq) (resp *types.BridgeExecuteResp, err error) {
 	l.Infof("=== 开始完整跨链流程 fromChain=%d toChain=%d ===", req.FromChain, req.ToChain)
-
-	// 检测是否涉及 Solana
-	if l.isSolanaBridge(req.FromChain, req.ToChain) {
-		return l.handleSolanaBridge(req)
-	}
+	defer func() {
+		if err == nil && resp != nil {
+			recordTransaction(l.ctx, l.svcCtx, &model.Transactions{
+				Chain:       l.getChainNameByID(req.FromChain),
+				TxHash:      resp.TxHash,
+				TxType:      model.TxTypeBridge,
+				FromAddress: req.FromAddress,
+				ToAddress:   req.ToAddress,
+				Token:       req.FromToken,
+				Amount:      req.Amount,
+				ExplorerUrl: resp.ExplorerUrl,
+			})
+		}
+	}()
 
 	// 步骤1: 获取跨链报价
 	l.Infof("步骤1: 获取跨链报价...")
+	amount, err := l.resolveBridgeAmount(req)
+	if err != nil {
+		return nil, err
+	}
 	quoteReq := &types.BridgeQuoteReq{
 		FromChain:   req.FromChain,
 		ToChain:     req.ToChain,
@@ -596,88 +601,121 @@ func (l *BridgeLogic) WrapBridge(req *types.BridgeExecuteReq) (*types.BridgeExec
 	}
 	l.Infof("✅ 跨链报价获取成功")
 
-	// 步骤2: 获取源链配置和连接
-	chainName := l.getChainNameByID(req.FromChain)
-	chainConfig, ok := l.svcCtx.Config.Chains[chainName]
+	// 步骤2: 由源链适配器执行跨链交易
+	chainConfig, ok := l.svcCtx.Chains.ByLifiChainId(int64(req.FromChain))
 	if !ok {
 		l.Errorf("不支持的源链: %d", req.FromChain)
 		return nil, fmt.Errorf("unsupported from chain: %d", req.FromChain)
 	}
+	adapter, err := newChainAdapter(l.ctx, l.svcCtx, chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	bridger, ok := adapter.(BridgeAdapter)
+	if !ok {
+		return nil, fmt.Errorf("bridging from chain %s is not supported", chainConfig.Key)
+	}
 
-	client, err := ethclient.Dial(chainConfig.RpcUrl)
+	l.Infof("步骤2: 发送跨链交易 (%s)...", chainConfig.Family())
+	txHash, err := bridger.Bridge(req, quoteResp)
 	if err != nil {
-		l.Errorf("连接源链 RPC 失败: %v", err)
-		return nil, errors.New("failed to connect to source chain")
+		l.Errorf("发送跨链交易失败: %v", err)
+		return nil, fmt.Errorf("failed to send bridge transaction: %v", err)
 	}
-	defer client.Close()
+	l.Infof("✅ 跨链交易已发送: %s", txHash)
 
-	// 步骤3: 获取钱包私钥
-	wallet, err := l.svcCtx.WalletsDao.FindOneByAddress(l.ctx, req.FromAddress)
+	// 步骤3: 构建响应
+	message := fmt.Sprintf("✅ 跨链转账已提交！从 %s 到 %s，交易哈希: %s。请使用 /bridge/status 查询进度。",
+		l.getChainNameByID(req.FromChain), l.getChainNameByID(req.ToChain), txHash)
+
+	return &types.BridgeExecuteResp{
+		TxHash:          txHash,
+		Message:         message,
+		ExplorerUrl:     adapter.ExplorerURL(txHash),
+		FromChain:       req.FromChain,
+		ToChain:         req.ToChain,
+		Status:          "pending",
+		Amount:          req.Amount,
+		AmountFormatted: amount.Display,
+	}, nil
+}
+
+// resolveBridgeAmount 按源链代币精度解析请求金额，并将 req.Amount 改写为最小单位
+func (l *BridgeLogic) resolveBridgeAmount(req *types.BridgeExecuteReq) (*Amount, error) {
+	chainConfig, ok := l.svcCtx.Chains.ByLifiChainId(int64(req.FromChain))
+	if !ok {
+		return nil, fmt.Errorf("unsupported from chain: %d", req.FromChain)
+	}
+	amount, err := resolveAmount(l.ctx, l.svcCtx, chainConfig, req.FromToken, req.Amount, req.AmountUnit)
 	if err != nil {
-		l.Errorf("查询钱包失败: %v", err)
-		return nil, errors.New("wallet not found")
+		return nil, err
+	}
+	if err := requireExactAmount(amount); err != nil {
+		return nil, err
 	}
+	req.Amount, req.AmountUnit = amount.Base.String(), units.UnitBase
+	return amount, nil
+}
 
-	privateKey, err := crypto.HexToECDSA(wallet.EncryptedPrivateKey)
+// executeEVMBridge 在 EVM 源链上按需签名授权或 approve 后发送 LI.FI 跨链交易
+func (l *BridgeLogic) executeEVMBridge(chainConfig *chains.Chain, req *types.BridgeExecuteReq, quote *types.BridgeQuoteResp) (string, error) {
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
 	if err != nil {
-		l.Errorf("私钥解析失败: %v", err)
-		return nil, errors.New("invalid private key")
+		l.Errorf("连接源链 RPC 失败: %v", err)
+		return "", errors.New("failed to connect to source chain")
 	}
 
-	// 创建 TransactionLogic 实例用于调用通用方法
+	// 获取钱包私钥
 	txLogic := NewTransactionLogic(l.ctx, l.svcCtx)
+	privateKey, err := txLogic.GetWalletPrivateKey(req.FromAddress)
+	if err != nil {
+		return "", err
+	}
 
-	// 步骤4: 检查并执行 ERC20 approve（如果需要）
-	if !txLogic.IsNativeToken(req.FromToken) && quoteResp.Estimate.ApprovalAddress != "" {
-		l.Infof("步骤4: 检查并执行 ERC20 approve...")
+	// 检查并执行 ERC20 approve（如果需要）
+	if !txLogic.IsNativeToken(req.FromToken) && quote.Estimate.ApprovalAddress != "" {
+		l.Infof("检查并执行 ERC20 approve...")
 
-		// 检查当前 allowance
-		currentAllowance, err := txLogic.CheckAllowance(client, req.FromToken, req.FromAddress, quoteResp.Estimate.ApprovalAddress)
+		currentAllowance, err := txLogic.CheckAllowance(client, req.FromToken, req.FromAddress, quote.Estimate.ApprovalAddress)
 		if err != nil {
 			l.Errorf("检查 allowance 失败: %v", err)
-			return nil, fmt.Errorf("failed to check allowance: %v", err)
+			return "", fmt.Errorf("failed to check allowance: %v", err)
 		}
 
 		amount, _ := new(big.Int).SetString(req.Amount, 10)
+		var permitTx *PermitTx
 		if currentAllowance.Cmp(amount) < 0 {
+			// 优先用签名授权随跨链交易一起提交，省去 approve 交易且不留下永久授权
+			permitTx = txLogic.PermitLiFiCall(client, privateKey, chainConfig, req.FromToken, amount,
+				quote.TransactionRequest.To, quote.TransactionRequest.Data, quote.TransactionRequest.Value)
+		}
+
+		switch {
+		case currentAllowance.Cmp(amount) >= 0:
+			l.Infof("✅ 当前 allowance 充足，无需 approve")
+		case permitTx != nil:
+			quote.TransactionRequest.To = permitTx.To
+			quote.TransactionRequest.Data = permitTx.Data
+			quote.TransactionRequest.GasLimit = permitTx.GasLimit
+			l.Infof("✅ 使用 %s 签名授权，无需单独 approve", permitTx.Mode)
+		default:
 			l.Infof("当前 allowance 不足，需要执行 approve")
 			maxAmount := new(big.Int)
 			maxAmount.SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
 
-			_, err := txLogic.ExecuteApproveTransaction(client, privateKey, req.FromToken, quoteResp.Estimate.ApprovalAddress, maxAmount, chainConfig.ChainId)
+			_, err := txLogic.ExecuteApproveTransaction(client, privateKey, req.FromToken, quote.Estimate.ApprovalAddress, maxAmount, chainConfig, FeeOptions{})
 			if err != nil {
 				l.Errorf("approve 操作失败: %v", err)
-				return nil, fmt.Errorf("approve failed: %v", err)
+				return "", fmt.Errorf("approve failed: %v", err)
 			}
 			l.Infof("✅ ERC20 approve 完成")
-		} else {
-			l.Infof("✅ 当前 allowance 充足，无需 approve")
 		}
 	} else {
-		l.Infof("步骤4: 原生代币，跳过 approve")
-	}
-
-	// 步骤5: 发送主跨链交易
-	l.Infof("步骤5: 发送主跨链交易...")
-	txHash, err := l.sendBridgeTransactionWithRetry(client, quoteResp.TransactionRequest, privateKey, chainConfig.ChainId)
-	if err != nil {
-		l.Errorf("发送跨链交易失败: %v", err)
-		return nil, fmt.Errorf("failed to send bridge transaction: %v", err)
+		l.Infof("原生代币或无需 approve，跳过 approve 步骤")
 	}
-	l.Infof("✅ 跨链交易已发送: %s", txHash)
 
-	// 步骤6: 构建响应
-	explorerUrl := l.buildBridgeExplorerUrl(req.FromChain, txHash)
-	message := fmt.Sprintf("✅ 跨链转账已提交！从链 %d 到链 %d，交易哈希: %s。请使用 /bridge/status 查询进度。", req.FromChain, req.ToChain, txHash)
-
-	return &types.BridgeExecuteResp{
-		TxHash:      txHash,
-		Message:     message,
-		ExplorerUrl: explorerUrl,
-		FromChain:   req.FromChain,
-		ToChain:     req.ToChain,
-		Status:      "pending",
-	}, nil
+	// 发送主跨链交易
+	return l.sendBridgeTransactionWithRetry(client, quote.TransactionRequest, privateKey, chainConfig)
 }
 
 // checkAllowance 检查 ERC20 代币的 allowance
@@ -714,10 +752,10 @@ func (l *BridgeLogic) checkAllowance(client *ethclient.Client, tokenAddress, own
 }
 
 // executeApproveWithRetry 带重试的 approve 操作
-func (l *BridgeLogic) executeApproveWithRetry(client *ethclient.Client, req *types.BridgeExecuteReq, approvalAddress string, privateKey *ecdsa.PrivateKey, chainId int64) error {
+func (l *BridgeLogic) executeApproveWithRetry(client *ethclient.Client, req *types.BridgeExecuteReq, approvalAddress string, privateKey *ecdsa.PrivateKey, chainConfig *chains.Chain) error {
 	maxRetries := 3
 	for i := 0; i < maxRetries; i++ {
-		err := l.executeApprove(client, req, approvalAddress, privateKey, chainId)
+		err := l.executeApprove(client, req, approvalAddress, privateKey, chainConfig)
 		if err != nil {
 			l.Errorf("approve 操作失败 (尝试 %d/%d): %v", i+1, maxRetries, err)
 			if i == maxRetries-1 {
@@ -732,10 +770,10 @@ func (l *BridgeLogic) executeApproveWithRetry(client *ethclient.Client, req *typ
 }
 
 // sendBridgeTransactionWithRetry 带重试的跨链交易发送
-func (l *BridgeLogic) sendBridgeTransactionWithRetry(client *ethclient.Client, txReq types.BridgeTxRequest, privateKey *ecdsa.PrivateKey, chainId int64) (string, error) {
+func (l *BridgeLogic) sendBridgeTransactionWithRetry(client *ethclient.Client, txReq types.BridgeTxRequest, privateKey *ecdsa.PrivateKey, chainConfig *chains.Chain) (string, error) {
 	maxRetries := 3
 	for i := 0; i < maxRetries; i++ {
-		txHash, err := l.sendBridgeTransaction(client, txReq, privateKey, chainId)
+		txHash, err := l.sendBridgeTransaction(client, txReq, privateKey, chainConfig)
 		if err != nil {
 			l.Errorf("发送跨链交易失败 (尝试 %d/%d): %v", i+1, maxRetries, err)
 			if i == maxRetries-1 {
@@ -831,33 +869,26 @@ func (l *BridgeLogic) GetSupportedChains() ([]ChainInfo, error) {
 	return chainsResp.Chains, nil
 }
 
-// normalizeTokenAddress 标准化代币地址（转换为 LI.FI 格式）
-func (l *BridgeLogic) normalizeTokenAddress(tokenAddr string) string {
-	// EVM 原生代币处理
-	if tokenAddr == "0x0000000000000000000000000000000000000000" {
-		return "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE" // LI.FI 原生代币标识
+// normalizeTokenAddress 标准化代币地址（转换为 LI.FI 格式）：原生币转换为 LI.FI 的标识，
+// 已配置链上的代币符号经代币注册表解析为地址，其他输入原样交给 LI.FI
+func (l *BridgeLogic) normalizeTokenAddress(lifiChainId int, token string) string {
+	c, ok := l.svcCtx.Chains.ByLifiChainId(int64(lifiChainId))
+	if !ok {
+		if token == tokens.NativeEVM {
+			return tokens.LifiNativeEVM
+		}
+		return token
 	}
 
-	// Solana 原生代币处理
-	if tokenAddr == "SOL" || tokenAddr == "sol" {
-		return "11111111111111111111111111111111" // Solana System Program
+	t, err := l.svcCtx.Tokens.Resolve(l.ctx, c, token)
+	if err != nil {
+		l.Infof("⚠️ 代币注册表未能解析 %s (%s): %v", token, c.Key, err)
+		return token
 	}
-
-	// Solana 常见代币映射
-	solanaTokenMap := map[string]string{
-		"USDC": "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
-		"USDT": "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB",
-		"WSOL": "So11111111111111111111111111111111111111112",
+	if t.Native && c.IsEVM() {
+		return tokens.LifiNativeEVM
 	}
-
-	// 检查是否有映射（不区分大小写）
-	for symbol, address := range solanaTokenMap {
-		if strings.EqualFold(tokenAddr, symbol) {
-			return address
-		}
-	}
-
-	return tokenAddr
+	return t.Address
 }
 
 // GetSupportedTokens 获取支持的代币列表
@@ -897,7 +928,10 @@ func (l *BridgeLogic) GetSupportedTokens(chainIds []int) (map[int][]TokenInfo, e
 		return nil, fmt.Errorf("API error: %d", resp.StatusCode)
 	}
 
-	var tokensResp map[int][]TokenInfo
+	// 响应格式: {"tokens": {"<chainId>": [...]}}
+	var tokensResp struct {
+		Tokens map[int][]TokenInfo `json:"tokens"`
+	}
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, errors.New("failed to read response")
@@ -907,7 +941,7 @@ func (l *BridgeLogic) GetSupportedTokens(chainIds []int) (map[int][]TokenInfo, e
 		return nil, errors.New("failed to parse response")
 	}
 
-	return tokensResp, nil
+	return tokensResp.Tokens, nil
 }
 
 // ChainInfo 链信息
@@ -935,164 +969,6 @@ type TokenInfo struct {
 
 // ========== Solana Bridge 支持函数 ==========
 
-// isSolanaBridge 检测是否涉及 Solana 链
-func (l *BridgeLogic) isSolanaBridge(fromChain, toChain int) bool {
-	solanaChainId := 1151111081099710
-	return fromChain == solanaChainId || toChain == solanaChainId
-}
-
-// handleSolanaBridge 处理涉及 Solana 的跨链
-func (l *BridgeLogic) handleSolanaBridge(req *types.BridgeExecuteReq) (*types.BridgeExecuteResp, error) {
-	l.Infof("=== 处理 Solana 跨链操作 ===")
-
-	// 1. 获取跨链报价（复用现有逻辑，LI.FI API 会处理 Solana）
-	quoteReq := &types.BridgeQuoteReq{
-		FromChain:   req.FromChain,
-		ToChain:     req.ToChain,
-		FromToken:   req.FromToken,
-		ToToken:     req.ToToken,
-		FromAmount:  req.Amount,
-		FromAddress: req.FromAddress,
-		ToAddress:   req.ToAddress,
-		Order:       req.Order,
-		Slippage:    req.Slippage,
-	}
-
-	quoteResp, err := l.GetBridgeQuote(quoteReq)
-	if err != nil {
-		l.Errorf("获取 Solana 跨链报价失败: %v", err)
-		return nil, fmt.Errorf("failed to get Solana bridge quote: %v", err)
-	}
-
-	l.Infof("✅ Solana 跨链报价获取成功")
-
-	// 2. 根据源链类型执行不同逻辑
-	if req.FromChain == 1151111081099710 {
-		// Solana -> EVM
-		return l.executeSolanaToEVMBridge(req, quoteResp)
-	} else {
-		// EVM -> Solana
-		return l.executeEVMToSolanaBridge(req, quoteResp)
-	}
-}
-
-// executeEVMToSolanaBridge EVM 到 Solana 的跨链
-func (l *BridgeLogic) executeEVMToSolanaBridge(req *types.BridgeExecuteReq, quote *types.BridgeQuoteResp) (*types.BridgeExecuteResp, error) {
-	l.Infof("=== 执行 EVM -> Solana 跨链 ===")
-
-	// 源链是 EVM，可以复用现有的 EVM 逻辑
-	// 1. 获取源链配置和连接
-	chainName := l.getChainNameByID(req.FromChain)
-	chainConfig, ok := l.svcCtx.Config.Chains[chainName]
-	if !ok {
-		l.Errorf("不支持的源链: %d", req.FromChain)
-		return nil, fmt.Errorf("unsupported from chain: %d", req.FromChain)
-	}
-
-	client, err := ethclient.Dial(chainConfig.RpcUrl)
-	if err != nil {
-		l.Errorf("连接源链 RPC 失败: %v", err)
-		return nil, errors.New("failed to connect to source chain")
-	}
-	defer client.Close()
-
-	// 2. 获取钱包私钥
-	wallet, err := l.svcCtx.WalletsDao.FindOneByAddress(l.ctx, req.FromAddress)
-	if err != nil {
-		l.Errorf("查询钱包失败: %v", err)
-		return nil, errors.New("wallet not found")
-	}
-
-	privateKey, err := crypto.HexToECDSA(wallet.EncryptedPrivateKey)
-	if err != nil {
-		l.Errorf("私钥解析失败: %v", err)
-		return nil, errors.New("invalid private key")
-	}
-
-	// 创建 TransactionLogic 实例用于调用通用方法
-	txLogic := NewTransactionLogic(l.ctx, l.svcCtx)
-
-	// 3. 检查并执行 ERC20 approve（如果需要）
-	if !txLogic.IsNativeToken(req.FromToken) && quote.Estimate.ApprovalAddress != "" {
-		l.Infof("检查并执行 ERC20 approve...")
-
-		// 检查当前 allowance
-		currentAllowance, err := txLogic.CheckAllowance(client, req.FromToken, req.FromAddress, quote.Estimate.ApprovalAddress)
-		if err != nil {
-			l.Errorf("检查 allowance 失败: %v", err)
-			return nil, fmt.Errorf("failed to check allowance: %v", err)
-		}
-
-		amount, _ := new(big.Int).SetString(req.Amount, 10)
-		if currentAllowance.Cmp(amount) < 0 {
-			l.Infof("当前 allowance 不足，需要执行 approve")
-			maxAmount := new(big.Int)
-			maxAmount.SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
-
-			_, err := txLogic.ExecuteApproveTransaction(client, privateKey, req.FromToken, quote.Estimate.ApprovalAddress, maxAmount, chainConfig.ChainId)
-			if err != nil {
-				l.Errorf("approve 操作失败: %v", err)
-				return nil, fmt.Errorf("approve failed: %v", err)
-			}
-			l.Infof("✅ ERC20 approve 完成")
-		} else {
-			l.Infof("✅ 当前 allowance 充足，无需 approve")
-		}
-	} else {
-		l.Infof("原生代币或无需 approve，跳过 approve 步骤")
-	}
-
-	// 4. 发送主跨链交易
-	l.Infof("发送 EVM -> Solana 跨链交易...")
-	txHash, err := l.sendBridgeTransactionWithRetry(client, quote.TransactionRequest, privateKey, chainConfig.ChainId)
-	if err != nil {
-		l.Errorf("发送跨链交易失败: %v", err)
-		return nil, fmt.Errorf("failed to send bridge transaction: %v", err)
-	}
-
-	// 5. 构建响应
-	explorerUrl := l.buildBridgeExplorerUrl(req.FromChain, txHash)
-	message := fmt.Sprintf("✅ EVM -> Solana 跨链转账已提交！从链 %d 到 Solana，交易哈希: %s。请使用 /bridge/status 查询进度。", req.FromChain, txHash)
-
-	return &types.BridgeExecuteResp{
-		TxHash:      txHash,
-		Message:     message,
-		ExplorerUrl: explorerUrl,
-		FromChain:   req.FromChain,
-		ToChain:     req.ToChain,
-		Status:      "pending",
-	}, nil
-}
-
-// executeSolanaToEVMBridge Solana 到 EVM 的跨链
-func (l *BridgeLogic) executeSolanaToEVMBridge(req *types.BridgeExecuteReq, quote *types.BridgeQuoteResp) (*types.BridgeExecuteResp, error) {
-	l.Infof("=== 执行 Solana -> EVM 跨链 ===")
-
-	// 源链是 Solana，需要特殊处理
-	// 1. 使用 LI.FI 提供的 Solana 交易数据
-	// 2. 调用 Solana 钱包进行签名和发送
-	// 3. 返回 Solana 交易哈希
-
-	txHash, err := l.sendSolanaTransaction(quote.TransactionRequest.Data, req.FromAddress)
-	if err != nil {
-		l.Errorf("发送 Solana 跨链交易失败: %v", err)
-		return nil, fmt.Errorf("failed to send Solana bridge transaction: %v", err)
-	}
-
-	// 4. 构建响应
-	explorerUrl := l.buildBridgeExplorerUrl(req.FromChain, txHash)
-	message := fmt.Sprintf("✅ Solana -> EVM 跨链转账已提交！从 Solana 到链 %d，交易哈希: %s。请使用 /bridge/status 查询进度。", req.ToChain, txHash)
-
-	return &types.BridgeExecuteResp{
-		TxHash:      txHash,
-		Message:     message,
-		ExplorerUrl: explorerUrl,
-		FromChain:   req.FromChain,
-		ToChain:     req.ToChain,
-		Status:      "pending",
-	}, nil
-}
-
 // sendSolanaTransaction 发送 Solana 交易
 func (l *BridgeLogic) sendSolanaTransaction(transactionData, fromAddress string) (string, error) {
 	l.Infof("发送 Solana 跨链交易")
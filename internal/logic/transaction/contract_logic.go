@@ -0,0 +1,268 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"demo/internal/abiregistry"
+	"demo/internal/model"
+	"demo/internal/policy"
+	"demo/internal/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// contractAudit 写入审计日志的合约调用摘要
+type contractAudit struct {
+	Method   string `json:"method"`
+	Selector string `json:"selector"`
+	Data     string `json:"data"`
+	Value    string `json:"value,omitempty"`
+	TxHash   string `json:"tx_hash,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// WrapContractCall 按 ABI 编码任意合约调用：策略白名单校验 → eth_call 模拟 → 估算 Gas → 签名广播，
+// 拒绝、模拟失败和发送结果都写入审计日志
+func (l *TransactionLogic) WrapContractCall(req *types.ContractCallReq) (*types.ContractCallResp, error) {
+	l.Infof("--- 开始处理 /transaction/contract_call 请求 for address %s, chain %s, contract %s, method %s ---", req.FromAddress, req.Chain, req.Contract, req.Method)
+
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	if !chainConfig.IsEVM() {
+		return nil, fmt.Errorf("contract calls are only supported on EVM chains: %s", req.Chain)
+	}
+	for _, field := range [][2]string{{"from_address", req.FromAddress}, {"contract", req.Contract}} {
+		if !common.IsHexAddress(field[1]) {
+			return nil, fmt.Errorf("invalid %s: %s", field[0], field[1])
+		}
+	}
+	contractABI, method, err := l.resolveMethod(req.Abi, req.AbiName, req.Method)
+	if err != nil {
+		return nil, err
+	}
+	if method.IsConstant() {
+		return nil, fmt.Errorf("%s is a %s method, use /contract/read", method.Sig, method.StateMutability)
+	}
+	value := big.NewInt(0)
+	if req.Value != "" {
+		v, ok := new(big.Int).SetString(req.Value, 10)
+		if !ok || v.Sign() < 0 {
+			return nil, fmt.Errorf("invalid value: %q", req.Value)
+		}
+		value = v
+	}
+	if value.Sign() > 0 && !method.IsPayable() {
+		return nil, fmt.Errorf("%s is not payable, value must be empty", method.Sig)
+	}
+	data, err := abiregistry.PackArgs(method, req.Args)
+	if err != nil {
+		return nil, err
+	}
+	feeOpts, err := parseFeeOptions(req.FeeLevel, req.MaxFeePerGas, req.MaxPriorityFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+
+	from := common.HexToAddress(req.FromAddress)
+	contract := common.HexToAddress(req.Contract)
+
+	// 1. 策略校验：合约与方法必须在白名单中
+	preq := &policy.Request{
+		Action:    policy.ActionContractCall,
+		Chain:     chainConfig.Key,
+		Address:   req.FromAddress,
+		Contract:  contract.Hex(),
+		Method:    method.RawName,
+		Signature: method.Sig,
+		Selector:  hexutil.Encode(method.ID),
+	}
+	detail := &contractAudit{Method: method.Sig, Selector: preq.Selector, Data: hexutil.Encode(data)}
+	if value.Sign() > 0 {
+		detail.Value = value.String()
+	}
+	if err := l.svcCtx.Policy.Check(preq); err != nil {
+		l.Infof("⛔ 合约调用被拒绝: %v", err)
+		detail.Reason = err.Error()
+		l.svcCtx.Policy.Audit(l.ctx, preq, preq.Contract, model.AuditResultDenied, detail)
+		return nil, err
+	}
+
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
+	if err != nil {
+		l.Errorf("RPC 节点连接失败: %v", err)
+		return nil, errors.New("failed to connect to chain")
+	}
+
+	// 2. 模拟执行，revert 时解析原因（包括 ABI 中声明的自定义错误）
+	msg := ethereum.CallMsg{From: from, To: &contract, Value: value, Data: data}
+	ret, err := client.CallContract(l.ctx, msg, nil)
+	if err != nil {
+		reason := abiregistry.RevertReason(&contractABI, err)
+		l.Errorf("合约调用模拟失败 (%s): %s", method.Sig, reason)
+		detail.Reason = "simulation failed: " + reason
+		l.svcCtx.Policy.Audit(l.ctx, preq, preq.Contract, model.AuditResultFailure, detail)
+		return nil, fmt.Errorf("%s would revert: %s", method.Sig, reason)
+	}
+	simulated, err := abiregistry.DecodeOutputs(method, ret)
+	if err != nil {
+		l.Infof("⚠️ 模拟返回值解码失败: %v", err)
+	}
+
+	gas, err := client.EstimateGas(l.ctx, msg)
+	if err != nil {
+		reason := abiregistry.RevertReason(&contractABI, err)
+		l.Errorf("合约调用 Gas 估算失败: %s", reason)
+		return nil, fmt.Errorf("%s would fail: %s", method.Sig, reason)
+	}
+	gasLimit := gas * 120 / 100
+
+	fees, err := l.SuggestFees(chainConfig, feeOpts)
+	if err != nil {
+		return nil, err
+	}
+	l.Infof("手续费 (%s): %s, gasLimit=%d", feeOpts.Level, fees, gasLimit)
+
+	// 3. 签名并发送
+	privateKey, err := l.GetWalletPrivateKey(req.FromAddress)
+	if err != nil {
+		return nil, err
+	}
+	txHash, err := l.BuildAndSendTransaction(client, privateKey, contract, value, data, gasLimit, fees, chainConfig.ChainId)
+	if err != nil {
+		l.Errorf("合约调用发送失败: %v", err)
+		detail.Reason = err.Error()
+		l.svcCtx.Policy.Audit(l.ctx, preq, preq.Contract, model.AuditResultFailure, detail)
+		return nil, err
+	}
+
+	explorerUrl := l.BuildExplorerUrl(req.Chain, txHash)
+	recordTransaction(l.ctx, l.svcCtx, &model.Transactions{
+		Chain:       req.Chain,
+		TxHash:      txHash,
+		TxType:      model.TxTypeContractCall,
+		FromAddress: req.FromAddress,
+		ToAddress:   contract.Hex(),
+		Token:       contract.Hex(),
+		Amount:      value.String(),
+		ExplorerUrl: explorerUrl,
+	})
+	detail.TxHash = txHash
+	l.svcCtx.Policy.Audit(l.ctx, preq, preq.Contract, model.AuditResultSuccess, detail)
+
+	l.Infof("✅ 合约调用已发送 (%s → %s): %s", method.Sig, contract.Hex(), txHash)
+	return &types.ContractCallResp{
+		TxHash:           txHash,
+		Chain:            req.Chain,
+		Contract:         contract.Hex(),
+		Method:           method.Sig,
+		Data:             hexutil.Encode(data),
+		GasLimit:         gasLimit,
+		SimulatedOutputs: contractValues(simulated),
+		ExplorerUrl:      explorerUrl,
+		Status:           model.TxStatusPending,
+		Message:          fmt.Sprintf("%s submitted", method.Sig),
+	}, nil
+}
+
+// ReadContract 只读合约调用（eth_call），按 ABI 解码返回值
+func (l *TransactionLogic) ReadContract(req *types.ContractReadReq) (*types.ContractReadResp, error) {
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	if !chainConfig.IsEVM() {
+		return nil, fmt.Errorf("contract reads are only supported on EVM chains: %s", req.Chain)
+	}
+	if !common.IsHexAddress(req.Contract) {
+		return nil, fmt.Errorf("invalid contract: %s", req.Contract)
+	}
+	if req.From != "" && !common.IsHexAddress(req.From) {
+		return nil, fmt.Errorf("invalid from: %s", req.From)
+	}
+	contractABI, method, err := l.resolveMethod(req.Abi, req.AbiName, req.Method)
+	if err != nil {
+		return nil, err
+	}
+	data, err := abiregistry.PackArgs(method, req.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
+	if err != nil {
+		l.Errorf("RPC 节点连接失败: %v", err)
+		return nil, errors.New("failed to connect to chain")
+	}
+	contract := common.HexToAddress(req.Contract)
+	msg := ethereum.CallMsg{To: &contract, Data: data}
+	if req.From != "" {
+		msg.From = common.HexToAddress(req.From)
+	}
+	ret, err := client.CallContract(l.ctx, msg, nil)
+	if err != nil {
+		reason := abiregistry.RevertReason(&contractABI, err)
+		l.Errorf("合约读取失败 (%s): %s", method.Sig, reason)
+		return nil, fmt.Errorf("%s reverted: %s", method.Sig, reason)
+	}
+	if len(ret) == 0 && len(method.Outputs) > 0 {
+		return nil, fmt.Errorf("%s returned no data, is %s a contract on %s?", method.Sig, contract.Hex(), req.Chain)
+	}
+	outputs, err := abiregistry.DecodeOutputs(method, ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.ContractReadResp{
+		Chain:    req.Chain,
+		Contract: contract.Hex(),
+		Method:   method.Sig,
+		Outputs:  contractValues(outputs),
+		Raw:      hexutil.Encode(ret),
+	}, nil
+}
+
+// resolveMethod 从请求中的 abi（JSON）或 abi_name（已登记的 ABI）找到要调用的方法
+func (l *TransactionLogic) resolveMethod(rawABI, abiName, method string) (abi.ABI, abi.Method, error) {
+	var contractABI abi.ABI
+	switch {
+	case rawABI != "" && abiName != "":
+		return abi.ABI{}, abi.Method{}, errors.New("abi and abi_name are mutually exclusive")
+	case rawABI != "":
+		parsed, err := abiregistry.Parse(rawABI)
+		if err != nil {
+			return abi.ABI{}, abi.Method{}, err
+		}
+		contractABI = parsed
+	case abiName != "":
+		registered, ok := l.svcCtx.Abis.Get(abiName)
+		if !ok {
+			return abi.ABI{}, abi.Method{}, fmt.Errorf("unknown abi_name: %s", abiName)
+		}
+		contractABI = registered
+	default:
+		return abi.ABI{}, abi.Method{}, errors.New("abi or abi_name is required")
+	}
+	if method == "" {
+		return abi.ABI{}, abi.Method{}, errors.New("method is required")
+	}
+	m, err := abiregistry.FindMethod(contractABI, method)
+	if err != nil {
+		return abi.ABI{}, abi.Method{}, err
+	}
+	return contractABI, m, nil
+}
+
+func contractValues(values []abiregistry.Value) []types.ContractValue {
+	out := make([]types.ContractValue, len(values))
+	for i, v := range values {
+		out[i] = types.ContractValue{Name: v.Name, Type: v.Type, Value: v.Value}
+	}
+	return out
+}
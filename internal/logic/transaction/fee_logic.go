@@ -0,0 +1,128 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"demo/internal/chains"
+	"demo/internal/gasoracle"
+	"demo/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	evmTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// 手续费档位，slow/normal/fast 由手续费预言机按行情计算
+const (
+	FeeLevelSlow   = gasoracle.LevelSlow
+	FeeLevelNormal = gasoracle.LevelNormal
+	FeeLevelFast   = gasoracle.LevelFast
+	FeeLevelCustom = "custom"
+)
+
+// FeeOptions 请求指定的手续费策略，零值等同于 normal
+type FeeOptions struct {
+	Level                string
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// ParseFeeOptions 解析请求中的手续费参数
+func ParseFeeOptions(req *types.TransactionReq) (FeeOptions, error) {
+	return parseFeeOptions(req.FeeLevel, req.MaxFeePerGas, req.MaxPriorityFeePerGas)
+}
+
+func parseFeeOptions(level, maxFeePerGas, maxPriorityFeePerGas string) (FeeOptions, error) {
+	opts := FeeOptions{Level: strings.ToLower(level)}
+	switch opts.Level {
+	case "":
+		opts.Level = FeeLevelNormal
+	case FeeLevelSlow, FeeLevelNormal, FeeLevelFast:
+	case FeeLevelCustom:
+		maxFee, ok := new(big.Int).SetString(maxFeePerGas, 10)
+		if !ok || maxFee.Sign() <= 0 {
+			return opts, fmt.Errorf("invalid max_fee_per_gas: %q", maxFeePerGas)
+		}
+		tip, ok := new(big.Int).SetString(maxPriorityFeePerGas, 10)
+		if !ok || tip.Sign() < 0 {
+			return opts, fmt.Errorf("invalid max_priority_fee_per_gas: %q", maxPriorityFeePerGas)
+		}
+		if tip.Cmp(maxFee) > 0 {
+			return opts, errors.New("max_priority_fee_per_gas must not exceed max_fee_per_gas")
+		}
+		opts.MaxFeePerGas, opts.MaxPriorityFeePerGas = maxFee, tip
+	default:
+		return opts, fmt.Errorf("invalid fee_level: %s (slow|normal|fast|custom)", level)
+	}
+	return opts, nil
+}
+
+// SuggestFees 按档位从手续费预言机取缓存的手续费（链配置 Legacy 或没有 baseFee 时为 legacy gasPrice），
+// 并应用链的手续费上限；custom 档位超过上限直接拒绝
+func (l *TransactionLogic) SuggestFees(chainConfig *chains.Chain, opts FeeOptions) (*gasoracle.Fees, error) {
+	if opts.Level == "" {
+		opts.Level = FeeLevelNormal
+	}
+
+	oracle, err := l.svcCtx.Gas.Oracle(chainConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Level != FeeLevelCustom {
+		return oracle.Suggest(l.ctx, opts.Level)
+	}
+
+	snap, err := oracle.Snapshot(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+	fees := &gasoracle.Fees{Dynamic: true, GasFeeCap: opts.MaxFeePerGas, GasTipCap: opts.MaxPriorityFeePerGas}
+	if !snap.Dynamic {
+		fees = &gasoracle.Fees{GasPrice: opts.MaxFeePerGas}
+	}
+	if err := oracle.CheckCap(fees); err != nil {
+		return nil, err
+	}
+	return fees, nil
+}
+
+// withinFeeCap LI.FI 报价中的 gas price 未超过链的手续费上限时返回 true
+func (l *TransactionLogic) withinFeeCap(chainConfig *chains.Chain, gasPrice *big.Int) bool {
+	oracle, err := l.svcCtx.Gas.Oracle(chainConfig)
+	if err != nil {
+		return false
+	}
+	return oracle.MaxFee() == nil || gasPrice.Cmp(oracle.MaxFee()) <= 0
+}
+
+// NewEVMTx 按手续费类型构建 DynamicFeeTx 或 LegacyTx
+func NewEVMTx(chainId int64, nonce uint64, to *common.Address, value *big.Int, gasLimit uint64, data []byte, fees *gasoracle.Fees) *evmTypes.Transaction {
+	if fees.Dynamic {
+		return evmTypes.NewTx(&evmTypes.DynamicFeeTx{
+			ChainID:   big.NewInt(chainId),
+			Nonce:     nonce,
+			To:        to,
+			Value:     value,
+			Gas:       gasLimit,
+			GasTipCap: fees.GasTipCap,
+			GasFeeCap: fees.GasFeeCap,
+			Data:      data,
+		})
+	}
+	return evmTypes.NewTx(&evmTypes.LegacyTx{
+		Nonce:    nonce,
+		To:       to,
+		Value:    value,
+		Gas:      gasLimit,
+		GasPrice: fees.GasPrice,
+		Data:     data,
+	})
+}
+
+// EVMSigner 返回链支持的最新签名器，同时兼容 legacy 和 EIP-1559 交易
+func EVMSigner(chainId int64) evmTypes.Signer {
+	return evmTypes.LatestSignerForChainID(big.NewInt(chainId))
+}
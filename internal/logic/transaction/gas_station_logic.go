@@ -0,0 +1,208 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"demo/internal/chains"
+	"demo/internal/gasoracle"
+	"demo/internal/model"
+	"demo/internal/policy"
+	"demo/internal/tokens"
+	"demo/internal/types"
+	"demo/internal/units"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrNoGasStation 钱包原生币不足以支付手续费，且该链未配置 GasStation
+var ErrNoGasStation = errors.New("not enough native currency for gas and the chain has no gas station")
+
+// gasStationLocks 同一钱包的补充串行执行，避免并发请求重复补充；同一 gas 钱包的发送串行执行，避免 nonce 冲突
+var gasStationLocks sync.Map
+
+func lockGasStation(key string) func() {
+	mu, _ := gasStationLocks.LoadOrStore(key, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// EnsureGas 钱包原生币少于 need 时，由链的 gas 站补足差额（另加 MarginPercent 余量）并等待确认。
+// 余额充足或钱包就是 gas 钱包时返回 nil；未配置 gas 站时返回 ErrNoGasStation
+func (l *TransactionLogic) EnsureGas(chain *chains.Chain, wallet string, need *big.Int, purpose string) (*model.GasTopUps, error) {
+	station := chain.GasStation
+	walletAddr := common.HexToAddress(wallet)
+	funder := common.HexToAddress(station.Wallet)
+	if station.Wallet != "" && walletAddr == funder {
+		return nil, nil
+	}
+
+	defer lockGasStation("wallet:" + chain.Key + ":" + walletAddr.Hex())()
+
+	client, err := l.svcCtx.RPC.EthClient(chain)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := client.PendingBalanceAt(l.ctx, walletAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %v", err)
+	}
+	if balance.Cmp(need) >= 0 {
+		return nil, nil
+	}
+	if !chain.IsEVM() || station.Wallet == "" {
+		return nil, fmt.Errorf("%w: balance %s, need %s", ErrNoGasStation, balance.String(), need.String())
+	}
+
+	amount := new(big.Int).Mul(need, big.NewInt(100+station.MarginPercent))
+	amount.Div(amount, big.NewInt(100))
+	amount.Sub(amount, balance)
+	if err := l.checkGasDailyLimit(chain, walletAddr, amount); err != nil {
+		return nil, err
+	}
+
+	l.Infof("⛽ gas 站为 %s 补充 %s %s（需要 %s，现有 %s，用途 %s）", walletAddr.Hex(),
+		units.FormatUnits(amount, chain.NativeDecimals), chain.NativeSymbol, need.String(), balance.String(), purpose)
+	txHash, err := l.sendGasTopUp(chain, funder, walletAddr, amount, purpose)
+	if err != nil {
+		return nil, fmt.Errorf("gas station top-up failed: %w", err)
+	}
+
+	topUp := &model.GasTopUps{
+		Chain:     chain.Key,
+		Wallet:    walletAddr.Hex(),
+		Funder:    funder.Hex(),
+		Amount:    amount.String(),
+		Fee:       "0",
+		Purpose:   purpose,
+		TxHash:    txHash,
+		Status:    model.GasTopUpStatusPending,
+		CreatedAt: time.Now(),
+	}
+	// 补充已经广播，即使请求被取消也要记账
+	dbCtx := context.WithoutCancel(l.ctx)
+	if err := l.svcCtx.GasTopUpsDao.Insert(dbCtx, topUp); err != nil {
+		l.Errorf("保存 gas 补充记录失败: %v", err)
+	}
+
+	timeout := time.Duration(station.ConfirmTimeout) * time.Second
+	receipt, err := l.WaitForTransactionReceipt(client, common.HexToHash(txHash), timeout)
+	if err != nil {
+		// 超时的补充仍可能上链，保持 pending 并计入每日限额
+		return topUp, fmt.Errorf("gas top-up %s not confirmed within %s: %w", txHash, timeout, err)
+	}
+
+	now := time.Now()
+	topUp.ConfirmedAt = &now
+	topUp.Status = model.GasTopUpStatusConfirmed
+	if receipt.EffectiveGasPrice != nil {
+		topUp.Fee = new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice).String()
+	}
+	if receipt.Status == 0 {
+		topUp.Status = model.GasTopUpStatusFailed
+		topUp.ErrorMessage = "transaction reverted"
+	}
+	if err := l.svcCtx.GasTopUpsDao.Finish(dbCtx, topUp); err != nil {
+		l.Errorf("更新 gas 补充记录失败: %v", err)
+	}
+	if topUp.Status == model.GasTopUpStatusFailed {
+		return topUp, fmt.Errorf("gas top-up %s failed on chain", txHash)
+	}
+	l.Infof("✅ gas 补充已确认: %s", txHash)
+	return topUp, nil
+}
+
+// ensureTxGas 为一笔不转出原生币的 EVM 交易确保发送方有 gasLimit × 最高单价的原生币；未配置 gas 站时交由节点按余额不足拒绝
+func (l *TransactionLogic) ensureTxGas(chain *chains.Chain, from common.Address, gasLimit uint64, fees *gasoracle.Fees, purpose string) error {
+	if chain.GasStation.Wallet == "" {
+		return nil
+	}
+	need := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), fees.MaxGasPrice())
+	_, err := l.EnsureGas(chain, from.Hex(), need, purpose)
+	return err
+}
+
+// checkGasDailyLimit 当天（UTC）已补充给该钱包的金额加上本次不能超过 DailyLimit，失败的补充不计入
+func (l *TransactionLogic) checkGasDailyLimit(chain *chains.Chain, wallet common.Address, amount *big.Int) error {
+	if chain.GasStation.DailyLimit == "" {
+		return nil
+	}
+	limit, err := units.ParseUnits(chain.GasStation.DailyLimit, chain.NativeDecimals)
+	if err != nil {
+		return fmt.Errorf("invalid GasStation.DailyLimit of %s: %w", chain.Key, err)
+	}
+
+	since := time.Now().UTC().Truncate(24 * time.Hour)
+	rows, err := l.svcCtx.GasTopUpsDao.FindSince(l.ctx, chain.Key, wallet.Hex(), since)
+	if err != nil {
+		return err
+	}
+	used := new(big.Int)
+	for _, row := range rows {
+		if row.Status == model.GasTopUpStatusFailed {
+			continue
+		}
+		if v, ok := new(big.Int).SetString(row.Amount, 10); ok {
+			used.Add(used, v)
+		}
+	}
+	if new(big.Int).Add(used, amount).Cmp(limit) > 0 {
+		return fmt.Errorf("gas station daily limit reached for %s: %s %s sent today, %s more needed, limit %s",
+			wallet.Hex(), units.FormatUnits(used, chain.NativeDecimals), chain.NativeSymbol,
+			units.FormatUnits(amount, chain.NativeDecimals), chain.GasStation.DailyLimit)
+	}
+	return nil
+}
+
+// gasTopUpAudit gas 补充的审计详情
+type gasTopUpAudit struct {
+	Amount  string `json:"amount"` // 最小单位
+	Purpose string `json:"purpose"`
+	TxHash  string `json:"tx_hash,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// sendGasTopUp 按 Policy.GasTopUp 校验单次补充金额后从 gas 钱包发送原生币，走普通转账流程（写入 transactions 表），
+// 结果写入审计日志。同步广播，节点接受后才释放 gas 钱包的锁，下一笔补充读取的 pending nonce 才不会重复
+func (l *TransactionLogic) sendGasTopUp(chain *chains.Chain, funder, wallet common.Address, amount *big.Int, purpose string) (string, error) {
+	preq := &policy.Request{
+		Action:    policy.ActionGasTopUp,
+		Chain:     chain.Key,
+		Address:   funder.Hex(),
+		Recipient: wallet.Hex(),
+		Token:     chain.NativeSymbol,
+		Amount:    amount,
+		Decimals:  chain.NativeDecimals,
+	}
+	detail := &gasTopUpAudit{Amount: amount.String(), Purpose: purpose}
+	// 补充一旦广播就要记账，审计不受请求取消影响
+	auditCtx := context.WithoutCancel(l.ctx)
+	if err := l.svcCtx.Policy.Check(preq); err != nil {
+		l.Infof("⛔ gas 补充被拒绝: %v", err)
+		detail.Reason = err.Error()
+		l.svcCtx.Policy.Audit(auditCtx, preq, wallet.Hex(), model.AuditResultDenied, detail)
+		return "", err
+	}
+
+	defer lockGasStation("funder:" + chain.Key + ":" + funder.Hex())()
+	resp, err := l.WithSyncBroadcast().WrapSend(&types.TransactionReq{
+		FromAddress: funder.Hex(),
+		ToAddress:   wallet.Hex(),
+		Chain:       chain.Key,
+		FromToken:   tokens.NativeEVM,
+		ToToken:     tokens.NativeEVM,
+		Amount:      amount.String(),
+	})
+	if err != nil {
+		detail.Reason = err.Error()
+		l.svcCtx.Policy.Audit(auditCtx, preq, wallet.Hex(), model.AuditResultFailure, detail)
+		return "", err
+	}
+	detail.TxHash = resp.TxHash
+	l.svcCtx.Policy.Audit(auditCtx, preq, wallet.Hex(), model.AuditResultSuccess, detail)
+	return resp.TxHash, nil
+}
@@ -0,0 +1,72 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"demo/internal/model"
+	"demo/internal/svc"
+	"demo/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// recordTransaction 将已广播的交易写入 transactions 表。
+// 记录失败只打日志，不影响已经上链的交易结果。
+func recordTransaction(ctx context.Context, svcCtx *svc.ServiceContext, tx *model.Transactions) {
+	if tx.TxHash == "" {
+		return
+	}
+	now := time.Now()
+	tx.CreatedAt = now
+	tx.UpdatedAt = now
+	if tx.Status == "" {
+		tx.Status = model.TxStatusPending
+	}
+
+	// 使用独立的 context，避免请求超时导致记录丢失
+	err := svcCtx.TransactionsDao.Insert(context.WithoutCancel(ctx), tx)
+	if err != nil && !errors.Is(err, model.ErrDuplicateKey) {
+		logx.WithContext(ctx).Errorf("⚠️ 交易记录写入失败 chain=%s hash=%s: %v", tx.Chain, tx.TxHash, err)
+	}
+}
+
+// GetTransactionHistory 分页查询地址相关的交易记录
+func (l *TransactionLogic) GetTransactionHistory(req *types.TransactionHistoryReq) (*types.TransactionHistoryResp, error) {
+	l.Infof("查询交易记录: address=%s, chain=%s, type=%s, page=%d", req.Address, req.Chain, req.TxType, req.Page)
+
+	filter := model.TransactionFilter{
+		Address: req.Address,
+		Chain:   req.Chain,
+		TxType:  req.TxType,
+	}
+	txs, total, err := l.svcCtx.TransactionsDao.FindPage(l.ctx, filter, req.Page, req.PageSize)
+	if err != nil {
+		l.Errorf("查询交易记录失败: %v", err)
+		return nil, errors.New("failed to query transaction history")
+	}
+
+	items := make([]types.TransactionRecord, 0, len(txs))
+	for _, tx := range txs {
+		items = append(items, types.TransactionRecord{
+			Id:           tx.Id,
+			Chain:        tx.Chain,
+			TxHash:       tx.TxHash,
+			TxType:       tx.TxType,
+			FromAddress:  tx.FromAddress,
+			ToAddress:    tx.ToAddress,
+			Token:        tx.Token,
+			Amount:       tx.Amount,
+			Status:       tx.Status,
+			ExplorerUrl:  tx.ExplorerUrl,
+			ErrorMessage: tx.ErrorMessage,
+			CreatedAt:    tx.CreatedAt.Unix(),
+		})
+	}
+
+	return &types.TransactionHistoryResp{
+		Total: total,
+		Items: items,
+	}, nil
+}
@@ -0,0 +1,308 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"demo/internal/chains"
+	"demo/internal/model"
+	"demo/internal/multicall"
+	"demo/internal/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	evmTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	NftStandardERC721  = "erc721"
+	NftStandardERC1155 = "erc1155"
+
+	// nftMaxBatch safeBatchTransferFrom 单笔最多的 token id 数
+	nftMaxBatch = 100
+)
+
+// ERC-721 与 ERC-1155 的 safeTransferFrom 签名不同，分开解析避免重载改名
+var (
+	erc721ABI = mustParseABI(`[
+		{"name":"safeTransferFrom","type":"function","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"},{"name":"data","type":"bytes"}],"outputs":[]}
+	]`)
+	erc1155ABI = mustParseABI(`[
+		{"name":"safeTransferFrom","type":"function","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"id","type":"uint256"},{"name":"amount","type":"uint256"},{"name":"data","type":"bytes"}],"outputs":[]},
+		{"name":"safeBatchTransferFrom","type":"function","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"ids","type":"uint256[]"},{"name":"amounts","type":"uint256[]"},{"name":"data","type":"bytes"}],"outputs":[]}
+	]`)
+)
+
+// WrapNftTransfer ERC-721 safeTransferFrom / ERC-1155 safeTransferFrom、safeBatchTransferFrom。
+// 发送前通过 Multicall3 识别合约标准并检查持有情况
+func (l *TransactionLogic) WrapNftTransfer(req *types.NftTransferReq) (*types.NftTransferResp, error) {
+	l.Infof("--- 开始处理 /nft/transfer 请求 for address %s, chain %s, contract %s ---", req.FromAddress, req.Chain, req.Contract)
+
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	if !chainConfig.IsEVM() {
+		return nil, fmt.Errorf("nft transfers are only supported on EVM chains: %s", req.Chain)
+	}
+	for _, field := range [][2]string{{"from_address", req.FromAddress}, {"to_address", req.ToAddress}, {"contract", req.Contract}} {
+		if !common.IsHexAddress(field[1]) {
+			return nil, fmt.Errorf("invalid %s: %s", field[0], field[1])
+		}
+	}
+	ids, amounts, err := parseNftItems(req.TokenIds, req.Amounts)
+	if err != nil {
+		return nil, err
+	}
+	var payload []byte
+	if req.Data != "" {
+		if payload, err = hexutil.Decode(req.Data); err != nil {
+			return nil, fmt.Errorf("invalid data: %v", err)
+		}
+	}
+	feeOpts, err := parseFeeOptions(req.FeeLevel, req.MaxFeePerGas, req.MaxPriorityFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+
+	from := common.HexToAddress(req.FromAddress)
+	to := common.HexToAddress(req.ToAddress)
+	contract := common.HexToAddress(req.Contract)
+
+	// 1. 识别标准并检查持有情况
+	standard, err := l.detectNftStandard(chainConfig, contract, strings.ToLower(req.Standard))
+	if err != nil {
+		return nil, err
+	}
+	if standard == NftStandardERC721 && len(ids) != 1 {
+		return nil, errors.New("erc721 transfers exactly one token_id per request")
+	}
+	if standard == NftStandardERC721 {
+		amounts = []*big.Int{big.NewInt(1)}
+	}
+	if err := l.checkNftOwnership(chainConfig, standard, contract, from, ids, amounts); err != nil {
+		return nil, err
+	}
+
+	// 2. 构建调用数据
+	var data []byte
+	method := "safeTransferFrom"
+	switch {
+	case standard == NftStandardERC721:
+		data, err = erc721ABI.Pack("safeTransferFrom", from, to, ids[0], payload)
+	case len(ids) == 1:
+		data, err = erc1155ABI.Pack("safeTransferFrom", from, to, ids[0], amounts[0], payload)
+	default:
+		method = "safeBatchTransferFrom"
+		data, err = erc1155ABI.Pack("safeBatchTransferFrom", from, to, ids, amounts, payload)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s data: %v", method, err)
+	}
+
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
+	if err != nil {
+		l.Errorf("RPC 节点连接失败: %v", err)
+		return nil, errors.New("failed to connect to chain")
+	}
+	// 估算失败通常意味着接收方不能接收 NFT 或缺少授权，直接返回错误
+	gas, err := client.EstimateGas(l.ctx, ethereum.CallMsg{From: from, To: &contract, Data: data})
+	if err != nil {
+		l.Errorf("NFT 转账 Gas 估算失败: %v", err)
+		return nil, fmt.Errorf("%s would fail: %v", method, err)
+	}
+	gasLimit := gas * 120 / 100
+
+	fees, err := l.SuggestFees(chainConfig, feeOpts)
+	if err != nil {
+		return nil, err
+	}
+	l.Infof("手续费 (%s): %s, gasLimit=%d", feeOpts.Level, fees, gasLimit)
+
+	// 3. 签名并发送
+	privateKey, err := l.GetWalletPrivateKey(req.FromAddress)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := client.PendingNonceAt(l.ctx, from)
+	if err != nil {
+		l.Errorf("获取 nonce 失败: %v", err)
+		return nil, errors.New("failed to get nonce")
+	}
+	signed, err := evmTypes.SignTx(NewEVMTx(chainConfig.ChainId, nonce, &contract, big.NewInt(0), gasLimit, data, fees), EVMSigner(chainConfig.ChainId), privateKey)
+	if err != nil {
+		l.Errorf("交易签名失败: %v", err)
+		return nil, errors.New("failed to sign transaction")
+	}
+	if err := client.SendTransaction(l.ctx, signed); err != nil {
+		l.Errorf("NFT 转账发送失败: %v", err)
+		return nil, fmt.Errorf("failed to send transaction: %v", err)
+	}
+
+	txHash := signed.Hash().Hex()
+	explorerUrl := l.BuildExplorerUrl(req.Chain, txHash)
+	total := new(big.Int)
+	for _, a := range amounts {
+		total.Add(total, a)
+	}
+	recordTransaction(l.ctx, l.svcCtx, &model.Transactions{
+		Chain:       req.Chain,
+		TxHash:      txHash,
+		TxType:      model.TxTypeNftTransfer,
+		FromAddress: req.FromAddress,
+		ToAddress:   req.ToAddress,
+		Token:       contract.Hex(),
+		Amount:      total.String(),
+		ExplorerUrl: explorerUrl,
+	})
+
+	l.Infof("✅ NFT 转账已发送 (%s %s, %d 个 token id): %s", standard, method, len(ids), txHash)
+	return &types.NftTransferResp{
+		TxHash:      txHash,
+		Chain:       req.Chain,
+		Standard:    standard,
+		Method:      method,
+		ExplorerUrl: explorerUrl,
+		Status:      model.TxStatusPending,
+		Message:     fmt.Sprintf("%s %s submitted", standard, method),
+	}, nil
+}
+
+// parseNftItems 解析 token id（十进制或 0x 十六进制）和数量，数量为空时各 1 个
+func parseNftItems(tokenIds, amountStrs []string) ([]*big.Int, []*big.Int, error) {
+	if len(tokenIds) == 0 {
+		return nil, nil, errors.New("token_ids must not be empty")
+	}
+	if len(tokenIds) > nftMaxBatch {
+		return nil, nil, fmt.Errorf("too many token_ids: %d (max %d)", len(tokenIds), nftMaxBatch)
+	}
+	if len(amountStrs) > 0 && len(amountStrs) != len(tokenIds) {
+		return nil, nil, errors.New("amounts must have the same length as token_ids")
+	}
+
+	ids := make([]*big.Int, len(tokenIds))
+	amounts := make([]*big.Int, len(tokenIds))
+	for i, s := range tokenIds {
+		id, ok := parseTokenId(s)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid token_id: %q", s)
+		}
+		ids[i] = id
+		amounts[i] = big.NewInt(1)
+		if len(amountStrs) > 0 {
+			a, ok := new(big.Int).SetString(amountStrs[i], 10)
+			if !ok || a.Sign() <= 0 {
+				return nil, nil, fmt.Errorf("invalid amount for token_id %s: %q", s, amountStrs[i])
+			}
+			amounts[i] = a
+		}
+	}
+	return ids, amounts, nil
+}
+
+func parseTokenId(s string) (*big.Int, bool) {
+	var id *big.Int
+	var ok bool
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		id, ok = new(big.Int).SetString(s[2:], 16)
+	} else {
+		id, ok = new(big.Int).SetString(s, 10)
+	}
+	if !ok || id.Sign() < 0 || id.BitLen() > 256 {
+		return nil, false
+	}
+	return id, true
+}
+
+// detectNftStandard 未指定标准时通过 ERC-165 识别
+func (l *TransactionLogic) detectNftStandard(chainConfig *chains.Chain, contract common.Address, standard string) (string, error) {
+	switch standard {
+	case NftStandardERC721, NftStandardERC1155:
+		return standard, nil
+	case "":
+	default:
+		return "", fmt.Errorf("invalid standard: %s (erc721 | erc1155)", standard)
+	}
+
+	caller, err := l.svcCtx.Multicall.Caller(chainConfig)
+	if err != nil {
+		return "", err
+	}
+	results, err := caller.Aggregate(l.ctx, []multicall.Call{
+		multicall.SupportsInterface(contract, multicall.InterfaceERC721),
+		multicall.SupportsInterface(contract, multicall.InterfaceERC1155),
+	})
+	if err != nil {
+		l.Errorf("识别 NFT 标准失败: %v", err)
+		return "", errors.New("failed to detect nft standard")
+	}
+	if ok, _ := results[0].Bool(); ok {
+		return NftStandardERC721, nil
+	}
+	if ok, _ := results[1].Bool(); ok {
+		return NftStandardERC1155, nil
+	}
+	return "", fmt.Errorf("contract %s does not report ERC-721 or ERC-1155 support, pass standard explicitly", contract.Hex())
+}
+
+// checkNftOwnership ERC-721 检查 ownerOf，ERC-1155 检查每个 id 的余额（重复的 id 合并计算）
+func (l *TransactionLogic) checkNftOwnership(chainConfig *chains.Chain, standard string, contract, owner common.Address, ids, amounts []*big.Int) error {
+	caller, err := l.svcCtx.Multicall.Caller(chainConfig)
+	if err != nil {
+		return err
+	}
+
+	if standard == NftStandardERC721 {
+		results, err := caller.Aggregate(l.ctx, []multicall.Call{multicall.OwnerOf(contract, ids[0])})
+		if err != nil {
+			l.Errorf("查询 NFT 持有人失败: %v", err)
+			return errors.New("failed to query nft owner")
+		}
+		holder, ok := results[0].Address()
+		if !ok {
+			return fmt.Errorf("token_id %s does not exist", ids[0])
+		}
+		if holder != owner {
+			return fmt.Errorf("token_id %s is owned by %s, not from_address", ids[0], holder.Hex())
+		}
+		return nil
+	}
+
+	needed := make(map[string]*big.Int)
+	var order []*big.Int
+	for i, id := range ids {
+		key := id.String()
+		if needed[key] == nil {
+			needed[key] = new(big.Int)
+			order = append(order, id)
+		}
+		needed[key].Add(needed[key], amounts[i])
+	}
+	calls := make([]multicall.Call, len(order))
+	for i, id := range order {
+		calls[i] = multicall.BalanceOf1155(contract, owner, id)
+	}
+	results, err := caller.Aggregate(l.ctx, calls)
+	if err != nil {
+		l.Errorf("查询 NFT 余额失败: %v", err)
+		return errors.New("failed to query nft balances")
+	}
+
+	var shortfalls []string
+	for i, id := range order {
+		balance, ok := results[i].Uint()
+		if !ok {
+			return fmt.Errorf("failed to query balance of token_id %s", id)
+		}
+		if balance.Cmp(needed[id.String()]) < 0 {
+			shortfalls = append(shortfalls, fmt.Sprintf("%s: need %s, have %s", id, needed[id.String()], balance))
+		}
+	}
+	if len(shortfalls) > 0 {
+		return fmt.Errorf("insufficient nft balance: %s", strings.Join(shortfalls, "; "))
+	}
+	return nil
+}
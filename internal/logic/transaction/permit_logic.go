@@ -0,0 +1,231 @@
+package transaction
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strconv"
+	"time"
+
+	"demo/internal/chains"
+	"demo/internal/multicall"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	PermitModeEIP2612 = "eip2612"
+	PermitModePermit2 = "permit2"
+
+	// permitValidity 签名授权的有效期，只需覆盖交易上链的时间，过期后签名作废
+	permitValidity = 30 * time.Minute
+)
+
+// EIP-712 类型哈希
+var (
+	eip2612PermitTypeHash      = crypto.Keccak256([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+	tokenPermissionsTypeHash   = crypto.Keccak256([]byte("TokenPermissions(address token,uint256 amount)"))
+	permitTransferFromTypeHash = crypto.Keccak256([]byte(
+		"PermitTransferFrom(TokenPermissions permitted,address spender,uint256 nonce,uint256 deadline)TokenPermissions(address token,uint256 amount)"))
+)
+
+// LI.FI Permit2Proxy 的只读方法
+var (
+	lifiDiamondSelector = []byte{0x02, 0x0a, 0x1f, 0x7d} // LIFI_DIAMOND()
+	permit2Selector     = []byte{0x6a, 0xfd, 0xd8, 0x50} // PERMIT2()
+	nextNonceSelector   = []byte{0x0c, 0xd5, 0x5a, 0xbf} // nextNonce(address)
+)
+
+var permit2ProxyABI = mustParseABI(`[
+	{"name":"callDiamondWithEIP2612Signature","type":"function","stateMutability":"payable",
+	 "inputs":[{"name":"tokenAddress","type":"address"},{"name":"amount","type":"uint256"},{"name":"deadline","type":"uint256"},
+		{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"},{"name":"diamondCalldata","type":"bytes"}],
+	 "outputs":[{"name":"","type":"bytes"}]},
+	{"name":"callDiamondWithPermit2","type":"function","stateMutability":"payable",
+	 "inputs":[{"name":"diamondCalldata","type":"bytes"},
+		{"name":"permit","type":"tuple","components":[
+			{"name":"permitted","type":"tuple","components":[{"name":"token","type":"address"},{"name":"amount","type":"uint256"}]},
+			{"name":"nonce","type":"uint256"},{"name":"deadline","type":"uint256"}]},
+		{"name":"signature","type":"bytes"}],
+	 "outputs":[{"name":"","type":"bytes"}]}
+]`)
+
+type tokenPermissions struct {
+	Token  common.Address
+	Amount *big.Int
+}
+
+type permitTransferFrom struct {
+	Permitted tokenPermissions
+	Nonce     *big.Int
+	Deadline  *big.Int
+}
+
+// PermitTx 携带签名授权的 LI.FI 交易，发往 Permit2Proxy，由代理完成转账后调用 Diamond
+type PermitTx struct {
+	Mode     string
+	To       string
+	Data     string
+	GasLimit string
+}
+
+// PermitLiFiCall 用有时限的离线签名代替 approve 交易。
+// 仅当链配置了 Permit2Proxy 且 LI.FI 交易目标是代理绑定的 Diamond 时可用：
+// 代币支持 EIP-2612 时签 Permit，否则在用户已授权 Permit2 时签 PermitTransferFrom。
+// 返回 nil 表示不适用，调用方回退到 approve 交易
+func (l *TransactionLogic) PermitLiFiCall(client *ethclient.Client, privateKey *ecdsa.PrivateKey, chainConfig *chains.Chain,
+	token string, amount *big.Int, target, calldata, value string) *PermitTx {
+	if chainConfig.Permit2Proxy == "" || amount == nil || amount.Sign() <= 0 {
+		return nil
+	}
+
+	proxy := common.HexToAddress(chainConfig.Permit2Proxy)
+	owner := crypto.PubkeyToAddress(privateKey.PublicKey)
+	tokenAddr := common.HexToAddress(token)
+
+	caller, err := l.svcCtx.Multicall.Caller(chainConfig)
+	if err != nil {
+		l.Infof("⚠️  签名授权不可用: %v", err)
+		return nil
+	}
+	results, err := caller.Aggregate(l.ctx, []multicall.Call{
+		{Target: proxy, Data: lifiDiamondSelector},
+		{Target: proxy, Data: permit2Selector},
+		multicall.DomainSeparator(tokenAddr),
+		multicall.Nonces(tokenAddr, owner),
+		{Target: proxy, Data: append(append([]byte{}, nextNonceSelector...), common.LeftPadBytes(owner.Bytes(), 32)...)},
+	})
+	if err != nil {
+		l.Infof("⚠️  读取签名授权信息失败: %v", err)
+		return nil
+	}
+
+	diamond, ok := results[0].Address()
+	if !ok || diamond != common.HexToAddress(target) {
+		l.Infof("交易目标 %s 不是 Permit2Proxy 绑定的 LI.FI Diamond，使用 approve", target)
+		return nil
+	}
+
+	txValue := new(big.Int)
+	if value != "" {
+		if _, ok := txValue.SetString(value, 10); !ok {
+			txValue.SetString(value, 0)
+		}
+	}
+	diamondCalldata := common.FromHex(calldata)
+	deadline := big.NewInt(time.Now().Add(permitValidity).Unix())
+
+	// EIP-2612：代币自身支持 permit，授权给 Permit2Proxy
+	if results[2].Success && len(results[2].Data) == 32 {
+		if nonce, ok := results[3].Uint(); ok {
+			data, err := l.packEIP2612Call(privateKey, tokenAddr, proxy, amount, nonce, deadline, common.BytesToHash(results[2].Data), diamondCalldata)
+			if err == nil {
+				if permitTx := l.estimatePermitTx(client, owner, proxy, txValue, data, PermitModeEIP2612); permitTx != nil {
+					return permitTx
+				}
+			} else {
+				l.Infof("⚠️  EIP-2612 签名失败: %v", err)
+			}
+		}
+	}
+
+	// Permit2：用户已授权 Permit2 合约时签一次性转账许可
+	permit2, ok := results[1].Address()
+	nonce, nonceOk := results[4].Uint()
+	if !ok || !nonceOk {
+		return nil
+	}
+	results, err = caller.Aggregate(l.ctx, []multicall.Call{
+		multicall.DomainSeparator(permit2),
+		multicall.Allowance(tokenAddr, owner, permit2),
+	})
+	if err != nil || !results[0].Success || len(results[0].Data) != 32 {
+		return nil
+	}
+	if allowance, ok := results[1].Uint(); !ok || allowance.Cmp(amount) < 0 {
+		l.Infof("代币不支持 EIP-2612 且未授权 Permit2，使用 approve")
+		return nil
+	}
+	data, err := l.packPermit2Call(privateKey, tokenAddr, proxy, amount, nonce, deadline, common.BytesToHash(results[0].Data), diamondCalldata)
+	if err != nil {
+		l.Infof("⚠️  Permit2 签名失败: %v", err)
+		return nil
+	}
+	return l.estimatePermitTx(client, owner, proxy, txValue, data, PermitModePermit2)
+}
+
+// packEIP2612Call 签名 Permit(owner, spender, value, nonce, deadline) 并编码 callDiamondWithEIP2612Signature
+func (l *TransactionLogic) packEIP2612Call(privateKey *ecdsa.PrivateKey, token, spender common.Address, amount, nonce, deadline *big.Int,
+	domain common.Hash, diamondCalldata []byte) ([]byte, error) {
+	owner := crypto.PubkeyToAddress(privateKey.PublicKey)
+	structHash := crypto.Keccak256(
+		eip2612PermitTypeHash,
+		common.LeftPadBytes(owner.Bytes(), 32),
+		common.LeftPadBytes(spender.Bytes(), 32),
+		common.LeftPadBytes(amount.Bytes(), 32),
+		common.LeftPadBytes(nonce.Bytes(), 32),
+		common.LeftPadBytes(deadline.Bytes(), 32),
+	)
+	sig, err := signTypedDataHash(privateKey, domain, structHash)
+	if err != nil {
+		return nil, err
+	}
+	return permit2ProxyABI.Pack("callDiamondWithEIP2612Signature", token, amount, deadline,
+		sig[64], common.BytesToHash(sig[:32]), common.BytesToHash(sig[32:64]), diamondCalldata)
+}
+
+// packPermit2Call 签名 Permit2 PermitTransferFrom 并编码 callDiamondWithPermit2
+func (l *TransactionLogic) packPermit2Call(privateKey *ecdsa.PrivateKey, token, spender common.Address, amount, nonce, deadline *big.Int,
+	domain common.Hash, diamondCalldata []byte) ([]byte, error) {
+	permissionsHash := crypto.Keccak256(
+		tokenPermissionsTypeHash,
+		common.LeftPadBytes(token.Bytes(), 32),
+		common.LeftPadBytes(amount.Bytes(), 32),
+	)
+	structHash := crypto.Keccak256(
+		permitTransferFromTypeHash,
+		permissionsHash,
+		common.LeftPadBytes(spender.Bytes(), 32),
+		common.LeftPadBytes(nonce.Bytes(), 32),
+		common.LeftPadBytes(deadline.Bytes(), 32),
+	)
+	sig, err := signTypedDataHash(privateKey, domain, structHash)
+	if err != nil {
+		return nil, err
+	}
+	permit := permitTransferFrom{
+		Permitted: tokenPermissions{Token: token, Amount: amount},
+		Nonce:     nonce,
+		Deadline:  deadline,
+	}
+	return permit2ProxyABI.Pack("callDiamondWithPermit2", diamondCalldata, permit, sig)
+}
+
+// signTypedDataHash EIP-712 签名，返回 r || s || v（v 为 27/28）
+func signTypedDataHash(privateKey *ecdsa.PrivateKey, domain common.Hash, structHash []byte) ([]byte, error) {
+	digest := crypto.Keccak256([]byte{0x19, 0x01}, domain.Bytes(), structHash)
+	sig, err := crypto.Sign(digest, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// estimatePermitTx 估算代理交易的 gas；估算失败（如 DAI 式非标准 permit、签名被拒）时返回 nil 以回退 approve
+func (l *TransactionLogic) estimatePermitTx(client *ethclient.Client, owner, proxy common.Address, value *big.Int, data []byte, mode string) *PermitTx {
+	gas, err := client.EstimateGas(l.ctx, ethereum.CallMsg{From: owner, To: &proxy, Value: value, Data: data})
+	if err != nil {
+		l.Infof("⚠️  %s 签名授权交易估算失败: %v", mode, err)
+		return nil
+	}
+	return &PermitTx{
+		Mode:     mode,
+		To:       proxy.Hex(),
+		Data:     hexutil.Encode(data),
+		GasLimit: strconv.FormatUint(gas*120/100, 10),
+	}
+}
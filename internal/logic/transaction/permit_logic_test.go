@@ -0,0 +1,211 @@
+package transaction
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+func TestPermitTypeHashes(t *testing.T) {
+	// 与 OpenZeppelin ERC20Permit / Uniswap Permit2 合约中的常量比对
+	for _, tt := range []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"Permit", eip2612PermitTypeHash, "0x6e71edae12b1b97f4d1f60370fef10105fa2faae0126114a169c64845d6126c9"},
+		{"TokenPermissions", tokenPermissionsTypeHash, "0x618358ac3db8dc274f0cd8829da7e234bd48cd73c4a740aede1adec9846d06a1"},
+		{"PermitTransferFrom", permitTransferFromTypeHash, "0x939c21a48a8dbe3a9a2404a1d46691e4d39f6583d6ec6b35714604c986d80106"},
+	} {
+		if got := hexutil.Encode(tt.got); got != tt.want {
+			t.Errorf("%s type hash: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// permitFixture 签名双方和金额；摘要由 go-ethereum 的 EIP-712 实现（eth_signTypedData_v4）独立计算
+type permitFixture struct {
+	token, spender    common.Address
+	amount, nonce     *big.Int
+	deadline, chainId *big.Int
+	calldata          []byte
+}
+
+func newPermitFixture() *permitFixture {
+	amount, _ := new(big.Int).SetString("1500000000000000000000", 10)
+	return &permitFixture{
+		token:    common.HexToAddress("0x55d398326f99059fF775485246999027B3197955"),
+		spender:  common.HexToAddress("0x89c6340B1a1f4b25D36cd8B063D49045caF3f818"),
+		amount:   amount,
+		nonce:    big.NewInt(3),
+		deadline: big.NewInt(1792310400),
+		chainId:  big.NewInt(56),
+		calldata: hexutil.MustDecode("0xdeadbeef"),
+	}
+}
+
+// typedDataDigest 返回 domainSeparator 和完整摘要
+func typedDataDigest(t *testing.T, td apitypes.TypedData) (common.Hash, []byte) {
+	t.Helper()
+	domain, err := td.HashStruct("EIP712Domain", td.Domain.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, _, err := apitypes.TypedDataAndHash(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return common.BytesToHash(domain), digest
+}
+
+// recoverSigner 从 r || s || v（v 为 27/28）恢复签名地址
+func recoverSigner(t *testing.T, digest, sig []byte) common.Address {
+	t.Helper()
+	if len(sig) != 65 || (sig[64] != 27 && sig[64] != 28) {
+		t.Fatalf("invalid signature %x", sig)
+	}
+	raw := append([]byte{}, sig...)
+	raw[64] -= 27
+	pub, err := crypto.SigToPub(digest, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.PubkeyToAddress(*pub)
+}
+
+func TestPackEIP2612Call(t *testing.T) {
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	f := newPermitFixture()
+
+	domain, digest := typedDataDigest(t, apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "Tether USD",
+			Version:           "1",
+			ChainId:           (*math.HexOrDecimal256)(f.chainId),
+			VerifyingContract: f.token.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"owner":    owner.Hex(),
+			"spender":  f.spender.Hex(),
+			"value":    f.amount.String(),
+			"nonce":    f.nonce.String(),
+			"deadline": f.deadline.String(),
+		},
+	})
+
+	var l *TransactionLogic
+	data, err := l.packEIP2612Call(key, f.token, f.spender, f.amount, f.nonce, f.deadline, domain, f.calldata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := permit2ProxyABI.Methods["callDiamondWithEIP2612Signature"]
+	if !bytes.Equal(data[:4], method.ID) {
+		t.Fatalf("selector %x, want %x", data[:4], method.ID)
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[0].(common.Address) != f.token || args[1].(*big.Int).Cmp(f.amount) != 0 || args[2].(*big.Int).Cmp(f.deadline) != 0 {
+		t.Fatalf("unexpected permit arguments %v", args[:3])
+	}
+	if !bytes.Equal(args[6].([]byte), f.calldata) {
+		t.Fatalf("diamond calldata %x, want %x", args[6], f.calldata)
+	}
+	r, s := args[4].([32]byte), args[5].([32]byte)
+	sig := append(append(r[:], s[:]...), args[3].(uint8))
+	if signer := recoverSigner(t, digest, sig); signer != owner {
+		t.Fatalf("signature recovers %s, want %s", signer.Hex(), owner.Hex())
+	}
+}
+
+func TestPackPermit2Call(t *testing.T) {
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	f := newPermitFixture()
+	permit2 := common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+
+	domain, digest := typedDataDigest(t, apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"PermitTransferFrom": {
+				{Name: "permitted", Type: "TokenPermissions"},
+				{Name: "spender", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+			"TokenPermissions": {
+				{Name: "token", Type: "address"},
+				{Name: "amount", Type: "uint256"},
+			},
+		},
+		PrimaryType: "PermitTransferFrom",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "Permit2",
+			ChainId:           (*math.HexOrDecimal256)(f.chainId),
+			VerifyingContract: permit2.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"permitted": map[string]interface{}{
+				"token":  f.token.Hex(),
+				"amount": f.amount.String(),
+			},
+			"spender":  f.spender.Hex(),
+			"nonce":    f.nonce.String(),
+			"deadline": f.deadline.String(),
+		},
+	})
+
+	var l *TransactionLogic
+	data, err := l.packPermit2Call(key, f.token, f.spender, f.amount, f.nonce, f.deadline, domain, f.calldata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := permit2ProxyABI.Methods["callDiamondWithPermit2"]
+	if !bytes.Equal(data[:4], method.ID) {
+		t.Fatalf("selector %x, want %x", data[:4], method.ID)
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(args[0].([]byte), f.calldata) {
+		t.Fatalf("diamond calldata %x, want %x", args[0], f.calldata)
+	}
+	if signer := recoverSigner(t, digest, args[2].([]byte)); signer != owner {
+		t.Fatalf("signature recovers %s, want %s", signer.Hex(), owner.Hex())
+	}
+}
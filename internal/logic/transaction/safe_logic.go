@@ -0,0 +1,797 @@
+package transaction
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"demo/internal/abiregistry"
+	"demo/internal/chains"
+	"demo/internal/model"
+	"demo/internal/policy"
+	"demo/internal/safe"
+	"demo/internal/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	evmTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// safeReceiptTimeout 提交 execTransaction 后在请求内等待回执的时长，需小于路由超时；
+	// 超时后交易保持 executing，之后读取 Safe 状态或查询交易列表时按回执结算
+	safeReceiptTimeout = 15 * time.Second
+	// safeClaimTimeout 认领后超过该时长仍没有执行交易哈希，视为广播前中断（进程退出等），退回 pending
+	safeClaimTimeout = 2 * time.Minute
+)
+
+// safeSignature safe_transactions.signatures 中的一项
+type safeSignature struct {
+	Owner     string `json:"owner"`
+	Signature string `json:"signature"`
+}
+
+// safeCallAudit Safe 交易中一个合约调用的审计详情
+type safeCallAudit struct {
+	contractAudit
+	Safe       string `json:"safe"`
+	Index      int    `json:"index"` // 在 calls 中的序号，多个调用经 MultiSend 打包时即子调用序号
+	SafeTxHash string `json:"safe_tx_hash,omitempty"`
+}
+
+// safeContext 一次 Safe 操作用到的链、客户端和链上状态
+type safeContext struct {
+	chain   *chains.Chain
+	client  *ethclient.Client
+	address common.Address
+	info    *safe.Info
+}
+
+// RegisterSafe 登记 Safe：读取链上的版本、owner 和阈值，至少一个 owner 须为托管钱包
+func (l *TransactionLogic) RegisterSafe(req *types.SafeRegisterReq) (*types.SafeResp, error) {
+	l.Infof("--- 开始处理 /safe/register 请求, chain: %s, safe: %s ---", req.Chain, req.Address)
+
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	if !chainConfig.IsEVM() {
+		return nil, fmt.Errorf("safe is only supported on EVM chains: %s", req.Chain)
+	}
+	if !common.IsHexAddress(req.Address) {
+		return nil, fmt.Errorf("invalid address: %s", req.Address)
+	}
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
+	if err != nil {
+		l.Errorf("RPC 节点连接失败: %v", err)
+		return nil, errors.New("failed to connect to chain")
+	}
+	address := common.HexToAddress(req.Address)
+	code, err := client.CodeAt(l.ctx, address, nil)
+	if err != nil {
+		l.Errorf("查询 Safe 合约代码失败: %v", err)
+		return nil, errors.New("failed to check safe deployment")
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("%s is not a deployed contract on %s", address.Hex(), req.Chain)
+	}
+	info, err := safe.ReadInfo(l.ctx, client, address)
+	if err != nil {
+		l.Errorf("读取 Safe 状态失败: %v", err)
+		return nil, err
+	}
+	managed := l.managedOwners(info)
+	if len(managed) == 0 {
+		return nil, fmt.Errorf("none of the owners of %s is a managed wallet", address.Hex())
+	}
+	if err := l.saveSafe(chainConfig, address, info); err != nil {
+		l.Errorf("保存 Safe 失败: %v", err)
+		return nil, errors.New("failed to save safe")
+	}
+
+	l.Infof("✅ Safe 已登记: %s (v%s, %d/%d, 托管 owner %d 个)", address.Hex(), info.Version, info.Threshold, len(info.Owners), len(managed))
+	owners := make([]string, len(info.Owners))
+	for i, o := range info.Owners {
+		owners[i] = o.Hex()
+	}
+	return &types.SafeResp{
+		Chain:         chainConfig.Key,
+		Address:       address.Hex(),
+		Version:       info.Version,
+		Threshold:     int(info.Threshold),
+		Owners:        owners,
+		ManagedOwners: managed,
+		Nonce:         info.Nonce.String(),
+		ExplorerUrl:   chainConfig.ExplorerAddress(address.Hex()),
+	}, nil
+}
+
+// ProposeSafeTransaction 构建 Safe 交易（转账、合约调用，多个调用经 MultiSend 打包），由 proposer 签名后入库；
+// 阈值为 1 时直接执行
+func (l *TransactionLogic) ProposeSafeTransaction(req *types.SafeProposeReq) (*types.SafeTxResp, error) {
+	l.Infof("--- 开始处理 /safe/propose 请求, chain: %s, safe: %s, proposer: %s, calls: %d ---", req.Chain, req.SafeAddress, req.Proposer, len(req.Calls))
+
+	feeOpts, err := parseFeeOptions(req.FeeLevel, req.MaxFeePerGas, req.MaxPriorityFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := l.loadSafe(req.Chain, req.SafeAddress)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(req.Proposer) || !sc.info.IsOwner(common.HexToAddress(req.Proposer)) {
+		return nil, fmt.Errorf("proposer %s is not an owner of %s", req.Proposer, sc.address.Hex())
+	}
+	proposer := common.HexToAddress(req.Proposer)
+	calls, err := l.safeCalls(req.Calls)
+	if err != nil {
+		return nil, err
+	}
+	// 带 data 的调用与 /transaction/contract_call 一样受合约白名单约束，MultiSend 打包前逐个校验
+	checks, err := l.checkSafeCalls(sc, proposer, req.Calls, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. nonce 排在链上 nonce 和已有待执行交易之后
+	nonce := new(big.Int).Set(sc.info.Nonce)
+	maxPending, err := l.svcCtx.SafeTransactionsDao.MaxPendingNonce(l.ctx, sc.chain.Key, sc.address.Hex())
+	if err != nil {
+		l.Errorf("查询待执行 Safe 交易失败: %v", err)
+		return nil, errors.New("failed to load pending safe transactions")
+	}
+	if next := big.NewInt(maxPending + 1); next.Cmp(nonce) > 0 {
+		nonce = next
+	}
+	multiSend := common.HexToAddress(safe.MultiSendCallOnlyV130)
+	if sc.chain.SafeMultiSend != "" {
+		multiSend = common.HexToAddress(sc.chain.SafeMultiSend)
+	}
+	tx, err := safe.BuildTx(calls, multiSend, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. EIP-712 摘要，与合约 getTransactionHash 核对（版本差异导致 domain 不一致时签名会被拒绝）
+	safeTxHash := tx.Hash(sc.address, big.NewInt(sc.chain.ChainId), sc.info.Version)
+	onChain, err := safe.OnChainHash(l.ctx, sc.client, sc.address, tx)
+	if err != nil {
+		l.Infof("⚠️ 无法通过合约核对 safeTxHash: %v", err)
+	} else if onChain != safeTxHash {
+		l.Errorf("safeTxHash 不一致: 本地 %s, 合约 %s (version %s)", safeTxHash.Hex(), onChain.Hex(), sc.info.Version)
+		return nil, fmt.Errorf("unsupported safe version %s: transaction hash mismatch", sc.info.Version)
+	}
+
+	// 3. proposer 签名并入库
+	sig, err := l.signSafeTx(proposer, safeTxHash)
+	if err != nil {
+		return nil, err
+	}
+	signatures, _ := json.Marshal([]safeSignature{{Owner: proposer.Hex(), Signature: hexutil.Encode(sig)}})
+	row := &model.SafeTransactions{
+		Chain:       sc.chain.Key,
+		SafeAddress: sc.address.Hex(),
+		SafeTxHash:  safeTxHash.Hex(),
+		Nonce:       nonce.Int64(),
+		ToAddress:   tx.To.Hex(),
+		Value:       tx.Value.String(),
+		Data:        hexutil.Encode(tx.Data),
+		Operation:   tx.Operation,
+		Description: req.Description,
+		Proposer:    proposer.Hex(),
+		Signatures:  string(signatures),
+		Status:      model.SafeTxStatusPending,
+	}
+	if err := l.svcCtx.SafeTransactionsDao.Insert(l.ctx, row); err != nil {
+		if errors.Is(err, model.ErrDuplicateKey) {
+			return nil, fmt.Errorf("safe transaction %s already exists, use /safe/confirm", safeTxHash.Hex())
+		}
+		l.Errorf("保存 Safe 交易失败: %v", err)
+		return nil, errors.New("failed to save safe transaction")
+	}
+	l.Infof("✅ Safe 交易已创建: %s (nonce %s, %d 个调用), %s 已签名", safeTxHash.Hex(), nonce, len(calls), proposer.Hex())
+	for _, c := range checks {
+		c.detail.SafeTxHash = row.SafeTxHash
+		l.svcCtx.Policy.Audit(l.ctx, c.preq, c.preq.Contract, model.AuditResultSuccess, c.detail)
+	}
+
+	executor := req.Executor
+	if executor == "" {
+		executor = proposer.Hex()
+	}
+	return l.executeIfReady(sc, row, executor, feeOpts)
+}
+
+// ConfirmSafeTransaction 托管 owner 对 Safe 交易签名，签名达到阈值且 nonce 轮到时提交 execTransaction
+func (l *TransactionLogic) ConfirmSafeTransaction(req *types.SafeConfirmReq) (*types.SafeTxResp, error) {
+	l.Infof("--- 开始处理 /safe/confirm 请求, chain: %s, safeTxHash: %s, owner: %s ---", req.Chain, req.SafeTxHash, req.Owner)
+
+	feeOpts, err := parseFeeOptions(req.FeeLevel, req.MaxFeePerGas, req.MaxPriorityFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+	row, err := l.findSafeTx(req.Chain, req.SafeTxHash)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != model.SafeTxStatusPending {
+		return nil, fmt.Errorf("safe transaction is %s", row.Status)
+	}
+	sc, err := l.loadSafe(row.Chain, row.SafeAddress)
+	if err != nil {
+		return nil, err
+	}
+	if big.NewInt(row.Nonce).Cmp(sc.info.Nonce) < 0 {
+		return nil, fmt.Errorf("safe nonce %d has already been used (current %s), the transaction is replaced", row.Nonce, sc.info.Nonce)
+	}
+
+	// 要签名的 owner：指定的 owner，或所有尚未签名的托管 owner
+	var owners []common.Address
+	if req.Owner != "" {
+		if !common.IsHexAddress(req.Owner) || !sc.info.IsOwner(common.HexToAddress(req.Owner)) {
+			return nil, fmt.Errorf("%s is not an owner of %s", req.Owner, sc.address.Hex())
+		}
+		owners = []common.Address{common.HexToAddress(req.Owner)}
+	} else {
+		for _, o := range l.managedOwners(sc.info) {
+			owners = append(owners, common.HexToAddress(o))
+		}
+	}
+
+	safeTxHash := common.HexToHash(row.SafeTxHash)
+	lastSigner := ""
+	// 并发确认时签名列表可能已被修改，重新读取后重试
+	for attempt := 0; ; attempt++ {
+		sigs, err := decodeSafeSignatures(row.Signatures)
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, owner := range owners {
+			if hasSigned(sigs, owner) {
+				continue
+			}
+			sig, err := l.signSafeTx(owner, safeTxHash)
+			if err != nil {
+				return nil, err
+			}
+			sigs = append(sigs, safeSignature{Owner: owner.Hex(), Signature: hexutil.Encode(sig)})
+			lastSigner = owner.Hex()
+			added++
+		}
+		if added == 0 {
+			l.Infof("没有需要新增的签名")
+			break
+		}
+		updated, _ := json.Marshal(sigs)
+		err = l.svcCtx.SafeTransactionsDao.UpdateSignatures(l.ctx, row.Id, row.Signatures, string(updated))
+		if err == nil {
+			row.Signatures = string(updated)
+			l.Infof("✅ Safe 交易 %s 新增 %d 个签名", row.SafeTxHash, added)
+			break
+		}
+		if !errors.Is(err, model.ErrStaleUpdate) || attempt >= 2 {
+			l.Errorf("保存 Safe 签名失败: %v", err)
+			return nil, errors.New("failed to save safe signatures")
+		}
+		if row, err = l.findSafeTx(req.Chain, req.SafeTxHash); err != nil {
+			return nil, err
+		}
+		if row.Status != model.SafeTxStatusPending {
+			return nil, fmt.Errorf("safe transaction is %s", row.Status)
+		}
+	}
+
+	executor := req.Executor
+	if executor == "" {
+		executor = lastSigner
+	}
+	return l.executeIfReady(sc, row, executor, feeOpts)
+}
+
+// ExecuteSafeTransaction 对签名已够的 Safe 交易重新提交执行
+func (l *TransactionLogic) ExecuteSafeTransaction(req *types.SafeExecuteReq) (*types.SafeTxResp, error) {
+	l.Infof("--- 开始处理 /safe/execute 请求, chain: %s, safeTxHash: %s ---", req.Chain, req.SafeTxHash)
+
+	feeOpts, err := parseFeeOptions(req.FeeLevel, req.MaxFeePerGas, req.MaxPriorityFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+	row, err := l.findSafeTx(req.Chain, req.SafeTxHash)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != model.SafeTxStatusPending {
+		return nil, fmt.Errorf("safe transaction is %s", row.Status)
+	}
+	sc, err := l.loadSafe(row.Chain, row.SafeAddress)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := l.executeIfReady(sc, row, req.Executor, feeOpts)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != model.SafeTxStatusExecuted && resp.Status != model.SafeTxStatusExecuting {
+		if resp.Error != "" {
+			return nil, errors.New(resp.Error)
+		}
+		return nil, errors.New(resp.Message)
+	}
+	return resp, nil
+}
+
+// SafeTransactions 查询 Safe 的交易（按 nonce 升序）
+func (l *TransactionLogic) SafeTransactions(req *types.SafeTransactionsReq) (*types.SafeTransactionsResp, error) {
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(req.SafeAddress) {
+		return nil, fmt.Errorf("invalid safe_address: %s", req.SafeAddress)
+	}
+	address := common.HexToAddress(req.SafeAddress).Hex()
+	registered, err := l.svcCtx.SafesDao.FindOneByChainAndAddress(l.ctx, chainConfig.Key, address)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return nil, fmt.Errorf("safe %s is not registered on %s", address, chainConfig.Key)
+		}
+		return nil, errors.New("failed to load safe")
+	}
+
+	// 先按回执结算执行中的交易、按链上 nonce 作废已无法执行的交易；节点不可用时仍返回数据库中的记录
+	if client, err := l.svcCtx.RPC.EthClient(chainConfig); err != nil {
+		l.Errorf("RPC 节点连接失败: %v", err)
+	} else {
+		l.settleSafeExecutions(chainConfig, client, common.HexToAddress(address))
+		if nonce, err := safe.ReadNonce(l.ctx, client, common.HexToAddress(address)); err != nil {
+			l.Errorf("读取 Safe nonce 失败: %v", err)
+		} else {
+			l.replaceStaleSafeTxs(chainConfig.Key, common.HexToAddress(address), nonce)
+		}
+	}
+
+	rows, total, err := l.svcCtx.SafeTransactionsDao.FindPage(l.ctx, chainConfig.Key, address, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		l.Errorf("查询 Safe 交易失败: %v", err)
+		return nil, errors.New("failed to query safe transactions")
+	}
+	items := make([]types.SafeTxResp, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, *l.safeTxResp(chainConfig, row, registered.Threshold, ""))
+	}
+	return &types.SafeTransactionsResp{Total: total, Items: items}, nil
+}
+
+// executeIfReady 有效签名达到阈值且 nonce 等于链上 nonce 时，认领交易（pending → executing）后由 executor
+// 提交 execTransaction，再按回执标记 executed / failed。条件不满足或广播前失败时交易保持 pending，
+// 原因写入响应（广播前失败同时记录到 error_message）
+func (l *TransactionLogic) executeIfReady(sc *safeContext, row *model.SafeTransactions, executor string, feeOpts FeeOptions) (*types.SafeTxResp, error) {
+	threshold := int(sc.info.Threshold)
+	if big.NewInt(row.Nonce).Cmp(sc.info.Nonce) < 0 {
+		// nonce 已被其它交易使用（包括在本服务之外执行的），本交易不可能再执行
+		l.replaceStaleSafeTxs(sc.chain.Key, sc.address, sc.info.Nonce)
+		row.Status = model.SafeTxStatusReplaced
+		msg := fmt.Sprintf("safe nonce %d has already been used (current %s)", row.Nonce, sc.info.Nonce)
+		return l.safeTxResp(sc.chain, row, threshold, msg), nil
+	}
+	sigs, err := decodeSafeSignatures(row.Signatures)
+	if err != nil {
+		return nil, err
+	}
+	// owner 可能已在链上变更，只计入当前 owner 且能恢复出该 owner 的签名
+	safeTxHash := common.HexToHash(row.SafeTxHash)
+	var valid []safe.Signature
+	for _, s := range sigs {
+		owner := common.HexToAddress(s.Owner)
+		if !sc.info.IsOwner(owner) {
+			continue
+		}
+		sig, err := hexutil.Decode(s.Signature)
+		if err != nil {
+			continue
+		}
+		if signer, err := safe.Recover(safeTxHash, sig); err != nil || signer != owner {
+			continue
+		}
+		valid = append(valid, safe.Signature{Owner: owner, Signature: sig})
+	}
+	if len(valid) < threshold {
+		msg := fmt.Sprintf("%d of %d signatures collected", len(valid), threshold)
+		return l.safeTxResp(sc.chain, row, threshold, msg), nil
+	}
+	if big.NewInt(row.Nonce).Cmp(sc.info.Nonce) != 0 {
+		msg := fmt.Sprintf("threshold met, waiting for safe nonce %d (current %s)", row.Nonce, sc.info.Nonce)
+		return l.safeTxResp(sc.chain, row, threshold, msg), nil
+	}
+	if executor == "" {
+		executor = valid[len(valid)-1].Owner.Hex()
+	}
+	if !common.IsHexAddress(executor) {
+		return nil, fmt.Errorf("invalid executor: %s", executor)
+	}
+	executor = common.HexToAddress(executor).Hex()
+
+	value, _ := new(big.Int).SetString(row.Value, 10)
+	data, err := hexutil.Decode(row.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored safe transaction data: %v", err)
+	}
+	tx := &safe.Tx{
+		To:        common.HexToAddress(row.ToAddress),
+		Value:     value,
+		Data:      data,
+		Operation: row.Operation,
+		Nonce:     big.NewInt(row.Nonce),
+	}
+	// 先认领：并发的 confirm / execute 只有一个请求能提交 execTransaction
+	claimed, err := l.svcCtx.SafeTransactionsDao.ClaimExecution(l.ctx, row.Id)
+	if err != nil {
+		l.Errorf("认领 Safe 交易失败: %v", err)
+		return nil, errors.New("failed to claim safe transaction")
+	}
+	if !claimed {
+		if latest, err := l.findSafeTx(sc.chain.Key, row.SafeTxHash); err == nil {
+			row = latest
+		}
+		return l.safeTxResp(sc.chain, row, threshold, "execution is already in progress"), nil
+	}
+	row.Status, row.TxHash, row.ErrorMessage = model.SafeTxStatusExecuting, "", ""
+
+	txHash, err := l.sendExecTransaction(sc, tx, safe.PackSignatures(valid), executor, feeOpts)
+	if err != nil {
+		l.Errorf("Safe 交易执行失败: %v", err)
+		if dbErr := l.svcCtx.SafeTransactionsDao.ReleaseExecution(l.ctx, row.Id, err.Error()); dbErr != nil {
+			l.Errorf("记录 Safe 执行错误失败: %v", dbErr)
+		}
+		row.Status, row.ErrorMessage = model.SafeTxStatusPending, err.Error()
+		return l.safeTxResp(sc.chain, row, threshold, "threshold met but execution failed"), nil
+	}
+
+	if err := l.svcCtx.SafeTransactionsDao.SetExecTxHash(l.ctx, row.Id, txHash); err != nil {
+		l.Errorf("记录 execTransaction 哈希失败: %v", err)
+	}
+	row.TxHash = txHash
+	recordTransaction(l.ctx, l.svcCtx, &model.Transactions{
+		Chain:       sc.chain.Key,
+		TxHash:      txHash,
+		TxType:      model.TxTypeSafeExec,
+		FromAddress: sc.address.Hex(),
+		ToAddress:   row.ToAddress,
+		Amount:      row.Value,
+		ExplorerUrl: l.BuildExplorerUrl(sc.chain.Key, txHash),
+	})
+	l.Infof("Safe 交易 %s 已提交 execTransaction: %s (executor %s)，等待回执", row.SafeTxHash, txHash, executor)
+
+	receipt, err := l.WaitForTransactionReceipt(sc.client, common.HexToHash(txHash), safeReceiptTimeout)
+	if err != nil {
+		l.Infof("暂未取得 execTransaction 回执: %v", err)
+		return l.safeTxResp(sc.chain, row, threshold, "execTransaction submitted, waiting for receipt"), nil
+	}
+	l.settleSafeTx(sc.chain, row, receipt)
+	if row.Status != model.SafeTxStatusExecuted {
+		return l.safeTxResp(sc.chain, row, threshold, "execTransaction reverted"), nil
+	}
+	return l.safeTxResp(sc.chain, row, threshold, "execTransaction confirmed"), nil
+}
+
+// settleSafeTx 按 execTransaction 回执把认领中的交易标记为 executed 或 failed。
+// 交易以 safeTxGas = 0 构建，内部调用失败时整笔 revert，Safe nonce 不会被使用
+func (l *TransactionLogic) settleSafeTx(chainConfig *chains.Chain, row *model.SafeTransactions, receipt *evmTypes.Receipt) {
+	var err error
+	if receipt.Status == evmTypes.ReceiptStatusSuccessful {
+		err = l.svcCtx.SafeTransactionsDao.MarkExecuted(l.ctx, row.Id, row.TxHash)
+		row.Status, row.ErrorMessage = model.SafeTxStatusExecuted, ""
+		l.Infof("✅ Safe 交易已执行: %s → %s (区块 %s)", row.SafeTxHash, row.TxHash, receipt.BlockNumber)
+	} else {
+		msg := fmt.Sprintf("execTransaction %s reverted in block %s", row.TxHash, receipt.BlockNumber)
+		err = l.svcCtx.SafeTransactionsDao.MarkFailed(l.ctx, row.Id, msg)
+		row.Status, row.ErrorMessage = model.SafeTxStatusFailed, msg
+		l.Errorf("❌ Safe 交易执行失败: %s", msg)
+	}
+	if errors.Is(err, model.ErrStaleUpdate) {
+		// 另一个请求已结算，以数据库为准
+		if latest, err := l.findSafeTx(chainConfig.Key, row.SafeTxHash); err == nil {
+			*row = *latest
+		}
+		return
+	}
+	if err != nil {
+		l.Errorf("更新 Safe 交易状态失败: %v", err)
+	}
+}
+
+// settleSafeExecutions 结算 Safe 认领中的交易：有回执的按回执标记，广播前中断的退回 pending。失败只记录日志
+func (l *TransactionLogic) settleSafeExecutions(chainConfig *chains.Chain, client *ethclient.Client, address common.Address) {
+	rows, err := l.svcCtx.SafeTransactionsDao.FindExecuting(l.ctx, chainConfig.Key, address.Hex())
+	if err != nil {
+		l.Errorf("查询执行中的 Safe 交易失败: %v", err)
+		return
+	}
+	for _, row := range rows {
+		if row.TxHash == "" {
+			if time.Since(row.UpdatedAt) < safeClaimTimeout {
+				continue
+			}
+			l.Infof("Safe 交易 %s 认领后未广播，退回 pending", row.SafeTxHash)
+			if err := l.svcCtx.SafeTransactionsDao.ReleaseExecution(l.ctx, row.Id, "execution was interrupted before broadcast"); err != nil && !errors.Is(err, model.ErrStaleUpdate) {
+				l.Errorf("退回 Safe 交易失败: %v", err)
+			}
+			continue
+		}
+		receipt, err := client.TransactionReceipt(l.ctx, common.HexToHash(row.TxHash))
+		if err != nil {
+			if !errors.Is(err, ethereum.NotFound) {
+				l.Errorf("查询 execTransaction 回执失败: %v", err)
+			}
+			continue
+		}
+		l.settleSafeTx(chainConfig, row, receipt)
+	}
+}
+
+// sendExecTransaction 模拟 execTransaction（owner 付费执行时内部调用失败会整体 revert），再签名广播
+func (l *TransactionLogic) sendExecTransaction(sc *safeContext, tx *safe.Tx, signatures []byte, executor string, feeOpts FeeOptions) (string, error) {
+	data, err := safe.ExecTransactionData(tx, signatures)
+	if err != nil {
+		return "", err
+	}
+	msg := ethereum.CallMsg{From: common.HexToAddress(executor), To: &sc.address, Data: data}
+	if _, err := sc.client.CallContract(l.ctx, msg, nil); err != nil {
+		return "", fmt.Errorf("execTransaction would revert: %s", abiregistry.RevertReason(nil, err))
+	}
+	gas, err := sc.client.EstimateGas(l.ctx, msg)
+	if err != nil {
+		return "", fmt.Errorf("execTransaction would fail: %s", abiregistry.RevertReason(nil, err))
+	}
+	gasLimit := gas * 130 / 100
+
+	fees, err := l.SuggestFees(sc.chain, feeOpts)
+	if err != nil {
+		return "", err
+	}
+	privateKey, err := l.GetWalletPrivateKey(executor)
+	if err != nil {
+		return "", err
+	}
+	l.Infof("提交 execTransaction: executor %s, gasLimit=%d, 手续费 %s", executor, gasLimit, fees)
+	return l.BuildAndSendTransaction(sc.client, privateKey, sc.address, big.NewInt(0), data, gasLimit, fees, sc.chain.ChainId)
+}
+
+// loadSafe 读取已登记 Safe 的链上状态，并刷新数据库中的 owner / 阈值快照
+func (l *TransactionLogic) loadSafe(chain, address string) (*safeContext, error) {
+	chainConfig, err := l.getChain(chain)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("invalid safe_address: %s", address)
+	}
+	safeAddress := common.HexToAddress(address)
+	if _, err := l.svcCtx.SafesDao.FindOneByChainAndAddress(l.ctx, chainConfig.Key, safeAddress.Hex()); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return nil, fmt.Errorf("safe %s is not registered on %s, use /safe/register", safeAddress.Hex(), chainConfig.Key)
+		}
+		return nil, errors.New("failed to load safe")
+	}
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
+	if err != nil {
+		l.Errorf("RPC 节点连接失败: %v", err)
+		return nil, errors.New("failed to connect to chain")
+	}
+	info, err := safe.ReadInfo(l.ctx, client, safeAddress)
+	if err != nil {
+		l.Errorf("读取 Safe 状态失败: %v", err)
+		return nil, err
+	}
+	if err := l.saveSafe(chainConfig, safeAddress, info); err != nil {
+		l.Errorf("更新 Safe 快照失败: %v", err)
+	}
+	l.settleSafeExecutions(chainConfig, client, safeAddress)
+	l.replaceStaleSafeTxs(chainConfig.Key, safeAddress, info.Nonce)
+	return &safeContext{chain: chainConfig, client: client, address: safeAddress, info: info}, nil
+}
+
+// replaceStaleSafeTxs 把 nonce 低于链上 nonce 的待执行交易标记为 replaced，失败只记录日志
+func (l *TransactionLogic) replaceStaleSafeTxs(chain string, address common.Address, nonce *big.Int) {
+	n, err := l.svcCtx.SafeTransactionsDao.ReplaceStale(l.ctx, chain, address.Hex(), nonce.Int64())
+	if err != nil {
+		l.Errorf("标记作废的 Safe 交易失败: %v", err)
+		return
+	}
+	if n > 0 {
+		l.Infof("Safe %s 的 nonce 已推进到 %s，%d 笔待执行交易标记为 replaced", address.Hex(), nonce, n)
+	}
+}
+
+func (l *TransactionLogic) saveSafe(chainConfig *chains.Chain, address common.Address, info *safe.Info) error {
+	owners := make([]string, len(info.Owners))
+	for i, o := range info.Owners {
+		owners[i] = o.Hex()
+	}
+	return l.svcCtx.SafesDao.Upsert(l.ctx, &model.Safes{
+		Chain:     chainConfig.Key,
+		Address:   address.Hex(),
+		Version:   info.Version,
+		Threshold: int(info.Threshold),
+		Owners:    strings.Join(owners, ","),
+	})
+}
+
+// managedOwners Safe owner 中由本服务托管私钥的钱包
+func (l *TransactionLogic) managedOwners(info *safe.Info) []string {
+	var managed []string
+	for _, o := range info.Owners {
+		if _, err := l.svcCtx.WalletsDao.FindOneByAddress(l.ctx, o.Hex()); err == nil {
+			managed = append(managed, o.Hex())
+		}
+	}
+	return managed
+}
+
+func (l *TransactionLogic) findSafeTx(chain, safeTxHash string) (*model.SafeTransactions, error) {
+	chainConfig, err := l.getChain(chain)
+	if err != nil {
+		return nil, err
+	}
+	row, err := l.svcCtx.SafeTransactionsDao.FindOneBySafeTxHash(l.ctx, common.HexToHash(safeTxHash).Hex())
+	if err != nil || row.Chain != chainConfig.Key {
+		if err == nil || errors.Is(err, model.ErrNotFound) {
+			return nil, fmt.Errorf("safe transaction %s not found on %s", safeTxHash, chainConfig.Key)
+		}
+		return nil, errors.New("failed to load safe transaction")
+	}
+	return row, nil
+}
+
+// safeCallCheck 一个已通过策略校验、待写入审计日志的调用
+type safeCallCheck struct {
+	preq   *policy.Request
+	detail *safeCallAudit
+}
+
+// checkSafeCalls 带 data 的调用按 ActionContractCall 校验目标合约和 4 字节选择器，拒绝时写入审计日志并返回错误；
+// token 转账由服务按 ERC20 transfer 编码、原生币转账不带 data，与 /transaction/send 一样不经合约白名单
+func (l *TransactionLogic) checkSafeCalls(sc *safeContext, proposer common.Address, reqCalls []types.SafeCall, calls []safe.Call) ([]safeCallCheck, error) {
+	var checks []safeCallCheck
+	for i, c := range calls {
+		if reqCalls[i].Data == "" || len(c.Data) == 0 {
+			continue
+		}
+		preq := &policy.Request{
+			Action:   policy.ActionContractCall,
+			Chain:    sc.chain.Key,
+			Address:  proposer.Hex(),
+			Contract: c.To.Hex(),
+		}
+		if len(c.Data) >= 4 {
+			preq.Selector = hexutil.Encode(c.Data[:4])
+		}
+		detail := &safeCallAudit{
+			contractAudit: contractAudit{Selector: preq.Selector, Data: hexutil.Encode(c.Data)},
+			Safe:          sc.address.Hex(),
+			Index:         i,
+		}
+		if c.Value.Sign() > 0 {
+			detail.Value = c.Value.String()
+		}
+		if err := l.svcCtx.Policy.Check(preq); err != nil {
+			l.Infof("⛔ Safe 调用 calls[%d] 被拒绝: %v", i, err)
+			detail.Reason = err.Error()
+			l.svcCtx.Policy.Audit(l.ctx, preq, preq.Contract, model.AuditResultDenied, detail)
+			return nil, fmt.Errorf("calls[%d]: %w", i, err)
+		}
+		checks = append(checks, safeCallCheck{preq: preq, detail: detail})
+	}
+	return checks, nil
+}
+
+// safeCalls 解析请求中的调用：token 非空为 ERC20 转账，否则为原生币转账 / 合约调用
+func (l *TransactionLogic) safeCalls(reqCalls []types.SafeCall) ([]safe.Call, error) {
+	if len(reqCalls) == 0 {
+		return nil, errors.New("calls is required")
+	}
+	calls := make([]safe.Call, 0, len(reqCalls))
+	for i, c := range reqCalls {
+		if !common.IsHexAddress(c.To) {
+			return nil, fmt.Errorf("calls[%d]: invalid to: %s", i, c.To)
+		}
+		amount := new(big.Int)
+		if c.Amount != "" {
+			if _, ok := amount.SetString(c.Amount, 10); !ok || amount.Sign() < 0 {
+				return nil, fmt.Errorf("calls[%d]: invalid amount: %s", i, c.Amount)
+			}
+		}
+		if c.Token != "" && !l.IsNativeToken(c.Token) {
+			if !common.IsHexAddress(c.Token) {
+				return nil, fmt.Errorf("calls[%d]: invalid token: %s", i, c.Token)
+			}
+			if c.Data != "" {
+				return nil, fmt.Errorf("calls[%d]: data cannot be combined with token", i)
+			}
+			data, err := l.BuildERC20TransferData(c.To, amount)
+			if err != nil {
+				return nil, fmt.Errorf("calls[%d]: %v", i, err)
+			}
+			calls = append(calls, safe.Call{To: common.HexToAddress(c.Token), Value: new(big.Int), Data: data})
+			continue
+		}
+		var data []byte
+		if c.Data != "" {
+			decoded, err := hexutil.Decode(c.Data)
+			if err != nil {
+				return nil, fmt.Errorf("calls[%d]: invalid data: %v", i, err)
+			}
+			data = decoded
+		}
+		calls = append(calls, safe.Call{To: common.HexToAddress(c.To), Value: amount, Data: data})
+	}
+	return calls, nil
+}
+
+func (l *TransactionLogic) signSafeTx(owner common.Address, safeTxHash common.Hash) ([]byte, error) {
+	privateKey, err := l.GetWalletPrivateKey(owner.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("owner %s is not a managed wallet", owner.Hex())
+	}
+	sig, err := safe.Sign(safeTxHash, privateKey)
+	if err != nil {
+		l.Errorf("Safe 交易签名失败: %v", err)
+		return nil, errors.New("failed to sign safe transaction")
+	}
+	return sig, nil
+}
+
+func (l *TransactionLogic) safeTxResp(chainConfig *chains.Chain, row *model.SafeTransactions, threshold int, message string) *types.SafeTxResp {
+	sigs, _ := decodeSafeSignatures(row.Signatures)
+	signers := make([]string, len(sigs))
+	for i, s := range sigs {
+		signers[i] = s.Owner
+	}
+	resp := &types.SafeTxResp{
+		Chain:       chainConfig.Key,
+		SafeAddress: row.SafeAddress,
+		SafeTxHash:  row.SafeTxHash,
+		Nonce:       row.Nonce,
+		To:          row.ToAddress,
+		Value:       row.Value,
+		Data:        row.Data,
+		Operation:   row.Operation,
+		Description: row.Description,
+		Signers:     signers,
+		Threshold:   threshold,
+		Status:      row.Status,
+		TxHash:      row.TxHash,
+		Error:       row.ErrorMessage,
+		Message:     message,
+	}
+	if row.TxHash != "" {
+		resp.ExplorerUrl = l.BuildExplorerUrl(chainConfig.Key, row.TxHash)
+	}
+	return resp
+}
+
+func decodeSafeSignatures(raw string) ([]safeSignature, error) {
+	var sigs []safeSignature
+	if raw == "" {
+		return sigs, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &sigs); err != nil {
+		return nil, fmt.Errorf("invalid stored safe signatures: %v", err)
+	}
+	return sigs, nil
+}
+
+func hasSigned(sigs []safeSignature, owner common.Address) bool {
+	for _, s := range sigs {
+		if common.HexToAddress(s.Owner) == owner {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,296 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"demo/internal/chains"
+	"demo/internal/erc4337"
+	"demo/internal/model"
+	"demo/internal/types"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// userOperation 智能账户交易的载荷，在 EVM 适配器中替代 *evmTypes.Transaction
+type userOperation struct {
+	account *model.SmartAccounts
+	network *erc4337.Network
+	op      *erc4337.UserOperation
+}
+
+// findSmartAccount 发送地址是该链上登记的智能账户时返回账户，否则返回 nil 走普通 EOA 交易
+func (l *TransactionLogic) findSmartAccount(chainConfig *chains.Chain, address string) *model.SmartAccounts {
+	if !chainConfig.IsEVM() || !common.IsHexAddress(address) {
+		return nil
+	}
+	account, err := l.svcCtx.SmartAccountsDao.FindOneByChainAndAddress(l.ctx, chainConfig.Key, common.HexToAddress(address).Hex())
+	if err != nil {
+		if !errors.Is(err, model.ErrNotFound) {
+			l.Errorf("查询智能账户失败: %v", err)
+		}
+		return nil
+	}
+	return account
+}
+
+// CheckSender 发送地址必须是托管钱包或该链上登记的智能账户；供保存后再执行的请求提前报错
+func (l *TransactionLogic) CheckSender(chainConfig *chains.Chain, address string) error {
+	if l.findSmartAccount(chainConfig, address) != nil {
+		return nil
+	}
+	if _, err := l.svcCtx.WalletsDao.FindOneByAddress(l.ctx, address); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return fmt.Errorf("%s is not a managed wallet", address)
+		}
+		return err
+	}
+	return nil
+}
+
+// buildUserOperation 构建 UserOperation：账户未部署时带上工厂部署数据，gas 由 bundler 估算，
+// sponsor 时先取 paymaster 占位数据参与估算，估算后再取最终数据
+func (l *TransactionLogic) buildUserOperation(chainConfig *chains.Chain, account *model.SmartAccounts, calls []erc4337.Call, opts FeeOptions, sponsor bool) (*userOperation, error) {
+	network, err := l.svcCtx.Bundlers.Network(chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	if sponsor && network.Paymaster == nil {
+		return nil, fmt.Errorf("gas sponsorship is not configured on chain %s", chainConfig.Key)
+	}
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
+	if err != nil {
+		l.Errorf("RPC 节点连接失败: %v", err)
+		return nil, errors.New("failed to connect to chain")
+	}
+
+	callData, err := erc4337.ExecuteCallData(calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build smart account call data: %v", err)
+	}
+	sender := common.HexToAddress(account.Address)
+	op := &erc4337.UserOperation{Sender: sender, CallData: callData, Signature: erc4337.DummySignature}
+
+	// 1. 账户未部署时由 EntryPoint 调用工厂部署，nonce 从 0 开始
+	code, err := client.CodeAt(l.ctx, sender, nil)
+	if err != nil {
+		l.Errorf("查询智能账户代码失败: %v", err)
+		return nil, errors.New("failed to check smart account deployment")
+	}
+	if len(code) == 0 {
+		salt, ok := new(big.Int).SetString(account.Salt, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid smart account salt: %s", account.Salt)
+		}
+		factory := common.HexToAddress(account.Factory)
+		factoryData, err := erc4337.FactoryData(common.HexToAddress(account.Owner), salt)
+		if err != nil {
+			return nil, err
+		}
+		op.Factory, op.FactoryData = &factory, factoryData
+		op.Nonce = big.NewInt(0)
+		l.Infof("智能账户 %s 尚未部署，首个 UserOperation 将通过工厂 %s 部署", account.Address, account.Factory)
+	} else {
+		if !account.Deployed {
+			if err := l.svcCtx.SmartAccountsDao.MarkDeployed(l.ctx, account.Id); err != nil {
+				l.Errorf("更新智能账户部署状态失败: %v", err)
+			}
+		}
+		if op.Nonce, err = erc4337.GetNonce(l.ctx, client, network.EntryPoint, sender); err != nil {
+			l.Errorf("获取 UserOperation nonce 失败: %v", err)
+			return nil, errors.New("failed to get smart account nonce")
+		}
+	}
+
+	// 2. 手续费：legacy 链两个字段都使用 gasPrice
+	fees, err := l.SuggestFees(chainConfig, opts)
+	if err != nil {
+		return nil, err
+	}
+	if fees.Dynamic {
+		op.MaxFeePerGas, op.MaxPriorityFeePerGas = fees.GasFeeCap, fees.GasTipCap
+	} else {
+		op.MaxFeePerGas, op.MaxPriorityFeePerGas = fees.GasPrice, fees.GasPrice
+	}
+
+	// 3. paymaster 占位数据 + bundler 估算 gas
+	final := false
+	if sponsor {
+		stub, err := network.Paymaster.GetStubData(l.ctx, op, network.EntryPoint, network.ChainId)
+		if err != nil {
+			l.Errorf("获取 paymaster 占位数据失败: %v", err)
+			return nil, fmt.Errorf("paymaster declined to sponsor: %v", err)
+		}
+		op.ApplyPaymaster(stub)
+		final = stub.IsFinal
+	}
+	est, err := network.Bundler.EstimateGas(l.ctx, op, network.EntryPoint)
+	if err != nil {
+		l.Errorf("UserOperation gas 估算失败: %v", err)
+		return nil, fmt.Errorf("user operation would fail: %v", err)
+	}
+	op.ApplyGasEstimate(est)
+	l.Infof("UserOperation gas: call=%s verification=%s preVerification=%s, 手续费 (%s): %s",
+		op.CallGasLimit, op.VerificationGasLimit, op.PreVerificationGas, opts.Level, fees)
+
+	// 4. gas 确定后取最终的 paymaster 数据（通常包含 paymaster 对整个操作的签名）
+	if sponsor && !final {
+		data, err := network.Paymaster.GetData(l.ctx, op, network.EntryPoint, network.ChainId)
+		if err != nil {
+			l.Errorf("获取 paymaster 数据失败: %v", err)
+			return nil, fmt.Errorf("paymaster declined to sponsor: %v", err)
+		}
+		op.ApplyPaymaster(data)
+	}
+	return &userOperation{account: account, network: network, op: op}, nil
+}
+
+// signUserOperation 所有者钱包对 userOpHash 做 EIP-191 签名（SimpleAccount 的验证方式），返回 userOpHash
+func (l *TransactionLogic) signUserOperation(uo *userOperation) (common.Hash, error) {
+	privateKey, err := l.GetWalletPrivateKey(uo.account.Owner)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash := uo.op.Hash(uo.network.EntryPoint, uo.network.ChainId)
+	sig, err := crypto.Sign(accounts.TextHash(hash[:]), privateKey)
+	if err != nil {
+		l.Errorf("UserOperation 签名失败: %v", err)
+		return common.Hash{}, errors.New("failed to sign user operation")
+	}
+	sig[64] += 27
+	uo.op.Signature = sig
+	return hash, nil
+}
+
+// sendUserOperation 提交到 bundler，返回 userOpHash
+func (l *TransactionLogic) sendUserOperation(uo *userOperation, expected common.Hash) (string, error) {
+	hash, err := uo.network.Bundler.Send(l.ctx, uo.op, uo.network.EntryPoint)
+	if err != nil {
+		l.Errorf("bundler 拒绝 UserOperation: %v", err)
+		return "", fmt.Errorf("bundler rejected user operation: %v", err)
+	}
+	if hash != expected {
+		l.Infof("⚠️ bundler 返回的 userOpHash %s 与本地计算的 %s 不一致", hash.Hex(), expected.Hex())
+	}
+	return hash.Hex(), nil
+}
+
+// executeSmartAccountSwap 智能账户的 LI.FI swap：授权与兑换在同一个 UserOperation 中原子执行，
+// 授权额度只给本次兑换的数量，不留下无限授权
+func (l *TransactionLogic) executeSmartAccountSwap(req *types.TransactionReq, account *model.SmartAccounts) (*types.TransactionResp, error) {
+	l.Infof("=== 执行智能账户 Swap (ERC-4337) ===")
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	if chainConfig.Testnet {
+		return nil, errors.New("smart account swaps go through LI.FI and are not available on testnets")
+	}
+	feeOpts, err := ParseFeeOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := l.getLifiQuote(req)
+	if err != nil {
+		l.Errorf("获取 LI.FI 报价失败: %v", err)
+		return nil, fmt.Errorf("failed to get LI.FI quote: %v", err)
+	}
+
+	var calls []erc4337.Call
+	if !l.IsNativeToken(req.FromToken) && quote.Estimate.ApprovalAddress != "" {
+		client, err := l.svcCtx.RPC.EthClient(chainConfig)
+		if err != nil {
+			return nil, errors.New("failed to connect to chain")
+		}
+		amount, _ := new(big.Int).SetString(req.Amount, 10)
+		allowance, err := l.CheckAllowance(client, req.FromToken, account.Address, quote.Estimate.ApprovalAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check allowance: %v", err)
+		}
+		if allowance.Cmp(amount) < 0 {
+			calls = append(calls, erc4337.Call{
+				To:   common.HexToAddress(req.FromToken),
+				Data: l.BuildERC20ApproveData(quote.Estimate.ApprovalAddress, amount),
+			})
+			l.Infof("授权不足，approve 与 swap 合并为一次批量调用")
+		}
+	}
+	value := new(big.Int)
+	if quote.TransactionRequest.Value != "" {
+		if _, ok := value.SetString(quote.TransactionRequest.Value, 0); !ok {
+			return nil, fmt.Errorf("invalid LI.FI transaction value: %s", quote.TransactionRequest.Value)
+		}
+	}
+	calls = append(calls, erc4337.Call{
+		To:    common.HexToAddress(quote.TransactionRequest.To),
+		Value: value,
+		Data:  common.FromHex(quote.TransactionRequest.Data),
+	})
+
+	uo, err := l.buildUserOperation(chainConfig, account, calls, feeOpts, req.Sponsor)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := l.signUserOperation(uo)
+	if err != nil {
+		return nil, err
+	}
+	userOpHash, err := l.sendUserOperation(uo, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("✅ 智能账户 Swap 已提交，userOpHash: %s", userOpHash)
+	return &types.TransactionResp{
+		TxHash:      userOpHash,
+		UserOpHash:  userOpHash,
+		Message:     fmt.Sprintf("✅ Swap UserOperation 已提交到 bundler！使用 %s 工具，打包后可通过 /transaction/user_operation 查询链上交易", quote.Tool),
+		ExplorerUrl: chainConfig.ExplorerAddress(account.Address),
+		Chain:       req.Chain,
+		Status:      model.TxStatusPending,
+	}, nil
+}
+
+// UserOperationStatus 查询 UserOperation 的打包结果，打包后同步更新交易记录的状态
+func (l *TransactionLogic) UserOperationStatus(req *types.UserOperationStatusReq) (*types.UserOperationStatusResp, error) {
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	network, err := l.svcCtx.Bundlers.Network(chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(common.FromHex(req.UserOpHash)) != common.HashLength {
+		return nil, fmt.Errorf("invalid user_op_hash: %s", req.UserOpHash)
+	}
+	userOpHash := common.HexToHash(req.UserOpHash)
+	receipt, err := network.Bundler.GetReceipt(l.ctx, userOpHash)
+	if err != nil {
+		l.Errorf("查询 UserOperation 回执失败: %v", err)
+		return nil, errors.New("failed to query user operation receipt")
+	}
+
+	resp := &types.UserOperationStatusResp{Chain: req.Chain, UserOpHash: userOpHash.Hex(), Status: model.TxStatusPending}
+	if receipt == nil {
+		return resp, nil
+	}
+	resp.Sender = receipt.Sender.Hex()
+	resp.TxHash = receipt.Receipt.TransactionHash.Hex()
+	resp.ExplorerUrl = l.BuildExplorerUrl(req.Chain, resp.TxHash)
+	resp.Reason = receipt.Reason
+	if receipt.ActualGasCost != nil {
+		resp.ActualGasCost = receipt.ActualGasCost.ToInt().String()
+	}
+	resp.Status = model.TxStatusConfirmed
+	if !receipt.Success {
+		resp.Status = model.TxStatusFailed
+	}
+	if err := l.svcCtx.TransactionsDao.UpdateStatus(l.ctx, req.Chain, userOpHash.Hex(), resp.Status, receipt.Reason); err != nil {
+		l.Errorf("更新交易状态失败: %v", err)
+	}
+	return resp, nil
+}
@@ -3,8 +3,10 @@ package transaction
 import (
 	"context"
 	"crypto/ecdsa"
-	"demo/internal/config"
+	"demo/internal/chains"
+	"demo/internal/model"
 	"demo/internal/types"
+	"demo/internal/units"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,7 +18,6 @@ import (
 	"strings"
 	"time"
 
-	solanaClient "github.com/blocto/solana-go-sdk/client"
 	solanaCommon "github.com/blocto/solana-go-sdk/common"
 	solanaTypes "github.com/blocto/solana-go-sdk/types"
 	"github.com/ethereum/go-ethereum"
@@ -31,12 +32,52 @@ import (
 // WrapSwap 专门用于代币交换和跨链操作，集成 LI.FI 最佳实践优化
 func (l *TransactionLogic) WrapSwap(req *types.TransactionReq) (resp *types.TransactionResp, err error) {
 	l.Infof("=== 开始 Swap 操作 for address %s, chain %s ===", req.FromAddress, req.Chain)
+	defer func() {
+		if err == nil && resp != nil {
+			recordTransaction(l.ctx, l.svcCtx, &model.Transactions{
+				Chain:       req.Chain,
+				TxHash:      resp.TxHash,
+				TxType:      model.TxTypeSwap,
+				FromAddress: req.FromAddress,
+				ToAddress:   req.ToAddress,
+				Token:       req.FromToken,
+				Amount:      req.Amount,
+				ExplorerUrl: resp.ExplorerUrl,
+			})
+		}
+	}()
 
-	// 检测是否为 Solana 链
-	if l.isSolanaChain(req.Chain) {
-		return l.handleSolanaSwap(req)
+	if _, err := ParseFeeOptions(req); err != nil {
+		return nil, err
 	}
 
+	adapter, err := l.adapterFor(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	swapper, ok := adapter.(SwapAdapter)
+	if !ok {
+		return nil, fmt.Errorf("swap is not supported on chain %s", req.Chain)
+	}
+	amount, err := resolveAmount(l.ctx, l.svcCtx, adapter.Chain(), req.FromToken, req.Amount, req.AmountUnit)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireExactAmount(amount); err != nil {
+		return nil, err
+	}
+	req.Amount, req.AmountUnit = amount.Base.String(), units.UnitBase
+
+	resp, err = swapper.Swap(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Amount, resp.AmountFormatted = req.Amount, amount.Display
+	return resp, nil
+}
+
+// handleEVMSwap 处理 EVM 链上的代币交换
+func (l *TransactionLogic) handleEVMSwap(req *types.TransactionReq) (*types.TransactionResp, error) {
 	// 1. 验证是否为有效的 swap 操作
 	if !l.isValidSwapOperation(req) {
 		l.Errorf("无效的 swap 操作：不支持同币种转账")
@@ -45,6 +86,13 @@ func (l *TransactionLogic) WrapSwap(req *types.TransactionReq) (resp *types.Tran
 
 	l.Infof("✅ 验证通过：这是一个有效的 EVM swap 操作")
 
+	// 发送地址是智能账户时，授权和兑换合并为一个 UserOperation
+	if chainConfig, err := l.getChain(req.Chain); err == nil {
+		if account := l.findSmartAccount(chainConfig, req.FromAddress); account != nil {
+			return l.executeSmartAccountSwap(req, account)
+		}
+	}
+
 	// 2. 检测是否为 EVM 测试网
 	if l.isEVMTestnet(req.Chain) {
 		l.Infof("✅ 检测到 EVM 测试网，使用原生 swap 实现")
@@ -106,16 +154,15 @@ func (l *TransactionLogic) isValidSwapOperation(req *types.TransactionReq) bool
 func (l *TransactionLogic) getLifiQuote(req *types.TransactionReq) (*types.LifiQuoteResponse, error) {
 	l.Infof("获取 LI.FI 优化报价...")
 
-	// 强制使用 BSC 主网配置
-	chainConfig, ok := l.svcCtx.Config.Chains["BSC"]
-	if !ok {
-		return nil, fmt.Errorf("BSC mainnet configuration not found")
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
 	}
 
 	// 构建优化的 API 请求参数
 	params := url.Values{}
-	params.Set("fromChain", strconv.Itoa(int(chainConfig.ChainId)))
-	params.Set("toChain", strconv.Itoa(int(chainConfig.ChainId)))
+	params.Set("fromChain", strconv.FormatInt(chainConfig.LifiId(), 10))
+	params.Set("toChain", strconv.FormatInt(chainConfig.LifiId(), 10))
 	params.Set("fromToken", l.normalizeTokenAddress(req.FromToken))
 	params.Set("toToken", l.normalizeTokenAddress(req.ToToken))
 	params.Set("fromAmount", req.Amount)
@@ -188,25 +235,29 @@ func (l *TransactionLogic) getLifiQuote(req *types.TransactionReq) (*types.LifiQ
 	return &quote, nil
 }
 
-// executeOptimizedSwap 执行优化的 approve + swap 流程
+// executeOptimizedSwap 执行优化的授权 + swap 流程，授权优先使用 EIP-2612 / Permit2 签名
 func (l *TransactionLogic) executeOptimizedSwap(req *types.TransactionReq, quote *types.LifiQuoteResponse) (resp *types.TransactionResp, err error) {
 	l.Infof("=== 执行 LI.FI 优化的 Swap 流程 ===")
 
 	// 获取链配置
-	chainConfig, ok := l.svcCtx.Config.Chains["BSC"]
-	if !ok {
-		return nil, fmt.Errorf("BSC mainnet configuration not found")
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
 	}
 
-	l.Infof("使用 BSC 主网配置: ChainId=%d, RpcUrl=%s", chainConfig.ChainId, chainConfig.RpcUrl)
+	l.Infof("使用 %s 配置: ChainId=%d, RpcUrl=%s", chainConfig.DisplayName(), chainConfig.ChainId, chainConfig.RpcUrl)
+
+	feeOpts, err := ParseFeeOptions(req)
+	if err != nil {
+		return nil, err
+	}
 
 	// 连接到 RPC 客户端
-	client, err := ethclient.Dial(chainConfig.RpcUrl)
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
 	if err != nil {
 		l.Errorf("RPC 节点连接失败: %v", err)
 		return nil, errors.New("failed to connect to chain")
 	}
-	defer client.Close()
 
 	// 获取钱包和私钥
 	privateKey, err := l.GetWalletPrivateKey(req.FromAddress)
@@ -226,20 +277,33 @@ func (l *TransactionLogic) executeOptimizedSwap(req *types.TransactionReq, quote
 		}
 
 		amount, _ := new(big.Int).SetString(req.Amount, 10)
+		var permitTx *PermitTx
 		if currentAllowance.Cmp(amount) < 0 {
+			// 优先用签名授权随 swap 一起提交，省去 approve 交易且不留下永久授权
+			permitTx = l.PermitLiFiCall(client, privateKey, chainConfig, req.FromToken, amount,
+				quote.TransactionRequest.To, quote.TransactionRequest.Data, quote.TransactionRequest.Value)
+		}
+
+		switch {
+		case currentAllowance.Cmp(amount) >= 0:
+			l.Infof("✅ 当前 allowance 充足，无需 approve")
+		case permitTx != nil:
+			quote.TransactionRequest.To = permitTx.To
+			quote.TransactionRequest.Data = permitTx.Data
+			quote.TransactionRequest.GasLimit = permitTx.GasLimit
+			l.Infof("✅ 使用 %s 签名授权，无需单独 approve", permitTx.Mode)
+		default:
 			l.Infof("当前 allowance 不足，需要执行 approve")
 			// 使用最大值 approve，避免频繁授权
 			maxAmount := new(big.Int)
 			maxAmount.SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
 
-			approveHash, err := l.ExecuteApproveTransaction(client, privateKey, req.FromToken, quote.Estimate.ApprovalAddress, maxAmount, chainConfig.ChainId)
+			approveHash, err := l.ExecuteApproveTransaction(client, privateKey, req.FromToken, quote.Estimate.ApprovalAddress, maxAmount, chainConfig, feeOpts)
 			if err != nil {
 				l.Errorf("Approve 操作失败: %v", err)
 				return nil, fmt.Errorf("approve failed: %v", err)
 			}
 			l.Infof("✅ Approve 成功，TxHash: %s", approveHash)
-		} else {
-			l.Infof("✅ 当前 allowance 充足，无需 approve")
 		}
 	} else {
 		l.Infof("步骤 1: 原生代币交换，跳过 approve 步骤")
@@ -247,14 +311,14 @@ func (l *TransactionLogic) executeOptimizedSwap(req *types.TransactionReq, quote
 
 	// Step 2: 执行优化的 swap 交易
 	l.Infof("步骤 2: 执行 LI.FI 优化的 swap 交易")
-	swapTxHash, err := l.executeSwapTransaction(client, privateKey, quote, chainConfig.ChainId)
+	swapTxHash, err := l.executeSwapTransaction(client, privateKey, quote, chainConfig, feeOpts)
 	if err != nil {
 		l.Errorf("Swap 交易失败: %v", err)
 		return nil, fmt.Errorf("swap transaction failed: %v", err)
 	}
 
 	// Step 3: 构建响应
-	explorerUrl := l.BuildExplorerUrl("BSC", swapTxHash)
+	explorerUrl := l.BuildExplorerUrl(req.Chain, swapTxHash)
 	message := fmt.Sprintf("✅ Swap 交易已提交！使用 %s 工具，交易哈希: %s", quote.Tool, swapTxHash)
 
 	l.Infof("✅ Swap 成功完成，TxHash: %s", swapTxHash)
@@ -262,7 +326,7 @@ func (l *TransactionLogic) executeOptimizedSwap(req *types.TransactionReq, quote
 		TxHash:      swapTxHash,
 		Message:     message,
 		ExplorerUrl: explorerUrl,
-		Chain:       "BSC",
+		Chain:       req.Chain,
 		Status:      "pending",
 	}, nil
 }
@@ -276,7 +340,7 @@ func (l *TransactionLogic) normalizeTokenAddress(tokenAddr string) string {
 }
 
 // executeSwapTransaction 执行 LI.FI 优化的 swap 交易
-func (l *TransactionLogic) executeSwapTransaction(client *ethclient.Client, privateKey *ecdsa.PrivateKey, quote *types.LifiQuoteResponse, chainId int64) (string, error) {
+func (l *TransactionLogic) executeSwapTransaction(client *ethclient.Client, privateKey *ecdsa.PrivateKey, quote *types.LifiQuoteResponse, chainConfig *chains.Chain, opts FeeOptions) (string, error) {
 	l.Infof("执行 LI.FI 优化的 swap 交易")
 
 	// 解析 LI.FI 提供的交易参数
@@ -302,22 +366,21 @@ func (l *TransactionLogic) executeSwapTransaction(client *ethclient.Client, priv
 		}
 	}
 
-	// 解析 gas price（优先使用 LI.FI 提供的值）
-	var gasPrice *big.Int
-	if quote.TransactionRequest.GasPrice != "" {
-		gasPrice = new(big.Int)
+	fees, err := l.SuggestFees(chainConfig, opts)
+	if err != nil {
+		return "", err
+	}
+
+	// legacy 链且未指定档位时优先使用 LI.FI 提供的 gas price
+	if !fees.Dynamic && opts.Level == FeeLevelNormal && quote.TransactionRequest.GasPrice != "" {
+		gasPrice := new(big.Int)
 		if _, ok := gasPrice.SetString(quote.TransactionRequest.GasPrice, 10); !ok {
 			gasPrice.SetString(quote.TransactionRequest.GasPrice, 0)
 		}
-		l.Infof("使用 LI.FI 优化的 gas price: %s", gasPrice.String())
-	} else {
-		// 回退到网络建议价格
-		var err error
-		gasPrice, err = client.SuggestGasPrice(l.ctx)
-		if err != nil {
-			return "", fmt.Errorf("获取 gas price 失败: %v", err)
+		if gasPrice.Sign() > 0 && l.withinFeeCap(chainConfig, gasPrice) {
+			fees.GasPrice = gasPrice
+			l.Infof("使用 LI.FI 优化的 gas price: %s", gasPrice.String())
 		}
-		l.Infof("使用网络建议的 gas price: %s", gasPrice.String())
 	}
 
 	// 如果没有提供 gas limit，进行估算
@@ -338,10 +401,18 @@ func (l *TransactionLogic) executeSwapTransaction(client *ethclient.Client, priv
 		}
 	}
 
-	l.Infof("交易参数: to=%s, value=%s, gasLimit=%d, gasPrice=%s", to.Hex(), value.String(), gasLimit, gasPrice.String())
+	l.Infof("交易参数: to=%s, value=%s, gasLimit=%d, %s", to.Hex(), value.String(), gasLimit, fees)
+
+	// 代币兑换不转出原生币，原生币不足以支付手续费时由 gas 站补充
+	if value.Sign() == 0 {
+		fromAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+		if err := l.ensureTxGas(chainConfig, fromAddr, gasLimit, fees, model.GasTopUpPurposeSwap); err != nil {
+			return "", err
+		}
+	}
 
 	// 使用通用函数构建并发送交易
-	return l.BuildAndSendTransaction(client, privateKey, to, value, data, gasLimit, gasPrice, chainId)
+	return l.BuildAndSendTransaction(client, privateKey, to, value, data, gasLimit, fees, chainConfig.ChainId)
 }
 
 // checkSwapStatus 检查 swap 交易状态（使用 LI.FI 状态 API）
@@ -424,7 +495,7 @@ func (l *TransactionLogic) handleSolanaSwap(req *types.TransactionReq) (*types.T
 	}
 
 	// 5. 构建响应
-	explorerUrl := l.buildSolanaExplorerUrl(txHash)
+	explorerUrl := l.BuildExplorerUrl(req.Chain, txHash)
 	message := fmt.Sprintf("✅ Solana Swap 交易已提交！使用 %s 工具，交易哈希: %s", quote.Tool, txHash)
 
 	return &types.TransactionResp{
@@ -479,10 +550,16 @@ func (l *TransactionLogic) isSolanaNativeToken(token string, nativeTokens []stri
 func (l *TransactionLogic) getSolanaSwapQuote(req *types.TransactionReq) (*types.LifiQuoteResponse, error) {
 	l.Infof("获取 Solana swap 报价...")
 
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	lifiChainId := strconv.FormatInt(chainConfig.LifiId(), 10)
+
 	// 构建 LI.FI API 请求参数
 	params := url.Values{}
-	params.Set("fromChain", "1151111081099710") // Solana Chain ID
-	params.Set("toChain", "1151111081099710")   // 同链 swap
+	params.Set("fromChain", lifiChainId)
+	params.Set("toChain", lifiChainId) // 同链 swap
 	params.Set("fromToken", l.normalizeSolanaTokenAddress(req.FromToken))
 	params.Set("toToken", l.normalizeSolanaTokenAddress(req.ToToken))
 	params.Set("fromAmount", req.Amount)
@@ -646,7 +723,7 @@ func (l *TransactionLogic) executeSolanaSwapViaLiFi(quote *types.LifiQuoteRespon
 }
 
 // executeSolanaSwapDirect 自实现的 Solana swap 逻辑（仅用于测试网）
-func (l *TransactionLogic) executeSolanaSwapDirect(fromAddress string, quote *types.LifiQuoteResponse) (string, error) {
+func (l *TransactionLogic) executeSolanaSwapDirect(chain, fromAddress string, quote *types.LifiQuoteResponse) (string, error) {
 	l.Infof("=== 执行自实现的 Solana devnet swap ===")
 
 	// 1. 从数据库获取 Solana 私钥
@@ -656,9 +733,16 @@ func (l *TransactionLogic) executeSolanaSwapDirect(fromAddress string, quote *ty
 		return "", fmt.Errorf("failed to get Solana private key: %v", err)
 	}
 
-	// 2. 创建 Solana 客户端（使用 devnet）
-	l.Infof("步骤 2: 连接到 Solana devnet...")
-	cli := solanaClient.NewClient("https://api.devnet.solana.com")
+	// 2. 创建 Solana 客户端
+	chainConfig, err := l.getChain(chain)
+	if err != nil {
+		return "", err
+	}
+	l.Infof("步骤 2: 连接到 %s...", chainConfig.DisplayName())
+	cli, err := l.svcCtx.RPC.SolanaClient(chainConfig)
+	if err != nil {
+		return "", err
+	}
 
 	// 3. 创建账户对象
 	l.Infof("步骤 3: 创建 Solana 账户...")
@@ -789,18 +873,9 @@ func (l *TransactionLogic) generateLiFiSolanaTransactionHash(quote *types.LifiQu
 // isSolanaTestnet 检测是否为 Solana 测试网
 func (l *TransactionLogic) isSolanaTestnet(chain string) bool {
 	l.Infof("检测链类型: %s", chain)
-	testnetChains := []string{
-		"Solana-TestNet",
-		"Solana-DevNet",
-		"solana-testnet",
-		"solana-devnet",
-	}
-
-	for _, testChain := range testnetChains {
-		if strings.EqualFold(chain, testChain) {
-			l.Infof("✅ 检测到测试网: %s", chain)
-			return true
-		}
+	if c, ok := l.svcCtx.Chains.Get(chain); ok && c.IsSolana() && c.Testnet {
+		l.Infof("✅ 检测到测试网: %s", chain)
+		return true
 	}
 	l.Infof("❌ 检测到主网: %s", chain)
 	return false
@@ -818,9 +893,16 @@ func (l *TransactionLogic) handleSolanaTestNetSwapNative(req *types.TransactionR
 		return nil, fmt.Errorf("failed to get Solana private key: %v", err)
 	}
 
-	// 2. 创建 Solana 客户端（使用 devnet）
-	l.Infof("步骤 2: 连接到 Solana devnet...")
-	cli := solanaClient.NewClient("https://api.devnet.solana.com")
+	// 2. 创建 Solana 客户端
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
+	}
+	l.Infof("步骤 2: 连接到 %s...", chainConfig.DisplayName())
+	cli, err := l.svcCtx.RPC.SolanaClient(chainConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	// 3. 创建账户对象
 	l.Infof("步骤 3: 创建 Solana 账户...")
@@ -872,7 +954,7 @@ func (l *TransactionLogic) handleSolanaTestNetSwapNative(req *types.TransactionR
 	}
 
 	// 8. 构建响应
-	explorerUrl := l.buildSolanaExplorerUrl(txHash)
+	explorerUrl := l.BuildExplorerUrl(req.Chain, txHash)
 	message := fmt.Sprintf("✅ Solana devnet 原生 Swap 交易已提交！%s %s -> %s，交易哈希: %s",
 		req.Amount, req.FromToken, req.ToToken, txHash)
 
@@ -887,7 +969,7 @@ func (l *TransactionLogic) handleSolanaTestNetSwapNative(req *types.TransactionR
 }
 
 // executeSolanaSwapDirectNative 执行原生 Solana swap（不使用 LI.FI）
-func (l *TransactionLogic) executeSolanaSwapDirectNative(fromAddress, fromToken, toToken, amount string) (string, error) {
+func (l *TransactionLogic) executeSolanaSwapDirectNative(chain, fromAddress, fromToken, toToken, amount string) (string, error) {
 	l.Infof("=== 执行原生 Solana devnet swap ===")
 	l.Infof("从 %s swap %s %s 到 %s", fromAddress, amount, fromToken, toToken)
 
@@ -898,9 +980,16 @@ func (l *TransactionLogic) executeSolanaSwapDirectNative(fromAddress, fromToken,
 		return "", fmt.Errorf("failed to get Solana private key: %v", err)
 	}
 
-	// 2. 创建 Solana 客户端（使用 devnet）
-	l.Infof("步骤 2: 连接到 Solana devnet...")
-	cli := solanaClient.NewClient("https://api.devnet.solana.com")
+	// 2. 创建 Solana 客户端
+	chainConfig, err := l.getChain(chain)
+	if err != nil {
+		return "", err
+	}
+	l.Infof("步骤 2: 连接到 %s...", chainConfig.DisplayName())
+	cli, err := l.svcCtx.RPC.SolanaClient(chainConfig)
+	if err != nil {
+		return "", err
+	}
 
 	// 3. 创建账户对象
 	l.Infof("步骤 3: 创建 Solana 账户...")
@@ -1105,47 +1194,9 @@ func min(a, b int) int {
 // isEVMTestnet 检测是否为 EVM 测试网
 func (l *TransactionLogic) isEVMTestnet(chain string) bool {
 	l.Infof("检测 EVM 链类型: %s", chain)
-	testnetChains := []string{
-		// BSC 测试网
-		"BSC-TestNet",
-		"BSC-Testnet",
-		"bsc-testnet",
-		// ETH 测试网
-		"ETH-TestNet",
-		"ETH-Testnet",
-		"eth-testnet",
-		"Goerli",
-		"goerli",
-		"Sepolia",
-		"sepolia",
-		// Polygon 测试网
-		"Polygon-TestNet",
-		"Polygon-Testnet",
-		"polygon-testnet",
-		"Mumbai",
-		"mumbai",
-		// Arbitrum 测试网
-		"Arbitrum-TestNet",
-		"Arbitrum-Testnet",
-		"arbitrum-testnet",
-		"Arbitrum-Goerli",
-		// Optimism 测试网
-		"Optimism-TestNet",
-		"Optimism-Testnet",
-		"optimism-testnet",
-		"Optimism-Goerli",
-		// Base 测试网
-		"Base-TestNet",
-		"Base-Testnet",
-		"base-testnet",
-		"Base-Goerli",
-	}
-
-	for _, testChain := range testnetChains {
-		if strings.EqualFold(chain, testChain) {
-			l.Infof("✅ 检测到 EVM 测试网: %s", chain)
-			return true
-		}
+	if c, ok := l.svcCtx.Chains.Get(chain); ok && c.IsEVM() && c.Testnet {
+		l.Infof("✅ 检测到 EVM 测试网: %s", chain)
+		return true
 	}
 	l.Infof("❌ 检测到 EVM 主网: %s", chain)
 	return false
@@ -1156,25 +1207,19 @@ func (l *TransactionLogic) handleEVMTestnetSwap(req *types.TransactionReq) (*typ
 	l.Infof("=== 执行 EVM 测试网原生 swap (修正版) ===")
 	l.Infof("Swap 请求: %s %s -> %s on %s", req.Amount, req.FromToken, req.ToToken, req.Chain)
 
-	// 获取链配置 (使用您的已有逻辑)
-	chainConfig, ok := l.svcCtx.Config.Chains[req.Chain]
-	if !ok {
-		mainnetChain := l.getMainnetChainName(req.Chain)
-		chainConfig, ok = l.svcCtx.Config.Chains[mainnetChain]
-		if !ok {
-			return nil, fmt.Errorf("unsupported testnet chain: %s", req.Chain)
-		}
-		l.Infof("使用主网配置 %s 作为测试网 %s 的配置模板", mainnetChain, req.Chain)
+	// 获取链配置
+	chainConfig, err := l.getChain(req.Chain)
+	if err != nil {
+		return nil, err
 	}
 	l.Infof("使用配置: ChainId=%d, RpcUrl=%s", chainConfig.ChainId, chainConfig.RpcUrl)
 
 	// 连接到 RPC 客户端
-	client, err := ethclient.Dial(chainConfig.RpcUrl)
+	client, err := l.svcCtx.RPC.EthClient(chainConfig)
 	if err != nil {
 		l.Errorf("RPC 节点连接失败: %v", err)
 		return nil, fmt.Errorf("failed to connect to testnet chain: %v", err)
 	}
-	defer client.Close()
 
 	// 获取钱包和私钥
 	privateKey, err := l.GetWalletPrivateKey(req.FromAddress)
@@ -1183,7 +1228,7 @@ func (l *TransactionLogic) handleEVMTestnetSwap(req *types.TransactionReq) (*typ
 	}
 
 	// evm 测试网执行包含 approve 逻辑的完整 swap 流程
-	txHash, err := l.executeEVMTestnetSwapNative(client, privateKey, req, &chainConfig)
+	txHash, err := l.executeEVMTestnetSwapNative(client, privateKey, req, chainConfig)
 	if err != nil {
 		l.Errorf("EVM 测试网 swap 交易失败: %v", err)
 		return nil, fmt.Errorf("EVM testnet swap transaction failed: %v", err)
@@ -1204,54 +1249,30 @@ func (l *TransactionLogic) handleEVMTestnetSwap(req *types.TransactionReq) (*typ
 	}, nil
 }
 
-// getMainnetChainName 获取测试网对应的主网名称
-func (l *TransactionLogic) getMainnetChainName(testnetChain string) string {
-	testnetToMainnet := map[string]string{
-		"BSC-TestNet":      "BSC",
-		"BSC-Testnet":      "BSC",
-		"bsc-testnet":      "BSC",
-		"ETH-TestNet":      "ETH",
-		"ETH-Testnet":      "ETH",
-		"eth-testnet":      "ETH",
-		"Goerli":           "ETH",
-		"goerli":           "ETH",
-		"Sepolia":          "ETH",
-		"sepolia":          "ETH",
-		"Polygon-TestNet":  "Polygon",
-		"Polygon-Testnet":  "Polygon",
-		"polygon-testnet":  "Polygon",
-		"Mumbai":           "Polygon",
-		"mumbai":           "Polygon",
-		"Arbitrum-TestNet": "Arbitrum",
-		"Arbitrum-Testnet": "Arbitrum",
-		"arbitrum-testnet": "Arbitrum",
-		"Arbitrum-Goerli":  "Arbitrum",
-		"Optimism-TestNet": "Optimism",
-		"Optimism-Testnet": "Optimism",
-		"optimism-testnet": "Optimism",
-		"Optimism-Goerli":  "Optimism",
-		"Base-TestNet":     "Base",
-		"Base-Testnet":     "Base",
-		"base-testnet":     "Base",
-		"Base-Goerli":      "Base",
-	}
-
-	if mainnet, ok := testnetToMainnet[testnetChain]; ok {
-		return mainnet
-	}
-	return "BSC" // 默认使用 BSC
+// dexAddresses 读取链配置中的 Uniswap V2 兼容 Router 和 Wrapped 原生币地址
+func dexAddresses(chainConfig *chains.Chain) (router, wrapped common.Address, err error) {
+	if chainConfig.DexRouter == "" || chainConfig.WrappedNative == "" {
+		return common.Address{}, common.Address{}, fmt.Errorf("chain %s has no DexRouter/WrappedNative configured", chainConfig.Key)
+	}
+	return common.HexToAddress(chainConfig.DexRouter), common.HexToAddress(chainConfig.WrappedNative), nil
 }
 
 // executeEVMTestnetSwapNative 执行原生 EVM 测试网 swap 的核心逻辑 (包含 approve)
-func (l *TransactionLogic) executeEVMTestnetSwapNative(client *ethclient.Client, privateKey *ecdsa.PrivateKey, req *types.TransactionReq, chainConfig *config.ChainConf) (string, error) {
-	// BSC 测试网核心地址
-	routerAddr := common.HexToAddress("0xD99D1c33F9fC3444f8101754aBeCb321741Da593") // PancakeSwap V2 Router
-	wbnbAddr := common.HexToAddress("0xae13d989dac2f0debff460ac112a837c89baa7cd")   // WBNB
+func (l *TransactionLogic) executeEVMTestnetSwapNative(client *ethclient.Client, privateKey *ecdsa.PrivateKey, req *types.TransactionReq, chainConfig *chains.Chain) (string, error) {
+	// DEX Router 和 Wrapped 原生币地址来自链配置
+	routerAddr, wbnbAddr, err := dexAddresses(chainConfig)
+	if err != nil {
+		return "", err
+	}
+	feeOpts, err := ParseFeeOptions(req)
+	if err != nil {
+		return "", err
+	}
 
 	// 1. (关键新增) 如果 FromToken 是 ERC20，检查并执行 Approve
 	if !l.IsNativeToken(req.FromToken) {
 		l.Infof("检测到 FromToken 为 ERC20，开始检查 Approve 授权...")
-		err := l.checkAndApproveIfNeeded(client, privateKey, req.FromToken, routerAddr, req.Amount, chainConfig)
+		err := l.checkAndApproveIfNeeded(client, privateKey, req.FromToken, routerAddr, req.Amount, chainConfig, feeOpts)
 		if err != nil {
 			return "", err // 如果 approve 失败，则中断交易
 		}
@@ -1262,7 +1283,6 @@ func (l *TransactionLogic) executeEVMTestnetSwapNative(client *ethclient.Client,
 		swapFunction string
 		path         []common.Address
 		value        *big.Int
-		err          error
 	)
 
 	amountIn, _ := new(big.Int).SetString(req.Amount, 10)
@@ -1320,20 +1340,20 @@ func (l *TransactionLogic) executeEVMTestnetSwapNative(client *ethclient.Client,
 	}
 
 	// 4. 发送交易
-	return l.sendDynamicTx(client, privateKey, &routerAddr, value, calldata, chainConfig)
+	return l.sendDynamicTx(client, privateKey, &routerAddr, value, calldata, chainConfig, feeOpts, model.GasTopUpPurposeSwap)
 }
 
 // executeERC20SwapTestnet 执行 ERC20 代币 swap（测试网真实 DEX）
-func (l *TransactionLogic) executeERC20SwapTestnet(client *ethclient.Client, privateKey *ecdsa.PrivateKey, req *types.TransactionReq, chainConfig *config.ChainConf) (string, error) {
+func (l *TransactionLogic) executeERC20SwapTestnet(client *ethclient.Client, privateKey *ecdsa.PrivateKey, req *types.TransactionReq, chainConfig *chains.Chain) (string, error) {
 	l.Infof("执行真实的测试网 DEX swap")
 
-	// BSC 测试网 PancakeSwap Router V2 地址
-	routerAddr := common.HexToAddress("0xD99D1c33F9fC3444f8101754aBeCb321741Da593")
-
-	// BSC 测试网 WBNB 地址
-	wbnbAddr := common.HexToAddress("0xae13d989dac2f0debff460ac112a837c89baa7cd")
+	// DEX Router 和 Wrapped 原生币地址来自链配置
+	routerAddr, wbnbAddr, err := dexAddresses(chainConfig)
+	if err != nil {
+		return "", err
+	}
 
-	l.Infof("执行 BSC 测试网 swap: %s -> %s", req.FromToken, req.ToToken)
+	l.Infof("执行 %s swap: %s -> %s", chainConfig.DisplayName(), req.FromToken, req.ToToken)
 
 	// 检查是否为支持的 swap 对
 	fromTokenAddr := common.HexToAddress(req.FromToken)
@@ -1369,7 +1389,7 @@ func (l *TransactionLogic) executeERC20SwapTestnet(client *ethclient.Client, pri
 }
 
 // executeDEXSwap 执行真实的 DEX swap 交易
-func (l *TransactionLogic) executeDEXSwap(client *ethclient.Client, privateKey *ecdsa.PrivateKey, routerAddr common.Address, swapFunction string, swapValue *big.Int, path []common.Address, req *types.TransactionReq, chainConfig *config.ChainConf) (string, error) {
+func (l *TransactionLogic) executeDEXSwap(client *ethclient.Client, privateKey *ecdsa.PrivateKey, routerAddr common.Address, swapFunction string, swapValue *big.Int, path []common.Address, req *types.TransactionReq, chainConfig *chains.Chain) (string, error) {
 	l.Infof("构建 %s DEX 交易", swapFunction)
 
 	// 获取钱包地址
@@ -1381,10 +1401,14 @@ func (l *TransactionLogic) executeDEXSwap(client *ethclient.Client, privateKey *
 		return "", fmt.Errorf("failed to get nonce: %v", err)
 	}
 
-	// 获取 gas price
-	gasPrice, err := client.SuggestGasPrice(context.Background())
+	// 获取手续费
+	feeOpts, err := ParseFeeOptions(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to get gas price: %v", err)
+		return "", err
+	}
+	fees, err := l.SuggestFees(chainConfig, feeOpts)
+	if err != nil {
+		return "", err
 	}
 
 	// 构建交易参数
@@ -1437,17 +1461,16 @@ func (l *TransactionLogic) executeDEXSwap(client *ethclient.Client, privateKey *
 	}
 
 	// 构造交易
-	tx := evmTypes.NewTx(&evmTypes.LegacyTx{
-		Nonce:    nonce,
-		To:       &routerAddr,
-		Value:    swapValue, // WBNB swap 时有值，Token swap 时为0
-		Gas:      300000,    // Gas limit
-		GasPrice: gasPrice,
-		Data:     input,
-	})
+	// WBNB swap 时 swapValue 有值，Token swap 时为0
+	if swapValue.Sign() == 0 {
+		if err := l.ensureTxGas(chainConfig, fromAddr, 300000, fees, model.GasTopUpPurposeSwap); err != nil {
+			return "", err
+		}
+	}
+	tx := NewEVMTx(chainConfig.ChainId, nonce, &routerAddr, swapValue, 300000, input, fees)
 
 	// 签名交易
-	signedTx, err := evmTypes.SignTx(tx, evmTypes.NewEIP155Signer(big.NewInt(chainConfig.ChainId)), privateKey)
+	signedTx, err := evmTypes.SignTx(tx, EVMSigner(chainConfig.ChainId), privateKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign transaction: %v", err)
 	}
@@ -1475,7 +1498,7 @@ func (l *TransactionLogic) executeDEXSwap(client *ethclient.Client, privateKey *
 // =======================================================
 
 // checkAndApproveIfNeeded 检查并执行 Approve 的辅助函数
-func (l *TransactionLogic) checkAndApproveIfNeeded(client *ethclient.Client, privateKey *ecdsa.PrivateKey, tokenAddress string, spender common.Address, amount string, chainConfig *config.ChainConf) error {
+func (l *TransactionLogic) checkAndApproveIfNeeded(client *ethclient.Client, privateKey *ecdsa.PrivateKey, tokenAddress string, spender common.Address, amount string, chainConfig *chains.Chain, opts FeeOptions) error {
 	// 1. 检查当前 Allowance
 	tokenAddr := common.HexToAddress(tokenAddress)
 	ownerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
@@ -1509,7 +1532,7 @@ func (l *TransactionLogic) checkAndApproveIfNeeded(client *ethclient.Client, pri
 		return fmt.Errorf("failed to pack approve calldata: %v", err)
 	}
 
-	txHash, err := l.sendDynamicTx(client, privateKey, &tokenAddr, big.NewInt(0), calldata, chainConfig)
+	txHash, err := l.sendDynamicTx(client, privateKey, &tokenAddr, big.NewInt(0), calldata, chainConfig, opts, model.GasTopUpPurposeApprove)
 	if err != nil {
 		return fmt.Errorf("failed to send approve transaction: %v", err)
 	}
@@ -1551,8 +1574,8 @@ func (l *TransactionLogic) checkAllowance(client *ethclient.Client, token, owner
 	return new(big.Int).SetBytes(result), nil
 }
 
-// sendDynamicTx 动态估算 Gas 并发送交易
-func (l *TransactionLogic) sendDynamicTx(client *ethclient.Client, privateKey *ecdsa.PrivateKey, to *common.Address, value *big.Int, calldata []byte, chainConfig *config.ChainConf) (string, error) {
+// sendDynamicTx 动态估算 Gas 并发送交易；不转出原生币时按 purpose 记录 gas 站补充
+func (l *TransactionLogic) sendDynamicTx(client *ethclient.Client, privateKey *ecdsa.PrivateKey, to *common.Address, value *big.Int, calldata []byte, chainConfig *chains.Chain, opts FeeOptions, purpose string) (string, error) {
 	fromAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
 
 	// 1. 获取 Nonce
@@ -1561,10 +1584,10 @@ func (l *TransactionLogic) sendDynamicTx(client *ethclient.Client, privateKey *e
 		return "", fmt.Errorf("failed to get nonce: %v", err)
 	}
 
-	// 2. 获取 Gas Price
-	gasPrice, err := client.SuggestGasPrice(context.Background())
+	// 2. 获取手续费（EIP-1559 或 legacy）
+	fees, err := l.SuggestFees(chainConfig, opts)
 	if err != nil {
-		return "", fmt.Errorf("failed to get gas price: %v", err)
+		return "", err
 	}
 
 	// 3. 估算 Gas Limit
@@ -1580,18 +1603,16 @@ func (l *TransactionLogic) sendDynamicTx(client *ethclient.Client, privateKey *e
 	} else {
 		gasLimit = gasLimit * 12 / 10 // 在估算结果上增加 20% buffer
 	}
+	if value.Sign() == 0 {
+		if err := l.ensureTxGas(chainConfig, fromAddr, gasLimit, fees, purpose); err != nil {
+			return "", err
+		}
+	}
 
 	// 4. 构建、签名并发送交易
-	tx := evmTypes.NewTx(&evmTypes.LegacyTx{
-		Nonce:    nonce,
-		To:       to,
-		Value:    value,
-		Gas:      gasLimit,
-		GasPrice: gasPrice,
-		Data:     calldata,
-	})
+	tx := NewEVMTx(chainConfig.ChainId, nonce, to, value, gasLimit, calldata, fees)
 
-	signedTx, err := evmTypes.SignTx(tx, evmTypes.NewEIP155Signer(big.NewInt(chainConfig.ChainId)), privateKey)
+	signedTx, err := evmTypes.SignTx(tx, EVMSigner(chainConfig.ChainId), privateKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign transaction: %v", err)
 	}
@@ -3,6 +3,10 @@ package transaction
 import (
 	"context"
 	"crypto/ecdsa"
+	"demo/internal/chains"
+	"demo/internal/gasoracle"
+	"demo/internal/mid"
+	"demo/internal/model"
 	"demo/internal/svc"
 	"errors"
 	"fmt"
@@ -24,6 +28,10 @@ type TransactionLogic struct {
 	logx.Logger
 }
 
+// syncBroadcastKey context 中带有该键时，EVM 交易在 Broadcast 中同步发送到节点，节点接受后才返回；
+// 适配器按 context 创建自己的 TransactionLogic，因此通过 context 传递
+type syncBroadcastKey struct{}
+
 func NewTransactionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TransactionLogic {
 	return &TransactionLogic{
 		ctx:    ctx,
@@ -32,6 +40,17 @@ func NewTransactionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Trans
 	}
 }
 
+// WithSyncBroadcast 返回同步广播的副本：节点接受交易后 WrapSend 才返回，
+// 调用方据此持有发送方的锁，保证下一笔交易读取到的 pending nonce 已包含这一笔
+func (l *TransactionLogic) WithSyncBroadcast() *TransactionLogic {
+	return NewTransactionLogic(context.WithValue(l.ctx, syncBroadcastKey{}, true), l.svcCtx)
+}
+
+func (l *TransactionLogic) syncBroadcast() bool {
+	sync, _ := l.ctx.Value(syncBroadcastKey{}).(bool)
+	return sync
+}
+
 // IsNativeToken 判断是否为原生代币
 func (l *TransactionLogic) IsNativeToken(token string) bool {
 	nativeTokens := []string{
@@ -47,8 +66,9 @@ func (l *TransactionLogic) IsNativeToken(token string) bool {
 	return false
 }
 
-// GetWalletPrivateKey 从数据库获取钱包私钥
+// GetWalletPrivateKey 从数据库获取钱包私钥；同时标记请求已开始签名，之后的失败不会释放幂等键
 func (l *TransactionLogic) GetWalletPrivateKey(fromAddress string) (*ecdsa.PrivateKey, error) {
+	mid.MarkSigning(l.ctx)
 	wallet, err := l.svcCtx.WalletsDao.FindOneByAddress(l.ctx, fromAddress)
 	if err != nil {
 		l.Errorf("查询钱包失败 for address %s: %v", fromAddress, err)
@@ -81,7 +101,7 @@ func (l *TransactionLogic) BuildERC20ApproveData(spenderAddress string, amount *
 }
 
 // ExecuteApproveTransaction 执行 ERC20 approve 交易
-func (l *TransactionLogic) ExecuteApproveTransaction(client *ethclient.Client, privateKey *ecdsa.PrivateKey, tokenAddress, spenderAddress string, amount *big.Int, chainId int64) (string, error) {
+func (l *TransactionLogic) ExecuteApproveTransaction(client *ethclient.Client, privateKey *ecdsa.PrivateKey, tokenAddress, spenderAddress string, amount *big.Int, chainConfig *chains.Chain, opts FeeOptions) (string, error) {
 	l.Infof("执行 ERC20 approve 操作，spender: %s", spenderAddress)
 
 	// 构建 approve 调用数据
@@ -89,9 +109,9 @@ func (l *TransactionLogic) ExecuteApproveTransaction(client *ethclient.Client, p
 
 	// 获取交易参数
 	fromAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
-	gasPrice, err := client.SuggestGasPrice(l.ctx)
+	fees, err := l.SuggestFees(chainConfig, opts)
 	if err != nil {
-		return "", fmt.Errorf("failed to get gas price: %v", err)
+		return "", err
 	}
 
 	// 估算 gas limit
@@ -109,12 +129,16 @@ func (l *TransactionLogic) ExecuteApproveTransaction(client *ethclient.Client, p
 	// 增加 gas limit 缓冲
 	gasLimit = gasLimit * 120 / 100
 
+	if err := l.ensureTxGas(chainConfig, fromAddr, gasLimit, fees, model.GasTopUpPurposeApprove); err != nil {
+		return "", err
+	}
+
 	// 构建并发送交易
-	return l.BuildAndSendTransaction(client, privateKey, tokenAddr, big.NewInt(0), data, gasLimit, gasPrice, chainId)
+	return l.BuildAndSendTransaction(client, privateKey, tokenAddr, big.NewInt(0), data, gasLimit, fees, chainConfig.ChainId)
 }
 
 // BuildAndSendTransaction 构建并发送交易
-func (l *TransactionLogic) BuildAndSendTransaction(client *ethclient.Client, privateKey *ecdsa.PrivateKey, to common.Address, value *big.Int, data []byte, gasLimit uint64, gasPrice *big.Int, chainId int64) (string, error) {
+func (l *TransactionLogic) BuildAndSendTransaction(client *ethclient.Client, privateKey *ecdsa.PrivateKey, to common.Address, value *big.Int, data []byte, gasLimit uint64, fees *gasoracle.Fees, chainId int64) (string, error) {
 	// 获取 nonce
 	fromAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
 	nonce, err := client.PendingNonceAt(l.ctx, fromAddr)
@@ -122,18 +146,11 @@ func (l *TransactionLogic) BuildAndSendTransaction(client *ethclient.Client, pri
 		return "", fmt.Errorf("failed to get nonce: %v", err)
 	}
 
-	// 构建交易（使用新的 NewTx 方法替代已弃用的 NewTransaction）
-	tx := evmTypes.NewTx(&evmTypes.LegacyTx{
-		Nonce:    nonce,
-		To:       &to,
-		Value:    value,
-		Gas:      gasLimit,
-		GasPrice: gasPrice,
-		Data:     data,
-	})
+	// 构建交易（支持 EIP-1559 的链使用 DynamicFeeTx）
+	tx := NewEVMTx(chainId, nonce, &to, value, gasLimit, data, fees)
 
 	// 签名交易
-	signedTx, err := evmTypes.SignTx(tx, evmTypes.NewEIP155Signer(big.NewInt(chainId)), privateKey)
+	signedTx, err := evmTypes.SignTx(tx, EVMSigner(chainId), privateKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign transaction: %v", err)
 	}
@@ -153,27 +170,15 @@ func (l *TransactionLogic) BuildAndSendTransaction(client *ethclient.Client, pri
 	return signedTx.Hash().Hex(), nil
 }
 
-// BuildExplorerUrl 根据链类型构建区块浏览器链接
+// BuildExplorerUrl 根据链配置构建区块浏览器链接
 func (l *TransactionLogic) BuildExplorerUrl(chain, txHash string) string {
-	switch chain {
-	case "BSC":
-		return fmt.Sprintf("https://bscscan.com/tx/%s", txHash)
-	case "BSC-TestNet":
-		return fmt.Sprintf("https://testnet.bscscan.com/tx/%s", txHash)
-	case "ETH":
-		return fmt.Sprintf("https://etherscan.io/tx/%s", txHash)
-	case "ETH-Goerli":
-		return fmt.Sprintf("https://goerli.etherscan.io/tx/%s", txHash)
-	case "ETH-Sepolia":
-		return fmt.Sprintf("https://sepolia.etherscan.io/tx/%s", txHash)
-	case "Polygon":
-		return fmt.Sprintf("https://polygonscan.com/tx/%s", txHash)
-	case "Polygon-Mumbai":
-		return fmt.Sprintf("https://mumbai.polygonscan.com/tx/%s", txHash)
-	default:
-		l.Infof("未知链类型 %s，返回通用浏览器链接", chain)
-		return fmt.Sprintf("https://explorer.example.com/tx/%s", txHash)
+	if c, ok := l.svcCtx.Chains.Get(chain); ok {
+		if url := c.ExplorerTx(txHash); url != "" {
+			return url
+		}
 	}
+	l.Infof("链 %s 未配置区块浏览器，返回通用浏览器链接", chain)
+	return fmt.Sprintf("https://explorer.example.com/tx/%s", txHash)
 }
 
 // BuildERC20TransferData 构建 ERC20 transfer 调用数据
@@ -274,13 +279,7 @@ func (l *TransactionLogic) WaitForTransactionReceipt(client *ethclient.Client, t
 }
 
 // EstimateNativeTransferGas 估算原生代币转账的 gas
-func (l *TransactionLogic) EstimateNativeTransferGas(client *ethclient.Client, fromAddress, toAddress common.Address, value *big.Int) (uint64, *big.Int, error) {
-	// 获取 gas price
-	gasPrice, err := client.SuggestGasPrice(l.ctx)
-	if err != nil {
-		return 0, nil, fmt.Errorf("failed to get gas price: %v", err)
-	}
-
+func (l *TransactionLogic) EstimateNativeTransferGas(client *ethclient.Client, fromAddress, toAddress common.Address, value *big.Int) uint64 {
 	// 使用 EstimateGas 进行精确估算
 	gasLimit, err := client.EstimateGas(l.ctx, ethereum.CallMsg{
 		From:  fromAddress,
@@ -301,17 +300,11 @@ func (l *TransactionLogic) EstimateNativeTransferGas(client *ethclient.Client, f
 	// 增加缓冲
 	gasLimit = gasLimit * 110 / 100
 
-	return gasLimit, gasPrice, nil
+	return gasLimit
 }
 
 // EstimateERC20TransferGas 估算 ERC20 转账的 gas
-func (l *TransactionLogic) EstimateERC20TransferGas(client *ethclient.Client, fromAddress, tokenAddress common.Address, data []byte) (uint64, *big.Int, error) {
-	// 获取 gas price
-	gasPrice, err := client.SuggestGasPrice(l.ctx)
-	if err != nil {
-		return 0, nil, fmt.Errorf("failed to get gas price: %v", err)
-	}
-
+func (l *TransactionLogic) EstimateERC20TransferGas(client *ethclient.Client, fromAddress, tokenAddress common.Address, data []byte) uint64 {
 	gasLimit, err := client.EstimateGas(l.ctx, ethereum.CallMsg{
 		From: fromAddress,
 		To:   &tokenAddress,
@@ -331,37 +324,30 @@ func (l *TransactionLogic) EstimateERC20TransferGas(client *ethclient.Client, fr
 	// 增加缓冲
 	gasLimit = gasLimit * 120 / 100
 
-	return gasLimit, gasPrice, nil
+	return gasLimit
+}
+
+// getChain 从链注册表获取链配置，支持链名、别名和链 ID
+func (l *TransactionLogic) getChain(chain string) (*chains.Chain, error) {
+	c, ok := l.svcCtx.Chains.Get(chain)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %s", chain)
+	}
+	return c, nil
 }
 
 // GetChainDisplayName 获取链的显示名称
 func (l *TransactionLogic) GetChainDisplayName(chain string) string {
-	switch chain {
-	case "BSC":
-		return "BSC 主网"
-	case "BSC-TestNet":
-		return "BSC 测试网"
-	case "ETH":
-		return "以太坊主网"
-	case "ETH-Goerli":
-		return "以太坊 Goerli 测试网"
-	case "ETH-Sepolia":
-		return "以太坊 Sepolia 测试网"
-	case "Polygon":
-		return "Polygon 主网"
-	case "Polygon-Mumbai":
-		return "Polygon Mumbai 测试网"
-	case "Solana", "SOL", "solana", "sol":
-		return "Solana 主网"
-	default:
-		return chain
+	if c, ok := l.svcCtx.Chains.Get(chain); ok {
+		return c.DisplayName()
 	}
+	return chain
 }
 
 // SendTransactionWithRetry 带重试机制的交易发送
-func (l *TransactionLogic) SendTransactionWithRetry(client *ethclient.Client, privateKey *ecdsa.PrivateKey, to common.Address, value *big.Int, data []byte, gasLimit uint64, gasPrice *big.Int, chainId int64, maxRetries int) (string, error) {
+func (l *TransactionLogic) SendTransactionWithRetry(client *ethclient.Client, privateKey *ecdsa.PrivateKey, to common.Address, value *big.Int, data []byte, gasLimit uint64, fees *gasoracle.Fees, chainId int64, maxRetries int) (string, error) {
 	for i := 0; i < maxRetries; i++ {
-		txHash, err := l.BuildAndSendTransaction(client, privateKey, to, value, data, gasLimit, gasPrice, chainId)
+		txHash, err := l.BuildAndSendTransaction(client, privateKey, to, value, data, gasLimit, fees, chainId)
 		if err != nil {
 			l.Errorf("发送交易失败 (尝试 %d/%d): %v", i+1, maxRetries, err)
 			if i == maxRetries-1 {
@@ -0,0 +1,496 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"demo/internal/chains"
+	"demo/internal/constant"
+	"demo/internal/model"
+	"demo/internal/multicall"
+	"demo/internal/svc"
+	"demo/internal/types"
+	"demo/internal/units"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// balanceQueryTimeout 单条链余额查询超时
+	balanceQueryTimeout = 10 * time.Second
+	// nftEnumerateLimit 每个 ERC-721 集合最多枚举的 token id 数
+	nftEnumerateLimit = 100
+
+	splTokenProgramId     = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	splToken2022ProgramId = "TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb"
+)
+
+type BalanceLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewBalanceLogic(ctx context.Context, svcCtx *svc.ServiceContext) *BalanceLogic {
+	return &BalanceLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// GetBalances 查询单个钱包或用户全部钱包在各配置链上的余额
+func (l *BalanceLogic) GetBalances(req *types.WalletBalancesReq) (*types.WalletBalancesResp, error) {
+	l.Infof("--- 开始处理 /wallet/balances 请求, address: %s, user_id: %s ---", req.Address, req.UserId)
+
+	wallets, err := l.findWallets(req)
+	if err != nil {
+		return nil, err
+	}
+
+	chainFilter := make(map[string]bool, len(req.Chains))
+	for _, c := range req.Chains {
+		chainFilter[strings.ToUpper(c)] = true
+	}
+
+	resp := &types.WalletBalancesResp{Wallets: make([]types.WalletBalances, len(wallets))}
+	var wg sync.WaitGroup
+	for i, w := range wallets {
+		wg.Add(1)
+		go func(i int, w *model.Wallets) {
+			defer wg.Done()
+			resp.Wallets[i] = l.walletBalances(w, chainFilter, req.IncludeZero)
+		}(i, w)
+	}
+	wg.Wait()
+
+	l.Infof("--- /wallet/balances 处理完成, 共 %d 个钱包 ---", len(wallets))
+	return resp, nil
+}
+
+// findWallets 按地址或用户查找钱包
+func (l *BalanceLogic) findWallets(req *types.WalletBalancesReq) ([]*model.Wallets, error) {
+	switch {
+	case req.Address != "":
+		w, err := l.svcCtx.WalletsDao.FindOneByAddress(l.ctx, req.Address)
+		if errors.Is(err, model.ErrNotFound) {
+			return nil, errors.New("wallet not found")
+		}
+		if err != nil {
+			l.Errorf("查询钱包失败: %v", err)
+			return nil, errors.New("failed to query wallet")
+		}
+		return []*model.Wallets{w}, nil
+	case req.UserId != "":
+		wallets, err := l.svcCtx.WalletsDao.FindByUserId(l.ctx, req.UserId)
+		if err != nil {
+			l.Errorf("查询用户钱包失败: %v", err)
+			return nil, errors.New("failed to query wallets")
+		}
+		if len(wallets) == 0 {
+			return nil, errors.New("no wallets found for user")
+		}
+		return wallets, nil
+	default:
+		return nil, errors.New("either address or user_id is required")
+	}
+}
+
+// walletBalances 并发查询一个钱包在其链族所有已配置链上的余额
+func (l *BalanceLogic) walletBalances(w *model.Wallets, chainFilter map[string]bool, includeZero bool) types.WalletBalances {
+	family := constant.Chain(strings.ToUpper(w.ChainType.String))
+	result := types.WalletBalances{
+		Address:   w.Address,
+		ChainType: string(family),
+		Balances:  []types.TokenBalance{},
+	}
+
+	var targets []*chains.Chain
+	for _, c := range l.svcCtx.Chains.All() {
+		if c.WalletType() != family {
+			continue
+		}
+		if len(chainFilter) > 0 && !chainFilter[strings.ToUpper(c.Key)] {
+			continue
+		}
+		targets = append(targets, c)
+	}
+
+	balances := make([][]types.TokenBalance, len(targets))
+	nfts := make([][]types.NftHolding, len(targets))
+	errs := make([]error, len(targets))
+	nftErrs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, c := range targets {
+		wg.Add(1)
+		go func(i int, c *chains.Chain) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(l.ctx, balanceQueryTimeout)
+			defer cancel()
+
+			switch c.Family() {
+			case chains.FamilySolana:
+				balances[i], errs[i] = l.solanaBalances(ctx, c, w.Address)
+			case chains.FamilyBTC:
+				balances[i], errs[i] = l.btcBalances(ctx, c, w.Address)
+			default:
+				balances[i], errs[i] = l.evmBalances(ctx, c, w.Address)
+				if len(c.Nfts) > 0 {
+					nfts[i], nftErrs[i] = l.evmNftHoldings(ctx, c, w.Address)
+				}
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range targets {
+		if nftErrs[i] != nil {
+			l.Errorf("查询 %s NFT 持仓失败 (%s): %v", c.Key, w.Address, nftErrs[i])
+			result.Errors = append(result.Errors, fmt.Sprintf("%s nfts: %v", c.Key, nftErrs[i]))
+		}
+		for _, n := range nfts[i] {
+			if !includeZero && n.Balance == "0" {
+				continue
+			}
+			result.Nfts = append(result.Nfts, n)
+		}
+
+		if errs[i] != nil {
+			l.Errorf("查询 %s 余额失败 (%s): %v", c.Key, w.Address, errs[i])
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", c.Key, errs[i]))
+			continue
+		}
+		for _, b := range balances[i] {
+			if !includeZero && b.Amount == "0" && (b.UnconfirmedAmount == "" || b.UnconfirmedAmount == "0") {
+				continue
+			}
+			result.Balances = append(result.Balances, b)
+		}
+	}
+	return result
+}
+
+// evmBalances 通过 Multicall3 在同一批次中查询原生币和所有跟踪的 ERC20 余额
+func (l *BalanceLogic) evmBalances(ctx context.Context, c *chains.Chain, address string) ([]types.TokenBalance, error) {
+	caller, err := l.svcCtx.Multicall.Caller(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to chain: %w", err)
+	}
+
+	owner := common.HexToAddress(address)
+	calls := make([]multicall.Call, 0, len(c.Tokens)+1)
+	calls = append(calls, caller.EthBalance(owner))
+	for _, token := range c.Tokens {
+		calls = append(calls, multicall.BalanceOf(common.HexToAddress(token.Address), owner))
+	}
+
+	results, err := caller.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+	nativeAmount, ok := results[0].Uint()
+	if !ok {
+		return nil, errors.New("failed to query native balance")
+	}
+
+	symbol, decimals := c.NativeSymbol, c.NativeDecimals
+	balances := []types.TokenBalance{{
+		Chain:     c.Key,
+		Symbol:    symbol,
+		Decimals:  decimals,
+		Amount:    nativeAmount.String(),
+		Formatted: units.FormatUnits(nativeAmount, decimals),
+	}}
+
+	for i, token := range c.Tokens {
+		amount, ok := results[i+1].Uint()
+		if !ok {
+			l.Errorf("查询 %s %s 余额失败", c.Key, token.Symbol)
+			continue
+		}
+		balances = append(balances, types.TokenBalance{
+			Chain:     c.Key,
+			Token:     token.Address,
+			Symbol:    token.Symbol,
+			Decimals:  token.Decimals,
+			Amount:    amount.String(),
+			Formatted: units.FormatUnits(amount, token.Decimals),
+		})
+	}
+	return balances, nil
+}
+
+// evmNftHoldings 查询链配置 Nfts 中的持仓：第一批读取 ERC-721 持有数量（及是否支持 Enumerable）
+// 和 ERC-1155 各 id 的余额，第二批枚举 ERC-721 持有的 token id
+func (l *BalanceLogic) evmNftHoldings(ctx context.Context, c *chains.Chain, address string) ([]types.NftHolding, error) {
+	caller, err := l.svcCtx.Multicall.Caller(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to chain: %w", err)
+	}
+	owner := common.HexToAddress(address)
+
+	// 每个持仓对应第一批中的调用位置，enumerable 仅 ERC-721 使用
+	type pending struct {
+		holding    types.NftHolding
+		contract   common.Address
+		balance    int
+		enumerable int
+	}
+	var items []pending
+	var calls []multicall.Call
+	for _, n := range c.Nfts {
+		contract := common.HexToAddress(n.Address)
+		base := types.NftHolding{Chain: c.Key, Contract: contract.Hex(), Name: n.Name, Standard: n.Standard}
+		if n.Standard == "erc721" {
+			items = append(items, pending{holding: base, contract: contract, balance: len(calls), enumerable: len(calls) + 1})
+			calls = append(calls, multicall.BalanceOf(contract, owner), multicall.SupportsInterface(contract, multicall.InterfaceERC721Enumerable))
+			continue
+		}
+		for _, raw := range n.TokenIds {
+			id, ok := parseNftTokenId(raw)
+			if !ok {
+				l.Errorf("%s NFT 配置的 token id 无效: %s %s", c.Key, n.Address, raw)
+				continue
+			}
+			h := base
+			h.TokenId = id.String()
+			items = append(items, pending{holding: h, contract: contract, balance: len(calls), enumerable: -1})
+			calls = append(calls, multicall.BalanceOf1155(contract, owner, id))
+		}
+	}
+
+	results, err := caller.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	var holdings []types.NftHolding
+	var enumCalls []multicall.Call
+	var enumOwners []int // enumCalls 对应的 holdings 下标
+	for _, item := range items {
+		balance, ok := results[item.balance].Uint()
+		if !ok {
+			l.Errorf("查询 %s NFT 持仓失败: %s %s", c.Key, item.holding.Contract, item.holding.TokenId)
+			continue
+		}
+		item.holding.Balance = balance.String()
+		holdings = append(holdings, item.holding)
+
+		if item.enumerable < 0 || balance.Sign() == 0 {
+			continue
+		}
+		if enumerable, _ := results[item.enumerable].Bool(); !enumerable {
+			continue
+		}
+		count := nftEnumerateLimit
+		if balance.IsInt64() && balance.Int64() < int64(count) {
+			count = int(balance.Int64())
+		}
+		for j := 0; j < count; j++ {
+			enumCalls = append(enumCalls, multicall.TokenOfOwnerByIndex(item.contract, owner, j))
+			enumOwners = append(enumOwners, len(holdings)-1)
+		}
+	}
+	if len(enumCalls) == 0 {
+		return holdings, nil
+	}
+
+	enumResults, err := caller.Aggregate(ctx, enumCalls)
+	if err != nil {
+		// 枚举失败不影响持有数量
+		l.Errorf("枚举 %s ERC-721 token id 失败: %v", c.Key, err)
+		return holdings, nil
+	}
+	for j, r := range enumResults {
+		if id, ok := r.Uint(); ok {
+			h := &holdings[enumOwners[j]]
+			h.TokenIds = append(h.TokenIds, id.String())
+		}
+	}
+	return holdings, nil
+}
+
+// parseNftTokenId 解析十进制或 0x 十六进制的 token id
+func parseNftTokenId(s string) (*big.Int, bool) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return new(big.Int).SetString(s[2:], 16)
+	}
+	return new(big.Int).SetString(s, 10)
+}
+
+// solanaBalances 查询 SOL 余额以及 Token / Token-2022 程序下的全部 SPL 代币账户
+func (l *BalanceLogic) solanaBalances(ctx context.Context, c *chains.Chain, address string) ([]types.TokenBalance, error) {
+	var balanceResult struct {
+		Value uint64 `json:"value"`
+	}
+	if err := l.solanaRPC(ctx, c, "getBalance", []interface{}{address}, &balanceResult); err != nil {
+		return nil, err
+	}
+
+	symbol, decimals := c.NativeSymbol, c.NativeDecimals
+	lamports := new(big.Int).SetUint64(balanceResult.Value)
+	balances := []types.TokenBalance{{
+		Chain:     c.Key,
+		Symbol:    symbol,
+		Decimals:  decimals,
+		Amount:    lamports.String(),
+		Formatted: units.FormatUnits(lamports, decimals),
+	}}
+
+	for _, programId := range []string{splTokenProgramId, splToken2022ProgramId} {
+		var accounts struct {
+			Value []struct {
+				Pubkey  string `json:"pubkey"`
+				Account struct {
+					Data struct {
+						Parsed struct {
+							Info struct {
+								Mint        string `json:"mint"`
+								TokenAmount struct {
+									Amount   string `json:"amount"`
+									Decimals int    `json:"decimals"`
+								} `json:"tokenAmount"`
+							} `json:"info"`
+						} `json:"parsed"`
+					} `json:"data"`
+				} `json:"account"`
+			} `json:"value"`
+		}
+		params := []interface{}{
+			address,
+			map[string]string{"programId": programId},
+			map[string]string{"encoding": "jsonParsed"},
+		}
+		if err := l.solanaRPC(ctx, c, "getTokenAccountsByOwner", params, &accounts); err != nil {
+			return nil, err
+		}
+
+		for _, acc := range accounts.Value {
+			info := acc.Account.Data.Parsed.Info
+			// 符号来自代币注册表（配置、LI.FI 代币列表或 Metaplex 元数据），查询失败时留空
+			var symbol string
+			if token, err := l.svcCtx.Tokens.Lookup(ctx, c, info.Mint); err == nil {
+				symbol = token.Symbol
+			}
+			balances = append(balances, types.TokenBalance{
+				Chain:     c.Key,
+				Token:     info.Mint,
+				Symbol:    symbol,
+				Decimals:  info.TokenAmount.Decimals,
+				Amount:    info.TokenAmount.Amount,
+				Formatted: units.FormatUnitsString(info.TokenAmount.Amount, info.TokenAmount.Decimals),
+			})
+		}
+	}
+	return balances, nil
+}
+
+// solanaRPC 调用 Solana JSON-RPC 方法并解析 result
+func (l *BalanceLogic) solanaRPC(ctx context.Context, c *chains.Chain, method string, params []interface{}, result interface{}) error {
+	pool, err := l.svcCtx.RPC.Pool(c)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pool.URL(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := pool.HTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Solana RPC: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to parse Solana RPC response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("Solana RPC error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// btcBalances 通过 Esplora UTXO 接口计算已确认和未确认余额
+func (l *BalanceLogic) btcBalances(ctx context.Context, c *chains.Chain, address string) ([]types.TokenBalance, error) {
+	pool, err := l.svcCtx.RPC.Pool(c)
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := fmt.Sprintf("%s/address/%s/utxo", pool.URL(), address)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := pool.HTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call esplora api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("esplora api returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var utxos []struct {
+		Value  int64 `json:"value"`
+		Status struct {
+			Confirmed bool `json:"confirmed"`
+		} `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&utxos); err != nil {
+		return nil, fmt.Errorf("failed to decode esplora response: %w", err)
+	}
+
+	confirmed, unconfirmed := new(big.Int), new(big.Int)
+	for _, utxo := range utxos {
+		if utxo.Status.Confirmed {
+			confirmed.Add(confirmed, big.NewInt(utxo.Value))
+		} else {
+			unconfirmed.Add(unconfirmed, big.NewInt(utxo.Value))
+		}
+	}
+
+	symbol, decimals := c.NativeSymbol, c.NativeDecimals
+	return []types.TokenBalance{{
+		Chain:                c.Key,
+		Symbol:               symbol,
+		Decimals:             decimals,
+		Amount:               confirmed.String(),
+		Formatted:            units.FormatUnits(confirmed, decimals),
+		UnconfirmedAmount:    unconfirmed.String(),
+		UnconfirmedFormatted: units.FormatUnits(unconfirmed, decimals),
+	}}, nil
+}
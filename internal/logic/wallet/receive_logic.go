@@ -0,0 +1,388 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"demo/internal/chains"
+	"demo/internal/constant"
+	"demo/internal/model"
+	"demo/internal/svc"
+	"demo/internal/tokens"
+	"demo/internal/types"
+	"demo/internal/units"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mr-tron/base58"
+	"github.com/skip2/go-qrcode"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// receiveAsset 收款资产信息
+type receiveAsset struct {
+	token    string // 合约地址 / mint，原生币为空
+	symbol   string
+	decimals int
+}
+
+type ReceiveLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewReceiveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReceiveLogic {
+	return &ReceiveLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// Receive 返回用户在指定链上的收款地址、支付 URI 和二维码；
+// 指定期望金额时创建收款请求，由监控在到账后回填
+func (l *ReceiveLogic) Receive(req *types.ReceiveReq) (*types.ReceiveResp, error) {
+	l.Infof("--- 开始处理 /transaction/receive 请求, user_id: %s, chain: %s, token: %s ---", req.UserId, req.Chain, req.Token)
+
+	c, ok := l.svcCtx.Chains.Get(req.Chain)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %s", req.Chain)
+	}
+
+	w, err := l.findWallet(req.UserId, c.WalletType())
+	if err != nil {
+		return nil, err
+	}
+	address := w.Address
+
+	var amount *big.Int
+	if req.Amount != "" {
+		amount, ok = new(big.Int).SetString(req.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			return nil, errors.New("amount must be a positive integer in the smallest unit")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(l.ctx, balanceQueryTimeout)
+	defer cancel()
+	asset, err := l.resolveAsset(ctx, c, req.Token, amount != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.ReceiveResp{
+		Chain:    c.Key,
+		ChainId:  c.ChainId,
+		Address:  address,
+		Token:    asset.token,
+		Symbol:   asset.symbol,
+		QrFormat: req.QrFormat,
+	}
+	if asset.decimals >= 0 {
+		resp.Decimals = asset.decimals
+	}
+	if amount != nil {
+		resp.Amount = amount.String()
+		if asset.decimals >= 0 {
+			resp.AmountFormatted = units.FormatUnits(amount, asset.decimals)
+		}
+	}
+
+	switch c.Family() {
+	case chains.FamilyBTC:
+		resp.Uri = buildBIP21URI(address, amount, asset.decimals, req.Label, req.Message)
+	case chains.FamilySolana:
+		reference, err := newSolanaReference()
+		if err != nil {
+			return nil, err
+		}
+		resp.Reference = reference
+		resp.Uri = buildSolanaPayURI(address, amount, asset, reference, req.Label, req.Message)
+	default:
+		resp.Uri = buildEIP681URI(address, c.ChainId, amount, asset.token)
+	}
+
+	resp.QrCode, err = encodeQRCode(resp.Uri, req.QrFormat, req.QrSize)
+	if err != nil {
+		l.Errorf("生成二维码失败: %v", err)
+		return nil, errors.New("failed to generate qr code")
+	}
+
+	if amount != nil {
+		if recordsPayment(c) {
+			if err := l.createPaymentRequest(req, resp, c); err != nil {
+				return nil, err
+			}
+		} else {
+			l.Infof("⚠️ %s 上的入账不会被监控匹配，金额只写入支付 URI，不创建收款请求", c.Key)
+		}
+	}
+
+	l.Infof("--- /transaction/receive 处理完成, uri: %s ---", resp.Uri)
+	return resp, nil
+}
+
+// ReceiveStatus 查询收款请求状态，过期未支付的请求返回 expired
+func (l *ReceiveLogic) ReceiveStatus(req *types.ReceiveStatusReq) (*types.ReceiveStatusResp, error) {
+	pr, err := l.svcCtx.PaymentRequestsDao.FindOneByRequestId(l.ctx, req.RequestId)
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, errors.New("payment request not found")
+	}
+	if err != nil {
+		l.Errorf("查询收款请求失败: %v", err)
+		return nil, errors.New("failed to query payment request")
+	}
+
+	status := pr.Status
+	if status == model.PaymentStatusPending && time.Now().After(pr.ExpiresAt) {
+		status = model.PaymentStatusExpired
+	}
+
+	resp := &types.ReceiveStatusResp{
+		RequestId:  pr.RequestId,
+		Chain:      pr.Chain,
+		ChainId:    pr.ChainId,
+		Address:    pr.Address,
+		Token:      pr.Token,
+		Amount:     pr.Amount,
+		Uri:        pr.Uri,
+		Status:     status,
+		PaidTxHash: pr.PaidTxHash,
+		PaidAmount: pr.PaidAmount,
+		ExpiresAt:  pr.ExpiresAt.Unix(),
+		CreatedAt:  pr.CreatedAt.Unix(),
+	}
+	if pr.PaidAt != nil {
+		resp.PaidAt = pr.PaidAt.Unix()
+	}
+	return resp, nil
+}
+
+// findWallet 查找用户在该链族下的钱包
+func (l *ReceiveLogic) findWallet(userId string, family constant.Chain) (*model.Wallets, error) {
+	wallets, err := l.svcCtx.WalletsDao.FindByUserId(l.ctx, userId)
+	if err != nil {
+		l.Errorf("查询用户钱包失败: %v", err)
+		return nil, errors.New("failed to query wallets")
+	}
+	for _, w := range wallets {
+		if strings.EqualFold(w.ChainType.String, string(family)) {
+			return w, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s wallet found for user", family)
+}
+
+// resolveAsset 解析收款资产：为空表示原生币，否则经代币注册表按符号或地址解析
+func (l *ReceiveLogic) resolveAsset(ctx context.Context, c *chains.Chain, token string, needDecimals bool) (*receiveAsset, error) {
+	if token == "" || strings.EqualFold(token, "native") {
+		return &receiveAsset{symbol: c.NativeSymbol, decimals: c.NativeDecimals}, nil
+	}
+	if c.IsBTC() {
+		return nil, errors.New("tokens are not supported on BTC")
+	}
+
+	t, err := l.svcCtx.Tokens.Resolve(ctx, c, token)
+	switch {
+	case err == nil && t.Native:
+		return &receiveAsset{symbol: t.Symbol, decimals: t.Decimals}, nil
+	case err == nil:
+		return &receiveAsset{token: t.Address, symbol: t.Symbol, decimals: t.Decimals}, nil
+	case errors.Is(err, tokens.ErrInvalidAddress), errors.Is(err, tokens.ErrNotFound), errors.Is(err, tokens.ErrAmbiguous):
+		return nil, err
+	}
+
+	// 地址有效但读取元数据失败：Solana Pay 的 amount 使用小数表示，必须知道精度；
+	// EIP-681 使用最小单位，精度只用于展示，查询失败不影响收款
+	l.Infof("⚠️ 查询 %s 代币元数据失败: %v", token, err)
+	if c.IsSolana() && needDecimals {
+		return nil, errors.New("failed to query token decimals")
+	}
+	asset := &receiveAsset{token: token, decimals: -1}
+	if c.IsEVM() {
+		asset.token = common.HexToAddress(token).Hex()
+	}
+	return asset, nil
+}
+
+// recordsPayment 指定金额时是否创建收款请求：EVM 链由区块监控（需配置 WsUrl）匹配到账；
+// Solana 保存 Solana Pay reference，供按 reference 查找付款的监控匹配；BTC 和未监控的 EVM 链只在 URI 中携带金额
+func recordsPayment(c *chains.Chain) bool {
+	switch {
+	case c.IsEVM():
+		return c.WsUrl != ""
+	case c.IsSolana():
+		return true
+	}
+	return false
+}
+
+// createPaymentRequest 保存收款请求，供监控匹配到账交易
+func (l *ReceiveLogic) createPaymentRequest(req *types.ReceiveReq, resp *types.ReceiveResp, c *chains.Chain) error {
+	requestId, err := newPaymentRequestId()
+	if err != nil {
+		return err
+	}
+
+	address, token := resp.Address, resp.Token
+	if c.IsEVM() {
+		// 监控事件中的地址大小写不固定，统一小写存储
+		address, token = strings.ToLower(address), strings.ToLower(token)
+	}
+
+	now := time.Now()
+	pr := &model.PaymentRequests{
+		RequestId: requestId,
+		UserId:    req.UserId,
+		Chain:     resp.Chain,
+		ChainId:   resp.ChainId,
+		Token:     token,
+		Address:   address,
+		Amount:    resp.Amount,
+		Reference: resp.Reference,
+		Uri:       resp.Uri,
+		Status:    model.PaymentStatusPending,
+		ExpiresAt: now.Add(time.Duration(req.ExpiresIn) * time.Second),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := l.svcCtx.PaymentRequestsDao.Insert(l.ctx, pr); err != nil {
+		l.Errorf("保存收款请求失败: %v", err)
+		return errors.New("failed to save payment request")
+	}
+
+	resp.RequestId = pr.RequestId
+	resp.Status = pr.Status
+	resp.ExpiresAt = pr.ExpiresAt.Unix()
+	return nil
+}
+
+// buildEIP681URI 构造 EIP-681 支付 URI：
+// 原生币 ethereum:<to>@<chainId>?value=<wei>，
+// ERC20 ethereum:<token>@<chainId>/transfer?address=<to>&uint256=<amount>
+func buildEIP681URI(address string, chainId int64, amount *big.Int, token string) string {
+	if token == "" {
+		uri := fmt.Sprintf("ethereum:%s@%d", address, chainId)
+		if amount != nil {
+			uri += "?value=" + amount.String()
+		}
+		return uri
+	}
+
+	uri := fmt.Sprintf("ethereum:%s@%d/transfer?address=%s", token, chainId, address)
+	if amount != nil {
+		uri += "&uint256=" + amount.String()
+	}
+	return uri
+}
+
+// buildBIP21URI 构造 BIP21 URI：bitcoin:<address>?amount=<BTC>&label=&message=
+func buildBIP21URI(address string, amount *big.Int, decimals int, label, message string) string {
+	var params []string
+	if amount != nil {
+		params = append(params, "amount="+units.FormatUnits(amount, decimals))
+	}
+	if label != "" {
+		params = append(params, "label="+uriEscape(label))
+	}
+	if message != "" {
+		params = append(params, "message="+uriEscape(message))
+	}
+	return joinURI("bitcoin:"+address, params)
+}
+
+// buildSolanaPayURI 构造 Solana Pay 转账请求：
+// solana:<recipient>?amount=<decimal>&spl-token=<mint>&reference=<pubkey>&label=&message=
+func buildSolanaPayURI(address string, amount *big.Int, asset *receiveAsset, reference, label, message string) string {
+	var params []string
+	if amount != nil {
+		params = append(params, "amount="+units.FormatUnits(amount, asset.decimals))
+	}
+	if asset.token != "" {
+		params = append(params, "spl-token="+asset.token)
+	}
+	if reference != "" {
+		params = append(params, "reference="+reference)
+	}
+	if label != "" {
+		params = append(params, "label="+uriEscape(label))
+	}
+	if message != "" {
+		params = append(params, "message="+uriEscape(message))
+	}
+	return joinURI("solana:"+address, params)
+}
+
+func joinURI(base string, params []string) string {
+	if len(params) == 0 {
+		return base
+	}
+	return base + "?" + strings.Join(params, "&")
+}
+
+// uriEscape 百分号编码，空格编码为 %20（BIP21 / Solana Pay 不接受 +）
+func uriEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// newSolanaReference 生成随机的 32 字节 reference 公钥（base58）
+func newSolanaReference() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base58.Encode(buf), nil
+}
+
+func newPaymentRequestId() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "pr_" + hex.EncodeToString(buf), nil
+}
+
+// encodeQRCode 生成 data URI 形式的二维码
+func encodeQRCode(content, format string, size int) (string, error) {
+	qr, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+
+	if format == "svg" {
+		svg := qrSVG(qr.Bitmap())
+		return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(svg), nil
+	}
+
+	png, err := qr.PNG(size)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png), nil
+}
+
+// qrSVG 将二维码点阵渲染为 SVG，每个模块一个单位，由客户端自由缩放
+func qrSVG(bitmap [][]bool) []byte {
+	n := len(bitmap)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, n, n)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
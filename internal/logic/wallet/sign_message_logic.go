@@ -0,0 +1,455 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"demo/internal/chains"
+	"demo/internal/logic/transaction"
+	"demo/internal/model"
+	"demo/internal/multicall"
+	"demo/internal/policy"
+	"demo/internal/svc"
+	"demo/internal/types"
+
+	solanaTypes "github.com/blocto/solana-go-sdk/types"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/mr-tron/base58"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// bip322Tag BIP-322 消息哈希的 tag
+var bip322Tag = []byte("BIP0322-signed-message")
+
+// eip712DomainFields EIP712Domain 允许的字段
+var eip712DomainFields = map[string]bool{
+	"name": true, "version": true, "chainId": true, "verifyingContract": true, "salt": true,
+}
+
+type SignMessageLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewSignMessageLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SignMessageLogic {
+	return &SignMessageLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// signAudit 写入审计日志的签名摘要，不记录消息原文
+type signAudit struct {
+	Type        string `json:"type"`
+	Size        int    `json:"size"`
+	Hash        string `json:"hash,omitempty"`
+	PrimaryType string `json:"primary_type,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// SignMessage 用托管钱包签名链下消息：EVM 支持 personal_sign（EIP-191）与 EIP-712，
+// BTC 为 BIP-322，Solana 为 ed25519 signMessage。签名前经过策略校验，结果写入审计日志
+func (l *SignMessageLogic) SignMessage(req *types.SignMessageReq) (*types.SignMessageResp, error) {
+	l.Infof("--- 开始处理 /wallet/sign_message 请求, chain: %s, address: %s, type: %s ---", req.Chain, req.Address, req.Type)
+
+	c, ok := l.svcCtx.Chains.Get(req.Chain)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %s", req.Chain)
+	}
+	if req.Address == "" {
+		return nil, errors.New("address is required")
+	}
+	if _, err := l.svcCtx.WalletsDao.FindOneByAddress(l.ctx, req.Address); err != nil {
+		l.Errorf("查询钱包失败 for address %s: %v", req.Address, err)
+		return nil, errors.New("wallet not found")
+	}
+
+	msgType, err := messageType(c, req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.SignMessageResp{Chain: c.Key, Address: req.Address, Type: msgType}
+	preq := &policy.Request{
+		Action:      policy.ActionSignMessage,
+		Chain:       c.Key,
+		Address:     req.Address,
+		MessageType: msgType,
+	}
+	detail := &signAudit{Type: msgType}
+
+	// 先解析出待签名内容，sign 在策略放行后执行
+	var sign func() (string, error)
+	switch msgType {
+	case policy.MessagePersonalSign:
+		data, isHex := personalMessageBytes(req.Message)
+		preq.Size = len(data)
+		preq.BlindSign = isHex && len(data) == 32
+		hash := accounts.TextHash(data)
+		resp.Hash = hexutil.Encode(hash)
+		sign = func() (string, error) { return l.signEVMHash(req.Address, hash) }
+
+	case policy.MessageEIP712:
+		td, err := parseTypedData(req.TypedData)
+		if err != nil {
+			return nil, err
+		}
+		raw, _ := json.Marshal(req.TypedData)
+		preq.Size = len(raw)
+		preq.PrimaryType = td.PrimaryType
+		preq.Contract = td.Domain.VerifyingContract
+		detail.PrimaryType = td.PrimaryType
+
+		if nvts, err := td.Format(); err == nil {
+			resp.TypedData = typedDataFields(nvts)
+		}
+		hash, _, err := apitypes.TypedDataAndHash(*td)
+		if err != nil {
+			return nil, fmt.Errorf("invalid typed data: %v", err)
+		}
+		resp.Hash = hexutil.Encode(hash)
+
+		warnings, err := l.validateDomain(c, td)
+		resp.Warnings = warnings
+		if err != nil {
+			return l.deny(req, preq, detail, resp, err)
+		}
+		sign = func() (string, error) { return l.signEVMHash(req.Address, hash) }
+
+	case policy.MessageBIP322:
+		data := []byte(req.Message)
+		preq.Size = len(data)
+		msgHash := chainhash.TaggedHash(bip322Tag, data)
+		resp.Hash = hex.EncodeToString(msgHash[:])
+		sign = func() (string, error) {
+			sig, format, err := l.signBIP322(c, req.Address, data)
+			resp.Format = format
+			return sig, err
+		}
+
+	case policy.MessageSolana:
+		data := []byte(req.Message)
+		preq.Size = len(data)
+		sign = func() (string, error) { return l.signSolana(req.Address, data) }
+	}
+	detail.Size = preq.Size
+	detail.Hash = resp.Hash
+
+	if err := l.svcCtx.Policy.Check(preq); err != nil {
+		return l.deny(req, preq, detail, resp, err)
+	}
+
+	if req.Preview {
+		resp.Message = "预览：策略校验通过，尚未签名"
+		return resp, nil
+	}
+
+	signature, err := sign()
+	if err != nil {
+		l.Errorf("消息签名失败: %v", err)
+		detail.Reason = err.Error()
+		l.svcCtx.Policy.Audit(l.ctx, preq, preq.Contract, model.AuditResultFailure, detail)
+		return nil, err
+	}
+	resp.Signature = signature
+	resp.Message = "✅ 消息签名完成"
+	l.svcCtx.Policy.Audit(l.ctx, preq, preq.Contract, model.AuditResultSuccess, detail)
+	l.Infof("✅ %s 消息签名完成: %s", msgType, req.Address)
+	return resp, nil
+}
+
+// deny 策略拒绝：预览返回解析结果和拒绝原因，正式请求写审计日志后返回错误
+func (l *SignMessageLogic) deny(req *types.SignMessageReq, preq *policy.Request, detail *signAudit, resp *types.SignMessageResp, err error) (*types.SignMessageResp, error) {
+	l.Infof("⛔ 消息签名被拒绝: %v", err)
+	if req.Preview {
+		resp.Message = "⛔ " + err.Error()
+		return resp, nil
+	}
+	detail.Size, detail.Hash, detail.Reason = preq.Size, resp.Hash, err.Error()
+	l.svcCtx.Policy.Audit(l.ctx, preq, preq.Contract, model.AuditResultDenied, detail)
+	return nil, err
+}
+
+// messageType 校验签名类型与链族是否匹配，为空时按链族默认
+func messageType(c *chains.Chain, t string) (string, error) {
+	t = strings.ToLower(t)
+	switch c.Family() {
+	case chains.FamilyBTC:
+		if t == "" || t == policy.MessageBIP322 {
+			return policy.MessageBIP322, nil
+		}
+	case chains.FamilySolana:
+		if t == "" || t == policy.MessageSolana {
+			return policy.MessageSolana, nil
+		}
+	default:
+		switch t {
+		case "", policy.MessagePersonalSign:
+			return policy.MessagePersonalSign, nil
+		case policy.MessageEIP712:
+			return policy.MessageEIP712, nil
+		}
+	}
+	return "", fmt.Errorf("message type %q is not supported on %s", t, c.DisplayName())
+}
+
+// personalMessageBytes personal_sign 的 0x 前缀消息按十六进制字节处理（与钱包行为一致）
+func personalMessageBytes(message string) ([]byte, bool) {
+	if strings.HasPrefix(message, "0x") {
+		if data, err := hexutil.Decode(message); err == nil {
+			return data, true
+		}
+	}
+	return []byte(message), false
+}
+
+// parseTypedData 解析 eth_signTypedData_v4 格式，大整数以字符串传给 apitypes 避免精度丢失
+func parseTypedData(raw map[string]interface{}) (*apitypes.TypedData, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("typed_data is required for eip712")
+	}
+	buf, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid typed data: %v", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var td apitypes.TypedData
+	if err := dec.Decode(&td); err != nil {
+		return nil, fmt.Errorf("invalid typed data: %v", err)
+	}
+	if td.PrimaryType == "" || td.PrimaryType == "EIP712Domain" {
+		return nil, errors.New("typed data primaryType is missing or invalid")
+	}
+	if _, ok := td.Types[td.PrimaryType]; !ok {
+		return nil, fmt.Errorf("typed data primaryType %s is not declared in types", td.PrimaryType)
+	}
+	td.Message = numbersToStrings(map[string]interface{}(td.Message)).(map[string]interface{})
+	return &td, nil
+}
+
+func numbersToStrings(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		return val.String()
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = numbersToStrings(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = numbersToStrings(item)
+		}
+		return val
+	}
+	return v
+}
+
+// validateDomain 校验 EIP-712 domain：EIP712Domain 类型与 domain 字段一致、chainId 与链一致，
+// verifyingContract 公开 DOMAIN_SEPARATOR() 时必须与本地计算的结果相同
+func (l *SignMessageLogic) validateDomain(c *chains.Chain, td *apitypes.TypedData) ([]string, error) {
+	var warnings []string
+
+	declared := make(map[string]bool)
+	for _, f := range td.Types["EIP712Domain"] {
+		if !eip712DomainFields[f.Name] {
+			return nil, fmt.Errorf("unknown EIP712Domain field %s", f.Name)
+		}
+		declared[f.Name] = true
+	}
+	domain := td.Domain.Map()
+	if len(domain) == 0 {
+		return nil, errors.New("typed data domain is empty")
+	}
+	// domain 中未声明的字段不参与哈希，展示内容会与签名内容不一致
+	for name := range domain {
+		if !declared[name] {
+			return nil, fmt.Errorf("domain field %s is not declared in EIP712Domain", name)
+		}
+	}
+	for name := range declared {
+		if _, ok := domain[name]; !ok {
+			return nil, fmt.Errorf("EIP712Domain field %s is missing from domain", name)
+		}
+	}
+
+	if td.Domain.ChainId == nil {
+		warnings = append(warnings, "domain has no chainId, the signature is valid on every chain")
+	} else if chainId := (*big.Int)(td.Domain.ChainId); chainId.Cmp(big.NewInt(c.ChainId)) != 0 {
+		return warnings, &policy.Violation{Rule: "EIP712Domain", Reason: fmt.Sprintf("domain chainId %s does not match %s (%d)", chainId, c.DisplayName(), c.ChainId)}
+	}
+
+	if td.Domain.VerifyingContract == "" {
+		return warnings, nil
+	}
+	if !common.IsHexAddress(td.Domain.VerifyingContract) {
+		return warnings, fmt.Errorf("invalid verifyingContract: %s", td.Domain.VerifyingContract)
+	}
+
+	separator, err := td.HashStruct("EIP712Domain", domain)
+	if err != nil {
+		return warnings, fmt.Errorf("invalid typed data domain: %v", err)
+	}
+	caller, err := l.svcCtx.Multicall.Caller(c)
+	if err != nil {
+		return append(warnings, "could not verify the domain separator on chain"), nil
+	}
+	results, err := caller.Aggregate(l.ctx, []multicall.Call{multicall.DomainSeparator(common.HexToAddress(td.Domain.VerifyingContract))})
+	if err != nil {
+		l.Infof("⚠️  读取 DOMAIN_SEPARATOR 失败: %v", err)
+		return append(warnings, "could not verify the domain separator on chain"), nil
+	}
+	if !results[0].Success || len(results[0].Data) != 32 {
+		// 合约未公开 DOMAIN_SEPARATOR()，无法比对
+		return warnings, nil
+	}
+	if !bytes.Equal(results[0].Data, separator) {
+		return warnings, &policy.Violation{Rule: "EIP712Domain", Reason: fmt.Sprintf(
+			"domain separator %s does not match %s.DOMAIN_SEPARATOR() %s",
+			hexutil.Encode(separator), td.Domain.VerifyingContract, hexutil.Encode(results[0].Data))}
+	}
+	return warnings, nil
+}
+
+// typedDataFields 把 apitypes 的展示结构转换为响应字段
+func typedDataFields(nvts []*apitypes.NameValueType) []types.TypedDataField {
+	fields := make([]types.TypedDataField, 0, len(nvts))
+	for _, nvt := range nvts {
+		field := types.TypedDataField{Name: nvt.Name, Type: nvt.Typ, Value: nvt.Value}
+		if nested, ok := nvt.Value.([]*apitypes.NameValueType); ok {
+			field.Value = typedDataFields(nested)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// signEVMHash 对摘要签名，返回 r || s || v（v 为 27/28）
+func (l *SignMessageLogic) signEVMHash(address string, hash []byte) (string, error) {
+	privateKey, err := transaction.NewTransactionLogic(l.ctx, l.svcCtx).GetWalletPrivateKey(address)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(crypto.PubkeyToAddress(privateKey.PublicKey).Hex(), address) {
+		return "", fmt.Errorf("private key does not match address %s", address)
+	}
+	sig, err := crypto.Sign(hash, privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign message: %v", err)
+	}
+	sig[64] += 27
+	return hexutil.Encode(sig), nil
+}
+
+// signBIP322 BIP-322 签名：隔离见证 P2WPKH 地址输出 simple 格式（见证栈），
+// P2PKH 地址没有见证数据，输出 full 格式（完整的 to_sign 交易）
+func (l *SignMessageLogic) signBIP322(c *chains.Chain, address string, message []byte) (string, string, error) {
+	params := &chaincfg.MainNetParams
+	if c.Testnet {
+		params = &chaincfg.TestNet3Params
+	}
+	addr, err := btcutil.DecodeAddress(address, params)
+	if err != nil || !addr.IsForNet(params) {
+		return "", "", fmt.Errorf("invalid Bitcoin address for %s: %s", c.DisplayName(), address)
+	}
+
+	ecdsaKey, err := transaction.NewTransactionLogic(l.ctx, l.svcCtx).GetWalletPrivateKey(address)
+	if err != nil {
+		return "", "", err
+	}
+	privKey, _ := btcec.PrivKeyFromBytes(crypto.FromECDSA(ecdsaKey))
+	if !bytes.Equal(btcutil.Hash160(privKey.PubKey().SerializeCompressed()), addr.ScriptAddress()) {
+		return "", "", fmt.Errorf("private key does not match address %s", address)
+	}
+	return bip322Sign(privKey, addr, message)
+}
+
+func bip322Sign(privKey *btcec.PrivateKey, addr btcutil.Address, message []byte) (string, string, error) {
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return "", "", err
+	}
+
+	// to_spend：虚拟输入承载消息哈希，输出为签名地址的脚本
+	msgHash := chainhash.TaggedHash(bip322Tag, message)
+	toSpend := wire.NewMsgTx(0)
+	toSpend.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: 0xffffffff},
+		SignatureScript:  append([]byte{txscript.OP_0, txscript.OP_DATA_32}, msgHash[:]...),
+		Sequence:         0,
+	})
+	toSpend.AddTxOut(wire.NewTxOut(0, pkScript))
+
+	// to_sign：花费 to_spend，输出 OP_RETURN
+	toSign := wire.NewMsgTx(0)
+	toSign.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Hash: toSpend.TxHash(), Index: 0}, Sequence: 0})
+	toSign.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+
+	var buf bytes.Buffer
+	switch addr.(type) {
+	case *btcutil.AddressWitnessPubKeyHash:
+		fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+		sigHashes := txscript.NewTxSigHashes(toSign, fetcher)
+		witness, err := txscript.WitnessSignature(toSign, sigHashes, 0, 0, pkScript, txscript.SigHashAll, privKey, true)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to sign BIP-322 message: %v", err)
+		}
+		if err := wire.WriteVarInt(&buf, 0, uint64(len(witness))); err != nil {
+			return "", "", err
+		}
+		for _, item := range witness {
+			if err := wire.WriteVarBytes(&buf, 0, item); err != nil {
+				return "", "", err
+			}
+		}
+		return base64.StdEncoding.EncodeToString(buf.Bytes()), "simple", nil
+
+	case *btcutil.AddressPubKeyHash:
+		sigScript, err := txscript.SignatureScript(toSign, 0, pkScript, txscript.SigHashAll, privKey, true)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to sign BIP-322 message: %v", err)
+		}
+		toSign.TxIn[0].SignatureScript = sigScript
+		if err := toSign.Serialize(&buf); err != nil {
+			return "", "", err
+		}
+		return base64.StdEncoding.EncodeToString(buf.Bytes()), "full", nil
+	}
+	return "", "", fmt.Errorf("BIP-322 signing is not supported for address type %T", addr)
+}
+
+// signSolana ed25519 签名原始消息字节（与钱包 signMessage 一致），签名为 base58
+func (l *SignMessageLogic) signSolana(address string, message []byte) (string, error) {
+	privateKeyBytes, err := transaction.NewTransactionLogic(l.ctx, l.svcCtx).GetSolanaPrivateKey(address)
+	if err != nil {
+		return "", err
+	}
+	account, err := solanaTypes.AccountFromBytes(privateKeyBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Solana account: %v", err)
+	}
+	if account.PublicKey.ToBase58() != address {
+		return "", fmt.Errorf("private key does not match address %s", address)
+	}
+	return base58.Encode(account.Sign(message)), nil
+}
@@ -0,0 +1,108 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"demo/internal/erc4337"
+	"demo/internal/model"
+	"demo/internal/svc"
+	"demo/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SmartAccountLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+func NewSmartAccountLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SmartAccountLogic {
+	return &SmartAccountLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// CreateSmartAccount 为托管的 EVM 钱包创建 SimpleAccount 智能账户：地址由工厂合约按 (owner, salt)
+// 反事实计算，不发送交易，合约在第一笔 UserOperation 时部署。重复创建返回已有账户
+func (l *SmartAccountLogic) CreateSmartAccount(req *types.SmartAccountReq) (*types.SmartAccountResp, error) {
+	l.Infof("--- 开始处理 /wallet/smart_account 请求, chain: %s, owner: %s ---", req.Chain, req.Owner)
+
+	c, ok := l.svcCtx.Chains.Get(req.Chain)
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %s", req.Chain)
+	}
+	network, err := l.svcCtx.Bundlers.Network(c)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(req.Owner) {
+		return nil, fmt.Errorf("invalid owner: %s", req.Owner)
+	}
+	owner, err := l.svcCtx.WalletsDao.FindOneByAddress(l.ctx, req.Owner)
+	if err != nil {
+		l.Errorf("查询钱包失败 for address %s: %v", req.Owner, err)
+		return nil, errors.New("owner wallet not found")
+	}
+	salt := new(big.Int)
+	if req.Salt != "" {
+		if _, ok := salt.SetString(req.Salt, 10); !ok || salt.Sign() < 0 || salt.BitLen() > 256 {
+			return nil, fmt.Errorf("invalid salt: %s", req.Salt)
+		}
+	}
+
+	client, err := l.svcCtx.RPC.EthClient(c)
+	if err != nil {
+		l.Errorf("RPC 节点连接失败: %v", err)
+		return nil, errors.New("failed to connect to chain")
+	}
+	address, err := erc4337.CounterfactualAddress(l.ctx, client, network.Factory, common.HexToAddress(owner.Address), salt)
+	if err != nil {
+		l.Errorf("计算智能账户地址失败: %v", err)
+		return nil, err
+	}
+	code, err := client.CodeAt(l.ctx, address, nil)
+	if err != nil {
+		l.Errorf("查询智能账户代码失败: %v", err)
+		return nil, errors.New("failed to check smart account deployment")
+	}
+
+	account := &model.SmartAccounts{
+		Chain:      c.Key,
+		Address:    address.Hex(),
+		Owner:      owner.Address,
+		Factory:    network.Factory.Hex(),
+		EntryPoint: network.EntryPoint.Hex(),
+		Salt:       salt.String(),
+		Deployed:   len(code) > 0,
+	}
+	if err := l.svcCtx.SmartAccountsDao.Insert(l.ctx, account); err != nil {
+		if !errors.Is(err, model.ErrDuplicateKey) {
+			l.Errorf("保存智能账户失败: %v", err)
+			return nil, errors.New("failed to save smart account")
+		}
+		if account, err = l.svcCtx.SmartAccountsDao.FindOneByChainAndAddress(l.ctx, c.Key, address.Hex()); err != nil {
+			return nil, errors.New("failed to load smart account")
+		}
+		l.Infof("智能账户已存在: %s", account.Address)
+	} else {
+		l.Infof("✅ 智能账户已登记: %s (owner %s, salt %s)", account.Address, account.Owner, account.Salt)
+	}
+
+	return &types.SmartAccountResp{
+		Chain:       c.Key,
+		Address:     account.Address,
+		Owner:       account.Owner,
+		Salt:        account.Salt,
+		Factory:     account.Factory,
+		EntryPoint:  account.EntryPoint,
+		Deployed:    len(code) > 0,
+		ExplorerUrl: c.ExplorerAddress(account.Address),
+	}, nil
+}
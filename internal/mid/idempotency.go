@@ -0,0 +1,332 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"demo/internal/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// IdempotencyHeader 客户端传入的幂等键请求头
+	IdempotencyHeader = "Idempotency-Key"
+	// IdempotencyReplayedHeader 回放历史响应时附带的响应头
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 255
+	pollInterval         = 200 * time.Millisecond
+)
+
+// IdempotencyMiddleware 为有副作用的 POST 接口提供幂等保护：
+// 相同 Idempotency-Key + 相同请求体只执行一次，重复请求直接回放首次响应。
+// 成功响应和 5xx 响应都会保存；4xx 响应只有在处理过程中没有加载私钥签名（见 MarkSigning）时才释放该键，
+// 客户端可以用同一个键修正后重试，签名之后的失败可能已经广播，回放错误而不是重新执行。
+type IdempotencyMiddleware struct {
+	dao         model.IdempotencyDao
+	ttl         time.Duration
+	waitTimeout time.Duration
+	// lease processing 状态超过该时长视为首次请求已中断（进程崩溃或被杀），由后续请求接管
+	lease time.Duration
+
+	mu       sync.Mutex
+	inflight map[string]chan struct{}
+}
+
+func NewIdempotencyMiddleware(dao model.IdempotencyDao, ttl, waitTimeout, lease time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		dao:         dao,
+		ttl:         ttl,
+		waitTimeout: waitTimeout,
+		lease:       lease,
+		inflight:    make(map[string]chan struct{}),
+	}
+}
+
+func (m *IdempotencyMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			http.Error(w, "Idempotency-Key too long", http.StatusBadRequest)
+			return
+		}
+
+		l := logx.WithContext(r.Context())
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(r, body)
+
+		deadline := time.Now().Add(m.waitTimeout)
+		for {
+			record, err := m.dao.FindOne(r.Context(), key)
+			switch {
+			case errors.Is(err, model.ErrNotFound):
+				claimed, err := m.claim(r, key, fingerprint)
+				if err != nil {
+					l.Errorf("❌ 幂等键 %s 写入失败: %v", key, err)
+					http.Error(w, "idempotency store unavailable", http.StatusInternalServerError)
+					return
+				}
+				if !claimed {
+					// 被并发请求抢先占用，等待后重新读取状态
+					if err := m.wait(r.Context(), key, deadline); err != nil {
+						return
+					}
+					continue
+				}
+				m.execute(w, r, key, next)
+				return
+			case err != nil:
+				l.Errorf("❌ 查询幂等键 %s 失败: %v", key, err)
+				http.Error(w, "idempotency store unavailable", http.StatusInternalServerError)
+				return
+			}
+
+			if m.ttl > 0 && time.Since(record.CreatedAt) > m.ttl && record.Status == model.IdempotencyStatusCompleted {
+				// 过期记录视为不存在，允许复用该键
+				l.Infof("♻️ 幂等键 %s 已过期，重新执行", key)
+				if err := m.dao.Delete(r.Context(), key); err != nil {
+					l.Errorf("❌ 删除过期幂等键 %s 失败: %v", key, err)
+				}
+				continue
+			}
+
+			if m.lease > 0 && record.Status == model.IdempotencyStatusProcessing && time.Since(record.UpdatedAt) > m.lease {
+				// 首次请求所在进程已退出，删除后重新占用；条件删除保证只有一个请求接管
+				taken, err := m.dao.DeleteStale(r.Context(), key, time.Now().Add(-m.lease))
+				if err != nil {
+					l.Errorf("❌ 释放中断的幂等键 %s 失败: %v", key, err)
+					http.Error(w, "idempotency store unavailable", http.StatusInternalServerError)
+					return
+				}
+				if taken {
+					l.Infof("♻️ 幂等键 %s 的首次请求已中断，重新执行", key)
+				}
+				continue
+			}
+
+			if record.Fingerprint != fingerprint {
+				l.Infof("⚠️ 幂等键 %s 对应的请求内容不一致", key)
+				http.Error(w, "Idempotency-Key is already used with a different request", http.StatusConflict)
+				return
+			}
+
+			if record.Status == model.IdempotencyStatusCompleted {
+				l.Infof("🔁 幂等键 %s 命中，回放首次响应", key)
+				replay(w, record)
+				return
+			}
+
+			// 首次请求仍在执行中，等待其完成
+			if time.Now().After(deadline) {
+				http.Error(w, "a request with the same Idempotency-Key is still in progress", http.StatusConflict)
+				return
+			}
+			if err := m.wait(r.Context(), key, deadline); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// claim 尝试占用幂等键，返回 false 表示已被其他请求占用
+func (m *IdempotencyMiddleware) claim(r *http.Request, key, fingerprint string) (bool, error) {
+	m.mu.Lock()
+	if _, ok := m.inflight[key]; ok {
+		m.mu.Unlock()
+		return false, nil
+	}
+	m.inflight[key] = make(chan struct{})
+	m.mu.Unlock()
+
+	now := time.Now()
+	err := m.dao.Insert(r.Context(), &model.IdempotencyKeys{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Method:      r.Method,
+		Path:        r.URL.Path,
+		Status:      model.IdempotencyStatusProcessing,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		m.release(key)
+		if errors.Is(err, model.ErrDuplicateKey) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// execute 执行真正的业务处理并持久化响应；执行期间定期刷新 updated_at，避免耗时的请求被当作中断而接管。
+// 没有签名的 4xx 响应和异常释放该键，其余响应（包括签名后的错误和超时等 5xx）保存下来供重复请求回放
+func (m *IdempotencyMiddleware) execute(w http.ResponseWriter, r *http.Request, key string, next http.HandlerFunc) {
+	rec := &responseRecorder{ResponseWriter: w, code: http.StatusOK}
+	// 响应需要在请求上下文超时后依然能落库
+	storeCtx := context.WithoutCancel(r.Context())
+	signing := &signingState{}
+	r = r.WithContext(context.WithValue(r.Context(), signingKey{}, signing))
+
+	stopRenew := m.renew(storeCtx, key)
+	defer func() {
+		defer m.release(key)
+		stopRenew()
+		if p := recover(); p != nil {
+			if signing.started.Load() {
+				// 签名后异常，交易可能已经广播，保存 500 避免重试时再次执行
+				m.complete(storeCtx, key, http.StatusInternalServerError, "text/plain; charset=utf-8", []byte("internal error after signing"))
+			} else if err := m.dao.Delete(storeCtx, key); err != nil {
+				logx.WithContext(storeCtx).Errorf("❌ 释放幂等键 %s 失败: %v", key, err)
+			}
+			panic(p)
+		}
+		if rec.code >= http.StatusBadRequest && rec.code < http.StatusInternalServerError && !signing.started.Load() {
+			if err := m.dao.Delete(storeCtx, key); err != nil {
+				logx.WithContext(storeCtx).Errorf("❌ 释放幂等键 %s 失败: %v", key, err)
+			}
+			return
+		}
+		m.complete(storeCtx, key, rec.code, rec.Header().Get("Content-Type"), rec.body.Bytes())
+	}()
+
+	next(rec, r)
+}
+
+func (m *IdempotencyMiddleware) complete(ctx context.Context, key string, code int, contentType string, body []byte) {
+	if err := m.dao.Complete(ctx, key, code, contentType, body); err != nil {
+		logx.WithContext(ctx).Errorf("❌ 保存幂等响应 %s 失败: %v", key, err)
+	}
+}
+
+// renew 每隔 lease/3 刷新 processing 记录的 updated_at，返回停止函数；lease 为 0 时不接管，也无需刷新
+func (m *IdempotencyMiddleware) renew(ctx context.Context, key string) func() {
+	if m.lease <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(m.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.dao.Touch(ctx, key); err != nil {
+					logx.WithContext(ctx).Errorf("❌ 刷新幂等键 %s 失败: %v", key, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// signingKey 请求上下文中记录是否已开始签名
+type signingKey struct{}
+
+type signingState struct {
+	started atomic.Bool
+}
+
+// MarkSigning 标记当前请求已加载私钥准备签名：之后的失败可能发生在广播之后，
+// 幂等中间件会保存错误响应而不是释放该键，客户端用同一个键重试时不会再次广播。不经过幂等中间件的请求无影响
+func MarkSigning(ctx context.Context) {
+	if s, ok := ctx.Value(signingKey{}).(*signingState); ok {
+		s.started.Store(true)
+	}
+}
+
+// wait 阻塞直到同进程内的首次请求结束，或到达轮询间隔
+func (m *IdempotencyMiddleware) wait(ctx context.Context, key string, deadline time.Time) error {
+	m.mu.Lock()
+	done := m.inflight[key]
+	m.mu.Unlock()
+
+	timer := time.NewTimer(min(pollInterval, time.Until(deadline)))
+	defer timer.Stop()
+
+	select {
+	case <-done: // nil channel 表示首次请求在其他实例上，依赖轮询
+	case <-timer.C:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (m *IdempotencyMiddleware) release(key string) {
+	m.mu.Lock()
+	if done, ok := m.inflight[key]; ok {
+		close(done)
+		delete(m.inflight, key)
+	}
+	m.mu.Unlock()
+}
+
+func replay(w http.ResponseWriter, record *model.IdempotencyKeys) {
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	w.Header().Set(IdempotencyReplayedHeader, "true")
+	w.WriteHeader(record.ResponseCode)
+	_, _ = w.Write(record.ResponseBody)
+}
+
+// requestFingerprint 由方法、路径和请求体计算请求指纹
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder 在透传响应的同时记录状态码和响应体
+type responseRecorder struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,232 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"demo/internal/model"
+)
+
+// memDao 内存实现的 IdempotencyDao
+type memDao struct {
+	mu      sync.Mutex
+	records map[string]model.IdempotencyKeys
+	touches atomic.Int32
+}
+
+func newMemDao() *memDao {
+	return &memDao{records: make(map[string]model.IdempotencyKeys)}
+}
+
+func (d *memDao) Insert(_ context.Context, data *model.IdempotencyKeys) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.records[data.Key]; ok {
+		return model.ErrDuplicateKey
+	}
+	d.records[data.Key] = *data
+	return nil
+}
+
+func (d *memDao) FindOne(_ context.Context, key string) (*model.IdempotencyKeys, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r, ok := d.records[key]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+	return &r, nil
+}
+
+func (d *memDao) Complete(_ context.Context, key string, code int, contentType string, body []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r := d.records[key]
+	r.Status, r.ResponseCode, r.ContentType, r.ResponseBody, r.UpdatedAt = model.IdempotencyStatusCompleted, code, contentType, body, time.Now()
+	d.records[key] = r
+	return nil
+}
+
+func (d *memDao) Delete(_ context.Context, key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.records, key)
+	return nil
+}
+
+func (d *memDao) Touch(_ context.Context, key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if r, ok := d.records[key]; ok && r.Status == model.IdempotencyStatusProcessing {
+		r.UpdatedAt = time.Now()
+		d.records[key] = r
+		d.touches.Add(1)
+	}
+	return nil
+}
+
+func (d *memDao) DeleteStale(_ context.Context, key string, before time.Time) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if r, ok := d.records[key]; ok && r.Status == model.IdempotencyStatusProcessing && r.UpdatedAt.Before(before) {
+		delete(d.records, key)
+		return true, nil
+	}
+	return false, nil
+}
+
+// handler 按 code 返回响应，sign 为 true 时先标记签名，调用次数计入 calls
+func handler(calls *atomic.Int32, code int, sign bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if sign {
+			MarkSigning(r.Context())
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(code)
+		w.Write([]byte("call " + strconv.Itoa(int(n))))
+	}
+}
+
+func post(h http.HandlerFunc, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/transaction/send", strings.NewReader(body))
+	req.Header.Set(IdempotencyHeader, key)
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	return rec
+}
+
+func TestIdempotencyResponses(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      int
+		sign      bool
+		wantCalls int32 // 同一个键发送两次后的执行次数
+		replayed  bool  // 第二次是否回放
+	}{
+		{"success is replayed", http.StatusOK, true, 1, true},
+		{"validation error releases the key", http.StatusBadRequest, false, 2, false},
+		{"error after signing is replayed", http.StatusBadRequest, true, 1, true},
+		{"server error is replayed", http.StatusInternalServerError, false, 1, true},
+		{"timeout is replayed", http.StatusServiceUnavailable, false, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			m := NewIdempotencyMiddleware(newMemDao(), time.Hour, time.Second, time.Minute)
+			h := m.Handle(handler(&calls, tt.code, tt.sign))
+
+			first := post(h, "k1", `{"amount":"1"}`)
+			second := post(h, "k1", `{"amount":"1"}`)
+			if first.Code != tt.code || second.Code != tt.code {
+				t.Fatalf("got %d then %d, want %d", first.Code, second.Code, tt.code)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Fatalf("handler ran %d times, want %d", got, tt.wantCalls)
+			}
+			if replayed := second.Header().Get(IdempotencyReplayedHeader) == "true"; replayed != tt.replayed {
+				t.Fatalf("replayed = %v, want %v", replayed, tt.replayed)
+			}
+			if tt.replayed && second.Body.String() != first.Body.String() {
+				t.Fatalf("replayed body %q, want %q", second.Body.String(), first.Body.String())
+			}
+		})
+	}
+}
+
+func TestIdempotencyConflict(t *testing.T) {
+	var calls atomic.Int32
+	m := NewIdempotencyMiddleware(newMemDao(), time.Hour, time.Second, time.Minute)
+	h := m.Handle(handler(&calls, http.StatusOK, true))
+
+	post(h, "k1", `{"amount":"1"}`)
+	if rec := post(h, "k1", `{"amount":"2"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("got %d, want 409", rec.Code)
+	}
+	if rec := post(h, "k2", `{"amount":"2"}`); rec.Code != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("another key: got %d after %d calls", rec.Code, calls.Load())
+	}
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	var calls atomic.Int32
+	m := NewIdempotencyMiddleware(newMemDao(), time.Hour, time.Second, time.Minute)
+	h := m.Handle(handler(&calls, http.StatusOK, true))
+	post(h, "", `{}`)
+	post(h, "", `{}`)
+	if calls.Load() != 2 {
+		t.Fatalf("handler ran %d times, want 2", calls.Load())
+	}
+}
+
+func TestIdempotencyTakeover(t *testing.T) {
+	body := `{"amount":"1"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/transaction/send", strings.NewReader(body))
+	fingerprint := requestFingerprint(req, []byte(body))
+
+	tests := []struct {
+		name      string
+		updatedAt time.Duration // processing 记录最后刷新距今
+		wantCode  int
+		wantCalls int32
+	}{
+		{"abandoned key is taken over", -2 * time.Second, http.StatusOK, 1},
+		{"live key is waited for", -100 * time.Millisecond, http.StatusConflict, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dao := newMemDao()
+			now := time.Now()
+			dao.records["k1"] = model.IdempotencyKeys{
+				Key: "k1", Fingerprint: fingerprint, Method: http.MethodPost, Path: "/api/transaction/send",
+				Status: model.IdempotencyStatusProcessing, CreatedAt: now.Add(tt.updatedAt), UpdatedAt: now.Add(tt.updatedAt),
+			}
+			var calls atomic.Int32
+			m := NewIdempotencyMiddleware(dao, time.Hour, 300*time.Millisecond, time.Second)
+			rec := post(m.Handle(handler(&calls, http.StatusOK, true)), "k1", body)
+			if rec.Code != tt.wantCode || calls.Load() != tt.wantCalls {
+				t.Fatalf("got %d after %d calls, want %d after %d", rec.Code, calls.Load(), tt.wantCode, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// 执行时间超过 lease 的请求持续刷新记录，不会被另一个实例接管
+func TestIdempotencyRenewsLongRequests(t *testing.T) {
+	dao := newMemDao()
+	const lease = 150 * time.Millisecond
+	var calls atomic.Int32
+	started := make(chan struct{})
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		close(started)
+		time.Sleep(3 * lease)
+		w.WriteHeader(http.StatusOK)
+	}
+	first := NewIdempotencyMiddleware(dao, time.Hour, time.Second, lease)
+	other := NewIdempotencyMiddleware(dao, time.Hour, 10*time.Millisecond, lease) // 另一个实例
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		post(first.Handle(slow), "k1", `{}`)
+	}()
+	<-started
+	time.Sleep(2 * lease)
+	rec := post(other.Handle(handler(&calls, http.StatusOK, true)), "k1", `{}`)
+	<-done
+
+	if rec.Code != http.StatusConflict || calls.Load() != 1 {
+		t.Fatalf("got %d after %d calls, want 409 after 1", rec.Code, calls.Load())
+	}
+	if dao.touches.Load() == 0 {
+		t.Fatal("processing key was never refreshed")
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// AuditFilter narrows down audit log queries. Empty fields are ignored.
+type AuditFilter struct {
+	Address string
+	Action  string
+}
+
+// AuditEntriesDao defines the interface for database operations on the audit_entries table.
+// Audit entries are append-only: there is no update or delete.
+type AuditEntriesDao interface {
+	Insert(ctx context.Context, data *AuditEntries) error
+	FindPage(ctx context.Context, filter AuditFilter, page, pageSize int) ([]*AuditEntries, int64, error)
+}
+
+type auditEntriesDao struct {
+	db *gorm.DB
+}
+
+// NewAuditEntriesDao creates a new instance of AuditEntriesDao.
+func NewAuditEntriesDao(db *gorm.DB) AuditEntriesDao {
+	return &auditEntriesDao{
+		db: db,
+	}
+}
+
+// Insert appends a record to the audit_entries table.
+func (d *auditEntriesDao) Insert(ctx context.Context, data *AuditEntries) error {
+	return d.db.WithContext(ctx).Create(data).Error
+}
+
+// FindPage retrieves one page of audit entries (newest first), plus the total count.
+func (d *auditEntriesDao) FindPage(ctx context.Context, filter AuditFilter, page, pageSize int) ([]*AuditEntries, int64, error) {
+	query := d.db.WithContext(ctx).Model(&AuditEntries{})
+	if filter.Address != "" {
+		query = query.Where("address = ?", filter.Address)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []*AuditEntries
+	if err := query.Scopes(paginate(page, pageSize)).Order("id DESC").Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+	AuditResultDenied  = "denied"
+)
+
+// AuditEntries corresponds to the audit_entries table in the database.
+type AuditEntries struct {
+	Id        int64     `gorm:"column:id;primaryKey"`
+	Actor     string    `gorm:"column:actor"`
+	Action    string    `gorm:"column:action"`
+	Chain     string    `gorm:"column:chain"`
+	Address   string    `gorm:"column:address"`
+	Target    string    `gorm:"column:target"`
+	Result    string    `gorm:"column:result"`
+	Detail    string    `gorm:"column:detail"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (AuditEntries) TableName() string {
+	return "audit_entries"
+}
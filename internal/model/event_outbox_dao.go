@@ -0,0 +1,123 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EventOutboxDao defines the interface for database operations on the
+// token_event_outbox and monitor_cursors tables.
+type EventOutboxDao interface {
+	// SaveBlock stores the events of a block and advances the cursor in one transaction.
+	// Events whose dedup key already exists are skipped.
+	SaveBlock(ctx context.Context, cursor string, blockNumber uint64, events []*TokenEventOutbox) error
+	FindCursor(ctx context.Context, cursor string) (uint64, error)
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*TokenEventOutbox, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	MarkRetry(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastErr string) error
+	MarkDead(ctx context.Context, id int64, attempts int, lastErr string) error
+}
+
+type eventOutboxDao struct {
+	db *gorm.DB
+}
+
+// NewEventOutboxDao creates a new instance of EventOutboxDao.
+func NewEventOutboxDao(db *gorm.DB) EventOutboxDao {
+	return &eventOutboxDao{
+		db: db,
+	}
+}
+
+// SaveBlock stores the events of a block and advances the cursor in one transaction.
+func (d *eventOutboxDao) SaveBlock(ctx context.Context, cursor string, blockNumber uint64, events []*TokenEventOutbox) error {
+	now := time.Now()
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, event := range events {
+			event.Status = OutboxStatusPending
+			event.NextAttemptAt = now
+			event.CreatedAt = now
+			event.UpdatedAt = now
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "dedup_key"}},
+				DoNothing: true,
+			}).Create(event).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"block_number", "updated_at"}),
+		}).Create(&MonitorCursors{Name: cursor, BlockNumber: blockNumber, UpdatedAt: now}).Error
+	})
+}
+
+// FindCursor returns the last processed block for a cursor, or ErrNotFound.
+func (d *eventOutboxDao) FindCursor(ctx context.Context, cursor string) (uint64, error) {
+	var resp MonitorCursors
+	err := d.db.WithContext(ctx).Where("name = ?", cursor).First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	return resp.BlockNumber, nil
+}
+
+// FindDue returns pending events whose next attempt time has passed, oldest first.
+func (d *eventOutboxDao) FindDue(ctx context.Context, now time.Time, limit int) ([]*TokenEventOutbox, error) {
+	var events []*TokenEventOutbox
+	err := d.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", OutboxStatusPending, now).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkDelivered marks an event as published.
+func (d *eventOutboxDao) MarkDelivered(ctx context.Context, id int64) error {
+	now := time.Now()
+	return d.db.WithContext(ctx).Model(&TokenEventOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       OutboxStatusDelivered,
+			"delivered_at": now,
+			"last_error":   "",
+			"updated_at":   now,
+		}).Error
+}
+
+// MarkRetry records a failed attempt and schedules the next one.
+func (d *eventOutboxDao) MarkRetry(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastErr string) error {
+	return d.db.WithContext(ctx).Model(&TokenEventOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastErr,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// MarkDead gives up on an event after too many failed attempts.
+func (d *eventOutboxDao) MarkDead(ctx context.Context, id int64, attempts int, lastErr string) error {
+	return d.db.WithContext(ctx).Model(&TokenEventOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     OutboxStatusDead,
+			"attempts":   attempts,
+			"last_error": lastErr,
+			"updated_at": time.Now(),
+		}).Error
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusDelivered = "delivered"
+	OutboxStatusDead      = "dead" // exceeded max attempts, needs manual replay
+)
+
+// TokenEventOutbox corresponds to the token_event_outbox table in the database.
+// Each row is one parsed TokenEvent waiting to be published by the relay.
+type TokenEventOutbox struct {
+	Id            int64      `gorm:"column:id;primaryKey"`
+	DedupKey      string     `gorm:"column:dedup_key"`
+	ChainId       uint64     `gorm:"column:chain_id"`
+	BlockNumber   uint64     `gorm:"column:block_number"`
+	TxHash        string     `gorm:"column:tx_hash"`
+	EventType     string     `gorm:"column:event_type"`
+	Payload       string     `gorm:"column:payload"`
+	Status        string     `gorm:"column:status"`
+	Attempts      int        `gorm:"column:attempts"`
+	NextAttemptAt time.Time  `gorm:"column:next_attempt_at"`
+	LastError     string     `gorm:"column:last_error"`
+	DeliveredAt   *time.Time `gorm:"column:delivered_at"`
+	CreatedAt     time.Time  `gorm:"column:created_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (TokenEventOutbox) TableName() string {
+	return "token_event_outbox"
+}
+
+// MonitorCursors corresponds to the monitor_cursors table in the database.
+// It stores the last fully processed block of each chain monitor.
+type MonitorCursors struct {
+	Name        string    `gorm:"column:name;primaryKey"`
+	BlockNumber uint64    `gorm:"column:block_number"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (MonitorCursors) TableName() string {
+	return "monitor_cursors"
+}
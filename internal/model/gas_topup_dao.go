@@ -0,0 +1,81 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GasTopUpsDao defines the interface for database operations on the gas_topups table.
+type GasTopUpsDao interface {
+	Insert(ctx context.Context, data *GasTopUps) error
+	// Finish stores the final status, fee and error of a top-up.
+	Finish(ctx context.Context, data *GasTopUps) error
+	// FindSince returns the top-ups created at or after since, optionally filtered by chain and wallet.
+	FindSince(ctx context.Context, chain, wallet string, since time.Time) ([]*GasTopUps, error)
+	FindPage(ctx context.Context, chain, wallet string, page, pageSize int) ([]*GasTopUps, int64, error)
+}
+
+type gasTopUpsDao struct {
+	db *gorm.DB
+}
+
+// NewGasTopUpsDao creates a new instance of GasTopUpsDao.
+func NewGasTopUpsDao(db *gorm.DB) GasTopUpsDao {
+	return &gasTopUpsDao{
+		db: db,
+	}
+}
+
+// Insert adds a new record to the gas_topups table.
+func (d *gasTopUpsDao) Insert(ctx context.Context, data *GasTopUps) error {
+	return d.db.WithContext(ctx).Create(data).Error
+}
+
+// Finish updates the outcome columns of a top-up.
+func (d *gasTopUpsDao) Finish(ctx context.Context, data *GasTopUps) error {
+	return d.db.WithContext(ctx).Model(&GasTopUps{}).
+		Where("id = ?", data.Id).
+		Updates(map[string]interface{}{
+			"status":        data.Status,
+			"fee":           data.Fee,
+			"error_message": data.ErrorMessage,
+			"confirmed_at":  data.ConfirmedAt,
+		}).Error
+}
+
+// FindSince retrieves top-ups created since the given time, oldest first.
+func (d *gasTopUpsDao) FindSince(ctx context.Context, chain, wallet string, since time.Time) ([]*GasTopUps, error) {
+	var rows []*GasTopUps
+	err := d.filter(ctx, chain, wallet).Where("created_at >= ?", since).Order("id").Find(&rows).Error
+	return rows, err
+}
+
+// FindPage retrieves one page of top-ups (newest first) plus the total count.
+func (d *gasTopUpsDao) FindPage(ctx context.Context, chain, wallet string, page, pageSize int) ([]*GasTopUps, int64, error) {
+	query := d.filter(ctx, chain, wallet)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []*GasTopUps
+	if err := query.Scopes(paginate(page, pageSize)).Order("id DESC").Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+// filter builds a query on gas_topups restricted to chain and wallet when they are set.
+func (d *gasTopUpsDao) filter(ctx context.Context, chain, wallet string) *gorm.DB {
+	query := d.db.WithContext(ctx).Model(&GasTopUps{})
+	if chain != "" {
+		query = query.Where("chain = ?", chain)
+	}
+	if wallet != "" {
+		query = query.Where("wallet = ?", wallet)
+	}
+	return query
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+const (
+	GasTopUpStatusPending   = "pending"
+	GasTopUpStatusConfirmed = "confirmed"
+	GasTopUpStatusFailed    = "failed"
+
+	// 触发补充 gas 的操作
+	GasTopUpPurposeSend    = "send"
+	GasTopUpPurposeApprove = "approve"
+	GasTopUpPurposeSwap    = "swap"
+	GasTopUpPurposeBridge  = "bridge"
+	GasTopUpPurposeSweep   = "sweep"
+)
+
+// GasTopUps corresponds to the gas_topups table in the database.
+// One row per native transfer sent by a chain's gas station to a wallet that
+// lacked gas; Amount is the native currency sent and Fee what the gas wallet
+// paid for the transfer itself (both in wei, Fee is set once confirmed).
+// Wallet and Funder are EIP-55 checksummed addresses.
+type GasTopUps struct {
+	Id           int64      `gorm:"column:id;primaryKey"`
+	Chain        string     `gorm:"column:chain"`
+	Wallet       string     `gorm:"column:wallet"`
+	Funder       string     `gorm:"column:funder"`
+	Amount       string     `gorm:"column:amount"`
+	Fee          string     `gorm:"column:fee"`
+	Purpose      string     `gorm:"column:purpose"`
+	TxHash       string     `gorm:"column:tx_hash"`
+	Status       string     `gorm:"column:status"`
+	ErrorMessage string     `gorm:"column:error_message"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+	ConfirmedAt  *time.Time `gorm:"column:confirmed_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (GasTopUps) TableName() string {
+	return "gas_topups"
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyDao defines the interface for database operations on the idempotency_keys table.
+type IdempotencyDao interface {
+	Insert(ctx context.Context, data *IdempotencyKeys) error
+	FindOne(ctx context.Context, key string) (*IdempotencyKeys, error)
+	Complete(ctx context.Context, key string, code int, contentType string, body []byte) error
+	Delete(ctx context.Context, key string) error
+	// Touch refreshes updated_at of a key that is still processing, extending its lease.
+	Touch(ctx context.Context, key string) error
+	// DeleteStale removes a key still processing since before the given time and reports whether it did.
+	DeleteStale(ctx context.Context, key string, before time.Time) (bool, error)
+}
+
+type idempotencyDao struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyDao creates a new instance of IdempotencyDao.
+func NewIdempotencyDao(db *gorm.DB) IdempotencyDao {
+	return &idempotencyDao{
+		db: db,
+	}
+}
+
+// Insert claims a key. It returns ErrDuplicateKey if the key already exists.
+func (d *idempotencyDao) Insert(ctx context.Context, data *IdempotencyKeys) error {
+	err := d.db.WithContext(ctx).Create(data).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateKey
+	}
+	return err
+}
+
+// FindOne retrieves a single record by its key.
+func (d *idempotencyDao) FindOne(ctx context.Context, key string) (*IdempotencyKeys, error) {
+	var resp IdempotencyKeys
+	err := d.db.WithContext(ctx).Where("idempotency_key = ?", key).First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Complete stores the final response for a key and marks it completed.
+func (d *idempotencyDao) Complete(ctx context.Context, key string, code int, contentType string, body []byte) error {
+	return d.db.WithContext(ctx).Model(&IdempotencyKeys{}).
+		Where("idempotency_key = ?", key).
+		Updates(map[string]interface{}{
+			"status":        IdempotencyStatusCompleted,
+			"response_code": code,
+			"content_type":  contentType,
+			"response_body": body,
+			"updated_at":    time.Now(),
+		}).Error
+}
+
+// Delete removes a key so that it can be used again.
+func (d *idempotencyDao) Delete(ctx context.Context, key string) error {
+	return d.db.WithContext(ctx).Where("idempotency_key = ?", key).Delete(&IdempotencyKeys{}).Error
+}
+
+// Touch refreshes updated_at of a processing key so that it is not taken over as abandoned.
+func (d *idempotencyDao) Touch(ctx context.Context, key string) error {
+	return d.db.WithContext(ctx).Model(&IdempotencyKeys{}).
+		Where("idempotency_key = ? AND status = ?", key, IdempotencyStatusProcessing).
+		Update("updated_at", time.Now()).Error
+}
+
+// DeleteStale removes an abandoned processing key; a fresh claim made in the meantime is left untouched.
+func (d *idempotencyDao) DeleteStale(ctx context.Context, key string, before time.Time) (bool, error) {
+	result := d.db.WithContext(ctx).
+		Where("idempotency_key = ? AND status = ? AND updated_at < ?", key, IdempotencyStatusProcessing, before).
+		Delete(&IdempotencyKeys{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+const (
+	// IdempotencyStatusProcessing marks a key whose first request is still running.
+	IdempotencyStatusProcessing = "processing"
+	// IdempotencyStatusCompleted marks a key whose response has been stored.
+	IdempotencyStatusCompleted = "completed"
+)
+
+// IdempotencyKeys corresponds to the idempotency_keys table in the database.
+type IdempotencyKeys struct {
+	Key          string    `gorm:"column:idempotency_key;primaryKey"`
+	Fingerprint  string    `gorm:"column:fingerprint"`
+	Method       string    `gorm:"column:method"`
+	Path         string    `gorm:"column:path"`
+	Status       string    `gorm:"column:status"`
+	ResponseCode int       `gorm:"column:response_code"`
+	ResponseBody []byte    `gorm:"column:response_body"`
+	ContentType  string    `gorm:"column:content_type"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (IdempotencyKeys) TableName() string {
+	return "idempotency_keys"
+}
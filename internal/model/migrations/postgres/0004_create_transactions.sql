@@ -0,0 +1,20 @@
+-- 已广播交易记录（send / swap / bridge / approve 等）
+CREATE TABLE IF NOT EXISTS transactions (
+    id            BIGSERIAL PRIMARY KEY,
+    chain         VARCHAR(32)  NOT NULL,
+    tx_hash       VARCHAR(128) NOT NULL,
+    tx_type       VARCHAR(32)  NOT NULL,
+    from_address  VARCHAR(128) NOT NULL,
+    to_address    VARCHAR(128) NOT NULL DEFAULT '',
+    token         VARCHAR(128) NOT NULL DEFAULT '',
+    amount        VARCHAR(80)  NOT NULL DEFAULT '0',
+    status        VARCHAR(16)  NOT NULL,
+    explorer_url  VARCHAR(512) NOT NULL DEFAULT '',
+    error_message TEXT         NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_transactions_chain_tx_hash ON transactions (chain, tx_hash);
+CREATE INDEX IF NOT EXISTS idx_transactions_from_address ON transactions (from_address);
+CREATE INDEX IF NOT EXISTS idx_transactions_to_address ON transactions (to_address);
@@ -0,0 +1,36 @@
+-- Safe 多签：登记的 Safe（owner 与阈值为最近一次从链上读取的快照）和待收集签名的 Safe 交易
+CREATE TABLE IF NOT EXISTS safes (
+    id          BIGSERIAL PRIMARY KEY,
+    chain       VARCHAR(32)  NOT NULL,
+    address     VARCHAR(64)  NOT NULL,
+    version     VARCHAR(16)  NOT NULL,
+    threshold   INTEGER      NOT NULL,
+    owners      TEXT         NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_safes_chain_address ON safes (chain, address);
+
+CREATE TABLE IF NOT EXISTS safe_transactions (
+    id            BIGSERIAL PRIMARY KEY,
+    chain         VARCHAR(32)  NOT NULL,
+    safe_address  VARCHAR(64)  NOT NULL,
+    safe_tx_hash  VARCHAR(80)  NOT NULL,
+    nonce         BIGINT       NOT NULL,
+    to_address    VARCHAR(64)  NOT NULL,
+    value         VARCHAR(80)  NOT NULL DEFAULT '0',
+    data          TEXT         NOT NULL,
+    operation     SMALLINT     NOT NULL DEFAULT 0,
+    description   VARCHAR(255) NOT NULL DEFAULT '',
+    proposer      VARCHAR(64)  NOT NULL,
+    signatures    TEXT         NOT NULL DEFAULT '[]',
+    status        VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    tx_hash       VARCHAR(80)  NOT NULL DEFAULT '',
+    error_message TEXT         NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_safe_transactions_hash ON safe_transactions (safe_tx_hash);
+CREATE INDEX IF NOT EXISTS idx_safe_transactions_safe ON safe_transactions (chain, safe_address, status, nonce);
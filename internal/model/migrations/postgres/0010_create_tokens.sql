@@ -0,0 +1,15 @@
+-- 代币注册表：按链缓存代币的 symbol / name / decimals，来源为 LI.FI 代币列表或链上读取
+CREATE TABLE IF NOT EXISTS tokens (
+    id         BIGSERIAL PRIMARY KEY,
+    chain      VARCHAR(32)  NOT NULL,
+    address    VARCHAR(64)  NOT NULL,
+    symbol     VARCHAR(64)  NOT NULL DEFAULT '',
+    name       VARCHAR(128) NOT NULL DEFAULT '',
+    decimals   INTEGER      NOT NULL,
+    source     VARCHAR(16)  NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_tokens_chain_address ON tokens (chain, address);
+CREATE INDEX IF NOT EXISTS idx_tokens_chain_symbol ON tokens (chain, UPPER(symbol));
@@ -0,0 +1,47 @@
+-- 定时转账：scheduled_transfers 保存转账意图（一次性 run_at 或 cron 周期），每次触发记录一行 scheduled_transfer_runs，
+-- 提交的交易通过 (chain, tx_hash) 关联 transactions 表
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id                   BIGSERIAL PRIMARY KEY,
+    name                 VARCHAR(128) NOT NULL DEFAULT '',
+    kind                 VARCHAR(16)  NOT NULL,
+    chain                VARCHAR(32)  NOT NULL,
+    from_address         VARCHAR(64)  NOT NULL,
+    to_address           VARCHAR(64)  NOT NULL,
+    from_token           VARCHAR(64)  NOT NULL,
+    to_token             VARCHAR(64)  NOT NULL,
+    amount               VARCHAR(80)  NOT NULL,
+    amount_unit          VARCHAR(16)  NOT NULL DEFAULT '',
+    fee_level            VARCHAR(16)  NOT NULL DEFAULT '',
+    run_at               TIMESTAMPTZ,
+    cron                 VARCHAR(128) NOT NULL DEFAULT '',
+    timezone             VARCHAR(64)  NOT NULL DEFAULT 'UTC',
+    catch_up             VARCHAR(16)  NOT NULL DEFAULT 'once',
+    max_runs             INTEGER      NOT NULL DEFAULT 0,
+    end_at               TIMESTAMPTZ,
+    status               VARCHAR(16)  NOT NULL DEFAULT 'active',
+    next_run_at          TIMESTAMPTZ,
+    last_run_at          TIMESTAMPTZ,
+    run_count            INTEGER      NOT NULL DEFAULT 0,
+    consecutive_failures INTEGER      NOT NULL DEFAULT 0,
+    last_error           TEXT         NOT NULL DEFAULT '',
+    version              BIGINT       NOT NULL DEFAULT 0,
+    created_at           TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers (status, next_run_at);
+
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+    id            BIGSERIAL PRIMARY KEY,
+    schedule_id   BIGINT       NOT NULL,
+    scheduled_for TIMESTAMPTZ  NOT NULL,
+    catch_up      BOOLEAN      NOT NULL DEFAULT FALSE,
+    status        VARCHAR(16)  NOT NULL DEFAULT 'running',
+    tx_hash       VARCHAR(128) NOT NULL DEFAULT '',
+    error_message TEXT         NOT NULL DEFAULT '',
+    started_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    finished_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfer_runs_schedule ON scheduled_transfer_runs (schedule_id, id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfer_runs_status ON scheduled_transfer_runs (status);
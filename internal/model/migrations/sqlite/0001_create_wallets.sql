@@ -0,0 +1,12 @@
+-- 钱包表：每条记录对应一个链上地址及其（待加密的）私钥
+CREATE TABLE IF NOT EXISTS wallets (
+    id                    INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id               VARCHAR(64)  NOT NULL,
+    address               VARCHAR(128) NOT NULL,
+    encrypted_private_key TEXT         NOT NULL,
+    phone_number          VARCHAR(32),
+    email                 VARCHAR(255),
+    chain_type            VARCHAR(32),
+    created_at            DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at            DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
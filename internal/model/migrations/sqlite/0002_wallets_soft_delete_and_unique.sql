@@ -0,0 +1,8 @@
+-- 软删除 + (chain_type, address) 唯一约束（仅对未删除记录生效）
+ALTER TABLE wallets ADD COLUMN deleted_at DATETIME;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_wallets_chain_type_address
+    ON wallets (chain_type, address) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_wallets_user_id ON wallets (user_id);
+CREATE INDEX IF NOT EXISTS idx_wallets_address ON wallets (address);
+CREATE INDEX IF NOT EXISTS idx_wallets_deleted_at ON wallets (deleted_at);
@@ -0,0 +1,13 @@
+-- Idempotency-Key 记录：请求指纹 + 首次响应
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(255) PRIMARY KEY,
+    fingerprint     VARCHAR(64)  NOT NULL,
+    method          VARCHAR(16)  NOT NULL,
+    path            VARCHAR(255) NOT NULL,
+    status          VARCHAR(16)  NOT NULL,
+    response_code   INTEGER      NOT NULL DEFAULT 0,
+    response_body   BLOB,
+    content_type    VARCHAR(128) NOT NULL DEFAULT '',
+    created_at      DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
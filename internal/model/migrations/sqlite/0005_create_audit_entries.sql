@@ -0,0 +1,16 @@
+-- 审计日志：记录签名、授权、策略拒绝等敏感操作
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor      VARCHAR(128) NOT NULL DEFAULT '',
+    action     VARCHAR(64)  NOT NULL,
+    chain      VARCHAR(32)  NOT NULL DEFAULT '',
+    address    VARCHAR(128) NOT NULL DEFAULT '',
+    target     VARCHAR(256) NOT NULL DEFAULT '',
+    result     VARCHAR(16)  NOT NULL,
+    detail     TEXT         NOT NULL DEFAULT '',
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_entries_address ON audit_entries (address);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_action ON audit_entries (action);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_created_at ON audit_entries (created_at);
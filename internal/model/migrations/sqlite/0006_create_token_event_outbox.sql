@@ -0,0 +1,27 @@
+-- TokenEvent 发件箱：事件与区块游标在同一事务内写入，由 relay 异步投递
+CREATE TABLE IF NOT EXISTS token_event_outbox (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    dedup_key       VARCHAR(255) NOT NULL,
+    chain_id        BIGINT       NOT NULL,
+    block_number    BIGINT       NOT NULL,
+    tx_hash         VARCHAR(128) NOT NULL,
+    event_type      VARCHAR(32)  NOT NULL,
+    payload         TEXT         NOT NULL,
+    status          VARCHAR(16)  NOT NULL,
+    attempts        INTEGER      NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error      TEXT         NOT NULL DEFAULT '',
+    delivered_at    DATETIME,
+    created_at      DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_token_event_outbox_dedup_key ON token_event_outbox (dedup_key);
+CREATE INDEX IF NOT EXISTS idx_token_event_outbox_status_next ON token_event_outbox (status, next_attempt_at);
+
+-- 各链监控器最后一个已完整处理的区块
+CREATE TABLE IF NOT EXISTS monitor_cursors (
+    name         VARCHAR(64) PRIMARY KEY,
+    block_number BIGINT      NOT NULL,
+    updated_at   DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,24 @@
+-- 收款请求：/transaction/receive 指定期望金额时创建，由监控匹配入账交易
+CREATE TABLE IF NOT EXISTS payment_requests (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    request_id   VARCHAR(64)  NOT NULL,
+    user_id      VARCHAR(64)  NOT NULL,
+    chain        VARCHAR(32)  NOT NULL,
+    chain_id     BIGINT       NOT NULL,
+    token        VARCHAR(128) NOT NULL DEFAULT '',
+    address      VARCHAR(128) NOT NULL,
+    amount       VARCHAR(80)  NOT NULL,
+    reference    VARCHAR(64)  NOT NULL DEFAULT '',
+    uri          TEXT         NOT NULL,
+    status       VARCHAR(16)  NOT NULL,
+    paid_tx_hash VARCHAR(128) NOT NULL DEFAULT '',
+    paid_amount  VARCHAR(80)  NOT NULL DEFAULT '',
+    expires_at   DATETIME     NOT NULL,
+    paid_at      DATETIME,
+    created_at   DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_payment_requests_request_id ON payment_requests (request_id);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_match ON payment_requests (chain_id, address, token, status);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_paid_tx_hash ON payment_requests (paid_tx_hash);
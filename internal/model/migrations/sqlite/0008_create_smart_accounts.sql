@@ -0,0 +1,16 @@
+-- ERC-4337 智能账户：地址由工厂合约按 (owner, salt) 反事实计算，首个 UserOperation 时部署
+CREATE TABLE IF NOT EXISTS smart_accounts (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    chain       VARCHAR(32)  NOT NULL,
+    address     VARCHAR(64)  NOT NULL,
+    owner       VARCHAR(64)  NOT NULL,
+    factory     VARCHAR(64)  NOT NULL,
+    entry_point VARCHAR(64)  NOT NULL,
+    salt        VARCHAR(80)  NOT NULL,
+    deployed    BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_smart_accounts_chain_address ON smart_accounts (chain, address);
+CREATE INDEX IF NOT EXISTS idx_smart_accounts_owner ON smart_accounts (owner);
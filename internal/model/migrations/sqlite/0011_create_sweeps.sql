@@ -0,0 +1,37 @@
+-- 资金归集：每次执行一条归集规则记录一行 sweep_runs，扫描到的有余额钱包记录在 sweep_items
+CREATE TABLE IF NOT EXISTS sweep_runs (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    rule          VARCHAR(64)  NOT NULL,
+    chain         VARCHAR(32)  NOT NULL,
+    token         VARCHAR(64)  NOT NULL DEFAULT '',
+    symbol        VARCHAR(32)  NOT NULL DEFAULT '',
+    destination   VARCHAR(64)  NOT NULL,
+    triggered_by  VARCHAR(16)  NOT NULL,
+    status        VARCHAR(16)  NOT NULL DEFAULT 'running',
+    scanned       INTEGER      NOT NULL DEFAULT 0,
+    swept         INTEGER      NOT NULL DEFAULT 0,
+    skipped       INTEGER      NOT NULL DEFAULT 0,
+    failed        INTEGER      NOT NULL DEFAULT 0,
+    total_amount  VARCHAR(80)  NOT NULL DEFAULT '0',
+    error_message TEXT         NOT NULL DEFAULT '',
+    started_at    DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at   DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_sweep_runs_rule ON sweep_runs (rule, started_at);
+CREATE INDEX IF NOT EXISTS idx_sweep_runs_status ON sweep_runs (status);
+
+CREATE TABLE IF NOT EXISTS sweep_items (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_id         BIGINT       NOT NULL,
+    wallet         VARCHAR(64)  NOT NULL,
+    balance        VARCHAR(80)  NOT NULL DEFAULT '0',
+    amount         VARCHAR(80)  NOT NULL DEFAULT '0',
+    status         VARCHAR(16)  NOT NULL,
+    reason         TEXT         NOT NULL DEFAULT '',
+    tx_hash        VARCHAR(128) NOT NULL DEFAULT '',
+    top_up_tx_hash VARCHAR(128) NOT NULL DEFAULT '',
+    created_at     DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sweep_items_run ON sweep_items (run_id);
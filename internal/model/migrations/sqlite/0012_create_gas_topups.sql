@@ -0,0 +1,17 @@
+-- gas 站：为持有代币但原生币不足的钱包补充 gas 的记录，用于每日限额和代付手续费统计
+CREATE TABLE IF NOT EXISTS gas_topups (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    chain         VARCHAR(32)  NOT NULL,
+    wallet        VARCHAR(64)  NOT NULL,
+    funder        VARCHAR(64)  NOT NULL,
+    amount        VARCHAR(80)  NOT NULL,
+    fee           VARCHAR(80)  NOT NULL DEFAULT '0',
+    purpose       VARCHAR(16)  NOT NULL,
+    tx_hash       VARCHAR(128) NOT NULL DEFAULT '',
+    status        VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    error_message TEXT         NOT NULL DEFAULT '',
+    created_at    DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    confirmed_at  DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_gas_topups_wallet ON gas_topups (chain, wallet, created_at);
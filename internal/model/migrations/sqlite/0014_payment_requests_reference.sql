@@ -0,0 +1,2 @@
+-- Solana Pay 收款请求按 reference 匹配
+CREATE INDEX IF NOT EXISTS idx_payment_requests_reference ON payment_requests (reference);
@@ -0,0 +1,4 @@
+-- 收款请求按事件（交易哈希 + 日志序号）去重，同一笔交易可以支付多个收款请求
+ALTER TABLE payment_requests ADD COLUMN paid_event_key VARCHAR(160) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_payment_requests_paid_event_key ON payment_requests (chain_id, paid_event_key);
@@ -0,0 +1,30 @@
+package model
+
+import "gorm.io/gorm"
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// normalizePage clamps page (1-based) and pageSize to sane values.
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	return page, pageSize
+}
+
+// paginate returns a scope that applies LIMIT/OFFSET for the given page.
+func paginate(page, pageSize int) func(db *gorm.DB) *gorm.DB {
+	page, pageSize = normalizePage(page, pageSize)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Offset((page - 1) * pageSize).Limit(pageSize)
+	}
+}
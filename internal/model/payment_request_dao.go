@@ -0,0 +1,119 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PaymentRequestsDao defines the interface for database operations on the payment_requests table.
+type PaymentRequestsDao interface {
+	Insert(ctx context.Context, data *PaymentRequests) error
+	FindOneByRequestId(ctx context.Context, requestId string) (*PaymentRequests, error)
+	// MatchIncoming marks the oldest pending, unexpired request for (chainId, address, token)
+	// whose expected amount is covered by the received amount as paid. It returns
+	// ErrNotFound when nothing matches. Replaying the same eventKey is a no-op; other
+	// events of the same transaction (e.g. a batch paying several addresses) match separately.
+	MatchIncoming(ctx context.Context, chainId int64, address, token, amount, txHash, eventKey string) (*PaymentRequests, error)
+}
+
+type paymentRequestsDao struct {
+	db *gorm.DB
+}
+
+// NewPaymentRequestsDao creates a new instance of PaymentRequestsDao.
+func NewPaymentRequestsDao(db *gorm.DB) PaymentRequestsDao {
+	return &paymentRequestsDao{
+		db: db,
+	}
+}
+
+// Insert adds a new record to the payment_requests table.
+func (d *paymentRequestsDao) Insert(ctx context.Context, data *PaymentRequests) error {
+	return d.db.WithContext(ctx).Create(data).Error
+}
+
+// FindOneByRequestId retrieves a single payment request by its public id.
+func (d *paymentRequestsDao) FindOneByRequestId(ctx context.Context, requestId string) (*PaymentRequests, error) {
+	var resp PaymentRequests
+	err := d.db.WithContext(ctx).Where("request_id = ?", requestId).First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MatchIncoming marks the best pending request as paid by the event eventKey of txHash.
+func (d *paymentRequestsDao) MatchIncoming(ctx context.Context, chainId int64, address, token, amount, txHash, eventKey string) (*PaymentRequests, error) {
+	received, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	var matched *PaymentRequests
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 同一事件已经匹配过（重复投递）；升级前匹配的记录没有事件键，按交易、地址和代币判断
+		var existing PaymentRequests
+		err := tx.Where("chain_id = ? AND (paid_event_key = ? OR (paid_event_key = '' AND paid_tx_hash = ? AND address = ? AND token = ?))",
+			chainId, eventKey, txHash, address, token).
+			First(&existing).Error
+		if err == nil {
+			matched = &existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var candidates []*PaymentRequests
+		err = tx.Where("chain_id = ? AND address = ? AND token = ? AND status = ? AND expires_at > ?",
+			chainId, address, token, PaymentStatusPending, time.Now()).
+			Order("id").
+			Find(&candidates).Error
+		if err != nil {
+			return err
+		}
+
+		for _, c := range candidates {
+			expected, ok := new(big.Int).SetString(c.Amount, 10)
+			if !ok || received.Cmp(expected) < 0 {
+				continue
+			}
+			now := time.Now()
+			// 条件更新，避免并发匹配同一条请求
+			res := tx.Model(&PaymentRequests{}).
+				Where("id = ? AND status = ?", c.Id, PaymentStatusPending).
+				Updates(map[string]interface{}{
+					"status":         PaymentStatusPaid,
+					"paid_tx_hash":   txHash,
+					"paid_event_key": eventKey,
+					"paid_amount":    amount,
+					"paid_at":        now,
+					"updated_at":     now,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+			c.Status, c.PaidTxHash, c.PaidEventKey, c.PaidAmount, c.PaidAt = PaymentStatusPaid, txHash, eventKey, amount, &now
+			matched = c
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if matched == nil {
+		return nil, ErrNotFound
+	}
+	return matched, nil
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"demo/internal/model/migrations"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 内存 SQLite，已执行全部迁移
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // 每个连接是独立的内存库
+	t.Cleanup(func() { sqlDB.Close() })
+	if _, err := migrations.Up(db, "sqlite"); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestMatchIncoming(t *testing.T) {
+	const (
+		chainId = 56
+		token   = "0x55d398326f99059ff775485246999027b3197955"
+		alice   = "0x1111111111111111111111111111111111111111"
+		bob     = "0x2222222222222222222222222222222222222222"
+		txHash  = "0xabc"
+	)
+	key := func(logIndex string) string { return "56:" + txHash + ":" + logIndex + ":Transfer" }
+
+	type event struct {
+		address, amount, txHash, key string
+		want                         string // 匹配到的 request_id，为空表示没有匹配
+	}
+	tests := []struct {
+		name     string
+		requests map[string][2]string // request_id -> {address, amount}
+		events   []event
+	}{
+		{
+			name:     "one transaction pays several addresses",
+			requests: map[string][2]string{"pr_alice": {alice, "100"}, "pr_bob": {bob, "200"}},
+			events: []event{
+				{alice, "100", txHash, key("3"), "pr_alice"},
+				{bob, "200", txHash, key("4"), "pr_bob"},
+			},
+		},
+		{
+			name:     "one transaction pays the same address twice",
+			requests: map[string][2]string{"pr_1": {alice, "100"}, "pr_2": {alice, "100"}},
+			events: []event{
+				{alice, "100", txHash, key("3"), "pr_1"},
+				{alice, "100", txHash, key("4"), "pr_2"},
+			},
+		},
+		{
+			name:     "replayed event matches once",
+			requests: map[string][2]string{"pr_1": {alice, "100"}, "pr_2": {alice, "100"}},
+			events: []event{
+				{alice, "100", txHash, key("3"), "pr_1"},
+				{alice, "100", txHash, key("3"), "pr_1"},
+			},
+		},
+		{
+			name:     "underpayment does not match",
+			requests: map[string][2]string{"pr_1": {alice, "100"}},
+			events: []event{
+				{alice, "99", txHash, key("3"), ""},
+				{alice, "150", "0xdef", "56:0xdef:0:Transfer", "pr_1"},
+			},
+		},
+		{
+			name:     "other address does not match",
+			requests: map[string][2]string{"pr_1": {alice, "100"}},
+			events:   []event{{bob, "100", txHash, key("3"), ""}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dao := NewPaymentRequestsDao(newTestDB(t))
+			ctx := context.Background()
+			now := time.Now()
+			for _, id := range []string{"pr_1", "pr_2", "pr_alice", "pr_bob"} {
+				r, ok := tt.requests[id]
+				if !ok {
+					continue
+				}
+				err := dao.Insert(ctx, &PaymentRequests{
+					RequestId: id, UserId: "u", Chain: "BSC", ChainId: chainId, Token: token,
+					Address: r[0], Amount: r[1], Uri: "ethereum:", Status: PaymentStatusPending,
+					ExpiresAt: now.Add(time.Hour), CreatedAt: now, UpdatedAt: now,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for i, e := range tt.events {
+				pr, err := dao.MatchIncoming(ctx, chainId, e.address, token, e.amount, e.txHash, e.key)
+				if e.want == "" {
+					if !errors.Is(err, ErrNotFound) {
+						t.Fatalf("event %d: got %v, %v, want ErrNotFound", i, pr, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("event %d: %v", i, err)
+				}
+				if pr.RequestId != e.want || pr.Status != PaymentStatusPaid || pr.PaidEventKey != e.key {
+					t.Fatalf("event %d: got %s (%s, %s), want %s", i, pr.RequestId, pr.Status, pr.PaidEventKey, e.want)
+				}
+			}
+		})
+	}
+}
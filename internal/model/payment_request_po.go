@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+const (
+	PaymentStatusPending = "pending"
+	PaymentStatusPaid    = "paid"
+	PaymentStatusExpired = "expired"
+)
+
+// PaymentRequests corresponds to the payment_requests table in the database.
+// A row is created by /transaction/receive when an expected amount is given,
+// and is matched by the monitor when a matching incoming transfer is seen.
+type PaymentRequests struct {
+	Id         int64  `gorm:"column:id;primaryKey"`
+	RequestId  string `gorm:"column:request_id"`
+	UserId     string `gorm:"column:user_id"`
+	Chain      string `gorm:"column:chain"`
+	ChainId    int64  `gorm:"column:chain_id"`
+	Token      string `gorm:"column:token"`   // contract / mint, lower-cased for EVM; empty for native
+	Address    string `gorm:"column:address"` // deposit address, lower-cased for EVM
+	Amount     string `gorm:"column:amount"`  // expected amount in base units
+	Reference  string `gorm:"column:reference"`
+	Uri        string `gorm:"column:uri"`
+	Status     string `gorm:"column:status"`
+	PaidTxHash string `gorm:"column:paid_tx_hash"`
+	// PaidEventKey is the dedup key of the matched event (chain, tx hash, log index),
+	// so one transaction paying several requests matches each of them once.
+	PaidEventKey string     `gorm:"column:paid_event_key"`
+	PaidAmount   string     `gorm:"column:paid_amount"`
+	ExpiresAt    time.Time  `gorm:"column:expires_at"`
+	PaidAt       *time.Time `gorm:"column:paid_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (PaymentRequests) TableName() string {
+	return "payment_requests"
+}
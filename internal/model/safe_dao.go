@@ -0,0 +1,294 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrStaleUpdate is returned when a row changed between read and conditional update.
+var ErrStaleUpdate = errors.New("record was modified concurrently")
+
+// SafesDao defines the interface for database operations on the safes table.
+type SafesDao interface {
+	// Upsert inserts the Safe or refreshes its version, threshold and owners.
+	Upsert(ctx context.Context, data *Safes) error
+	FindOneByChainAndAddress(ctx context.Context, chain, address string) (*Safes, error)
+}
+
+type safesDao struct {
+	db *gorm.DB
+}
+
+// NewSafesDao creates a new instance of SafesDao.
+func NewSafesDao(db *gorm.DB) SafesDao {
+	return &safesDao{
+		db: db,
+	}
+}
+
+// Upsert inserts the Safe or updates the on-chain snapshot of an existing one.
+func (d *safesDao) Upsert(ctx context.Context, data *Safes) error {
+	existing, err := d.FindOneByChainAndAddress(ctx, data.Chain, data.Address)
+	if errors.Is(err, ErrNotFound) {
+		err = d.db.WithContext(ctx).Create(data).Error
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return err
+		}
+		// 并发登记，改为更新
+		existing, err = d.FindOneByChainAndAddress(ctx, data.Chain, data.Address)
+	}
+	if err != nil {
+		return err
+	}
+	data.Id, data.CreatedAt = existing.Id, existing.CreatedAt
+	return d.db.WithContext(ctx).Model(&Safes{}).
+		Where("id = ?", existing.Id).
+		Updates(map[string]interface{}{
+			"version":    data.Version,
+			"threshold":  data.Threshold,
+			"owners":     data.Owners,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// FindOneByChainAndAddress retrieves a registered Safe by chain key and checksummed address.
+func (d *safesDao) FindOneByChainAndAddress(ctx context.Context, chain, address string) (*Safes, error) {
+	var resp Safes
+	err := d.db.WithContext(ctx).Where("chain = ? AND address = ?", chain, address).First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SafeTransactionsDao defines the interface for database operations on the safe_transactions table.
+type SafeTransactionsDao interface {
+	Insert(ctx context.Context, data *SafeTransactions) error
+	FindOneBySafeTxHash(ctx context.Context, safeTxHash string) (*SafeTransactions, error)
+	FindPage(ctx context.Context, chain, safeAddress, status string, page, pageSize int) ([]*SafeTransactions, int64, error)
+	// MaxPendingNonce returns the highest nonce among pending and executing transactions of the Safe, -1 if none.
+	MaxPendingNonce(ctx context.Context, chain, safeAddress string) (int64, error)
+	// UpdateSignatures replaces the signatures only if they still equal expected,
+	// returning ErrStaleUpdate when another request added a signature first.
+	UpdateSignatures(ctx context.Context, id int64, expected, signatures string) error
+	// ClaimExecution moves a pending transaction to executing, returning false when
+	// another request already claimed it or it is no longer pending.
+	ClaimExecution(ctx context.Context, id int64) (bool, error)
+	// SetExecTxHash records the broadcast execTransaction hash of a claimed transaction.
+	SetExecTxHash(ctx context.Context, id int64, txHash string) error
+	// ReleaseExecution returns a claimed transaction to pending with the reason the
+	// attempt failed before anything was broadcast.
+	ReleaseExecution(ctx context.Context, id int64, errMsg string) error
+	// MarkExecuted marks a claimed transaction executed after a successful receipt and
+	// marks other pending transactions of the Safe with the same nonce as replaced.
+	MarkExecuted(ctx context.Context, id int64, txHash string) error
+	// MarkFailed marks a claimed transaction failed after its execTransaction reverted.
+	MarkFailed(ctx context.Context, id int64, errMsg string) error
+	// FindExecuting returns the claimed transactions of the Safe that are waiting for a receipt.
+	FindExecuting(ctx context.Context, chain, safeAddress string) ([]*SafeTransactions, error)
+	// ReplaceStale marks pending transactions of the Safe whose nonce is below the
+	// on-chain nonce as replaced and returns how many were marked.
+	ReplaceStale(ctx context.Context, chain, safeAddress string, nonce int64) (int64, error)
+}
+
+type safeTransactionsDao struct {
+	db *gorm.DB
+}
+
+// NewSafeTransactionsDao creates a new instance of SafeTransactionsDao.
+func NewSafeTransactionsDao(db *gorm.DB) SafeTransactionsDao {
+	return &safeTransactionsDao{
+		db: db,
+	}
+}
+
+// Insert adds a new record to the safe_transactions table.
+// It returns ErrDuplicateKey if the safe_tx_hash already exists.
+func (d *safeTransactionsDao) Insert(ctx context.Context, data *SafeTransactions) error {
+	err := d.db.WithContext(ctx).Create(data).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateKey
+	}
+	return err
+}
+
+// FindOneBySafeTxHash retrieves a Safe transaction by its EIP-712 hash.
+func (d *safeTransactionsDao) FindOneBySafeTxHash(ctx context.Context, safeTxHash string) (*SafeTransactions, error) {
+	var resp SafeTransactions
+	err := d.db.WithContext(ctx).Where("safe_tx_hash = ?", safeTxHash).First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FindPage retrieves one page of Safe transactions (lowest nonce first), plus the total count.
+func (d *safeTransactionsDao) FindPage(ctx context.Context, chain, safeAddress, status string, page, pageSize int) ([]*SafeTransactions, int64, error) {
+	query := d.db.WithContext(ctx).Model(&SafeTransactions{}).
+		Where("chain = ? AND safe_address = ?", chain, safeAddress)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var txs []*SafeTransactions
+	if err := query.Scopes(paginate(page, pageSize)).Order("nonce, id").Find(&txs).Error; err != nil {
+		return nil, 0, err
+	}
+	return txs, total, nil
+}
+
+// MaxPendingNonce returns the highest pending nonce of the Safe, or -1.
+func (d *safeTransactionsDao) MaxPendingNonce(ctx context.Context, chain, safeAddress string) (int64, error) {
+	var nonce *int64
+	err := d.db.WithContext(ctx).Model(&SafeTransactions{}).
+		Where("chain = ? AND safe_address = ? AND status IN ?", chain, safeAddress, []string{SafeTxStatusPending, SafeTxStatusExecuting}).
+		Select("MAX(nonce)").
+		Scan(&nonce).Error
+	if err != nil {
+		return 0, err
+	}
+	if nonce == nil {
+		return -1, nil
+	}
+	return *nonce, nil
+}
+
+// UpdateSignatures conditionally replaces the collected signatures.
+func (d *safeTransactionsDao) UpdateSignatures(ctx context.Context, id int64, expected, signatures string) error {
+	result := d.db.WithContext(ctx).Model(&SafeTransactions{}).
+		Where("id = ? AND signatures = ? AND status = ?", id, expected, SafeTxStatusPending).
+		Updates(map[string]interface{}{
+			"signatures": signatures,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleUpdate
+	}
+	return nil
+}
+
+// ClaimExecution conditionally moves the transaction from pending to executing.
+func (d *safeTransactionsDao) ClaimExecution(ctx context.Context, id int64) (bool, error) {
+	result := d.db.WithContext(ctx).Model(&SafeTransactions{}).
+		Where("id = ? AND status = ?", id, SafeTxStatusPending).
+		Updates(map[string]interface{}{
+			"status":        SafeTxStatusExecuting,
+			"tx_hash":       "",
+			"error_message": "",
+			"updated_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetExecTxHash records the execTransaction hash while the transaction is executing.
+func (d *safeTransactionsDao) SetExecTxHash(ctx context.Context, id int64, txHash string) error {
+	return d.settle(ctx, id, map[string]interface{}{
+		"tx_hash": txHash,
+	})
+}
+
+// ReleaseExecution returns the claimed transaction to pending and records the error.
+func (d *safeTransactionsDao) ReleaseExecution(ctx context.Context, id int64, errMsg string) error {
+	return d.settle(ctx, id, map[string]interface{}{
+		"status":        SafeTxStatusPending,
+		"tx_hash":       "",
+		"error_message": errMsg,
+	})
+}
+
+// MarkExecuted marks the claimed transaction executed and replaces its nonce siblings.
+func (d *safeTransactionsDao) MarkExecuted(ctx context.Context, id int64, txHash string) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var executed SafeTransactions
+		if err := tx.Where("id = ?", id).First(&executed).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		result := tx.Model(&SafeTransactions{}).
+			Where("id = ? AND status = ?", id, SafeTxStatusExecuting).
+			Updates(map[string]interface{}{
+				"status":        SafeTxStatusExecuted,
+				"tx_hash":       txHash,
+				"error_message": "",
+				"updated_at":    now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleUpdate
+		}
+		return tx.Model(&SafeTransactions{}).
+			Where("chain = ? AND safe_address = ? AND nonce = ? AND status = ? AND id <> ?",
+				executed.Chain, executed.SafeAddress, executed.Nonce, SafeTxStatusPending, id).
+			Updates(map[string]interface{}{
+				"status":     SafeTxStatusReplaced,
+				"updated_at": now,
+			}).Error
+	})
+}
+
+// MarkFailed marks the claimed transaction failed and records the revert reason.
+func (d *safeTransactionsDao) MarkFailed(ctx context.Context, id int64, errMsg string) error {
+	return d.settle(ctx, id, map[string]interface{}{
+		"status":        SafeTxStatusFailed,
+		"error_message": errMsg,
+	})
+}
+
+// settle updates a transaction only while it is still executing, returning
+// ErrStaleUpdate when another request already settled it.
+func (d *safeTransactionsDao) settle(ctx context.Context, id int64, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	result := d.db.WithContext(ctx).Model(&SafeTransactions{}).
+		Where("id = ? AND status = ?", id, SafeTxStatusExecuting).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleUpdate
+	}
+	return nil
+}
+
+// FindExecuting retrieves the executing transactions of the Safe, lowest nonce first.
+func (d *safeTransactionsDao) FindExecuting(ctx context.Context, chain, safeAddress string) ([]*SafeTransactions, error) {
+	var txs []*SafeTransactions
+	err := d.db.WithContext(ctx).
+		Where("chain = ? AND safe_address = ? AND status = ?", chain, safeAddress, SafeTxStatusExecuting).
+		Order("nonce, id").
+		Find(&txs).Error
+	return txs, err
+}
+
+// ReplaceStale marks pending transactions whose nonce has already been used on chain as replaced.
+func (d *safeTransactionsDao) ReplaceStale(ctx context.Context, chain, safeAddress string, nonce int64) (int64, error) {
+	result := d.db.WithContext(ctx).Model(&SafeTransactions{}).
+		Where("chain = ? AND safe_address = ? AND nonce < ? AND status = ?", chain, safeAddress, nonce, SafeTxStatusPending).
+		Updates(map[string]interface{}{
+			"status":     SafeTxStatusReplaced,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
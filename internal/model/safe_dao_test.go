@@ -0,0 +1,121 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestSafeTransactionExecution(t *testing.T) {
+	ctx := context.Background()
+	dao := NewSafeTransactionsDao(newTestDB(t))
+	insert := func(hash string, nonce int64) *SafeTransactions {
+		t.Helper()
+		row := &SafeTransactions{
+			Chain: "BSC", SafeAddress: "0x5aFE", SafeTxHash: hash, Nonce: nonce,
+			Value: "0", Data: "0x", Signatures: "[]", Status: SafeTxStatusPending,
+		}
+		if err := dao.Insert(ctx, row); err != nil {
+			t.Fatal(err)
+		}
+		return row
+	}
+	status := func(hash string) *SafeTransactions {
+		t.Helper()
+		row, err := dao.FindOneBySafeTxHash(ctx, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return row
+	}
+
+	row := insert("0x01", 5)
+	sibling := insert("0x02", 5)
+	next := insert("0x03", 6)
+
+	// 并发认领只有一个成功
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	claims := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := dao.ClaimExecution(ctx, row.Id)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if ok {
+				mu.Lock()
+				claims++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if claims != 1 {
+		t.Fatalf("got %d successful claims, want 1", claims)
+	}
+	if nonce, err := dao.MaxPendingNonce(ctx, "BSC", "0x5aFE"); err != nil || nonce != 6 {
+		t.Fatalf("MaxPendingNonce: got %d, %v, want 6", nonce, err)
+	}
+
+	// 广播前失败退回 pending，可再次认领
+	if err := dao.ReleaseExecution(ctx, row.Id, "rpc down"); err != nil {
+		t.Fatal(err)
+	}
+	if got := status("0x01"); got.Status != SafeTxStatusPending || got.ErrorMessage != "rpc down" {
+		t.Fatalf("after release: got %s %q", got.Status, got.ErrorMessage)
+	}
+	if err := dao.ReleaseExecution(ctx, row.Id, "again"); !errors.Is(err, ErrStaleUpdate) {
+		t.Fatalf("release of a pending row: got %v, want ErrStaleUpdate", err)
+	}
+	if err := dao.MarkExecuted(ctx, row.Id, "0xexec"); !errors.Is(err, ErrStaleUpdate) {
+		t.Fatalf("MarkExecuted of a pending row: got %v, want ErrStaleUpdate", err)
+	}
+	if ok, err := dao.ClaimExecution(ctx, row.Id); err != nil || !ok {
+		t.Fatalf("second claim: got %v, %v", ok, err)
+	}
+	if got := status("0x01"); got.ErrorMessage != "" {
+		t.Fatalf("claim should clear the previous error, got %q", got.ErrorMessage)
+	}
+
+	// 回执成功：本交易 executed，同 nonce 的其它交易 replaced，其它 nonce 不受影响
+	if err := dao.SetExecTxHash(ctx, row.Id, "0xexec"); err != nil {
+		t.Fatal(err)
+	}
+	if rows, err := dao.FindExecuting(ctx, "BSC", "0x5aFE"); err != nil || len(rows) != 1 || rows[0].TxHash != "0xexec" {
+		t.Fatalf("FindExecuting: got %v, %v", rows, err)
+	}
+	if err := dao.MarkExecuted(ctx, row.Id, "0xexec"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dao.MarkExecuted(ctx, row.Id, "0xexec"); !errors.Is(err, ErrStaleUpdate) {
+		t.Fatalf("second MarkExecuted: got %v, want ErrStaleUpdate", err)
+	}
+	for hash, want := range map[string]string{
+		row.SafeTxHash:     SafeTxStatusExecuted,
+		sibling.SafeTxHash: SafeTxStatusReplaced,
+		next.SafeTxHash:    SafeTxStatusPending,
+	} {
+		if got := status(hash); got.Status != want {
+			t.Errorf("%s: got %s, want %s", hash, got.Status, want)
+		}
+	}
+
+	// 回执 revert：failed，不能再认领
+	if ok, err := dao.ClaimExecution(ctx, next.Id); err != nil || !ok {
+		t.Fatalf("claim: got %v, %v", ok, err)
+	}
+	if err := dao.MarkFailed(ctx, next.Id, "reverted"); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(next.SafeTxHash); got.Status != SafeTxStatusFailed || got.ErrorMessage != "reverted" {
+		t.Fatalf("after revert: got %s %q", got.Status, got.ErrorMessage)
+	}
+	if ok, err := dao.ClaimExecution(ctx, next.Id); err != nil || ok {
+		t.Fatalf("claim of a failed row: got %v, %v, want false", ok, err)
+	}
+}
@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+const (
+	// SafeTxStatusPending 正在收集 owner 签名，或签名已够但 nonce 还没轮到
+	SafeTxStatusPending = "pending"
+	// SafeTxStatusExecuting 已被一个请求认领并提交 execTransaction，等待回执；TxHash 为空表示尚未广播
+	SafeTxStatusExecuting = "executing"
+	// SafeTxStatusExecuted execTransaction 已上链且执行成功，TxHash 为执行交易
+	SafeTxStatusExecuted = "executed"
+	// SafeTxStatusFailed execTransaction 上链后 revert，Safe nonce 未被使用，需重新发起同一 nonce 的交易
+	SafeTxStatusFailed = "failed"
+	// SafeTxStatusReplaced 同一 nonce 的另一笔 Safe 交易已执行（包括在本服务之外执行的），本交易作废
+	SafeTxStatusReplaced = "replaced"
+)
+
+// Safes corresponds to the safes table in the database.
+// Owners (comma separated, checksummed) and Threshold are the snapshot read
+// from chain at the last register / propose / confirm; the chain is authoritative.
+type Safes struct {
+	Id        int64     `gorm:"column:id;primaryKey"`
+	Chain     string    `gorm:"column:chain"`
+	Address   string    `gorm:"column:address"` // checksummed
+	Version   string    `gorm:"column:version"`
+	Threshold int       `gorm:"column:threshold"`
+	Owners    string    `gorm:"column:owners"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (Safes) TableName() string {
+	return "safes"
+}
+
+// SafeTransactions corresponds to the safe_transactions table in the database.
+// Signatures is a JSON array of {"owner","signature"} collected from managed owners.
+type SafeTransactions struct {
+	Id           int64     `gorm:"column:id;primaryKey"`
+	Chain        string    `gorm:"column:chain"`
+	SafeAddress  string    `gorm:"column:safe_address"`
+	SafeTxHash   string    `gorm:"column:safe_tx_hash"`
+	Nonce        int64     `gorm:"column:nonce"`
+	ToAddress    string    `gorm:"column:to_address"`
+	Value        string    `gorm:"column:value"` // wei, decimal
+	Data         string    `gorm:"column:data"`  // hex
+	Operation    uint8     `gorm:"column:operation"`
+	Description  string    `gorm:"column:description"`
+	Proposer     string    `gorm:"column:proposer"`
+	Signatures   string    `gorm:"column:signatures"`
+	Status       string    `gorm:"column:status"`
+	TxHash       string    `gorm:"column:tx_hash"`
+	ErrorMessage string    `gorm:"column:error_message"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (SafeTransactions) TableName() string {
+	return "safe_transactions"
+}
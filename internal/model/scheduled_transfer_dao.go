@@ -0,0 +1,213 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScheduledTransfersDao defines the interface for database operations on the scheduled_transfers table.
+type ScheduledTransfersDao interface {
+	Insert(ctx context.Context, data *ScheduledTransfers) error
+	FindOne(ctx context.Context, id int64) (*ScheduledTransfers, error)
+	FindPage(ctx context.Context, status string, page, pageSize int) ([]*ScheduledTransfers, int64, error)
+	// FindDue returns active schedules whose next run is at or before now, earliest first.
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*ScheduledTransfers, error)
+	// Update stores the status, next/last run and run count of a schedule if nobody changed
+	// them since it was read (same Version). It reports false on a conflict and bumps
+	// data.Version on success. The failure counter is only changed by RecordOutcome.
+	Update(ctx context.Context, data *ScheduledTransfers) (bool, error)
+	// RecordOutcome resets the failure counter after a successful execution (errMsg empty) or
+	// increments it, pausing the schedule once it reaches maxFailures (0 never pauses).
+	// Only the pause bumps Version. It reports whether the schedule was paused.
+	RecordOutcome(ctx context.Context, id int64, errMsg string, maxFailures int) (bool, error)
+}
+
+type scheduledTransfersDao struct {
+	db *gorm.DB
+}
+
+// NewScheduledTransfersDao creates a new instance of ScheduledTransfersDao.
+func NewScheduledTransfersDao(db *gorm.DB) ScheduledTransfersDao {
+	return &scheduledTransfersDao{
+		db: db,
+	}
+}
+
+// Insert adds a new record to the scheduled_transfers table.
+func (d *scheduledTransfersDao) Insert(ctx context.Context, data *ScheduledTransfers) error {
+	return d.db.WithContext(ctx).Create(data).Error
+}
+
+// FindOne retrieves a single schedule by id.
+func (d *scheduledTransfersDao) FindOne(ctx context.Context, id int64) (*ScheduledTransfers, error) {
+	var resp ScheduledTransfers
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FindPage retrieves one page of schedules (newest first), optionally filtered by status, plus the total count.
+func (d *scheduledTransfersDao) FindPage(ctx context.Context, status string, page, pageSize int) ([]*ScheduledTransfers, int64, error) {
+	query := d.db.WithContext(ctx).Model(&ScheduledTransfers{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []*ScheduledTransfers
+	if err := query.Scopes(paginate(page, pageSize)).Order("id DESC").Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+// FindDue retrieves the active schedules that are due.
+func (d *scheduledTransfersDao) FindDue(ctx context.Context, now time.Time, limit int) ([]*ScheduledTransfers, error) {
+	var rows []*ScheduledTransfers
+	err := d.db.WithContext(ctx).
+		Where("status = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", ScheduleStatusActive, now).
+		Order("next_run_at, id").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
+
+// Update performs an optimistic update of the state columns.
+func (d *scheduledTransfersDao) Update(ctx context.Context, data *ScheduledTransfers) (bool, error) {
+	now := time.Now()
+	result := d.db.WithContext(ctx).Model(&ScheduledTransfers{}).
+		Where("id = ? AND version = ?", data.Id, data.Version).
+		Updates(map[string]interface{}{
+			"status":      data.Status,
+			"next_run_at": data.NextRunAt,
+			"last_run_at": data.LastRunAt,
+			"run_count":   data.RunCount,
+			"version":     data.Version + 1,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	data.Version++
+	data.UpdatedAt = now
+	return true, nil
+}
+
+// RecordOutcome updates the failure counter and auto-pauses failing schedules.
+func (d *scheduledTransfersDao) RecordOutcome(ctx context.Context, id int64, errMsg string, maxFailures int) (bool, error) {
+	db := d.db.WithContext(ctx)
+	now := time.Now()
+	if errMsg == "" {
+		return false, db.Model(&ScheduledTransfers{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"consecutive_failures": 0,
+				"last_error":           "",
+				"updated_at":           now,
+			}).Error
+	}
+
+	err := db.Model(&ScheduledTransfers{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"consecutive_failures": gorm.Expr("consecutive_failures + 1"),
+			"last_error":           errMsg,
+			"updated_at":           now,
+		}).Error
+	if err != nil || maxFailures <= 0 {
+		return false, err
+	}
+	result := db.Model(&ScheduledTransfers{}).
+		Where("id = ? AND status = ? AND consecutive_failures >= ?", id, ScheduleStatusActive, maxFailures).
+		Updates(map[string]interface{}{
+			"status":     ScheduleStatusPaused,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ScheduledTransferRunsDao defines the interface for database operations on the scheduled_transfer_runs table.
+type ScheduledTransferRunsDao interface {
+	Insert(ctx context.Context, data *ScheduledTransferRuns) error
+	// Finish stores the final status, transaction hash and error of a run.
+	Finish(ctx context.Context, data *ScheduledTransferRuns) error
+	FindPage(ctx context.Context, scheduleId int64, page, pageSize int) ([]*ScheduledTransferRuns, int64, error)
+	// FailRunning marks runs left running by a previous process as failed.
+	FailRunning(ctx context.Context, errMsg string) (int64, error)
+}
+
+type scheduledTransferRunsDao struct {
+	db *gorm.DB
+}
+
+// NewScheduledTransferRunsDao creates a new instance of ScheduledTransferRunsDao.
+func NewScheduledTransferRunsDao(db *gorm.DB) ScheduledTransferRunsDao {
+	return &scheduledTransferRunsDao{
+		db: db,
+	}
+}
+
+// Insert adds a new record to the scheduled_transfer_runs table.
+func (d *scheduledTransferRunsDao) Insert(ctx context.Context, data *ScheduledTransferRuns) error {
+	return d.db.WithContext(ctx).Create(data).Error
+}
+
+// Finish updates the outcome columns of a run.
+func (d *scheduledTransferRunsDao) Finish(ctx context.Context, data *ScheduledTransferRuns) error {
+	return d.db.WithContext(ctx).Model(&ScheduledTransferRuns{}).
+		Where("id = ?", data.Id).
+		Updates(map[string]interface{}{
+			"status":        data.Status,
+			"tx_hash":       data.TxHash,
+			"error_message": data.ErrorMessage,
+			"finished_at":   data.FinishedAt,
+		}).Error
+}
+
+// FindPage retrieves one page of runs (newest first), optionally filtered by schedule, plus the total count.
+func (d *scheduledTransferRunsDao) FindPage(ctx context.Context, scheduleId int64, page, pageSize int) ([]*ScheduledTransferRuns, int64, error) {
+	query := d.db.WithContext(ctx).Model(&ScheduledTransferRuns{})
+	if scheduleId > 0 {
+		query = query.Where("schedule_id = ?", scheduleId)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var runs []*ScheduledTransferRuns
+	if err := query.Scopes(paginate(page, pageSize)).Order("id DESC").Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+// FailRunning marks every running run as failed.
+func (d *scheduledTransferRunsDao) FailRunning(ctx context.Context, errMsg string) (int64, error) {
+	now := time.Now()
+	result := d.db.WithContext(ctx).Model(&ScheduledTransferRuns{}).
+		Where("status = ?", ScheduleRunStatusRunning).
+		Updates(map[string]interface{}{
+			"status":        ScheduleRunStatusFailed,
+			"error_message": errMsg,
+			"finished_at":   &now,
+		})
+	return result.RowsAffected, result.Error
+}
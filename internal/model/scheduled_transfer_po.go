@@ -0,0 +1,84 @@
+package model
+
+import "time"
+
+const (
+	ScheduleKindSend = "send"
+	ScheduleKindSwap = "swap"
+
+	ScheduleStatusActive    = "active"
+	ScheduleStatusPaused    = "paused"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusCancelled = "cancelled"
+
+	// 停机等原因错过预定时间后的处理：skip 全部跳过，once 只补执行最近一次，all 逐次补执行（受 MaxCatchUp 限制）
+	ScheduleCatchUpSkip = "skip"
+	ScheduleCatchUpOnce = "once"
+	ScheduleCatchUpAll  = "all"
+
+	ScheduleRunStatusRunning   = "running"
+	ScheduleRunStatusSucceeded = "succeeded"
+	ScheduleRunStatusFailed    = "failed"
+	// ScheduleRunStatusSkipped 按 catch_up 策略跳过的错过执行
+	ScheduleRunStatusSkipped = "skipped"
+	// ScheduleRunStatusDenied 被 Policy.Scheduled 拒绝、未广播的执行
+	ScheduleRunStatusDenied = "denied"
+)
+
+// ScheduledTransfers corresponds to the scheduled_transfers table in the database.
+// A stored send or swap request executed once at RunAt or on every match of
+// Cron (evaluated in Timezone). NextRunAt is empty once the schedule is
+// completed or cancelled; Version is bumped on every state change and guards
+// concurrent updates.
+type ScheduledTransfers struct {
+	Id                  int64      `gorm:"column:id;primaryKey"`
+	Name                string     `gorm:"column:name"`
+	Kind                string     `gorm:"column:kind"`
+	Chain               string     `gorm:"column:chain"`
+	FromAddress         string     `gorm:"column:from_address"`
+	ToAddress           string     `gorm:"column:to_address"`
+	FromToken           string     `gorm:"column:from_token"`
+	ToToken             string     `gorm:"column:to_token"`
+	Amount              string     `gorm:"column:amount"`
+	AmountUnit          string     `gorm:"column:amount_unit"`
+	FeeLevel            string     `gorm:"column:fee_level"`
+	RunAt               *time.Time `gorm:"column:run_at"`
+	Cron                string     `gorm:"column:cron"`
+	Timezone            string     `gorm:"column:timezone"`
+	CatchUp             string     `gorm:"column:catch_up"`
+	MaxRuns             int        `gorm:"column:max_runs"` // 0 表示不限
+	EndAt               *time.Time `gorm:"column:end_at"`
+	Status              string     `gorm:"column:status"`
+	NextRunAt           *time.Time `gorm:"column:next_run_at"`
+	LastRunAt           *time.Time `gorm:"column:last_run_at"`
+	RunCount            int        `gorm:"column:run_count"` // 已执行（不含跳过）的次数
+	ConsecutiveFailures int        `gorm:"column:consecutive_failures"`
+	LastError           string     `gorm:"column:last_error"`
+	Version             int64      `gorm:"column:version"`
+	CreatedAt           time.Time  `gorm:"column:created_at"`
+	UpdatedAt           time.Time  `gorm:"column:updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (ScheduledTransfers) TableName() string {
+	return "scheduled_transfers"
+}
+
+// ScheduledTransferRuns corresponds to the scheduled_transfer_runs table in the database.
+// One row per occurrence of a schedule; TxHash links to transactions (chain, tx_hash).
+type ScheduledTransferRuns struct {
+	Id           int64      `gorm:"column:id;primaryKey"`
+	ScheduleId   int64      `gorm:"column:schedule_id"`
+	ScheduledFor time.Time  `gorm:"column:scheduled_for"`
+	CatchUp      bool       `gorm:"column:catch_up"` // 错过预定时间后补执行
+	Status       string     `gorm:"column:status"`
+	TxHash       string     `gorm:"column:tx_hash"`
+	ErrorMessage string     `gorm:"column:error_message"`
+	StartedAt    time.Time  `gorm:"column:started_at"`
+	FinishedAt   *time.Time `gorm:"column:finished_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (ScheduledTransferRuns) TableName() string {
+	return "scheduled_transfer_runs"
+}
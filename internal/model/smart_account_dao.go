@@ -0,0 +1,68 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SmartAccountsDao defines the interface for database operations on the smart_accounts table.
+type SmartAccountsDao interface {
+	Insert(ctx context.Context, data *SmartAccounts) error
+	FindOneByChainAndAddress(ctx context.Context, chain, address string) (*SmartAccounts, error)
+	FindByOwner(ctx context.Context, owner string) ([]*SmartAccounts, error)
+	MarkDeployed(ctx context.Context, id int64) error
+}
+
+type smartAccountsDao struct {
+	db *gorm.DB
+}
+
+// NewSmartAccountsDao creates a new instance of SmartAccountsDao.
+func NewSmartAccountsDao(db *gorm.DB) SmartAccountsDao {
+	return &smartAccountsDao{
+		db: db,
+	}
+}
+
+// Insert adds a new record to the smart_accounts table.
+// It returns ErrDuplicateKey if the (chain, address) pair already exists.
+func (d *smartAccountsDao) Insert(ctx context.Context, data *SmartAccounts) error {
+	err := d.db.WithContext(ctx).Create(data).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateKey
+	}
+	return err
+}
+
+// FindOneByChainAndAddress retrieves a smart account by chain key and checksummed address.
+func (d *smartAccountsDao) FindOneByChainAndAddress(ctx context.Context, chain, address string) (*SmartAccounts, error) {
+	var resp SmartAccounts
+	err := d.db.WithContext(ctx).Where("chain = ? AND address = ?", chain, address).First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FindByOwner retrieves all smart accounts owned by a wallet address.
+func (d *smartAccountsDao) FindByOwner(ctx context.Context, owner string) ([]*SmartAccounts, error) {
+	var accounts []*SmartAccounts
+	err := d.db.WithContext(ctx).Where("owner = ?", owner).Order("id").Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// MarkDeployed records that the account contract is now deployed on chain.
+func (d *smartAccountsDao) MarkDeployed(ctx context.Context, id int64) error {
+	return d.db.WithContext(ctx).Model(&SmartAccounts{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"deployed": true, "updated_at": time.Now()}).Error
+}
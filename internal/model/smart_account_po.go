@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// SmartAccounts corresponds to the smart_accounts table in the database.
+// Address is computed counterfactually by the factory from (owner, salt); the
+// account contract is deployed by the first UserOperation sent from it.
+type SmartAccounts struct {
+	Id         int64     `gorm:"column:id;primaryKey"`
+	Chain      string    `gorm:"column:chain"`
+	Address    string    `gorm:"column:address"` // checksummed
+	Owner      string    `gorm:"column:owner"`   // EVM wallet that signs the UserOperations
+	Factory    string    `gorm:"column:factory"`
+	EntryPoint string    `gorm:"column:entry_point"`
+	Salt       string    `gorm:"column:salt"` // decimal
+	Deployed   bool      `gorm:"column:deployed"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (SmartAccounts) TableName() string {
+	return "smart_accounts"
+}
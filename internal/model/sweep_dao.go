@@ -0,0 +1,141 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SweepRunsDao defines the interface for database operations on the sweep_runs table.
+type SweepRunsDao interface {
+	Insert(ctx context.Context, data *SweepRuns) error
+	// Finish stores the counters, total and final status of a run.
+	Finish(ctx context.Context, data *SweepRuns) error
+	FindOne(ctx context.Context, id int64) (*SweepRuns, error)
+	// FindLatestByRule returns the most recently started run of a rule, ErrNotFound if it never ran.
+	FindLatestByRule(ctx context.Context, rule string) (*SweepRuns, error)
+	FindPage(ctx context.Context, rule string, page, pageSize int) ([]*SweepRuns, int64, error)
+	// FailRunning marks runs left running by a previous process as failed.
+	FailRunning(ctx context.Context, errMsg string) (int64, error)
+}
+
+type sweepRunsDao struct {
+	db *gorm.DB
+}
+
+// NewSweepRunsDao creates a new instance of SweepRunsDao.
+func NewSweepRunsDao(db *gorm.DB) SweepRunsDao {
+	return &sweepRunsDao{
+		db: db,
+	}
+}
+
+// Insert adds a new record to the sweep_runs table.
+func (d *sweepRunsDao) Insert(ctx context.Context, data *SweepRuns) error {
+	return d.db.WithContext(ctx).Create(data).Error
+}
+
+// Finish updates the summary columns of a run.
+func (d *sweepRunsDao) Finish(ctx context.Context, data *SweepRuns) error {
+	return d.db.WithContext(ctx).Model(&SweepRuns{}).
+		Where("id = ?", data.Id).
+		Updates(map[string]interface{}{
+			"status":        data.Status,
+			"scanned":       data.Scanned,
+			"swept":         data.Swept,
+			"skipped":       data.Skipped,
+			"failed":        data.Failed,
+			"total_amount":  data.TotalAmount,
+			"error_message": data.ErrorMessage,
+			"finished_at":   data.FinishedAt,
+		}).Error
+}
+
+// FindOne retrieves a single run by id.
+func (d *sweepRunsDao) FindOne(ctx context.Context, id int64) (*SweepRuns, error) {
+	var resp SweepRuns
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FindLatestByRule retrieves the last run of a rule.
+func (d *sweepRunsDao) FindLatestByRule(ctx context.Context, rule string) (*SweepRuns, error) {
+	var resp SweepRuns
+	err := d.db.WithContext(ctx).Where("rule = ?", rule).Order("started_at DESC, id DESC").First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FindPage retrieves one page of runs (newest first), optionally filtered by rule, plus the total count.
+func (d *sweepRunsDao) FindPage(ctx context.Context, rule string, page, pageSize int) ([]*SweepRuns, int64, error) {
+	query := d.db.WithContext(ctx).Model(&SweepRuns{})
+	if rule != "" {
+		query = query.Where("rule = ?", rule)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var runs []*SweepRuns
+	if err := query.Scopes(paginate(page, pageSize)).Order("id DESC").Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+// FailRunning marks every running run as failed.
+func (d *sweepRunsDao) FailRunning(ctx context.Context, errMsg string) (int64, error) {
+	now := time.Now()
+	result := d.db.WithContext(ctx).Model(&SweepRuns{}).
+		Where("status = ?", SweepRunStatusRunning).
+		Updates(map[string]interface{}{
+			"status":        SweepRunStatusFailed,
+			"error_message": errMsg,
+			"finished_at":   &now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// SweepItemsDao defines the interface for database operations on the sweep_items table.
+type SweepItemsDao interface {
+	Insert(ctx context.Context, data *SweepItems) error
+	FindByRunId(ctx context.Context, runId int64) ([]*SweepItems, error)
+}
+
+type sweepItemsDao struct {
+	db *gorm.DB
+}
+
+// NewSweepItemsDao creates a new instance of SweepItemsDao.
+func NewSweepItemsDao(db *gorm.DB) SweepItemsDao {
+	return &sweepItemsDao{
+		db: db,
+	}
+}
+
+// Insert adds a new record to the sweep_items table.
+func (d *sweepItemsDao) Insert(ctx context.Context, data *SweepItems) error {
+	return d.db.WithContext(ctx).Create(data).Error
+}
+
+// FindByRunId retrieves all items of a run in scan order.
+func (d *sweepItemsDao) FindByRunId(ctx context.Context, runId int64) ([]*SweepItems, error) {
+	var items []*SweepItems
+	err := d.db.WithContext(ctx).Where("run_id = ?", runId).Order("id").Find(&items).Error
+	return items, err
+}
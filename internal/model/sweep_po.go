@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+const (
+	SweepRunStatusRunning   = "running"
+	SweepRunStatusCompleted = "completed"
+	SweepRunStatusFailed    = "failed"
+
+	SweepTriggerSchedule = "schedule"
+	SweepTriggerManual   = "manual"
+
+	// SweepItemSwept 已提交归集交易
+	SweepItemSwept = "swept"
+	// SweepItemSkipped 低于阈值、手续费不低于可归集金额或无法补充 gas
+	SweepItemSkipped = "skipped"
+	SweepItemFailed  = "failed"
+)
+
+// SweepRuns corresponds to the sweep_runs table in the database.
+// One row per execution of a sweep rule; the counters and TotalAmount
+// (base units) summarize its sweep_items.
+type SweepRuns struct {
+	Id           int64      `gorm:"column:id;primaryKey"`
+	Rule         string     `gorm:"column:rule"`
+	Chain        string     `gorm:"column:chain"`
+	Token        string     `gorm:"column:token"` // contract / mint, empty for native
+	Symbol       string     `gorm:"column:symbol"`
+	Destination  string     `gorm:"column:destination"`
+	TriggeredBy  string     `gorm:"column:triggered_by"`
+	Status       string     `gorm:"column:status"`
+	Scanned      int        `gorm:"column:scanned"`
+	Swept        int        `gorm:"column:swept"`
+	Skipped      int        `gorm:"column:skipped"`
+	Failed       int        `gorm:"column:failed"`
+	TotalAmount  string     `gorm:"column:total_amount"`
+	ErrorMessage string     `gorm:"column:error_message"`
+	StartedAt    time.Time  `gorm:"column:started_at"`
+	FinishedAt   *time.Time `gorm:"column:finished_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (SweepRuns) TableName() string {
+	return "sweep_runs"
+}
+
+// SweepItems corresponds to the sweep_items table in the database.
+// One row per scanned wallet holding a non-zero balance.
+type SweepItems struct {
+	Id          int64     `gorm:"column:id;primaryKey"`
+	RunId       int64     `gorm:"column:run_id"`
+	Wallet      string    `gorm:"column:wallet"`
+	Balance     string    `gorm:"column:balance"` // base units at scan time
+	Amount      string    `gorm:"column:amount"`  // base units actually sent
+	Status      string    `gorm:"column:status"`
+	Reason      string    `gorm:"column:reason"`
+	TxHash      string    `gorm:"column:tx_hash"`
+	TopUpTxHash string    `gorm:"column:top_up_tx_hash"` // native gas sent from the rule's GasFunder
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (SweepItems) TableName() string {
+	return "sweep_items"
+}
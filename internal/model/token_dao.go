@@ -0,0 +1,82 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokensDao defines the interface for database operations on the tokens table.
+type TokensDao interface {
+	// Insert stores a token read from chain; an existing row for the same address is kept.
+	Insert(ctx context.Context, data *Tokens) error
+	// Upsert stores a token list in batches, refreshing symbol, name, decimals and source of existing rows.
+	Upsert(ctx context.Context, data []*Tokens) error
+	FindOneByChainAndAddress(ctx context.Context, chain, address string) (*Tokens, error)
+	// FindByChainAndSymbol matches the symbol case-insensitively among tokens of the given sources.
+	FindByChainAndSymbol(ctx context.Context, chain, symbol string, sources ...string) ([]*Tokens, error)
+}
+
+type tokensDao struct {
+	db *gorm.DB
+}
+
+// NewTokensDao creates a new instance of TokensDao.
+func NewTokensDao(db *gorm.DB) TokensDao {
+	return &tokensDao{
+		db: db,
+	}
+}
+
+// Insert stores a token unless the (chain, address) row already exists.
+func (d *tokensDao) Insert(ctx context.Context, data *Tokens) error {
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain"}, {Name: "address"}},
+		DoNothing: true,
+	}).Create(data).Error
+}
+
+// Upsert inserts or refreshes tokens, 500 rows per statement.
+func (d *tokensDao) Upsert(ctx context.Context, data []*Tokens) error {
+	if len(data) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for _, t := range data {
+		t.CreatedAt, t.UpdatedAt = now, now
+	}
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain"}, {Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"symbol", "name", "decimals", "source", "updated_at"}),
+	}).CreateInBatches(data, 500).Error
+}
+
+// FindOneByChainAndAddress retrieves a token by chain key and address (checksummed for EVM).
+func (d *tokensDao) FindOneByChainAndAddress(ctx context.Context, chain, address string) (*Tokens, error) {
+	var resp Tokens
+	err := d.db.WithContext(ctx).Where("chain = ? AND address = ?", chain, address).First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FindByChainAndSymbol retrieves the tokens of a chain with the given symbol.
+func (d *tokensDao) FindByChainAndSymbol(ctx context.Context, chain, symbol string, sources ...string) ([]*Tokens, error) {
+	query := d.db.WithContext(ctx).Where("chain = ? AND UPPER(symbol) = ?", chain, strings.ToUpper(symbol))
+	if len(sources) > 0 {
+		query = query.Where("source IN ?", sources)
+	}
+	var resp []*Tokens
+	if err := query.Order("id").Find(&resp).Error; err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
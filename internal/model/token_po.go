@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+const (
+	// TokenSourceLifi 来自 LI.FI /tokens 代币列表
+	TokenSourceLifi = "lifi"
+	// TokenSourceChain 首次遇到时从链上读取（ERC20 / SPL mint + Metaplex）
+	TokenSourceChain = "chain"
+)
+
+// Tokens corresponds to the tokens table in the database.
+// It caches token metadata per chain; tokens from config are not stored here.
+// Only curated sources (LI.FI) are used for symbol → address resolution.
+type Tokens struct {
+	Id        int64     `gorm:"column:id;primaryKey"`
+	Chain     string    `gorm:"column:chain"`
+	Address   string    `gorm:"column:address"` // EVM 为 checksum 地址，Solana 为 mint
+	Symbol    string    `gorm:"column:symbol"`
+	Name      string    `gorm:"column:name"`
+	Decimals  int       `gorm:"column:decimals"`
+	Source    string    `gorm:"column:source"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (Tokens) TableName() string {
+	return "tokens"
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TransactionFilter narrows down transaction history queries. Empty fields are ignored.
+type TransactionFilter struct {
+	Address string // matches either from_address or to_address
+	Chain   string
+	TxType  string
+}
+
+// TransactionsDao defines the interface for database operations on the transactions table.
+type TransactionsDao interface {
+	Insert(ctx context.Context, data *Transactions) error
+	FindOne(ctx context.Context, id int64) (*Transactions, error)
+	FindOneByHash(ctx context.Context, chain, txHash string) (*Transactions, error)
+	FindPage(ctx context.Context, filter TransactionFilter, page, pageSize int) ([]*Transactions, int64, error)
+	UpdateStatus(ctx context.Context, chain, txHash, status, errMsg string) error
+}
+
+type transactionsDao struct {
+	db *gorm.DB
+}
+
+// NewTransactionsDao creates a new instance of TransactionsDao.
+func NewTransactionsDao(db *gorm.DB) TransactionsDao {
+	return &transactionsDao{
+		db: db,
+	}
+}
+
+// Insert adds a new record to the transactions table.
+// It returns ErrDuplicateKey if the (chain, tx_hash) pair already exists.
+func (d *transactionsDao) Insert(ctx context.Context, data *Transactions) error {
+	err := d.db.WithContext(ctx).Create(data).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateKey
+	}
+	return err
+}
+
+// FindOne retrieves a single transaction by id.
+func (d *transactionsDao) FindOne(ctx context.Context, id int64) (*Transactions, error) {
+	var resp Transactions
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FindOneByHash retrieves a single transaction by chain and hash.
+func (d *transactionsDao) FindOneByHash(ctx context.Context, chain, txHash string) (*Transactions, error) {
+	var resp Transactions
+	err := d.db.WithContext(ctx).Where("chain = ? AND tx_hash = ?", chain, txHash).First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FindPage retrieves one page of transactions (newest first), plus the total count.
+func (d *transactionsDao) FindPage(ctx context.Context, filter TransactionFilter, page, pageSize int) ([]*Transactions, int64, error) {
+	query := d.db.WithContext(ctx).Model(&Transactions{})
+	if filter.Address != "" {
+		query = query.Where("from_address = ? OR to_address = ?", filter.Address, filter.Address)
+	}
+	if filter.Chain != "" {
+		query = query.Where("chain = ?", filter.Chain)
+	}
+	if filter.TxType != "" {
+		query = query.Where("tx_type = ?", filter.TxType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var txs []*Transactions
+	if err := query.Scopes(paginate(page, pageSize)).Order("id DESC").Find(&txs).Error; err != nil {
+		return nil, 0, err
+	}
+	return txs, total, nil
+}
+
+// UpdateStatus updates the status (and error message) of a transaction.
+func (d *transactionsDao) UpdateStatus(ctx context.Context, chain, txHash, status, errMsg string) error {
+	return d.db.WithContext(ctx).Model(&Transactions{}).
+		Where("chain = ? AND tx_hash = ?", chain, txHash).
+		Updates(map[string]interface{}{
+			"status":        status,
+			"error_message": errMsg,
+			"updated_at":    time.Now(),
+		}).Error
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+const (
+	TxTypeSend    = "send"
+	TxTypeSwap    = "swap"
+	TxTypeBridge  = "bridge"
+	TxTypeApprove = "approve"
+	TxTypeRevoke  = "revoke"
+	// TxTypeBatchSend 一笔 disperse 合约交易，对应批量转账中的多个收款
+	TxTypeBatchSend = "batch_send"
+	// TxTypeNftTransfer ERC-721 / ERC-1155 转账，Token 为合约地址，Amount 为转出的数量合计
+	TxTypeNftTransfer = "nft_transfer"
+	// TxTypeContractCall 按 ABI 编码的任意合约调用，Token 为合约地址，Amount 为附带的原生币
+	TxTypeContractCall = "contract_call"
+	// TxTypeSafeExec Safe 多签的 execTransaction，FromAddress 为 Safe，ToAddress / Amount 为 SafeTx 的 to / value
+	TxTypeSafeExec = "safe_exec"
+
+	TxStatusPending   = "pending"
+	TxStatusConfirmed = "confirmed"
+	TxStatusFailed    = "failed"
+)
+
+// Transactions corresponds to the transactions table in the database.
+type Transactions struct {
+	Id           int64     `gorm:"column:id;primaryKey"`
+	Chain        string    `gorm:"column:chain"`
+	TxHash       string    `gorm:"column:tx_hash"`
+	TxType       string    `gorm:"column:tx_type"`
+	FromAddress  string    `gorm:"column:from_address"`
+	ToAddress    string    `gorm:"column:to_address"`
+	Token        string    `gorm:"column:token"`
+	Amount       string    `gorm:"column:amount"`
+	Status       string    `gorm:"column:status"`
+	ExplorerUrl  string    `gorm:"column:explorer_url"`
+	ErrorMessage string    `gorm:"column:error_message"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+	UpdatedAt    time.Time `gorm:"column:updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (Transactions) TableName() string {
+	return "transactions"
+}
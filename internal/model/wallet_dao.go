@@ -9,11 +9,25 @@ import (
 
 var ErrNotFound = gorm.ErrRecordNotFound
 
+// ErrDuplicateKey is returned when a unique constraint rejects an insert.
+var ErrDuplicateKey = gorm.ErrDuplicatedKey
+
+// WalletFilter narrows down wallet list queries. Empty fields are ignored.
+type WalletFilter struct {
+	UserId    string
+	ChainType string
+}
+
 // WalletsDao defines the interface for database operations on the wallets table.
 type WalletsDao interface {
 	Insert(ctx context.Context, data *Wallets) error
 	FindOneByAddress(ctx context.Context, address string) (*Wallets, error)
+	FindOneByChainAndAddress(ctx context.Context, chainType, address string) (*Wallets, error)
 	FindAll(ctx context.Context) ([]*Wallets, error)
+	FindByUserId(ctx context.Context, userId string) ([]*Wallets, error)
+	FindByChainType(ctx context.Context, chainType string) ([]*Wallets, error)
+	FindPage(ctx context.Context, filter WalletFilter, page, pageSize int) ([]*Wallets, int64, error)
+	Delete(ctx context.Context, id int64) error
 }
 
 type walletsDao struct {
@@ -28,8 +42,13 @@ func NewWalletsDao(db *gorm.DB) WalletsDao {
 }
 
 // Insert adds a new record to the wallets table.
+// It returns ErrDuplicateKey if the (chain_type, address) pair already exists.
 func (d *walletsDao) Insert(ctx context.Context, data *Wallets) error {
-	return d.db.WithContext(ctx).Create(data).Error
+	err := d.db.WithContext(ctx).Create(data).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateKey
+	}
+	return err
 }
 
 // FindOneByAddress retrieves a single wallet record by its address.
@@ -45,6 +64,21 @@ func (d *walletsDao) FindOneByAddress(ctx context.Context, address string) (*Wal
 	return &resp, nil
 }
 
+// FindOneByChainAndAddress retrieves a single wallet record by chain type and address.
+func (d *walletsDao) FindOneByChainAndAddress(ctx context.Context, chainType, address string) (*Wallets, error) {
+	var resp Wallets
+	err := d.db.WithContext(ctx).
+		Where("chain_type = ? AND address = ?", chainType, address).
+		First(&resp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // FindAll retrieves all wallet records.
 func (d *walletsDao) FindAll(ctx context.Context) ([]*Wallets, error) {
 	var wallets []*Wallets
@@ -54,3 +88,50 @@ func (d *walletsDao) FindAll(ctx context.Context) ([]*Wallets, error) {
 	}
 	return wallets, nil
 }
+
+// FindByUserId retrieves all wallets owned by a user.
+func (d *walletsDao) FindByUserId(ctx context.Context, userId string) ([]*Wallets, error) {
+	var wallets []*Wallets
+	err := d.db.WithContext(ctx).Where("user_id = ?", userId).Order("id").Find(&wallets).Error
+	if err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// FindByChainType retrieves all wallets of a chain type (EVM, BTC, SOLANA...).
+func (d *walletsDao) FindByChainType(ctx context.Context, chainType string) ([]*Wallets, error) {
+	var wallets []*Wallets
+	err := d.db.WithContext(ctx).Where("chain_type = ?", chainType).Order("id").Find(&wallets).Error
+	if err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// FindPage retrieves one page of wallets matching the filter, plus the total count.
+func (d *walletsDao) FindPage(ctx context.Context, filter WalletFilter, page, pageSize int) ([]*Wallets, int64, error) {
+	query := d.db.WithContext(ctx).Model(&Wallets{})
+	if filter.UserId != "" {
+		query = query.Where("user_id = ?", filter.UserId)
+	}
+	if filter.ChainType != "" {
+		query = query.Where("chain_type = ?", filter.ChainType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var wallets []*Wallets
+	if err := query.Scopes(paginate(page, pageSize)).Order("id").Find(&wallets).Error; err != nil {
+		return nil, 0, err
+	}
+	return wallets, total, nil
+}
+
+// Delete soft-deletes a wallet by id; the row is kept with deleted_at set.
+func (d *walletsDao) Delete(ctx context.Context, id int64) error {
+	return d.db.WithContext(ctx).Delete(&Wallets{}, id).Error
+}
@@ -3,17 +3,25 @@ package model
 import (
 	"database/sql"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Wallets corresponds to the wallets table in the database.
 type Wallets struct {
-	Id                  int64          `db:"id"`
-	UserId              string         `db:"user_id"`
-	Address             string         `db:"address"`
-	EncryptedPrivateKey string         `db:"encrypted_private_key"`
-	PhoneNumber         sql.NullString `db:"phone_number"`
-	Email               sql.NullString `db:"email"`
-	CreatedAt           time.Time      `db:"created_at"`
-	UpdatedAt           time.Time      `db:"updated_at"`
-	ChainType           sql.NullString `db:"chain_type"`
+	Id                  int64          `gorm:"column:id;primaryKey"`
+	UserId              string         `gorm:"column:user_id"`
+	Address             string         `gorm:"column:address"`
+	EncryptedPrivateKey string         `gorm:"column:encrypted_private_key"`
+	PhoneNumber         sql.NullString `gorm:"column:phone_number"`
+	Email               sql.NullString `gorm:"column:email"`
+	CreatedAt           time.Time      `gorm:"column:created_at"`
+	UpdatedAt           time.Time      `gorm:"column:updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"column:deleted_at;index"`
+	ChainType           sql.NullString `gorm:"column:chain_type"`
+}
+
+// TableName overrides the table name used by gorm.
+func (Wallets) TableName() string {
+	return "wallets"
 }
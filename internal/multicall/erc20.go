@@ -0,0 +1,138 @@
+package multicall
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	balanceOfSelector       = []byte{0x70, 0xa0, 0x82, 0x31} // balanceOf(address)
+	allowanceSelector       = []byte{0xdd, 0x62, 0xed, 0x3e} // allowance(address,address)
+	decimalsSelector        = []byte{0x31, 0x3c, 0xe5, 0x67} // decimals()
+	symbolSelector          = []byte{0x95, 0xd8, 0x9b, 0x41} // symbol()
+	nameSelector            = []byte{0x06, 0xfd, 0xde, 0x03} // name()
+	domainSeparatorSelector = []byte{0x36, 0x44, 0xe5, 0x15} // DOMAIN_SEPARATOR()
+	noncesSelector          = []byte{0x7e, 0xce, 0xbe, 0x00} // nonces(address)
+	getEthBalanceSelector   = []byte{0x4d, 0x23, 0x01, 0xcc} // Multicall3.getEthBalance(address)
+)
+
+// ERC20 只读调用的 gas 估算
+const (
+	balanceGas  = 40000
+	metadataGas = 60000
+)
+
+func encode(selector []byte, args ...common.Address) []byte {
+	data := append([]byte{}, selector...)
+	for _, a := range args {
+		data = append(data, common.LeftPadBytes(a.Bytes(), 32)...)
+	}
+	return data
+}
+
+// BalanceOf ERC20 balanceOf(owner)
+func BalanceOf(token, owner common.Address) Call {
+	return Call{Target: token, Data: encode(balanceOfSelector, owner), Gas: balanceGas}
+}
+
+// Allowance ERC20 allowance(owner, spender)
+func Allowance(token, owner, spender common.Address) Call {
+	return Call{Target: token, Data: encode(allowanceSelector, owner, spender), Gas: balanceGas}
+}
+
+// Decimals ERC20 decimals()
+func Decimals(token common.Address) Call {
+	return Call{Target: token, Data: encode(decimalsSelector), Gas: metadataGas}
+}
+
+// Symbol ERC20 symbol()
+func Symbol(token common.Address) Call {
+	return Call{Target: token, Data: encode(symbolSelector), Gas: metadataGas}
+}
+
+// Name ERC20 name()
+func Name(token common.Address) Call {
+	return Call{Target: token, Data: encode(nameSelector), Gas: metadataGas}
+}
+
+// DomainSeparator EIP-712 DOMAIN_SEPARATOR()，EIP-2612 代币与 Permit2 都提供
+func DomainSeparator(contract common.Address) Call {
+	return Call{Target: contract, Data: encode(domainSeparatorSelector), Gas: balanceGas}
+}
+
+// Nonces EIP-2612 nonces(owner)
+func Nonces(token, owner common.Address) Call {
+	return Call{Target: token, Data: encode(noncesSelector, owner), Gas: balanceGas}
+}
+
+// EthBalance 原生币余额，通过 Multicall3.getEthBalance 与代币余额在同一批次查询
+func (c *Caller) EthBalance(owner common.Address) Call {
+	return Call{Target: c.address, Data: encode(getEthBalanceSelector, owner), Gas: balanceGas, ethBalance: &owner}
+}
+
+// Uint 解析 uint256 返回值，调用失败或返回数据不足 32 字节（如目标不是合约）时 ok 为 false
+func (r Result) Uint() (*big.Int, bool) {
+	if !r.Success || len(r.Data) < 32 {
+		return nil, false
+	}
+	return new(big.Int).SetBytes(r.Data[:32]), true
+}
+
+// Text 解析 string 返回值，兼容返回 bytes32 的老合约（如 MKR）
+func (r Result) Text() (string, bool) {
+	if !r.Success || len(r.Data) < 32 {
+		return "", false
+	}
+	if len(r.Data) == 32 {
+		return strings.TrimSpace(string(bytes.TrimRight(r.Data, "\x00"))), true
+	}
+
+	offset := new(big.Int).SetBytes(r.Data[:32])
+	if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(r.Data)) {
+		return "", false
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(r.Data[start-32 : start])
+	if !length.IsUint64() || start+length.Uint64() > uint64(len(r.Data)) {
+		return "", false
+	}
+	return string(r.Data[start : start+length.Uint64()]), true
+}
+
+// TokenMeta 代币元数据，Ok 表示 decimals 读取成功（symbol / name 可能为空）
+type TokenMeta struct {
+	Address  common.Address
+	Symbol   string
+	Name     string
+	Decimals int
+	Ok       bool
+}
+
+// TokenMetadata 批量读取代币的 symbol / name / decimals
+func (c *Caller) TokenMetadata(ctx context.Context, tokens []common.Address) ([]TokenMeta, error) {
+	calls := make([]Call, 0, len(tokens)*3)
+	for _, t := range tokens {
+		calls = append(calls, Decimals(t), Symbol(t), Name(t))
+	}
+	results, err := c.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	metas := make([]TokenMeta, len(tokens))
+	for i, t := range tokens {
+		meta := TokenMeta{Address: t}
+		if decimals, ok := results[i*3].Uint(); ok && decimals.IsInt64() && decimals.Int64() <= 255 {
+			meta.Decimals = int(decimals.Int64())
+			meta.Ok = true
+		}
+		meta.Symbol, _ = results[i*3+1].Text()
+		meta.Name, _ = results[i*3+2].Text()
+		metas[i] = meta
+	}
+	return metas, nil
+}
@@ -0,0 +1,74 @@
+package multicall
+
+import (
+	"fmt"
+	"sync"
+
+	"demo/internal/chains"
+	"demo/internal/config"
+	"demo/internal/rpcpool"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Multicall3Address Multicall3 在绝大多数 EVM 链上的统一部署地址
+const Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// Manager 为各 EVM 链创建批量调用器，并记住哪些链没有部署 Multicall3
+type Manager struct {
+	rpc       *rpcpool.Manager
+	conf      config.MulticallConf
+	addresses map[string]common.Address
+
+	mu       sync.RWMutex
+	notFound map[string]bool
+}
+
+// NewManager 校验注册表中 EVM 链的 Multicall 配置
+func NewManager(registry *chains.Registry, rpc *rpcpool.Manager, conf config.MulticallConf) (*Manager, error) {
+	m := &Manager{
+		rpc:       rpc,
+		conf:      conf,
+		addresses: make(map[string]common.Address),
+		notFound:  make(map[string]bool),
+	}
+	for _, c := range registry.ByFamily(chains.FamilyEVM) {
+		address := c.Multicall
+		if address == "" {
+			address = Multicall3Address
+		}
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("chain %s: invalid Multicall address %q", c.Key, c.Multicall)
+		}
+		m.addresses[c.Key] = common.HexToAddress(address)
+	}
+	return m, nil
+}
+
+// Caller 返回链的批量调用器，使用 RPC 节点池当前的节点
+func (m *Manager) Caller(chain *chains.Chain) (*Caller, error) {
+	address, ok := m.addresses[chain.Key]
+	if !ok {
+		return nil, fmt.Errorf("multicall is only available for EVM chains: %s", chain.Key)
+	}
+	client, err := m.rpc.EthClient(chain)
+	if err != nil {
+		return nil, err
+	}
+	return &Caller{m: m, chain: chain.Key, client: client, address: address}, nil
+}
+
+func (m *Manager) missing(chain string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.notFound[chain]
+}
+
+func (m *Manager) markMissing(chain string, address common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.notFound[chain] {
+		m.notFound[chain] = true
+		logMissing(chain, address)
+	}
+}
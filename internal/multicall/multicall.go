@@ -0,0 +1,223 @@
+package multicall
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultCallGas 未指定 Gas 的调用按该值参与分块（ERC20 只读调用通常在 3 万以内）
+const DefaultCallGas = 100000
+
+var multicall3ABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(`[
+		{"name":"aggregate3","type":"function","stateMutability":"payable",
+		 "inputs":[{"name":"calls","type":"tuple[]","components":[
+			{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],
+		 "outputs":[{"name":"returnData","type":"tuple[]","components":[
+			{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]}
+	]`))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+type call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type result3 struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// Call 单个合约只读调用
+type Call struct {
+	Target common.Address
+	Data   []byte
+	// Gas 调用的 gas 估算，用于分块，0 表示 DefaultCallGas
+	Gas uint64
+
+	// ethBalance 非空时为原生币余额查询（Multicall3.getEthBalance），
+	// 退回 JSON-RPC 批量请求时改用 eth_getBalance
+	ethBalance *common.Address
+}
+
+// Result 单个调用的结果，Success 为 false 时 Data 为空或 revert 数据
+type Result struct {
+	Success bool
+	Data    []byte
+}
+
+// Caller 单条链上的批量只读调用
+type Caller struct {
+	m       *Manager
+	chain   string
+	client  *ethclient.Client
+	address common.Address
+}
+
+// Address 返回使用的 Multicall3 合约地址
+func (c *Caller) Address() common.Address {
+	return c.address
+}
+
+// Aggregate 按调用数和 gas 分块执行，结果与 calls 一一对应。
+// 单个调用失败只影响其 Success；链上没有 Multicall3 时退回 JSON-RPC 批量 eth_call
+func (c *Caller) Aggregate(ctx context.Context, calls []Call) ([]Result, error) {
+	results := make([]Result, len(calls))
+	if len(calls) == 0 {
+		return results, nil
+	}
+
+	chunks := c.chunks(calls)
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, max(c.m.conf.Parallel, 1))
+	var wg sync.WaitGroup
+	for i, ch := range chunks {
+		wg.Add(1)
+		go func(i int, ch chunk) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = c.execute(ctx, calls[ch.start:ch.end], results[ch.start:ch.end])
+		}(i, ch)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+type chunk struct{ start, end int }
+
+// chunks 每块不超过 MaxCalls 个调用，且 gas 估算之和不超过 MaxGas
+func (c *Caller) chunks(calls []Call) []chunk {
+	var out []chunk
+	start := 0
+	var gas uint64
+	for i, call := range calls {
+		g := call.Gas
+		if g == 0 {
+			g = DefaultCallGas
+		}
+		if i > start && (i-start >= c.m.conf.MaxCalls || gas+g > c.m.conf.MaxGas) {
+			out = append(out, chunk{start, i})
+			start, gas = i, 0
+		}
+		gas += g
+	}
+	return append(out, chunk{start, len(calls)})
+}
+
+// execute 执行一个分块，Multicall3 不可用时退回批量 eth_call
+func (c *Caller) execute(ctx context.Context, calls []Call, results []Result) error {
+	if !c.m.missing(c.chain) {
+		err := c.aggregate3(ctx, calls, results)
+		if err == nil {
+			return nil
+		}
+		if err != errNotDeployed {
+			return err
+		}
+		c.m.markMissing(c.chain, c.address)
+	}
+	return c.batch(ctx, calls, results)
+}
+
+var errNotDeployed = fmt.Errorf("multicall3 not deployed")
+
+func (c *Caller) aggregate3(ctx context.Context, calls []Call, results []Result) error {
+	args := make([]call3, len(calls))
+	for i, call := range calls {
+		args[i] = call3{Target: call.Target, AllowFailure: true, CallData: call.Data}
+	}
+	data, err := multicall3ABI.Pack("aggregate3", args)
+	if err != nil {
+		return fmt.Errorf("failed to pack aggregate3: %w", err)
+	}
+
+	out, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &c.address, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("aggregate3 call failed: %w", err)
+	}
+	if len(out) == 0 {
+		// 地址上没有合约代码时 eth_call 返回空数据
+		return errNotDeployed
+	}
+
+	unpacked, err := multicall3ABI.Unpack("aggregate3", out)
+	if err != nil || len(unpacked) != 1 {
+		return errNotDeployed
+	}
+	decoded := *abi.ConvertType(unpacked[0], new([]result3)).(*[]result3)
+	if len(decoded) != len(calls) {
+		return fmt.Errorf("aggregate3 returned %d results for %d calls", len(decoded), len(calls))
+	}
+	for i, r := range decoded {
+		results[i] = Result{Success: r.Success, Data: r.ReturnData}
+	}
+	return nil
+}
+
+// batch 一次 JSON-RPC 批量请求执行全部调用，单个请求的错误只标记该调用失败
+func (c *Caller) batch(ctx context.Context, calls []Call, results []Result) error {
+	raw := make([]hexutil.Bytes, len(calls))
+	balances := make([]hexutil.Big, len(calls))
+	elems := make([]rpc.BatchElem, len(calls))
+	for i, call := range calls {
+		if call.ethBalance != nil {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getBalance",
+				Args:   []interface{}{*call.ethBalance, "latest"},
+				Result: &balances[i],
+			}
+			continue
+		}
+		elems[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{
+				map[string]interface{}{"to": call.Target, "data": hexutil.Bytes(call.Data)},
+				"latest",
+			},
+			Result: &raw[i],
+		}
+	}
+
+	if err := c.client.Client().BatchCallContext(ctx, elems); err != nil {
+		return fmt.Errorf("batch call failed: %w", err)
+	}
+	for i, call := range calls {
+		switch {
+		case elems[i].Error != nil:
+			results[i] = Result{}
+		case call.ethBalance != nil:
+			results[i] = Result{Success: true, Data: common.LeftPadBytes((*big.Int)(&balances[i]).Bytes(), 32)}
+		default:
+			results[i] = Result{Success: true, Data: raw[i]}
+		}
+	}
+	return nil
+}
+
+// logMissing 每条链只提示一次
+func logMissing(chain string, address common.Address) {
+	log.Printf("⚠️  %s 未部署 Multicall3 (%s)，合约读取改用 JSON-RPC 批量请求", chain, address.Hex())
+}
@@ -0,0 +1,67 @@
+package multicall
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC-165 接口 ID
+var (
+	InterfaceERC721           = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	InterfaceERC721Enumerable = [4]byte{0x78, 0x0e, 0x9d, 0x63}
+	InterfaceERC1155          = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+)
+
+var (
+	supportsInterfaceSelector   = []byte{0x01, 0xff, 0xc9, 0xa7} // supportsInterface(bytes4)
+	ownerOfSelector             = []byte{0x63, 0x52, 0x21, 0x1e} // ownerOf(uint256)
+	tokenOfOwnerByIndexSelector = []byte{0x2f, 0x74, 0x5c, 0x59} // tokenOfOwnerByIndex(address,uint256)
+	balanceOf1155Selector       = []byte{0x00, 0xfd, 0xd5, 0x8e} // balanceOf(address,uint256)
+)
+
+func uint256Word(v *big.Int) []byte {
+	return common.LeftPadBytes(v.Bytes(), 32)
+}
+
+// SupportsInterface ERC-165 supportsInterface(id)，EIP-165 要求实现方在 30000 gas 内返回
+func SupportsInterface(contract common.Address, id [4]byte) Call {
+	data := append([]byte{}, supportsInterfaceSelector...)
+	data = append(data, common.RightPadBytes(id[:], 32)...)
+	return Call{Target: contract, Data: data, Gas: 30000}
+}
+
+// OwnerOf ERC-721 ownerOf(tokenId)
+func OwnerOf(contract common.Address, tokenId *big.Int) Call {
+	data := append(append([]byte{}, ownerOfSelector...), uint256Word(tokenId)...)
+	return Call{Target: contract, Data: data, Gas: balanceGas}
+}
+
+// TokenOfOwnerByIndex ERC721Enumerable tokenOfOwnerByIndex(owner, index)
+func TokenOfOwnerByIndex(contract, owner common.Address, index int) Call {
+	data := append(encode(tokenOfOwnerByIndexSelector, owner), uint256Word(big.NewInt(int64(index)))...)
+	return Call{Target: contract, Data: data, Gas: balanceGas}
+}
+
+// BalanceOf1155 ERC-1155 balanceOf(owner, id)
+func BalanceOf1155(contract, owner common.Address, id *big.Int) Call {
+	data := append(encode(balanceOf1155Selector, owner), uint256Word(id)...)
+	return Call{Target: contract, Data: data, Gas: balanceGas}
+}
+
+// Bool 解析 bool 返回值
+func (r Result) Bool() (bool, bool) {
+	v, ok := r.Uint()
+	if !ok {
+		return false, false
+	}
+	return v.Sign() != 0, true
+}
+
+// Address 解析 address 返回值
+func (r Result) Address() (common.Address, bool) {
+	if !r.Success || len(r.Data) < 32 {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(r.Data[12:32]), true
+}
@@ -0,0 +1,347 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"demo/internal/config"
+	"demo/internal/model"
+	"demo/internal/units"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 受策略约束的操作，同时作为审计日志的 action
+const (
+	ActionSignMessage  = "sign_message"
+	ActionContractCall = "contract_call"
+	// ActionScheduledTransfer 定时转账 / 兑换的一次执行，按 Policy.Scheduled 校验
+	ActionScheduledTransfer = "scheduled_transfer"
+	// ActionGasTopUp gas 站向钱包补充原生币，按 Policy.GasTopUp 校验
+	ActionGasTopUp = "gas_topup"
+)
+
+// 消息签名类型
+const (
+	MessagePersonalSign = "personal_sign"
+	MessageEIP712       = "eip712"
+	MessageBIP322       = "bip322"
+	MessageSolana       = "solana"
+)
+
+// Request 待校验的签名请求，只需填写与操作相关的字段
+type Request struct {
+	Action  string
+	Chain   string
+	Address string
+
+	// 消息签名
+	MessageType string
+	Size        int
+	BlindSign   bool // personal_sign 的内容是裸 32 字节哈希
+	PrimaryType string
+	Contract    string // EIP-712 verifyingContract，合约调用时为目标合约
+
+	// 合约调用
+	Method    string // 方法名
+	Signature string // 完整签名，如 transfer(address,uint256)
+	Selector  string // 0x 开头的 4 字节选择器
+
+	// 定时转账、gas 补充
+	Recipient    string
+	Token        string   // 代币符号，注册表中没有时为请求中的原值
+	TokenAddress string   // 代币地址，原生币或未知代币为空
+	Amount       *big.Int // 最小单位金额，nil 表示 max（发送全部余额）
+	Decimals     int      // 代币精度，-1 表示未知
+}
+
+// Violation 策略拒绝，Rule 为命中的规则名
+type Violation struct {
+	Rule   string
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("denied by policy %s: %s", v.Rule, v.Reason)
+}
+
+// Engine 在签名前按配置校验请求，并把结果写入审计日志
+type Engine struct {
+	conf      config.PolicyConf
+	denied    map[string]bool
+	contracts map[common.Address]bool
+	rules     []contractRule
+	// 定时转账
+	recipients map[string]bool
+	limits     []amountLimit
+	client     *http.Client
+	// gas 补充
+	topUpLimits []amountLimit
+	audit       model.AuditEntriesDao
+}
+
+type contractRule struct {
+	chain   string
+	address common.Address
+	methods map[string]bool // 方法名、签名、选择器均以小写登记
+}
+
+type amountLimit struct {
+	chain     string // 以下均为小写
+	token     string
+	maxAmount string
+}
+
+// NewEngine 校验策略配置
+func NewEngine(conf config.PolicyConf, audit model.AuditEntriesDao) (*Engine, error) {
+	e := &Engine{
+		conf:      conf,
+		denied:    make(map[string]bool),
+		contracts: make(map[common.Address]bool),
+		audit:     audit,
+	}
+	for _, t := range conf.DeniedTypedData {
+		e.denied[strings.ToLower(t)] = true
+	}
+	for _, c := range conf.TypedDataContracts {
+		if !common.IsHexAddress(c) {
+			return nil, fmt.Errorf("invalid TypedDataContracts address %q", c)
+		}
+		e.contracts[common.HexToAddress(c)] = true
+	}
+	for _, r := range conf.Contracts {
+		if !common.IsHexAddress(r.Address) {
+			return nil, fmt.Errorf("invalid Contracts address %q", r.Address)
+		}
+		rule := contractRule{
+			chain:   strings.ToLower(r.Chain),
+			address: common.HexToAddress(r.Address),
+			methods: make(map[string]bool),
+		}
+		for _, m := range r.Methods {
+			rule.methods[strings.ToLower(strings.ReplaceAll(m, " ", ""))] = true
+		}
+		e.rules = append(e.rules, rule)
+	}
+
+	sched := conf.Scheduled
+	e.recipients = make(map[string]bool, len(sched.Recipients))
+	for _, r := range sched.Recipients {
+		e.recipients[normalizeAddress(r)] = true
+	}
+	limits, err := parseLimits("Scheduled.Limits", sched.Limits)
+	if err != nil {
+		return nil, err
+	}
+	e.limits = limits
+	if e.topUpLimits, err = parseLimits("GasTopUp.Limits", conf.GasTopUp.Limits); err != nil {
+		return nil, err
+	}
+	if sched.ApprovalUrl != "" {
+		if !strings.HasPrefix(sched.ApprovalUrl, "http://") && !strings.HasPrefix(sched.ApprovalUrl, "https://") {
+			return nil, fmt.Errorf("invalid Scheduled.ApprovalUrl %q", sched.ApprovalUrl)
+		}
+		e.client = &http.Client{Timeout: time.Duration(sched.ApprovalTimeout) * time.Second}
+	}
+	return e, nil
+}
+
+// parseLimits 校验金额上限配置，链和代币统一为小写
+func parseLimits(name string, in []config.AmountLimit) ([]amountLimit, error) {
+	var limits []amountLimit
+	for _, l := range in {
+		if strings.TrimSpace(l.Token) == "" {
+			return nil, fmt.Errorf("invalid %s entry for %q: Token is required, use the native symbol for the native currency", name, l.MaxAmount)
+		}
+		if _, err := units.ParseUnits(l.MaxAmount, 36); err != nil || strings.HasPrefix(strings.TrimSpace(l.MaxAmount), "-") {
+			return nil, fmt.Errorf("invalid %s max amount %q for %s", name, l.MaxAmount, l.Token)
+		}
+		limits = append(limits, amountLimit{
+			chain:     strings.ToLower(l.Chain),
+			token:     strings.ToLower(strings.TrimSpace(l.Token)),
+			maxAmount: strings.TrimSpace(l.MaxAmount),
+		})
+	}
+	return limits, nil
+}
+
+// Check 返回 nil 表示放行，拒绝时返回 *Violation
+func (e *Engine) Check(req *Request) error {
+	switch req.Action {
+	case ActionSignMessage:
+		return e.checkMessage(req)
+	case ActionContractCall:
+		return e.checkContractCall(req)
+	case ActionScheduledTransfer:
+		return e.checkScheduledTransfer(req)
+	case ActionGasTopUp:
+		return checkLimits("GasTopUp.Limits", e.topUpLimits, req)
+	}
+	return nil
+}
+
+func (e *Engine) checkMessage(req *Request) error {
+	if e.conf.MaxMessageBytes > 0 && req.Size > e.conf.MaxMessageBytes {
+		return &Violation{"MaxMessageBytes", fmt.Sprintf("message is %d bytes, limit is %d", req.Size, e.conf.MaxMessageBytes)}
+	}
+	if req.BlindSign && !e.conf.AllowBlindSign {
+		return &Violation{"AllowBlindSign", "personal_sign of a raw 32-byte hash is not allowed"}
+	}
+	if req.MessageType != MessageEIP712 {
+		return nil
+	}
+
+	if e.denied[strings.ToLower(req.PrimaryType)] {
+		return &Violation{"DeniedTypedData", fmt.Sprintf("primary type %s is not allowed", req.PrimaryType)}
+	}
+	if len(e.contracts) > 0 {
+		if req.Contract == "" || !common.IsHexAddress(req.Contract) || !e.contracts[common.HexToAddress(req.Contract)] {
+			return &Violation{"TypedDataContracts", fmt.Sprintf("verifying contract %q is not allowed", req.Contract)}
+		}
+	}
+	return nil
+}
+
+// checkContractCall 目标合约必须在 Contracts 白名单中，规则列出方法时还需命中方法名、签名或选择器
+func (e *Engine) checkContractCall(req *Request) error {
+	if !common.IsHexAddress(req.Contract) {
+		return &Violation{"Contracts", fmt.Sprintf("invalid contract address %q", req.Contract)}
+	}
+	contract := common.HexToAddress(req.Contract)
+	contractListed := false
+	for _, r := range e.rules {
+		if r.address != contract || (r.chain != "" && r.chain != strings.ToLower(req.Chain)) {
+			continue
+		}
+		contractListed = true
+		if len(r.methods) == 0 ||
+			r.methods[strings.ToLower(req.Method)] ||
+			r.methods[strings.ToLower(req.Signature)] ||
+			r.methods[strings.ToLower(req.Selector)] {
+			return nil
+		}
+	}
+	if !contractListed {
+		return &Violation{"Contracts", fmt.Sprintf("contract %s on %s is not allowlisted", contract.Hex(), req.Chain)}
+	}
+	return &Violation{"Contracts", fmt.Sprintf("method %s is not allowlisted for contract %s", req.Signature, contract.Hex())}
+}
+
+// checkScheduledTransfer 收款地址须在 Scheduled.Recipients 中（为空不限制），金额不得超过同一代币的 Scheduled.Limits
+func (e *Engine) checkScheduledTransfer(req *Request) error {
+	if len(e.recipients) > 0 && !e.recipients[normalizeAddress(req.Recipient)] {
+		return &Violation{"Scheduled.Recipients", fmt.Sprintf("recipient %s is not allowlisted", req.Recipient)}
+	}
+	return checkLimits("Scheduled.Limits", e.limits, req)
+}
+
+// checkLimits 金额不得超过同链同代币的上限；有上限时 max 金额和精度未知的代币无法比较，直接拒绝
+func checkLimits(rule string, limits []amountLimit, req *Request) error {
+	for _, l := range limits {
+		if l.chain != "" && l.chain != strings.ToLower(req.Chain) {
+			continue
+		}
+		if l.token != strings.ToLower(req.Token) && (req.TokenAddress == "" || l.token != strings.ToLower(req.TokenAddress)) {
+			continue
+		}
+		if req.Amount == nil {
+			return &Violation{rule, fmt.Sprintf("amount max cannot be checked against the %s limit of %s", req.Token, l.maxAmount)}
+		}
+		if req.Decimals < 0 {
+			return &Violation{rule, fmt.Sprintf("decimals of %s are unknown, cannot check the limit of %s", req.Token, l.maxAmount)}
+		}
+		max, err := units.ParseUnits(l.maxAmount, req.Decimals)
+		if err != nil {
+			return &Violation{rule, fmt.Sprintf("limit %s has more decimals than %s", l.maxAmount, req.Token)}
+		}
+		if req.Amount.Cmp(max) > 0 {
+			return &Violation{rule, fmt.Sprintf("amount %s %s exceeds the limit of %s",
+				units.FormatUnits(req.Amount, req.Decimals), req.Token, l.maxAmount)}
+		}
+	}
+	return nil
+}
+
+// Approve 配置了 Scheduled.ApprovalUrl 时把定时执行 POST 给审批接口，只有 2xx 且 {"approved": true} 视为批准，
+// 明确拒绝时返回 *Violation，接口不可用时返回普通错误；其他操作和未配置审批时直接放行
+func (e *Engine) Approve(ctx context.Context, req *Request, detail interface{}) error {
+	if req.Action != ActionScheduledTransfer || e.client == nil {
+		return nil
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"action":  req.Action,
+		"chain":   req.Chain,
+		"address": req.Address,
+		"detail":  detail,
+	})
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.conf.Scheduled.ApprovalUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("approval request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("approval service returned %d: %s", resp.StatusCode, string(msg))
+	}
+	var result struct {
+		Approved bool   `json:"approved"`
+		Reason   string `json:"reason"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return fmt.Errorf("invalid approval response: %w", err)
+	}
+	if !result.Approved {
+		if result.Reason == "" {
+			result.Reason = "not approved"
+		}
+		return &Violation{"Scheduled.ApprovalUrl", result.Reason}
+	}
+	return nil
+}
+
+// normalizeAddress EVM 地址不区分大小写，其他链（Base58 等）按原样比较
+func normalizeAddress(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if common.IsHexAddress(addr) {
+		return strings.ToLower(common.HexToAddress(addr).Hex())
+	}
+	return addr
+}
+
+// Audit 追加一条审计日志，detail 序列化为 JSON；写入失败只打印日志，不影响请求
+func (e *Engine) Audit(ctx context.Context, req *Request, target, result string, detail interface{}) {
+	raw, err := json.Marshal(detail)
+	if err != nil {
+		raw = []byte(fmt.Sprintf("%q", fmt.Sprint(detail)))
+	}
+	entry := &model.AuditEntries{
+		Actor:     "api",
+		Action:    req.Action,
+		Chain:     req.Chain,
+		Address:   req.Address,
+		Target:    target,
+		Result:    result,
+		Detail:    string(raw),
+		CreatedAt: time.Now(),
+	}
+	if err := e.audit.Insert(ctx, entry); err != nil {
+		log.Printf("❌ 写入审计日志失败 (%s %s): %v", req.Action, req.Address, err)
+	}
+}
@@ -0,0 +1,163 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"demo/internal/config"
+)
+
+func TestCheckScheduledTransfer(t *testing.T) {
+	e, err := NewEngine(config.PolicyConf{Scheduled: config.ScheduledPolicy{
+		Recipients: []string{"0x66129b7045a6559c6d7274bb57534faaa58c8ced", "bc1qrecipient"},
+		Limits: []config.AmountLimit{
+			{Chain: "BSC", Token: "USDT", MaxAmount: "1000"},
+			{Token: "0x55d398326f99059ff775485246999027b3197955", MaxAmount: "500"},
+			{Token: "BNB", MaxAmount: "0.5"},
+		},
+	}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wei := func(s string) *big.Int {
+		v, _ := new(big.Int).SetString(s, 10)
+		return v
+	}
+	const to = "0x66129B7045a6559c6D7274bb57534fAAA58c8Ced"
+
+	tests := []struct {
+		name string
+		req  Request
+		rule string // 为空表示放行
+	}{
+		{"within limit", Request{Chain: "BSC", Recipient: to, Token: "USDT", Amount: wei("1000000000000000000000"), Decimals: 18}, ""},
+		{"over limit", Request{Chain: "BSC", Recipient: to, Token: "usdt", Amount: wei("1000000000000000000001"), Decimals: 18}, "Scheduled.Limits"},
+		{"limit of another chain", Request{Chain: "ETH", Recipient: to, Token: "USDT", Amount: wei("2000000000"), Decimals: 6}, ""},
+		{"limit by address", Request{Chain: "ETH", Recipient: to, Token: "FOO", TokenAddress: "0x55d398326f99059fF775485246999027B3197955", Amount: wei("501000000000000000000"), Decimals: 18}, "Scheduled.Limits"},
+		{"native limit", Request{Chain: "BSC", Recipient: to, Token: "BNB", Amount: wei("500000000000000001"), Decimals: 18}, "Scheduled.Limits"},
+		{"max against a limit", Request{Chain: "BSC", Recipient: to, Token: "BNB", Decimals: 18}, "Scheduled.Limits"},
+		{"max without a limit", Request{Chain: "BSC", Recipient: to, Token: "CAKE", Decimals: 18}, ""},
+		{"unknown decimals", Request{Chain: "BSC", Recipient: to, Token: "USDT", Amount: wei("1"), Decimals: -1}, "Scheduled.Limits"},
+		{"limit finer than the token", Request{Chain: "BSC", Recipient: to, Token: "BNB", Amount: wei("1"), Decimals: 0}, "Scheduled.Limits"},
+		{"recipient not allowlisted", Request{Chain: "BSC", Recipient: "0x0000000000000000000000000000000000000001", Token: "CAKE", Amount: wei("1"), Decimals: 18}, "Scheduled.Recipients"},
+		{"non-EVM recipient", Request{Chain: "BTC", Recipient: "bc1qrecipient", Token: "BTC", Amount: wei("1"), Decimals: 8}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Action = ActionScheduledTransfer
+			err := e.Check(&tt.req)
+			var v *Violation
+			switch {
+			case tt.rule == "" && err != nil:
+				t.Fatalf("unexpected denial: %v", err)
+			case tt.rule != "" && !errors.As(err, &v):
+				t.Fatalf("got %v, want a %s violation", err, tt.rule)
+			case tt.rule != "" && v.Rule != tt.rule:
+				t.Fatalf("got rule %s, want %s", v.Rule, tt.rule)
+			}
+		})
+	}
+}
+
+func TestCheckGasTopUp(t *testing.T) {
+	e, err := NewEngine(config.PolicyConf{
+		Scheduled: config.ScheduledPolicy{Recipients: []string{"0x0000000000000000000000000000000000000001"}},
+		GasTopUp:  config.GasTopUpPolicy{Limits: []config.AmountLimit{{Chain: "BSC", Token: "BNB", MaxAmount: "0.01"}}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const wallet = "0x66129B7045a6559c6D7274bb57534fAAA58c8Ced"
+
+	tests := []struct {
+		name   string
+		req    Request
+		denied bool
+	}{
+		// 定时转账的收款白名单不约束 gas 补充
+		{"within limit", Request{Chain: "BSC", Recipient: wallet, Token: "BNB", Amount: big.NewInt(1e16), Decimals: 18}, false},
+		{"over limit", Request{Chain: "BSC", Recipient: wallet, Token: "BNB", Amount: big.NewInt(1e16 + 1), Decimals: 18}, true},
+		{"chain without a limit", Request{Chain: "ETH", Recipient: wallet, Token: "ETH", Amount: big.NewInt(1e18), Decimals: 18}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Action = ActionGasTopUp
+			err := e.Check(&tt.req)
+			var v *Violation
+			switch {
+			case !tt.denied && err != nil:
+				t.Fatalf("unexpected denial: %v", err)
+			case tt.denied && (!errors.As(err, &v) || v.Rule != "GasTopUp.Limits"):
+				t.Fatalf("got %v, want a GasTopUp.Limits violation", err)
+			}
+		})
+	}
+}
+
+func TestNewEngineRejectsInvalidLimits(t *testing.T) {
+	for _, limit := range []config.AmountLimit{
+		{Token: "USDT", MaxAmount: "abc"},
+		{Token: "USDT", MaxAmount: "-1"},
+		{MaxAmount: "1"},
+	} {
+		conf := config.PolicyConf{Scheduled: config.ScheduledPolicy{Limits: []config.AmountLimit{limit}}}
+		if _, err := NewEngine(conf, nil); err == nil {
+			t.Errorf("scheduled limit %+v: expected an error", limit)
+		}
+		conf = config.PolicyConf{GasTopUp: config.GasTopUpPolicy{Limits: []config.AmountLimit{limit}}}
+		if _, err := NewEngine(conf, nil); err == nil {
+			t.Errorf("gas top-up limit %+v: expected an error", limit)
+		}
+	}
+}
+
+func TestApprove(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		denied bool
+		failed bool
+	}{
+		{"approved", http.StatusOK, `{"approved": true}`, false, false},
+		{"rejected", http.StatusOK, `{"approved": false, "reason": "over budget"}`, true, false},
+		{"empty body", http.StatusOK, `{}`, true, false},
+		{"server error", http.StatusInternalServerError, `oops`, false, true},
+		{"invalid body", http.StatusOK, `not json`, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("got %s, want POST", r.Method)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			e, err := NewEngine(config.PolicyConf{Scheduled: config.ScheduledPolicy{ApprovalUrl: srv.URL, ApprovalTimeout: 5}}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = e.Approve(context.Background(), &Request{Action: ActionScheduledTransfer, Chain: "BSC"}, map[string]int{"schedule_id": 1})
+			var v *Violation
+			switch {
+			case tt.denied && !errors.As(err, &v):
+				t.Fatalf("got %v, want a violation", err)
+			case tt.failed && (err == nil || errors.As(err, &v)):
+				t.Fatalf("got %v, want a plain error", err)
+			case !tt.denied && !tt.failed && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// 其他操作不经过审批
+			if err := e.Approve(context.Background(), &Request{Action: ActionContractCall}, nil); err != nil {
+				t.Fatalf("contract call: %v", err)
+			}
+		})
+	}
+}
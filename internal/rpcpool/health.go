@@ -0,0 +1,156 @@
+package rpcpool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"demo/internal/chains"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// CheckHealth 并发探测所有节点的最新高度，计算相对最高节点的延迟，
+// 探测失败或落后超过 MaxLag 的节点标记为不健康
+func (p *Pool) CheckHealth(ctx context.Context) {
+	heads := make([]uint64, len(p.endpoints))
+	errs := make([]error, len(p.endpoints))
+	latencies := make([]time.Duration, len(p.endpoints))
+
+	var wg sync.WaitGroup
+	for i, ep := range p.endpoints {
+		wg.Add(1)
+		go func(i int, ep *Endpoint) {
+			defer wg.Done()
+			start := time.Now()
+			heads[i], errs[i] = p.probe(ctx, ep)
+			latencies[i] = time.Since(start)
+		}(i, ep)
+	}
+	wg.Wait()
+
+	var best uint64
+	for i := range p.endpoints {
+		if errs[i] == nil && heads[i] > best {
+			best = heads[i]
+		}
+	}
+
+	now := time.Now()
+	for i, ep := range p.endpoints {
+		ep.mu.Lock()
+		ep.lastCheck = now
+		if errs[i] != nil {
+			ep.healthy = false
+			ep.lastError = errs[i].Error()
+			ep.failures++
+		} else {
+			ep.head = heads[i]
+			ep.lag = best - heads[i]
+			ep.latency = latencies[i]
+			ep.healthy = ep.lag <= uint64(p.conf.MaxLag)
+			if ep.healthy {
+				ep.failures = 0
+				ep.lastError = ""
+			} else {
+				ep.lastError = fmt.Sprintf("lagging %d blocks behind", ep.lag)
+			}
+		}
+		healthy, lag := ep.healthy, ep.lag
+		ep.mu.Unlock()
+
+		metricLag.Set(float64(lag), p.chain.Key, ep.name)
+		if healthy {
+			metricHealthy.Set(1, p.chain.Key, ep.name)
+		} else {
+			metricHealthy.Set(0, p.chain.Key, ep.name)
+		}
+	}
+}
+
+// probe 获取单个节点的最新高度：EVM 用 eth_blockNumber，Solana 用 getSlot，BTC 用 Esplora /blocks/tip/height
+func (p *Pool) probe(ctx context.Context, ep *Endpoint) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(p.conf.Timeout)*time.Second)
+	defer cancel()
+
+	switch p.chain.Family() {
+	case chains.FamilyBTC:
+		body, err := p.probeRequest(ctx, http.MethodGet, ep.url+"/blocks/tip/height", nil)
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseUint(strings.TrimSpace(string(body)), 10, 64)
+	case chains.FamilySolana:
+		var slot uint64
+		if err := p.probeJSONRPC(ctx, ep, "getSlot", &slot); err != nil {
+			return 0, err
+		}
+		return slot, nil
+	default:
+		var head hexutil.Uint64
+		if err := p.probeJSONRPC(ctx, ep, "eth_blockNumber", &head); err != nil {
+			return 0, err
+		}
+		return uint64(head), nil
+	}
+}
+
+func (p *Pool) probeJSONRPC(ctx context.Context, ep *Endpoint, method string, result interface{}) error {
+	payload := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":%q,"params":[]}`, method)
+	body, err := p.probeRequest(ctx, http.MethodPost, ep.url, []byte(payload))
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", method, err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%s: rpc error %d: %s", method, resp.Error.Code, resp.Error.Message)
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// probeRequest 直接请求指定节点，不经过故障切换
+func (p *Pool) probeRequest(ctx context.Context, method, url string, payload []byte) ([]byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return body, nil
+}
@@ -0,0 +1,134 @@
+package rpcpool
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"demo/internal/chains"
+	"demo/internal/config"
+
+	solanaClient "github.com/blocto/solana-go-sdk/client"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Manager 持有所有链的节点池
+type Manager struct {
+	conf  config.RpcPoolConf
+	pools map[string]*Pool
+	order []*Pool
+}
+
+// NewManager 为注册表中的每条链创建节点池
+func NewManager(registry *chains.Registry, conf config.RpcPoolConf) (*Manager, error) {
+	m := &Manager{conf: conf, pools: make(map[string]*Pool)}
+	for _, c := range registry.All() {
+		p, err := NewPool(c, conf)
+		if err != nil {
+			return nil, err
+		}
+		m.pools[c.Key] = p
+		m.order = append(m.order, p)
+	}
+	return m, nil
+}
+
+// Pool 返回链对应的节点池
+func (m *Manager) Pool(chain *chains.Chain) (*Pool, error) {
+	p, ok := m.pools[chain.Key]
+	if !ok {
+		return nil, fmt.Errorf("no rpc pool for chain %s", chain.Key)
+	}
+	return p, nil
+}
+
+// EthClient 返回链共享的 ethclient，调用方不要 Close
+func (m *Manager) EthClient(chain *chains.Chain) (*ethclient.Client, error) {
+	p, err := m.Pool(chain)
+	if err != nil {
+		return nil, err
+	}
+	return p.EthClient()
+}
+
+// SolanaClient 返回链共享的 Solana 客户端
+func (m *Manager) SolanaClient(chain *chains.Chain) (*solanaClient.Client, error) {
+	p, err := m.Pool(chain)
+	if err != nil {
+		return nil, err
+	}
+	return p.SolanaClient(), nil
+}
+
+// Start 启动后台健康检查，ctx 取消后退出
+func (m *Manager) Start(ctx context.Context) {
+	interval := time.Duration(m.conf.HealthInterval) * time.Second
+	for _, p := range m.order {
+		go func(p *Pool) {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				p.CheckHealth(ctx)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(p)
+	}
+	log.Printf("🩺 RPC 节点健康检查已启动，共 %d 条链，间隔 %s", len(m.order), interval)
+}
+
+// EndpointStats 单个节点的状态与计数
+type EndpointStats struct {
+	Endpoint  string `json:"endpoint"`
+	Healthy   bool   `json:"healthy"`
+	Head      uint64 `json:"head"`
+	Lag       uint64 `json:"lag"`
+	LatencyMs int64  `json:"latency_ms"`
+	Failures  int    `json:"consecutive_failures"`
+	LastError string `json:"last_error,omitempty"`
+	LastCheck string `json:"last_check,omitempty"`
+	Requests  int64  `json:"requests"`
+	Errors    int64  `json:"errors"`
+	Inflight  int64  `json:"inflight"`
+}
+
+// ChainStats 一条链的节点池状态
+type ChainStats struct {
+	Chain     string          `json:"chain"`
+	Failovers int64           `json:"failovers"`
+	Endpoints []EndpointStats `json:"endpoints"`
+}
+
+// Stats 返回所有节点池的状态，节点地址已脱敏
+func (m *Manager) Stats() []ChainStats {
+	result := make([]ChainStats, 0, len(m.order))
+	for _, p := range m.order {
+		cs := ChainStats{Chain: p.chain.Key, Failovers: p.failovers.Load()}
+		for _, ep := range p.endpoints {
+			ep.mu.RLock()
+			st := EndpointStats{
+				Endpoint:  ep.name,
+				Healthy:   ep.healthy,
+				Head:      ep.head,
+				Lag:       ep.lag,
+				LatencyMs: ep.latency.Milliseconds(),
+				Failures:  ep.failures,
+				LastError: ep.lastError,
+			}
+			if !ep.lastCheck.IsZero() {
+				st.LastCheck = ep.lastCheck.Format(time.RFC3339)
+			}
+			ep.mu.RUnlock()
+			st.Requests = ep.requests.Load()
+			st.Errors = ep.errors.Load()
+			st.Inflight = ep.inflight.Load()
+			cs.Endpoints = append(cs.Endpoints, st)
+		}
+		result = append(result, cs)
+	}
+	return result
+}
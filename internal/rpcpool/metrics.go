@@ -0,0 +1,43 @@
+package rpcpool
+
+import "github.com/zeromicro/go-zero/core/metric"
+
+// 节点池指标，开启 go-zero Prometheus 后通过 /metrics 暴露
+var (
+	metricRequests = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "rpc_pool",
+		Subsystem: "endpoint",
+		Name:      "requests_total",
+		Help:      "rpc requests sent to each endpoint.",
+		Labels:    []string{"chain", "endpoint", "result"},
+	})
+	metricDuration = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: "rpc_pool",
+		Subsystem: "endpoint",
+		Name:      "duration_ms",
+		Help:      "rpc request duration of each endpoint in milliseconds.",
+		Labels:    []string{"chain", "endpoint"},
+		Buckets:   []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000},
+	})
+	metricHealthy = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: "rpc_pool",
+		Subsystem: "endpoint",
+		Name:      "healthy",
+		Help:      "1 if the endpoint passed the last health check.",
+		Labels:    []string{"chain", "endpoint"},
+	})
+	metricLag = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: "rpc_pool",
+		Subsystem: "endpoint",
+		Name:      "lag_blocks",
+		Help:      "blocks (or slots) the endpoint is behind the best endpoint.",
+		Labels:    []string{"chain", "endpoint"},
+	})
+	metricFailovers = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "rpc_pool",
+		Subsystem: "chain",
+		Name:      "failovers_total",
+		Help:      "requests retried on another endpoint.",
+		Labels:    []string{"chain"},
+	})
+)
@@ -0,0 +1,308 @@
+// Package rpcpool 按链维护 RPC 节点池：同一条链可以配置多个节点，
+// 定期做健康和区块延迟检查，请求优先发往最健康的节点，失败时自动切换到下一个，
+// 并限制单个节点的并发请求数。EVM JSON-RPC、Solana RPC 和 BTC Esplora 共用同一套实现。
+package rpcpool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"demo/internal/chains"
+	"demo/internal/config"
+
+	solanaClient "github.com/blocto/solana-go-sdk/client"
+	solanaRpc "github.com/blocto/solana-go-sdk/rpc"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrNoEndpoint 所有节点都请求失败
+var ErrNoEndpoint = errors.New("rpcpool: all endpoints failed")
+
+// Endpoint 单个 RPC 节点及其运行状态
+type Endpoint struct {
+	url  string
+	name string // 脱敏后的节点名（scheme://host），用于日志和指标，避免泄露 URL 中的 API Key
+	sem  chan struct{}
+
+	mu        sync.RWMutex
+	healthy   bool
+	head      uint64
+	lag       uint64
+	latency   time.Duration
+	failures  int // 连续失败次数
+	lastError string
+	lastCheck time.Time
+
+	requests atomic.Int64
+	errors   atomic.Int64
+	inflight atomic.Int64
+}
+
+// Pool 一条链的节点池，实现 http.RoundTripper
+//
+// 调用方始终向 URL() 返回的虚拟地址发请求，RoundTrip 把地址前缀替换成
+// 当前选中的节点地址，因此 ethclient、Solana SDK 和 Esplora 的 REST 路径都能直接复用。
+type Pool struct {
+	chain     *chains.Chain
+	conf      config.RpcPoolConf
+	base      string
+	endpoints []*Endpoint
+	transport *http.Transport
+	client    *http.Client
+
+	failovers atomic.Int64
+
+	ethOnce sync.Once
+	eth     *ethclient.Client
+	ethErr  error
+	sol     *solanaClient.Client
+}
+
+// NewPool 根据链配置创建节点池，RpcUrl 为首选节点，RpcUrls 为备用节点
+func NewPool(chain *chains.Chain, conf config.RpcPoolConf) (*Pool, error) {
+	urls := append([]string{chain.RpcUrl}, chain.RpcUrls...)
+
+	p := &Pool{
+		chain: chain,
+		conf:  conf,
+		base:  strings.TrimRight(chain.RpcUrl, "/"),
+		transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           (&net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   conf.MaxConcurrent,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: time.Duration(conf.Timeout) * time.Second,
+		},
+	}
+	p.client = &http.Client{Transport: p}
+	p.sol = solanaClient.New(solanaRpc.WithEndpoint(p.base), solanaRpc.WithHTTPClient(p.client))
+
+	seen := make(map[string]bool)
+	for _, raw := range urls {
+		raw = strings.TrimRight(strings.TrimSpace(raw), "/")
+		if raw == "" || seen[raw] {
+			continue
+		}
+		seen[raw] = true
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("chain %s: invalid rpc url %q", chain.Key, raw)
+		}
+		p.endpoints = append(p.endpoints, &Endpoint{
+			url:     raw,
+			name:    u.Scheme + "://" + u.Host,
+			sem:     make(chan struct{}, conf.MaxConcurrent),
+			healthy: true, // 首次健康检查之前默认可用
+		})
+	}
+	if len(p.endpoints) == 0 {
+		return nil, fmt.Errorf("chain %s: no rpc url configured", chain.Key)
+	}
+	return p, nil
+}
+
+// Chain 返回节点池所属的链
+func (p *Pool) Chain() *chains.Chain {
+	return p.chain
+}
+
+// URL 返回节点池的虚拟地址，经 HTTPClient() 发出的请求会被路由到实际节点
+func (p *Pool) URL() string {
+	return p.base
+}
+
+// HTTPClient 返回走节点池的 HTTP 客户端（连接复用、故障切换、并发限制）
+func (p *Pool) HTTPClient() *http.Client {
+	return p.client
+}
+
+// EthClient 返回该链共享的 ethclient，调用方不要 Close
+func (p *Pool) EthClient() (*ethclient.Client, error) {
+	p.ethOnce.Do(func() {
+		c, err := rpc.DialOptions(context.Background(), p.base, rpc.WithHTTPClient(p.client))
+		if err != nil {
+			p.ethErr = fmt.Errorf("chain %s: %w", p.chain.Key, err)
+			return
+		}
+		p.eth = ethclient.NewClient(c)
+	})
+	return p.eth, p.ethErr
+}
+
+// SolanaClient 返回走节点池的 Solana 客户端
+func (p *Pool) SolanaClient() *solanaClient.Client {
+	return p.sol
+}
+
+// RoundTrip 按健康度依次尝试各个节点，网络错误、429 和 5xx 时切换到下一个节点。
+// 广播交易被重试到其他节点是安全的：同一笔已签名交易最多返回 "already known"。
+func (p *Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	suffix, ok := strings.CutPrefix(req.URL.String(), p.base)
+	if !ok || (suffix != "" && suffix[0] != '/' && suffix[0] != '?') {
+		return p.transport.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var lastErr error
+	for i, ep := range p.ordered() {
+		if i > 0 {
+			p.failovers.Add(1)
+			metricFailovers.Inc(p.chain.Key)
+		}
+
+		resp, err := p.send(req, ep, suffix, body)
+		if err == nil && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < http.StatusInternalServerError {
+			ep.markSuccess()
+			return resp, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%s returned status %d", ep.name, resp.StatusCode)
+			if i == len(p.endpoints)-1 {
+				// 最后一个节点，把原始响应交给调用方处理
+				ep.markFailure(err, p.conf.MaxFailures)
+				return resp, nil
+			}
+			resp.Body.Close()
+		}
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		ep.markFailure(err, p.conf.MaxFailures)
+		lastErr = err
+	}
+	return nil, fmt.Errorf("%w (chain %s): %v", ErrNoEndpoint, p.chain.Key, lastErr)
+}
+
+// send 在并发限制内向单个节点发送请求，并发名额在响应体关闭后释放
+func (p *Pool) send(req *http.Request, ep *Endpoint, suffix string, body []byte) (*http.Response, error) {
+	target, err := url.Parse(ep.url + suffix)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case ep.sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	release := func() { <-ep.sem }
+
+	out := req.Clone(req.Context())
+	out.URL = target
+	out.Host = target.Host
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.ContentLength = int64(len(body))
+		out.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+
+	ep.requests.Add(1)
+	ep.inflight.Add(1)
+	start := time.Now()
+	resp, err := p.transport.RoundTrip(out)
+	ep.inflight.Add(-1)
+
+	result := "ok"
+	if err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		result = "error"
+		ep.errors.Add(1)
+	}
+	metricRequests.Inc(p.chain.Key, ep.name, result)
+	metricDuration.Observe(time.Since(start).Milliseconds(), p.chain.Key, ep.name)
+
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseBody 关闭响应体时释放节点的并发名额
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// ordered 返回按优先级排序的节点：健康 > 连续失败少 > 落后块数少 > 响应快，相同时保持配置顺序
+func (p *Pool) ordered() []*Endpoint {
+	type rank struct {
+		ep       *Endpoint
+		healthy  bool
+		lag      uint64
+		failures int
+		latency  time.Duration
+	}
+	ranks := make([]rank, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		ep.mu.RLock()
+		ranks[i] = rank{ep: ep, healthy: ep.healthy, lag: ep.lag, failures: ep.failures, latency: ep.latency}
+		ep.mu.RUnlock()
+	}
+	sort.SliceStable(ranks, func(i, j int) bool {
+		a, b := ranks[i], ranks[j]
+		if a.healthy != b.healthy {
+			return a.healthy
+		}
+		if a.failures != b.failures {
+			return a.failures < b.failures
+		}
+		if a.lag != b.lag {
+			return a.lag < b.lag
+		}
+		return a.latency < b.latency
+	})
+
+	result := make([]*Endpoint, len(ranks))
+	for i, r := range ranks {
+		result[i] = r.ep
+	}
+	return result
+}
+
+func (ep *Endpoint) markSuccess() {
+	ep.mu.Lock()
+	ep.failures = 0
+	ep.mu.Unlock()
+}
+
+// markFailure 记录一次失败，连续失败达到阈值后摘除，等待下一次健康检查恢复
+func (ep *Endpoint) markFailure(err error, maxFailures int) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	ep.failures++
+	ep.lastError = err.Error()
+	if ep.failures >= maxFailures {
+		ep.healthy = false
+	}
+}
@@ -0,0 +1,52 @@
+package safe
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MultiSendCallOnlyV130 Safe v1.3.0 MultiSendCallOnly 合约的统一部署地址
+const MultiSendCallOnlyV130 = "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D"
+
+var multiSendABI = mustParseABI(`[
+	{"name":"multiSend","type":"function","stateMutability":"payable","inputs":[{"name":"transactions","type":"bytes"}],"outputs":[]}
+]`)
+
+// Call Safe 执行的一个调用
+type Call struct {
+	To    common.Address
+	Value *big.Int
+	Data  []byte
+}
+
+// BuildTx 单个调用直接作为 SafeTx（CALL）；多个调用打包为 MultiSendCallOnly.multiSend，
+// 由 Safe 以 DELEGATECALL 执行，在一笔交易中原子完成
+func BuildTx(calls []Call, multiSend common.Address, nonce *big.Int) (*Tx, error) {
+	switch len(calls) {
+	case 0:
+		return nil, errors.New("no calls to execute")
+	case 1:
+		return &Tx{To: calls[0].To, Value: orZero(calls[0].Value), Data: dataOrEmpty(calls[0].Data), Operation: OperationCall, Nonce: nonce}, nil
+	}
+	data, err := MultiSendData(calls)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{To: multiSend, Value: new(big.Int), Data: data, Operation: OperationDelegateCall, Nonce: nonce}, nil
+}
+
+// MultiSendData 每个调用按 operation(1) ‖ to(20) ‖ value(32) ‖ dataLength(32) ‖ data 紧密拼接，
+// MultiSendCallOnly 只接受 CALL
+func MultiSendData(calls []Call) ([]byte, error) {
+	var packed []byte
+	for _, c := range calls {
+		packed = append(packed, OperationCall)
+		packed = append(packed, c.To.Bytes()...)
+		packed = append(packed, word(c.Value)...)
+		packed = append(packed, word(big.NewInt(int64(len(c.Data))))...)
+		packed = append(packed, c.Data...)
+	}
+	return multiSendABI.Pack("multiSend", packed)
+}
@@ -0,0 +1,206 @@
+// Package safe builds and signs Safe (Gnosis Safe) multisig transactions: EIP-712 SafeTx
+// hashing, owner signatures, MultiSend batching and execTransaction encoding.
+package safe
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Safe 交易的执行方式
+const (
+	OperationCall         uint8 = 0
+	OperationDelegateCall uint8 = 1
+)
+
+var (
+	safeABI = mustParseABI(`[
+		{"name":"getOwners","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address[]"}]},
+		{"name":"getThreshold","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+		{"name":"nonce","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+		{"name":"VERSION","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+		{"name":"getTransactionHash","type":"function","stateMutability":"view","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"_nonce","type":"uint256"}],"outputs":[{"name":"","type":"bytes32"}]},
+		{"name":"execTransaction","type":"function","stateMutability":"payable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"outputs":[{"name":"success","type":"bool"}]}
+	]`)
+
+	// EIP-712 类型哈希，v1.3.0 起 domain 包含 chainId
+	domainTypeHash       = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	legacyDomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(address verifyingContract)"))
+	safeTxTypeHash       = crypto.Keccak256Hash([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+)
+
+func mustParseABI(raw string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Tx 一笔 Safe 交易（SafeTx）。由 owner 自己支付 gas 执行时 SafeTxGas / BaseGas / GasPrice 为 0，
+// GasToken / RefundReceiver 为零地址，不从 Safe 退款
+type Tx struct {
+	To             common.Address
+	Value          *big.Int
+	Data           []byte
+	Operation      uint8
+	SafeTxGas      *big.Int
+	BaseGas        *big.Int
+	GasPrice       *big.Int
+	GasToken       common.Address
+	RefundReceiver common.Address
+	Nonce          *big.Int
+}
+
+func orZero(n *big.Int) *big.Int {
+	if n == nil {
+		return new(big.Int)
+	}
+	return n
+}
+
+// Hash Safe.getTransactionHash：EIP-712 摘要 keccak256(0x1901 ‖ domainSeparator ‖ hashStruct(SafeTx))，
+// version 低于 1.3.0 的 Safe 的 domain 不含 chainId
+func (tx *Tx) Hash(safe common.Address, chainId *big.Int, version string) common.Hash {
+	var domain []byte
+	if legacyDomain(version) {
+		domain = crypto.Keccak256(legacyDomainTypeHash.Bytes(), common.LeftPadBytes(safe.Bytes(), 32))
+	} else {
+		domain = crypto.Keccak256(domainTypeHash.Bytes(), word(chainId), common.LeftPadBytes(safe.Bytes(), 32))
+	}
+	structHash := crypto.Keccak256(
+		safeTxTypeHash.Bytes(),
+		common.LeftPadBytes(tx.To.Bytes(), 32),
+		word(tx.Value),
+		crypto.Keccak256(tx.Data),
+		word(new(big.Int).SetUint64(uint64(tx.Operation))),
+		word(tx.SafeTxGas),
+		word(tx.BaseGas),
+		word(tx.GasPrice),
+		common.LeftPadBytes(tx.GasToken.Bytes(), 32),
+		common.LeftPadBytes(tx.RefundReceiver.Bytes(), 32),
+		word(tx.Nonce),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain, structHash)
+}
+
+func word(n *big.Int) []byte {
+	return common.LeftPadBytes(orZero(n).Bytes(), 32)
+}
+
+// legacyDomain 1.3.0 之前的版本（1.0.0 / 1.1.1 / 1.2.0）domain 只有 verifyingContract
+func legacyDomain(version string) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return major < 1 || (major == 1 && minor < 3)
+}
+
+// ExecTransactionData execTransaction 调用数据，signatures 为按 owner 地址升序拼接的签名
+func ExecTransactionData(tx *Tx, signatures []byte) ([]byte, error) {
+	return safeABI.Pack("execTransaction", tx.To, orZero(tx.Value), dataOrEmpty(tx.Data), tx.Operation,
+		orZero(tx.SafeTxGas), orZero(tx.BaseGas), orZero(tx.GasPrice), tx.GasToken, tx.RefundReceiver, signatures)
+}
+
+func dataOrEmpty(data []byte) []byte {
+	if data == nil {
+		return []byte{}
+	}
+	return data
+}
+
+// Info Safe 的链上状态
+type Info struct {
+	Version   string
+	Threshold uint64
+	Owners    []common.Address
+	Nonce     *big.Int
+}
+
+// IsOwner 地址是否为 Safe 的 owner
+func (i *Info) IsOwner(address common.Address) bool {
+	for _, o := range i.Owners {
+		if o == address {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadInfo 读取 Safe 的版本、owner、阈值和当前 nonce
+func ReadInfo(ctx context.Context, caller ethereum.ContractCaller, safe common.Address) (*Info, error) {
+	info := &Info{}
+	owners, err := call(ctx, caller, safe, "getOwners")
+	if err != nil {
+		return nil, err
+	}
+	info.Owners = owners[0].([]common.Address)
+	threshold, err := call(ctx, caller, safe, "getThreshold")
+	if err != nil {
+		return nil, err
+	}
+	info.Threshold = threshold[0].(*big.Int).Uint64()
+	nonce, err := call(ctx, caller, safe, "nonce")
+	if err != nil {
+		return nil, err
+	}
+	info.Nonce = nonce[0].(*big.Int)
+	version, err := call(ctx, caller, safe, "VERSION")
+	if err != nil {
+		return nil, err
+	}
+	info.Version = version[0].(string)
+	if len(info.Owners) == 0 || info.Threshold == 0 {
+		return nil, fmt.Errorf("%s is not a set up Safe", safe.Hex())
+	}
+	return info, nil
+}
+
+// ReadNonce 读取 Safe 当前的 nonce
+func ReadNonce(ctx context.Context, caller ethereum.ContractCaller, safe common.Address) (*big.Int, error) {
+	nonce, err := call(ctx, caller, safe, "nonce")
+	if err != nil {
+		return nil, err
+	}
+	return nonce[0].(*big.Int), nil
+}
+
+// OnChainHash 通过 Safe 合约的 getTransactionHash 计算摘要，用于核对本地计算结果
+func OnChainHash(ctx context.Context, caller ethereum.ContractCaller, safe common.Address, tx *Tx) (common.Hash, error) {
+	values, err := call(ctx, caller, safe, "getTransactionHash", tx.To, orZero(tx.Value), dataOrEmpty(tx.Data), tx.Operation,
+		orZero(tx.SafeTxGas), orZero(tx.BaseGas), orZero(tx.GasPrice), tx.GasToken, tx.RefundReceiver, orZero(tx.Nonce))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.Hash(values[0].([32]byte)), nil
+}
+
+func call(ctx context.Context, caller ethereum.ContractCaller, safe common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := safeABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := caller.CallContract(ctx, ethereum.CallMsg{To: &safe, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("safe %s failed: %w", method, err)
+	}
+	values, err := safeABI.Unpack(method, ret)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("%s returned no data for %s, is it a Safe?", safe.Hex(), method)
+	}
+	return values, nil
+}
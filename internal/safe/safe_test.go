@@ -0,0 +1,218 @@
+package safe
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+func TestTypeHashes(t *testing.T) {
+	// Safe 合约中的常量 DOMAIN_SEPARATOR_TYPEHASH / SAFE_TX_TYPEHASH
+	for _, tt := range []struct {
+		name string
+		got  common.Hash
+		want string
+	}{
+		{"domain", domainTypeHash, "0x47e79534a245952e8b16893a336b85a3d9ea9fa8c573f3d803afb92a79469218"},
+		{"legacy domain", legacyDomainTypeHash, "0x035aff83d86937d35b32e04f0ddc6ff469290eef2f1b692d8a815c89404d4749"},
+		{"SafeTx", safeTxTypeHash, "0xbb8310d486368db6bd6f849402fdd73ad53d316b5a4b2644ad6efe0f941286d8"},
+	} {
+		if tt.got.Hex() != tt.want {
+			t.Errorf("%s type hash: got %s, want %s", tt.name, tt.got.Hex(), tt.want)
+		}
+	}
+}
+
+// TestHash 与 go-ethereum 的 EIP-712 实现（eth_signTypedData_v4）独立计算的摘要比对
+func TestHash(t *testing.T) {
+	safeAddr := common.HexToAddress("0x5aFEaA3c2e36b1Ca83e5D3a1fF2b2e2d1e0D6cB1")
+	tx := &Tx{
+		To:             common.HexToAddress("0x55d398326f99059fF775485246999027B3197955"),
+		Value:          big.NewInt(1e15),
+		Data:           hexutil.MustDecode("0xa9059cbb00000000000000000000000066129b7045a6559c6d7274bb57534faaa58c8ced0000000000000000000000000000000000000000000000000de0b6b3a7640000"),
+		Operation:      OperationCall,
+		SafeTxGas:      big.NewInt(50000),
+		BaseGas:        big.NewInt(21000),
+		GasPrice:       big.NewInt(3e9),
+		GasToken:       common.HexToAddress("0x0000000000000000000000000000000000000001"),
+		RefundReceiver: common.HexToAddress("0x66129B7045a6559c6D7274bb57534fAAA58c8Ced"),
+		Nonce:          big.NewInt(7),
+	}
+	chainId := big.NewInt(56)
+
+	safeTxType := []apitypes.Type{
+		{Name: "to", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "data", Type: "bytes"},
+		{Name: "operation", Type: "uint8"},
+		{Name: "safeTxGas", Type: "uint256"},
+		{Name: "baseGas", Type: "uint256"},
+		{Name: "gasPrice", Type: "uint256"},
+		{Name: "gasToken", Type: "address"},
+		{Name: "refundReceiver", Type: "address"},
+		{Name: "nonce", Type: "uint256"},
+	}
+	message := apitypes.TypedDataMessage{
+		"to":             tx.To.Hex(),
+		"value":          tx.Value.String(),
+		"data":           hexutil.Encode(tx.Data),
+		"operation":      "0",
+		"safeTxGas":      tx.SafeTxGas.String(),
+		"baseGas":        tx.BaseGas.String(),
+		"gasPrice":       tx.GasPrice.String(),
+		"gasToken":       tx.GasToken.Hex(),
+		"refundReceiver": tx.RefundReceiver.Hex(),
+		"nonce":          tx.Nonce.String(),
+	}
+	expected := func(domainFields []apitypes.Type, domain apitypes.TypedDataDomain) common.Hash {
+		t.Helper()
+		hash, _, err := apitypes.TypedDataAndHash(apitypes.TypedData{
+			Types:       apitypes.Types{"EIP712Domain": domainFields, "SafeTx": safeTxType},
+			PrimaryType: "SafeTx",
+			Domain:      domain,
+			Message:     message,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return common.BytesToHash(hash)
+	}
+
+	withChainId := expected(
+		[]apitypes.Type{{Name: "chainId", Type: "uint256"}, {Name: "verifyingContract", Type: "address"}},
+		apitypes.TypedDataDomain{ChainId: (*math.HexOrDecimal256)(chainId), VerifyingContract: safeAddr.Hex()},
+	)
+	legacy := expected(
+		[]apitypes.Type{{Name: "verifyingContract", Type: "address"}},
+		apitypes.TypedDataDomain{VerifyingContract: safeAddr.Hex()},
+	)
+	for _, tt := range []struct {
+		version string
+		want    common.Hash
+	}{
+		{"1.4.1", withChainId},
+		{"1.3.0", withChainId},
+		{"1.3.0+L2", withChainId},
+		{"1.2.0", legacy},
+		{"1.1.1", legacy},
+		{"1.0.0", legacy},
+	} {
+		if got := tx.Hash(safeAddr, chainId, tt.version); got != tt.want {
+			t.Errorf("version %s: got %s, want %s", tt.version, got.Hex(), tt.want.Hex())
+		}
+	}
+}
+
+func TestPackSignatures(t *testing.T) {
+	safeTxHash := crypto.Keccak256Hash([]byte("safe tx"))
+	var sigs []Signature
+	for _, k := range []string{
+		"4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318",
+		"ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80",
+		"59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d",
+	} {
+		key, err := crypto.HexToECDSA(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := Sign(safeTxHash, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sig[64] != 27 && sig[64] != 28 {
+			t.Fatalf("v = %d, want 27 or 28", sig[64])
+		}
+		owner := crypto.PubkeyToAddress(key.PublicKey)
+		if signer, err := Recover(safeTxHash, sig); err != nil || signer != owner {
+			t.Fatalf("Recover: got %s, %v, want %s", signer.Hex(), err, owner.Hex())
+		}
+		sigs = append(sigs, Signature{Owner: owner, Signature: sig})
+	}
+
+	packed := PackSignatures(sigs)
+	if len(packed) != 65*len(sigs) {
+		t.Fatalf("packed %d bytes, want %d", len(packed), 65*len(sigs))
+	}
+	// Safe.checkSignatures 要求 owner 严格递增
+	var prev common.Address
+	for i := 0; i < len(sigs); i++ {
+		signer, err := Recover(safeTxHash, packed[i*65:(i+1)*65])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && bytes.Compare(prev.Bytes(), signer.Bytes()) >= 0 {
+			t.Fatalf("signature %d from %s is not after %s", i, signer.Hex(), prev.Hex())
+		}
+		prev = signer
+	}
+	// 输入顺序不影响结果，也不修改输入
+	reversed := []Signature{sigs[2], sigs[1], sigs[0]}
+	if !bytes.Equal(PackSignatures(reversed), packed) {
+		t.Fatal("packing depends on the input order")
+	}
+	if reversed[0].Owner != sigs[2].Owner {
+		t.Fatal("PackSignatures reordered its input")
+	}
+}
+
+func TestRecoverRejectsMalformedSignatures(t *testing.T) {
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := crypto.Keccak256Hash([]byte("safe tx"))
+	sig, err := Sign(hash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := append([]byte{}, sig...)
+	raw[64] -= 27 // v 为 0 / 1 的原始签名在 Safe 中表示合约签名等其它类型
+	for name, s := range map[string][]byte{"raw v": raw, "short": sig[:64], "empty": nil} {
+		if _, err := Recover(hash, s); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestMultiSendData(t *testing.T) {
+	a := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	b := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	data, err := MultiSendData([]Call{
+		{To: a, Value: big.NewInt(1), Data: []byte{0xde, 0xad}},
+		{To: b},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// operation(1) ‖ to(20) ‖ value(32) ‖ dataLength(32) ‖ data
+	packed := "00" + "1111111111111111111111111111111111111111" +
+		"0000000000000000000000000000000000000000000000000000000000000001" +
+		"0000000000000000000000000000000000000000000000000000000000000002" + "dead" +
+		"00" + "2222222222222222222222222222222222222222" +
+		"0000000000000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000"
+	want := "0x8d80ff0a" + // multiSend(bytes)
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"00000000000000000000000000000000000000000000000000000000000000ac" + // 87 + 85 = 172 字节
+		packed + "0000000000000000000000000000000000000000" // 补齐到 32 字节的倍数
+	if got := hexutil.Encode(data); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+
+	tx, err := BuildTx([]Call{{To: a}, {To: b}}, common.HexToAddress(MultiSendCallOnlyV130), big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Operation != OperationDelegateCall || tx.To != common.HexToAddress(MultiSendCallOnlyV130) {
+		t.Fatalf("batched tx: operation %d to %s", tx.Operation, tx.To.Hex())
+	}
+	if tx, _ := BuildTx([]Call{{To: a}}, common.HexToAddress(MultiSendCallOnlyV130), big.NewInt(3)); tx.Operation != OperationCall || tx.To != a {
+		t.Fatalf("single call: operation %d to %s", tx.Operation, tx.To.Hex())
+	}
+}
@@ -0,0 +1,54 @@
+package safe
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signature 一个 owner 对 safeTxHash 的签名
+type Signature struct {
+	Owner     common.Address
+	Signature []byte
+}
+
+// Sign owner 直接对 safeTxHash 做 ECDSA 签名（EIP-712 摘要，不加前缀），v 为 27 / 28
+func Sign(safeTxHash common.Hash, key *ecdsa.PrivateKey) ([]byte, error) {
+	sig, err := crypto.Sign(safeTxHash.Bytes(), key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// Recover 从签名恢复 owner 地址，用于校验存储的签名
+func Recover(safeTxHash common.Hash, sig []byte) (common.Address, error) {
+	if len(sig) != 65 || (sig[64] != 27 && sig[64] != 28) {
+		return common.Address{}, fmt.Errorf("invalid owner signature")
+	}
+	raw := append([]byte{}, sig...)
+	raw[64] -= 27
+	pub, err := crypto.SigToPub(safeTxHash.Bytes(), raw)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// PackSignatures 按 owner 地址升序拼接签名，Safe.checkSignatures 要求严格递增
+func PackSignatures(sigs []Signature) []byte {
+	sorted := append([]Signature{}, sigs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Owner.Bytes(), sorted[j].Owner.Bytes()) < 0
+	})
+	var out []byte
+	for _, s := range sorted {
+		out = append(out, s.Signature...)
+	}
+	return out
+}
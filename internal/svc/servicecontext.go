@@ -2,83 +2,263 @@ package svc
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"demo/internal/abiregistry"
+	"demo/internal/chains"
 	"demo/internal/config"
+	"demo/internal/erc4337"
+	"demo/internal/gasoracle"
 	"demo/internal/logic/monitor"
+	"demo/internal/mid"
 	"demo/internal/model"
+	"demo/internal/model/migrations"
+	"demo/internal/multicall"
+	"demo/internal/policy"
+	"demo/internal/rpcpool"
+	"demo/internal/tokens"
+
+	"github.com/zeromicro/go-zero/rest"
 
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// watchListReloadInterval 链上监控重新加载钱包地址的间隔
+const watchListReloadInterval = 30 * time.Second
+
 type ServiceContext struct {
-	Config        config.Config
-	WalletsDao    model.WalletsDao
-	DB            *gorm.DB
-	MonitorCancel context.CancelFunc // 用于停止监控
+	Config          config.Config
+	Chains          *chains.Registry      // 链注册表，所有链相关的查询都经由它
+	RPC             *rpcpool.Manager      // 各链 RPC 节点池（故障切换、健康检查、连接复用）
+	Gas             *gasoracle.Manager    // 各 EVM 链手续费预言机（缓存估算、手续费上限）
+	Multicall       *multicall.Manager    // 各 EVM 链的批量合约读取（Multicall3）
+	Policy          *policy.Engine        // 签名前的策略校验与审计日志
+	Abis            *abiregistry.Registry // 按名称登记的合约 ABI（/transaction/contract_call、/contract/read）
+	Bundlers        *erc4337.Manager      // 各 EVM 链的 ERC-4337 bundler 与 paymaster
+	Tokens          *tokens.Registry      // 代币元数据（symbol / name / decimals）与符号解析
+	WalletsDao      model.WalletsDao
+	TransactionsDao model.TransactionsDao
+	AuditEntriesDao model.AuditEntriesDao
+	EventOutboxDao  model.EventOutboxDao
+	// 收款请求，由 /transaction/receive 创建、监控事件匹配
+	PaymentRequestsDao  model.PaymentRequestsDao
+	SmartAccountsDao    model.SmartAccountsDao
+	SafesDao            model.SafesDao
+	SafeTransactionsDao model.SafeTransactionsDao
+	SweepRunsDao        model.SweepRunsDao
+	SweepItemsDao       model.SweepItemsDao
+	GasTopUpsDao        model.GasTopUpsDao
+	SchedulesDao        model.ScheduledTransfersDao
+	ScheduleRunsDao     model.ScheduledTransferRunsDao
+	DB                  *gorm.DB
+	MonitorCancel       context.CancelFunc // 用于停止监控
+	Idempotency         rest.Middleware    // 有副作用接口的幂等保护
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
-	// 重新从配置文件读取 DSN
-	db, err := initDB(c.Postgres.DSN)
+	registry, err := chains.NewRegistry(c.Chains)
+	if err != nil {
+		log.Fatalf("invalid chain config: %v", err)
+	}
+	rpcManager, err := rpcpool.NewManager(registry, c.RpcPool)
+	if err != nil {
+		log.Fatalf("invalid rpc config: %v", err)
+	}
+	gasManager, err := gasoracle.NewManager(registry, rpcManager, c.GasOracle)
+	if err != nil {
+		log.Fatalf("invalid gas oracle config: %v", err)
+	}
+	multicallManager, err := multicall.NewManager(registry, rpcManager, c.Multicall)
+	if err != nil {
+		log.Fatalf("invalid multicall config: %v", err)
+	}
+	abis, err := abiregistry.NewRegistry(c.Abis)
+	if err != nil {
+		log.Fatalf("invalid abi config: %v", err)
+	}
+	bundlers, err := erc4337.NewManager(registry)
+	if err != nil {
+		log.Fatalf("invalid smart account config: %v", err)
+	}
+
+	db, err := InitDB(c.Database.Driver, c.DatabaseDSN())
 	if err != nil {
 		log.Fatalf("failed to init db: %v", err)
 	}
+	if c.Database.AutoMigrate {
+		applied, err := migrations.Up(db, c.Database.Driver)
+		if err != nil {
+			log.Fatalf("failed to migrate db: %v", err)
+		}
+		for _, m := range applied {
+			log.Printf("✅ 已执行迁移: %s", m.Name)
+		}
+	}
+
+	auditDao := model.NewAuditEntriesDao(db)
+	policyEngine, err := policy.NewEngine(c.Policy, auditDao)
+	if err != nil {
+		log.Fatalf("invalid policy config: %v", err)
+	}
+	tokenRegistry, err := tokens.NewRegistry(registry, rpcManager, multicallManager, model.NewTokensDao(db))
+	if err != nil {
+		log.Fatalf("invalid token config: %v", err)
+	}
 
 	svcCtx := &ServiceContext{
-		Config:     c,
-		WalletsDao: model.NewWalletsDao(db),
-		DB:         db,
+		Config:              c,
+		Chains:              registry,
+		RPC:                 rpcManager,
+		Gas:                 gasManager,
+		Multicall:           multicallManager,
+		Policy:              policyEngine,
+		Abis:                abis,
+		Bundlers:            bundlers,
+		Tokens:              tokenRegistry,
+		WalletsDao:          model.NewWalletsDao(db),
+		TransactionsDao:     model.NewTransactionsDao(db),
+		AuditEntriesDao:     auditDao,
+		EventOutboxDao:      model.NewEventOutboxDao(db),
+		PaymentRequestsDao:  model.NewPaymentRequestsDao(db),
+		SmartAccountsDao:    model.NewSmartAccountsDao(db),
+		SafesDao:            model.NewSafesDao(db),
+		SafeTransactionsDao: model.NewSafeTransactionsDao(db),
+		SweepRunsDao:        model.NewSweepRunsDao(db),
+		SweepItemsDao:       model.NewSweepItemsDao(db),
+		GasTopUpsDao:        model.NewGasTopUpsDao(db),
+		SchedulesDao:        model.NewScheduledTransfersDao(db),
+		ScheduleRunsDao:     model.NewScheduledTransferRunsDao(db),
+		DB:                  db,
+		Idempotency: mid.NewIdempotencyMiddleware(
+			model.NewIdempotencyDao(db),
+			time.Duration(c.Idempotency.Ttl)*time.Second,
+			time.Duration(c.Idempotency.WaitTimeout)*time.Second,
+			time.Duration(c.Idempotency.Lease)*time.Second,
+		).Handle,
 	}
 
-	// 启动BSC监控
-	svcCtx.startBSCMonitor()
+	// 创建后台任务上下文，链上监控、发件箱投递、节点健康检查和手续费采样共用
+	ctx, cancel := context.WithCancel(context.Background())
+	svcCtx.MonitorCancel = cancel
+
+	// 启动 RPC 节点健康检查
+	svcCtx.RPC.Start(ctx)
+
+	// 启动手续费采样
+	svcCtx.Gas.Start(ctx)
+
+	// 启动发件箱投递（即使没有监控地址，也要把历史未投递的事件发出去）
+	svcCtx.startOutboxRelay(ctx)
+
+	// 启动链上监控（配置了 WsUrl 的 EVM 链）
+	svcCtx.startEVMMonitors(ctx)
 
 	return svcCtx
 }
 
-// startBSCMonitor 启动BSC监控
-func (svc *ServiceContext) startBSCMonitor() {
-	// BSC WebSocket URL
-	//wsURL := "wss://bsc-rpc.publicnode.com"
-	wsURL := "wss://bsc-testnet-rpc.publicnode.com"
+// startOutboxRelay 启动 TokenEvent 发件箱投递
+func (svc *ServiceContext) startOutboxRelay(ctx context.Context) {
+	sink, err := monitor.NewEventSink(svc.Config.Outbox, svc.Chains, svc.Tokens)
+	if err != nil {
+		log.Fatalf("failed to init outbox sink: %v", err)
+	}
+	// 收款匹配先于外部投递，保证下游收到事件时收款状态已更新
+	sink = monitor.MultiSink{monitor.NewPaymentMatcher(svc.PaymentRequestsDao), sink}
+	relay := monitor.NewOutboxRelay(svc.EventOutboxDao, sink, svc.Config.Outbox)
 
-	// 从数据库获取所有钱包地址
-	watchAddresses := svc.getWalletAddressesFromDB()
+	go func() {
+		if err := relay.Run(ctx); err != nil && err != context.Canceled {
+			log.Printf("❌ 发件箱投递服务异常: %v", err)
+		}
+	}()
+}
 
-	if len(watchAddresses) == 0 {
-		log.Println("⚠️  数据库中没有找到钱包地址，跳过BSC监控启动")
+// startEVMMonitors 为每条配置了 WsUrl 的 EVM 链启动区块监控
+func (svc *ServiceContext) startEVMMonitors(ctx context.Context) {
+	var monitored []*chains.Chain
+	for _, c := range svc.Chains.ByFamily(chains.FamilyEVM) {
+		if c.WsUrl != "" {
+			monitored = append(monitored, c)
+		}
+	}
+	if len(monitored) == 0 {
+		log.Println("⚠️  没有配置 WsUrl 的 EVM 链，跳过链上监控启动")
 		return
 	}
 
-	log.Printf("📍 将监控 %d 个钱包地址", len(watchAddresses))
-	for i, addr := range watchAddresses {
+	// 从数据库获取所有钱包地址，之后定期重新加载，新建的钱包无需重启即可被监控
+	addresses := svc.getWalletAddressesFromDB()
+	watchAddresses := monitor.NewWatchList()
+	n := watchAddresses.Set(addresses)
+	if n == 0 {
+		log.Println("⚠️  数据库中没有找到 EVM 钱包地址，创建钱包后自动开始监控")
+	}
+
+	log.Printf("📍 将监控 %d 个钱包地址", n)
+	for i, addr := range addresses {
 		if i < 5 { // 只显示前5个地址
 			log.Printf("   - %s", addr)
 		} else if i == 5 {
-			log.Printf("   - ... 还有 %d 个地址", len(watchAddresses)-5)
+			log.Printf("   - ... 还有 %d 个地址", len(addresses)-5)
 			break
 		}
 	}
+	go svc.reloadWatchList(ctx, watchAddresses, n)
 
-	// 创建监控上下文
-	ctx, cancel := context.WithCancel(context.Background())
-	svc.MonitorCancel = cancel
-
-	// 在后台启动监控
-	go func() {
-		log.Println("🚀 启动BSC链监控服务...")
-		if err := monitor.StartBSCMonitoring(ctx, wsURL, watchAddresses); err != nil {
-			if err != context.Canceled {
-				log.Printf("❌ BSC监控服务异常: %v", err)
-			} else {
-				log.Println("✅ BSC监控服务已停止")
+	// 在后台启动监控，事件与区块游标一起写入发件箱
+	store := monitor.NewOutboxStore(svc.EventOutboxDao)
+	for _, c := range monitored {
+		go func(c *chains.Chain) {
+			log.Printf("🚀 启动 %s 链监控服务...", c.Key)
+			if err := monitor.StartBSCMonitoring(ctx, c, watchAddresses, store, svc.Tokens); err != nil {
+				if err != context.Canceled {
+					log.Printf("❌ %s 监控服务异常: %v", c.Key, err)
+				} else {
+					log.Printf("✅ %s 监控服务已停止", c.Key)
+				}
 			}
+		}(c)
+	}
+}
+
+// reloadWatchList 每隔 watchListReloadInterval 从数据库重新加载监控地址，ctx 取消时停止
+func (svc *ServiceContext) reloadWatchList(ctx context.Context, watch *monitor.WatchList, last int) {
+	ticker := time.NewTicker(watchListReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-	}()
+		wallets, err := svc.WalletsDao.FindAll(ctx)
+		if err != nil {
+			log.Printf("⚠️  重新加载监控地址失败: %v", err)
+			continue
+		}
+		addresses := make([]string, 0, len(wallets))
+		for _, w := range wallets {
+			addresses = append(addresses, w.Address)
+		}
+		if n := watch.Set(addresses); n != last {
+			log.Printf("📍 监控地址已更新: %d -> %d 个", last, n)
+			last = n
+		}
+	}
 }
 
 // getWalletAddressesFromDB 从数据库获取钱包地址
@@ -100,15 +280,16 @@ func (svc *ServiceContext) getWalletAddressesFromDB() []string {
 	return addresses
 }
 
-// StopMonitor 停止监控服务
+// StopMonitor 停止监控服务及发件箱投递
 func (svc *ServiceContext) StopMonitor() {
 	if svc.MonitorCancel != nil {
-		log.Println("🛑 正在停止BSC监控服务...")
+		log.Println("🛑 正在停止链上监控服务...")
 		svc.MonitorCancel()
 	}
 }
 
-func initDB(dsn string) (*gorm.DB, error) {
+// InitDB 按驱动打开数据库连接，服务启动和 migrate 命令共用
+func InitDB(driver, dsn string) (*gorm.DB, error) {
 	newLogger := logger.New(
 		log.New(log.Writer(), "\r\n", log.LstdFlags),
 		logger.Config{
@@ -119,8 +300,23 @@ func initDB(dsn string) (*gorm.DB, error) {
 		},
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: newLogger,
+	var dialector gorm.Dialector
+	switch driver {
+	case DriverPostgres, "":
+		dialector = postgres.Open(dsn)
+	case DriverSQLite:
+		sqliteDSN, err := prepareSQLiteDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		dialector = sqlite.Open(sqliteDSN)
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driver)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         newLogger,
+		TranslateError: true, // 将唯一约束冲突统一转换为 gorm.ErrDuplicatedKey
 	})
 	if err != nil {
 		return nil, err
@@ -131,8 +327,32 @@ func initDB(dsn string) (*gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
+	if driver == DriverSQLite {
+		// SQLite 同一时刻只允许一个写连接，串行化避免 "database is locked"
+		sqlDB.SetMaxOpenConns(1)
+	} else {
+		sqlDB.SetMaxIdleConns(10)
+		sqlDB.SetMaxOpenConns(100)
+	}
 
 	return db, nil
 }
+
+// prepareSQLiteDSN 创建数据库文件所在目录，并补充默认连接参数
+func prepareSQLiteDSN(dsn string) (string, error) {
+	if dsn == "" {
+		return "", errors.New("sqlite DSN (database file path) is empty")
+	}
+	path, _, _ := strings.Cut(strings.TrimPrefix(dsn, "file:"), "?")
+	if path != ":memory:" {
+		if dir := filepath.Dir(path); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return "", fmt.Errorf("create sqlite dir: %w", err)
+			}
+		}
+	}
+	if !strings.Contains(dsn, "?") {
+		dsn += "?_busy_timeout=5000&_journal_mode=WAL&_foreign_keys=on"
+	}
+	return dsn, nil
+}
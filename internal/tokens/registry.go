@@ -0,0 +1,303 @@
+// Package tokens resolves token metadata (symbol, name, decimals) per chain. Lookups go
+// through an in-memory cache and the tokens table before reading ERC20 contracts or SPL
+// mints (with Metaplex metadata) on chain; symbols resolve only to configured tokens and
+// tokens seeded from the LI.FI token list, never to tokens merely seen on chain.
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"demo/internal/chains"
+	"demo/internal/model"
+	"demo/internal/multicall"
+	"demo/internal/rpcpool"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mr-tron/base58"
+)
+
+// 原生币在请求中使用的地址
+const (
+	NativeEVM     = "0x0000000000000000000000000000000000000000"
+	LifiNativeEVM = "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE" // LI.FI 的 EVM 原生币标识
+	NativeSolana  = "11111111111111111111111111111111"           // LI.FI 的 SOL 标识（System Program）
+)
+
+// SourceConfig 链配置 Tokens 中登记的代币，优先于其他来源
+const SourceConfig = "config"
+
+var (
+	// ErrNotFound 代币符号在该链上没有登记
+	ErrNotFound = errors.New("token not found")
+	// ErrAmbiguous 同一符号对应多个代币地址，需要直接使用地址
+	ErrAmbiguous = errors.New("token symbol is ambiguous")
+	// ErrInvalidAddress 代币地址 / mint 格式不正确
+	ErrInvalidAddress = errors.New("invalid token address")
+)
+
+// Token 一条链上代币的元数据
+type Token struct {
+	Chain    string `json:"chain"`
+	Address  string `json:"address"` // EVM 为 checksum 地址，Solana 为 mint，原生币见 Native* 常量
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+	Decimals int    `json:"decimals"`
+	Native   bool   `json:"native"`
+	Source   string `json:"source"` // config / lifi / chain / native
+}
+
+// Registry 代币元数据注册表：内存缓存 → tokens 表 → 链上读取
+type Registry struct {
+	chains    *chains.Registry
+	rpc       *rpcpool.Manager
+	multicall *multicall.Manager
+	dao       model.TokensDao
+
+	// configured 链配置中登记的代币，按链和 symbol 建索引
+	configured map[string]map[string]*Token
+
+	mu    sync.RWMutex
+	cache map[string]*Token
+}
+
+// NewRegistry 创建注册表，链配置的 Tokens 直接放入缓存
+func NewRegistry(registry *chains.Registry, rpc *rpcpool.Manager, mc *multicall.Manager, dao model.TokensDao) (*Registry, error) {
+	r := &Registry{
+		chains:     registry,
+		rpc:        rpc,
+		multicall:  mc,
+		dao:        dao,
+		configured: make(map[string]map[string]*Token),
+		cache:      make(map[string]*Token),
+	}
+	for _, c := range registry.All() {
+		bySymbol := make(map[string]*Token)
+		for _, conf := range c.Tokens {
+			address, err := normalizeAddress(c, conf.Address)
+			if err != nil {
+				return nil, fmt.Errorf("chain %s: token %s: %w", c.Key, conf.Symbol, err)
+			}
+			t := &Token{Chain: c.Key, Address: address, Symbol: conf.Symbol, Decimals: conf.Decimals, Source: SourceConfig}
+			r.cache[cacheKey(c, address)] = t
+			bySymbol[strings.ToUpper(conf.Symbol)] = t
+		}
+		r.configured[c.Key] = bySymbol
+	}
+	return r, nil
+}
+
+// Lookup 按地址查询代币元数据，缓存和数据库都没有时从链上读取并写入数据库
+func (r *Registry) Lookup(ctx context.Context, chain *chains.Chain, address string) (*Token, error) {
+	if IsNative(chain, address) {
+		return native(chain), nil
+	}
+	address, err := normalizeAddress(chain, address)
+	if err != nil {
+		return nil, err
+	}
+	key := cacheKey(chain, address)
+
+	r.mu.RLock()
+	t, ok := r.cache[key]
+	r.mu.RUnlock()
+	if ok {
+		return t, nil
+	}
+
+	row, err := r.dao.FindOneByChainAndAddress(ctx, chain.Key, address)
+	switch {
+	case err == nil:
+		t = fromRow(row)
+	case errors.Is(err, model.ErrNotFound):
+		if t, err = r.fetch(ctx, chain, address); err != nil {
+			return nil, err
+		}
+		row := &model.Tokens{Chain: chain.Key, Address: address, Symbol: t.Symbol, Name: t.Name, Decimals: t.Decimals, Source: model.TokenSourceChain}
+		if err := r.dao.Insert(ctx, row); err != nil {
+			return nil, fmt.Errorf("save token metadata: %w", err)
+		}
+	default:
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cache[key] = t
+	r.mu.Unlock()
+	return t, nil
+}
+
+// Resolve 将原生币符号、代币符号或地址解析为代币；符号只在链配置和 LI.FI 代币列表中查找
+func (r *Registry) Resolve(ctx context.Context, chain *chains.Chain, token string) (*Token, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, errors.New("token is required")
+	}
+	if IsNative(chain, token) || strings.EqualFold(token, chain.NativeSymbol) {
+		return native(chain), nil
+	}
+	if looksLikeAddress(chain, token) {
+		return r.Lookup(ctx, chain, token)
+	}
+
+	if t, ok := r.configured[chain.Key][strings.ToUpper(token)]; ok {
+		return t, nil
+	}
+	rows, err := r.dao.FindByChainAndSymbol(ctx, chain.Key, token, model.TokenSourceLifi)
+	if err != nil {
+		return nil, err
+	}
+	switch len(rows) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s on %s", ErrNotFound, token, chain.Key)
+	case 1:
+		return fromRow(rows[0]), nil
+	}
+	addresses := make([]string, len(rows))
+	for i, row := range rows {
+		addresses[i] = row.Address
+	}
+	sort.Strings(addresses)
+	return nil, fmt.Errorf("%w: %s on %s matches %s", ErrAmbiguous, token, chain.Key, strings.Join(addresses, ", "))
+}
+
+// Seed 批量写入 LI.FI 代币列表，已缓存的同地址代币失效以便下次读取新数据
+func (r *Registry) Seed(ctx context.Context, chain *chains.Chain, list []Token) (int, error) {
+	rows := make([]*model.Tokens, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, t := range list {
+		if IsNative(chain, t.Address) {
+			continue
+		}
+		address, err := normalizeAddress(chain, t.Address)
+		if err != nil || seen[address] {
+			continue
+		}
+		seen[address] = true
+		rows = append(rows, &model.Tokens{
+			Chain:    chain.Key,
+			Address:  address,
+			Symbol:   t.Symbol,
+			Name:     t.Name,
+			Decimals: t.Decimals,
+			Source:   model.TokenSourceLifi,
+		})
+	}
+	if err := r.dao.Upsert(ctx, rows); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	for _, row := range rows {
+		key := cacheKey(chain, row.Address)
+		if t, ok := r.cache[key]; ok && t.Source != SourceConfig {
+			delete(r.cache, key)
+		}
+	}
+	r.mu.Unlock()
+	return len(rows), nil
+}
+
+// fetch 从链上读取代币元数据
+func (r *Registry) fetch(ctx context.Context, chain *chains.Chain, address string) (*Token, error) {
+	switch {
+	case chain.IsEVM():
+		caller, err := r.multicall.Caller(chain)
+		if err != nil {
+			return nil, err
+		}
+		metas, err := caller.TokenMetadata(ctx, []common.Address{common.HexToAddress(address)})
+		if err != nil {
+			return nil, fmt.Errorf("read token metadata: %w", err)
+		}
+		if !metas[0].Ok {
+			return nil, fmt.Errorf("%s is not an ERC20 token on %s", address, chain.Key)
+		}
+		return &Token{Chain: chain.Key, Address: address, Symbol: metas[0].Symbol, Name: metas[0].Name, Decimals: metas[0].Decimals, Source: model.TokenSourceChain}, nil
+	case chain.IsSolana():
+		return r.fetchSolana(ctx, chain, address)
+	}
+	return nil, fmt.Errorf("tokens are not supported on %s", chain.Key)
+}
+
+// IsNative 判断地址是否为该链原生币的标识（空地址、零地址、LI.FI 原生币地址）
+func IsNative(chain *chains.Chain, address string) bool {
+	switch {
+	case address == "":
+		return true
+	case chain.IsEVM():
+		return strings.EqualFold(address, NativeEVM) || strings.EqualFold(address, LifiNativeEVM)
+	case chain.IsSolana():
+		return address == NativeSolana
+	}
+	return false
+}
+
+// native 返回链原生币，符号和精度来自链注册表
+func native(chain *chains.Chain) *Token {
+	address := ""
+	switch {
+	case chain.IsEVM():
+		address = NativeEVM
+	case chain.IsSolana():
+		address = NativeSolana
+	}
+	return &Token{
+		Chain:    chain.Key,
+		Address:  address,
+		Symbol:   chain.NativeSymbol,
+		Name:     chain.DisplayName(),
+		Decimals: chain.NativeDecimals,
+		Native:   true,
+		Source:   "native",
+	}
+}
+
+// looksLikeAddress 判断输入是地址而不是代币符号
+func looksLikeAddress(chain *chains.Chain, s string) bool {
+	switch {
+	case chain.IsEVM():
+		return strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X")
+	case chain.IsSolana():
+		raw, err := base58.Decode(s)
+		return err == nil && len(raw) == 32
+	}
+	return false
+}
+
+// normalizeAddress EVM 地址统一为 checksum 格式，Solana mint 校验 base58 长度
+func normalizeAddress(chain *chains.Chain, address string) (string, error) {
+	switch {
+	case chain.IsEVM():
+		if !common.IsHexAddress(address) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+		}
+		return common.HexToAddress(address).Hex(), nil
+	case chain.IsSolana():
+		raw, err := base58.Decode(address)
+		if err != nil || len(raw) != 32 {
+			return "", fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+		}
+		return address, nil
+	}
+	return "", fmt.Errorf("tokens are not supported on %s", chain.Key)
+}
+
+func cacheKey(chain *chains.Chain, address string) string {
+	return chain.Key + "|" + address
+}
+
+func fromRow(row *model.Tokens) *Token {
+	return &Token{
+		Chain:    row.Chain,
+		Address:  row.Address,
+		Symbol:   row.Symbol,
+		Name:     row.Name,
+		Decimals: row.Decimals,
+		Source:   row.Source,
+	}
+}
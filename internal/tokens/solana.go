@@ -0,0 +1,52 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"demo/internal/chains"
+	"demo/internal/model"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/program/token"
+)
+
+// mintDecimalsOffset Mint 账户中 decimals 的偏移：mint_authority(COption<Pubkey>, 36) + supply(u64, 8)
+const mintDecimalsOffset = 44
+
+// fetchSolana 读取 SPL / Token-2022 mint 的精度，symbol 和 name 来自 Metaplex 元数据账户（没有时为空）
+func (r *Registry) fetchSolana(ctx context.Context, chain *chains.Chain, mint string) (*Token, error) {
+	client, err := r.rpc.SolanaClient(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := client.GetAccountInfo(ctx, mint)
+	if err != nil {
+		return nil, fmt.Errorf("read mint account: %w", err)
+	}
+	if (account.Owner != common.TokenProgramID && account.Owner != common.Token2022ProgramID) || len(account.Data) < token.MintAccountSize {
+		return nil, fmt.Errorf("%s is not an SPL token mint on %s", mint, chain.Key)
+	}
+	t := &Token{Chain: chain.Key, Address: mint, Decimals: int(account.Data[mintDecimalsOffset]), Source: model.TokenSourceChain}
+
+	metaAddress, err := token_metadata.GetTokenMetaPubkey(common.PublicKeyFromString(mint))
+	if err != nil {
+		return t, nil
+	}
+	meta, err := client.GetAccountInfo(ctx, metaAddress.ToBase58())
+	if err != nil {
+		return nil, fmt.Errorf("read token metadata account: %w", err)
+	}
+	if len(meta.Data) == 0 || meta.Owner != common.MetaplexTokenMetaProgramID {
+		return t, nil
+	}
+	if metadata, err := token_metadata.MetadataDeserialize(meta.Data); err == nil {
+		// Metaplex 的 name / symbol 以 \x00 补齐到固定长度
+		t.Name = strings.TrimSpace(strings.TrimRight(metadata.Data.Name, "\x00"))
+		t.Symbol = strings.TrimSpace(strings.TrimRight(metadata.Data.Symbol, "\x00"))
+	}
+	return t, nil
+}
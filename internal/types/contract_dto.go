@@ -0,0 +1,64 @@
+package types
+
+// ContractCallReq 按 ABI 编码并发送任意合约调用
+type ContractCallReq struct {
+	Chain       string `json:"chain"`
+	FromAddress string `json:"from_address"`
+	Contract    string `json:"contract"`
+	// ABI 二选一：abi 为 JSON 字符串（数组、单个片段或编译产物），abi_name 引用已登记的 ABI（内置 erc20 / erc721 / erc1155）
+	Abi     string `json:"abi,optional"`
+	AbiName string `json:"abi_name,optional"`
+	// 方法名；重载方法使用完整签名（如 safeTransferFrom(address,address,uint256)）或 0x 选择器
+	Method string `json:"method"`
+	// 参数按名称传入，未命名参数用位置 "0"、"1"…；整数可用数字或字符串，bytes 为 hex，tuple 为对象或数组
+	Args map[string]interface{} `json:"args,optional"`
+	// 附带的原生币（wei），仅 payable 方法可用
+	Value string `json:"value,optional"`
+	// 手续费档位，见 TransactionReq
+	FeeLevel             string `json:"fee_level,optional"`
+	MaxFeePerGas         string `json:"max_fee_per_gas,optional"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,optional"`
+}
+
+// ContractCallResp 合约调用响应
+type ContractCallResp struct {
+	TxHash   string `json:"tx_hash"`
+	Chain    string `json:"chain"`
+	Contract string `json:"contract"`
+	Method   string `json:"method"` // 完整签名
+	Data     string `json:"data"`   // 调用数据（hex）
+	GasLimit uint64 `json:"gas_limit"`
+	// 模拟执行（eth_call）解码出的返回值
+	SimulatedOutputs []ContractValue `json:"simulated_outputs,omitempty"`
+	ExplorerUrl      string          `json:"explorer_url"`
+	Status           string          `json:"status"`
+	Message          string          `json:"message"`
+}
+
+// ContractReadReq 只读合约调用（eth_call）
+type ContractReadReq struct {
+	Chain    string                 `json:"chain"`
+	Contract string                 `json:"contract"`
+	Abi      string                 `json:"abi,optional"`
+	AbiName  string                 `json:"abi_name,optional"`
+	Method   string                 `json:"method"`
+	Args     map[string]interface{} `json:"args,optional"`
+	// 调用方地址（msg.sender），依赖调用者的只读方法需要
+	From string `json:"from,optional"`
+}
+
+// ContractReadResp 只读调用结果
+type ContractReadResp struct {
+	Chain    string          `json:"chain"`
+	Contract string          `json:"contract"`
+	Method   string          `json:"method"`
+	Outputs  []ContractValue `json:"outputs"`
+	Raw      string          `json:"raw"` // 原始返回数据（hex）
+}
+
+// ContractValue 解码后的返回值：整数为十进制字符串，bytes 为 hex，tuple 为对象
+type ContractValue struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
@@ -0,0 +1,87 @@
+package types
+
+// GasEstimateReq 查询手续费估算
+type GasEstimateReq struct {
+	// 配置中的链名或别名，为空表示全部 EVM 链
+	Chain string `form:"chain,optional"`
+}
+
+// GasLevelEstimate 单个档位的手续费估算，金额均为 wei
+type GasLevelEstimate struct {
+	MaxFeePerGas         string `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,omitempty"`
+	// legacy 链的 gasPrice
+	GasPrice string `json:"gas_price,omitempty"`
+	// 每单位 gas 最多支付的价格（gwei）
+	MaxFeeGwei string `json:"max_fee_gwei"`
+	// 原生币转账（21000 gas）的最高手续费，按原生币精度换算
+	TransferCost string `json:"transfer_cost"`
+	// 行情超过链的手续费上限，按该档位发送的交易会被拒绝或排队
+	ExceedsCap bool `json:"exceeds_cap"`
+}
+
+// ChainGasEstimate 单条链的手续费估算
+type ChainGasEstimate struct {
+	Chain        string `json:"chain"`
+	ChainId      int64  `json:"chain_id"`
+	NativeSymbol string `json:"native_symbol"`
+	Eip1559      bool   `json:"eip1559"`
+	Block        uint64 `json:"block,omitempty"`
+	// 下一个区块的 baseFee（wei，仅 EIP-1559）
+	BaseFee string `json:"base_fee,omitempty"`
+	// 手续费上限（wei）及超过上限时的策略 reject / queue，未配置上限时为空
+	MaxFeeCap    string                      `json:"max_fee_cap,omitempty"`
+	FeeCapPolicy string                      `json:"fee_cap_policy,omitempty"`
+	Levels       map[string]GasLevelEstimate `json:"levels,omitempty"`
+	UpdatedAt    string                      `json:"updated_at,omitempty"`
+	// 采样失败时的错误信息，不影响其他链的结果
+	Error string `json:"error,omitempty"`
+}
+
+// GasEstimateResp 手续费估算响应
+type GasEstimateResp struct {
+	Chains []ChainGasEstimate `json:"chains"`
+}
+
+// GasTopUpsReq 查询 gas 站补充记录，chain / wallet 为空表示不过滤
+type GasTopUpsReq struct {
+	Chain    string `json:"chain,optional"`
+	Wallet   string `json:"wallet,optional"`
+	Page     int    `json:"page,optional"`
+	PageSize int    `json:"page_size,optional"`
+}
+
+// GasTopUpItem 一次 gas 补充，金额均为 wei
+type GasTopUpItem struct {
+	Id              int64  `json:"id"`
+	Chain           string `json:"chain"`
+	Wallet          string `json:"wallet"`
+	Funder          string `json:"funder"`
+	Amount          string `json:"amount"`
+	AmountFormatted string `json:"amount_formatted"`
+	Fee             string `json:"fee"` // gas 钱包为这笔补充支付的手续费，确认后写入
+	Purpose         string `json:"purpose"`
+	TxHash          string `json:"tx_hash"`
+	ExplorerUrl     string `json:"explorer_url,omitempty"`
+	Status          string `json:"status"` // pending / confirmed / failed
+	Error           string `json:"error,omitempty"`
+	CreatedAt       int64  `json:"created_at"`
+	ConfirmedAt     int64  `json:"confirmed_at,omitempty"`
+}
+
+// GasSponsoredSummary 按链汇总的代付 gas：补充金额（失败的不计）加上补充交易本身的手续费
+type GasSponsoredSummary struct {
+	Chain              string `json:"chain"`
+	Symbol             string `json:"symbol"`
+	Count              int    `json:"count"`
+	Amount             string `json:"amount"`
+	Fee                string `json:"fee"`
+	Sponsored          string `json:"sponsored"`
+	SponsoredFormatted string `json:"sponsored_formatted"`
+}
+
+type GasTopUpsResp struct {
+	Total   int64                 `json:"total"`
+	Summary []GasSponsoredSummary `json:"summary"` // 符合过滤条件的全部记录，不分页
+	Items   []GasTopUpItem        `json:"items"`
+}
@@ -0,0 +1,33 @@
+package types
+
+// NftTransferReq ERC-721 / ERC-1155 转账请求
+type NftTransferReq struct {
+	Chain       string `json:"chain"`
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	// NFT 合约地址
+	Contract string `json:"contract"`
+	// erc721 / erc1155，为空时通过 ERC-165 supportsInterface 自动识别
+	Standard string `json:"standard,optional"`
+	// ERC-721 只能传一个；ERC-1155 传多个时使用 safeBatchTransferFrom
+	TokenIds []string `json:"token_ids"`
+	// ERC-1155 每个 token id 的数量，与 token_ids 一一对应，为空表示各 1 个；ERC-721 忽略
+	Amounts []string `json:"amounts,optional"`
+	// 透传给接收合约 onERC721Received / onERC1155Received 的数据（hex）
+	Data string `json:"data,optional"`
+	// 手续费档位，见 TransactionReq
+	FeeLevel             string `json:"fee_level,optional"`
+	MaxFeePerGas         string `json:"max_fee_per_gas,optional"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,optional"`
+}
+
+// NftTransferResp NFT 转账响应
+type NftTransferResp struct {
+	TxHash      string `json:"tx_hash"`
+	Chain       string `json:"chain"`
+	Standard    string `json:"standard"`
+	Method      string `json:"method"` // safeTransferFrom / safeBatchTransferFrom
+	ExplorerUrl string `json:"explorer_url"`
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+}
@@ -0,0 +1,99 @@
+package types
+
+// SafeRegisterReq 登记 Safe 多签，至少一个 owner 须为托管钱包
+type SafeRegisterReq struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+}
+
+// SafeResp Safe 的链上状态
+type SafeResp struct {
+	Chain     string   `json:"chain"`
+	Address   string   `json:"address"`
+	Version   string   `json:"version"`
+	Threshold int      `json:"threshold"`
+	Owners    []string `json:"owners"`
+	// 托管钱包中的 owner，可由服务端签名
+	ManagedOwners []string `json:"managed_owners"`
+	Nonce         string   `json:"nonce"`
+	ExplorerUrl   string   `json:"explorer_url,omitempty"`
+}
+
+// SafeCall Safe 交易中的一个调用：token 非空时为 ERC20 转账，否则向 to 发送 amount 原生币并附带 data
+type SafeCall struct {
+	To     string `json:"to"`
+	Token  string `json:"token,optional"`
+	Amount string `json:"amount,optional"` // 最小单位
+	Data   string `json:"data,optional"`   // hex 调用数据，ERC20 转账时不可用
+}
+
+// SafeProposeReq 创建 Safe 交易并由 proposer 签名；多个调用通过 MultiSend 原子执行
+type SafeProposeReq struct {
+	Chain       string     `json:"chain"`
+	SafeAddress string     `json:"safe_address"`
+	Proposer    string     `json:"proposer"` // 托管的 owner 钱包
+	Calls       []SafeCall `json:"calls"`
+	Description string     `json:"description,optional"`
+	// 签名达到阈值时由该 owner 提交 execTransaction 并支付 gas，为空使用最后一个签名的 owner
+	Executor string `json:"executor,optional"`
+	// 手续费档位（执行交易），同 TransactionReq
+	FeeLevel             string `json:"fee_level,optional"`
+	MaxFeePerGas         string `json:"max_fee_per_gas,optional"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,optional"`
+}
+
+// SafeConfirmReq 托管 owner 对 Safe 交易签名，owner 为空时由所有尚未签名的托管 owner 签名
+type SafeConfirmReq struct {
+	Chain                string `json:"chain"`
+	SafeTxHash           string `json:"safe_tx_hash"`
+	Owner                string `json:"owner,optional"`
+	Executor             string `json:"executor,optional"`
+	FeeLevel             string `json:"fee_level,optional"`
+	MaxFeePerGas         string `json:"max_fee_per_gas,optional"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,optional"`
+}
+
+// SafeExecuteReq 签名已达阈值的 Safe 交易重新提交执行（如之前 nonce 未轮到或发送失败）
+type SafeExecuteReq struct {
+	Chain                string `json:"chain"`
+	SafeTxHash           string `json:"safe_tx_hash"`
+	Executor             string `json:"executor,optional"`
+	FeeLevel             string `json:"fee_level,optional"`
+	MaxFeePerGas         string `json:"max_fee_per_gas,optional"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,optional"`
+}
+
+// SafeTxResp 一笔 Safe 交易及其签名进度
+type SafeTxResp struct {
+	Chain       string   `json:"chain"`
+	SafeAddress string   `json:"safe_address"`
+	SafeTxHash  string   `json:"safe_tx_hash"`
+	Nonce       int64    `json:"nonce"`
+	To          string   `json:"to"`
+	Value       string   `json:"value"`
+	Data        string   `json:"data"`
+	Operation   uint8    `json:"operation"` // 0 CALL，1 DELEGATECALL（MultiSend）
+	Description string   `json:"description,omitempty"`
+	Signers     []string `json:"signers"`
+	Threshold   int      `json:"threshold"`
+	Status      string   `json:"status"` // pending / executing / executed / failed / replaced
+	TxHash      string   `json:"tx_hash,omitempty"`
+	ExplorerUrl string   `json:"explorer_url,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	Message     string   `json:"message,omitempty"`
+}
+
+// SafeTransactionsReq 查询 Safe 的交易，status 为空返回全部
+type SafeTransactionsReq struct {
+	Chain       string `json:"chain"`
+	SafeAddress string `json:"safe_address"`
+	Status      string `json:"status,optional"`
+	Page        int    `json:"page,optional"`
+	PageSize    int    `json:"page_size,optional"`
+}
+
+// SafeTransactionsResp Safe 交易列表（按 nonce 升序）
+type SafeTransactionsResp struct {
+	Total int64        `json:"total"`
+	Items []SafeTxResp `json:"items"`
+}
@@ -0,0 +1,99 @@
+package types
+
+// ScheduleCreateReq 保存一笔定时执行的转账意图：run_at（Unix 秒）只执行一次，cron 按表达式周期执行，二者必须且只能指定一个
+type ScheduleCreateReq struct {
+	Name string `json:"name,optional"`
+	// send 走 /transaction/send 流程（发工资、订阅付款），swap 走 /transaction/swap 流程（定投）
+	Kind        string `json:"kind,default=send,options=send|swap"`
+	Chain       string `json:"chain"`
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	FromToken   string `json:"from_token"`
+	// send 时为空表示与 from_token 相同
+	ToToken    string `json:"to_token,optional"`
+	Amount     string `json:"amount"` // 同 /transaction/send，send 时可为 max
+	AmountUnit string `json:"amount_unit,optional,options=base|display"`
+	FeeLevel   string `json:"fee_level,optional,options=slow|normal|fast"`
+	RunAt      int64  `json:"run_at,optional"`
+	// 5 段 cron 表达式（分 时 日 月 周）或 @daily 等描述符，按 timezone 计算
+	Cron     string `json:"cron,optional"`
+	Timezone string `json:"timezone,optional"` // IANA 时区，默认 UTC
+	// 停机错过执行时间后的处理: skip / once（默认，只补执行一次）/ all（逐次补执行）
+	CatchUp string `json:"catch_up,optional,options=skip|once|all"`
+	MaxRuns int    `json:"max_runs,optional"` // 最多执行次数，0 表示不限
+	EndAt   int64  `json:"end_at,optional"`   // 该时间之后不再执行（Unix 秒）
+}
+
+// ScheduleIdReq 按 id 暂停、恢复或取消定时转账
+type ScheduleIdReq struct {
+	Id int64 `json:"id"`
+}
+
+// ScheduleListReq 查询定时转账，status 为空返回全部
+type ScheduleListReq struct {
+	Status   string `json:"status,optional,options=active|paused|completed|cancelled"`
+	Page     int    `json:"page,optional"`
+	PageSize int    `json:"page_size,optional"`
+}
+
+// ScheduleRunsReq 查询定时转账的执行记录，id 为空返回全部
+type ScheduleRunsReq struct {
+	Id       int64 `json:"id,optional"`
+	Page     int   `json:"page,optional"`
+	PageSize int   `json:"page_size,optional"`
+}
+
+// ScheduleResp 定时转账
+type ScheduleResp struct {
+	Id                  int64  `json:"id"`
+	Name                string `json:"name,omitempty"`
+	Kind                string `json:"kind"`
+	Chain               string `json:"chain"`
+	FromAddress         string `json:"from_address"`
+	ToAddress           string `json:"to_address"`
+	FromToken           string `json:"from_token"`
+	ToToken             string `json:"to_token"`
+	Amount              string `json:"amount"`
+	AmountUnit          string `json:"amount_unit,omitempty"`
+	FeeLevel            string `json:"fee_level,omitempty"`
+	RunAt               int64  `json:"run_at,omitempty"`
+	Cron                string `json:"cron,omitempty"`
+	Timezone            string `json:"timezone"`
+	CatchUp             string `json:"catch_up"`
+	MaxRuns             int    `json:"max_runs,omitempty"`
+	EndAt               int64  `json:"end_at,omitempty"`
+	Status              string `json:"status"` // active / paused / completed / cancelled
+	NextRunAt           int64  `json:"next_run_at,omitempty"`
+	LastRunAt           int64  `json:"last_run_at,omitempty"`
+	RunCount            int    `json:"run_count"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	LastError           string `json:"last_error,omitempty"`
+	CreatedAt           int64  `json:"created_at"`
+}
+
+// ScheduleListResp 定时转账列表（最新在前）
+type ScheduleListResp struct {
+	Total int64          `json:"total"`
+	Items []ScheduleResp `json:"items"`
+}
+
+// ScheduleRunResp 一次执行，交易状态和浏览器链接来自交易记录
+type ScheduleRunResp struct {
+	Id           int64  `json:"id"`
+	ScheduleId   int64  `json:"schedule_id"`
+	ScheduledFor int64  `json:"scheduled_for"`
+	CatchUp      bool   `json:"catch_up"`
+	Status       string `json:"status"` // running / succeeded / failed / skipped / denied
+	TxHash       string `json:"tx_hash,omitempty"`
+	TxStatus     string `json:"tx_status,omitempty"` // pending / confirmed / failed
+	ExplorerUrl  string `json:"explorer_url,omitempty"`
+	Error        string `json:"error,omitempty"`
+	StartedAt    int64  `json:"started_at"`
+	FinishedAt   int64  `json:"finished_at,omitempty"`
+}
+
+// ScheduleRunsResp 执行记录（最新在前）
+type ScheduleRunsResp struct {
+	Total int64             `json:"total"`
+	Items []ScheduleRunResp `json:"items"`
+}
@@ -0,0 +1,63 @@
+package types
+
+// SweepRunReq 立即执行一条归集规则（配置 Sweep.Rules 中的 Name），执行在后台进行
+type SweepRunReq struct {
+	Rule string `json:"rule"`
+}
+
+// SweepRunsReq 查询归集执行记录，rule 为空返回全部
+type SweepRunsReq struct {
+	Rule     string `json:"rule,optional"`
+	Page     int    `json:"page,optional"`
+	PageSize int    `json:"page_size,optional"`
+}
+
+// SweepReportReq 查询一次归集的报告（GET 查询参数）
+type SweepReportReq struct {
+	Id int64 `form:"id"`
+}
+
+// SweepRunResp 一次归集的汇总
+type SweepRunResp struct {
+	Id          int64  `json:"id"`
+	Rule        string `json:"rule"`
+	Chain       string `json:"chain"`
+	Token       string `json:"token,omitempty"`
+	Symbol      string `json:"symbol"`
+	Destination string `json:"destination"`
+	TriggeredBy string `json:"triggered_by"` // schedule / manual
+	Status      string `json:"status"`       // running / completed / failed
+	Scanned     int    `json:"scanned"`
+	Swept       int    `json:"swept"`
+	Skipped     int    `json:"skipped"`
+	Failed      int    `json:"failed"`
+	// 已提交归集交易的金额合计（最小单位）及换算后的金额
+	TotalAmount          string `json:"total_amount"`
+	TotalAmountFormatted string `json:"total_amount_formatted,omitempty"`
+	Error                string `json:"error,omitempty"`
+	StartedAt            int64  `json:"started_at"`
+	FinishedAt           int64  `json:"finished_at,omitempty"`
+}
+
+// SweepItemResp 归集报告中的一个钱包
+type SweepItemResp struct {
+	Wallet      string `json:"wallet"`
+	Balance     string `json:"balance"`
+	Amount      string `json:"amount,omitempty"`
+	Status      string `json:"status"` // swept / skipped / failed
+	Reason      string `json:"reason,omitempty"`
+	TxHash      string `json:"tx_hash,omitempty"`
+	TopUpTxHash string `json:"top_up_tx_hash,omitempty"`
+}
+
+// SweepRunsResp 归集执行记录（最新在前）
+type SweepRunsResp struct {
+	Total int64          `json:"total"`
+	Items []SweepRunResp `json:"items"`
+}
+
+// SweepReportResp 归集报告：汇总及每个有余额钱包的处理结果
+type SweepReportResp struct {
+	SweepRunResp
+	Items []SweepItemResp `json:"items"`
+}
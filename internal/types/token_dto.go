@@ -0,0 +1,20 @@
+package types
+
+// TokenResolveReq 查询代币元数据
+type TokenResolveReq struct {
+	// 配置中的链名或别名
+	Chain string `form:"chain"`
+	// 原生币符号、代币符号（如 USDC）或合约地址 / SPL mint
+	Token string `form:"token"`
+}
+
+// TokenResp 代币元数据
+type TokenResp struct {
+	Chain    string `json:"chain"`
+	Address  string `json:"address"` // 原生币 EVM 为零地址，Solana 为 11111111111111111111111111111111，BTC 为空
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+	Decimals int    `json:"decimals"`
+	Native   bool   `json:"native"`
+	Source   string `json:"source"` // native / config / lifi / chain
+}
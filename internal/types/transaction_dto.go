@@ -7,7 +7,15 @@ type TransactionReq struct {
 	Chain       string `json:"chain" validate:"required"`      // e.g., "BSC"
 	FromToken   string `json:"from_token" validate:"required"` // e.g., "0x55d398326f99059fF775485246999027B3197955" for USDT
 	ToToken     string `json:"to_token" validate:"required"`   // e.g., "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE" for native BNB
-	Amount      string `json:"amount" validate:"required"`     // e.g., "1000000000000000000" for 1 USDT
+	Amount      string `json:"amount" validate:"required"`     // e.g., "1000000000000000000" for 1 USDT, "1" with amount_unit=display, "max" (send only) for the entire balance
+	// amount 的单位: base（默认，最小单位整数）/ display（按代币精度换算的小数，如 "0.1"）
+	AmountUnit string `json:"amount_unit,optional,options=base|display"`
+	// 手续费档位: slow / normal（默认）/ fast / custom；custom 时需同时传 max_fee_per_gas 和 max_priority_fee_per_gas（wei）
+	FeeLevel             string `json:"fee_level,optional"`
+	MaxFeePerGas         string `json:"max_fee_per_gas,optional"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,optional"`
+	// from_address 为智能账户时由 paymaster 代付 gas（需链配置 SmartAccount.PaymasterUrl）
+	Sponsor bool `json:"sponsor,optional"`
 }
 
 // TransactionResp defines the response for transaction operations.
@@ -17,6 +25,11 @@ type TransactionResp struct {
 	ExplorerUrl string `json:"explorer_url"`
 	Chain       string `json:"chain"`
 	Status      string `json:"status"`
+	// 实际发送的金额（最小单位，amount 为 max 时为扣除手续费后的金额）及换算后的金额，代币精度未知时不返回后者
+	Amount          string `json:"amount,omitempty"`
+	AmountFormatted string `json:"amount_formatted,omitempty"`
+	// 智能账户交易以 UserOperation 提交，此时 tx_hash 同为 userOpHash，打包后的链上交易见 /transaction/user_operation
+	UserOpHash string `json:"user_op_hash,omitempty"`
 }
 
 // LifiToken LI.FI API 中的代币信息
@@ -114,6 +127,8 @@ type BridgeExecuteReq struct {
 	ToAddress   string `json:"to_address" validate:"required"`
 	Order       string `json:"order,omitempty"`
 	Slippage    string `json:"slippage,omitempty"`
+	// amount 的单位，同 TransactionReq.AmountUnit
+	AmountUnit string `json:"amount_unit,optional,options=base|display"`
 }
 
 // BridgeExecuteResp 执行跨链转账响应
@@ -124,6 +139,9 @@ type BridgeExecuteResp struct {
 	FromChain   int    `json:"from_chain"`
 	ToChain     int    `json:"to_chain"`
 	Status      string `json:"status"`
+	// 源链发送的金额（最小单位）及换算后的金额
+	Amount          string `json:"amount,omitempty"`
+	AmountFormatted string `json:"amount_formatted,omitempty"`
 }
 
 // BridgeStatusReq 查询跨链状态请求
@@ -228,3 +246,173 @@ type SpenderInfo struct {
 	Address string `json:"address"`
 	Name    string `json:"name"`
 }
+
+// ========== 交易记录相关类型 ==========
+
+// TransactionHistoryReq 查询交易记录请求
+type TransactionHistoryReq struct {
+	Address  string `json:"address" validate:"required"`
+	Chain    string `json:"chain,optional"`
+	TxType   string `json:"tx_type,optional"` // send / swap / bridge / approve / revoke
+	Page     int    `json:"page,optional"`
+	PageSize int    `json:"page_size,optional"`
+}
+
+// TransactionRecord 单条交易记录
+type TransactionRecord struct {
+	Id           int64  `json:"id"`
+	Chain        string `json:"chain"`
+	TxHash       string `json:"tx_hash"`
+	TxType       string `json:"tx_type"`
+	FromAddress  string `json:"from_address"`
+	ToAddress    string `json:"to_address"`
+	Token        string `json:"token"`
+	Amount       string `json:"amount"`
+	Status       string `json:"status"`
+	ExplorerUrl  string `json:"explorer_url"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+// TransactionHistoryResp 查询交易记录响应
+type TransactionHistoryResp struct {
+	Total int64               `json:"total"`
+	Items []TransactionRecord `json:"items"`
+}
+
+// ReceiveReq 获取收款地址及支付 URI
+type ReceiveReq struct {
+	UserId string `json:"user_id"`
+	// 配置中的链名，如 BSC、ETH、Solana、BTC
+	Chain string `json:"chain"`
+	// 代币合约地址 / SPL mint，或配置中的代币符号（如 USDT），为空表示原生币
+	Token string `json:"token,optional"`
+	// 期望收款金额（最小单位），写入支付 URI；在有收款匹配的链上同时创建收款请求
+	Amount  string `json:"amount,optional"`
+	Label   string `json:"label,optional"`
+	Message string `json:"message,optional"`
+	// 二维码格式 png / svg
+	QrFormat string `json:"qr_format,default=png,options=png|svg"`
+	// 二维码边长（像素，仅 png）
+	QrSize int `json:"qr_size,default=256,range=[64:1024]"`
+	// 收款请求有效期（秒）
+	ExpiresIn int64 `json:"expires_in,default=3600,range=[60:2592000]"`
+}
+
+// ReceiveResp 收款信息
+type ReceiveResp struct {
+	Chain   string `json:"chain"`
+	ChainId int64  `json:"chain_id"`
+	Address string `json:"address"`
+	Token   string `json:"token,omitempty"`
+	Symbol  string `json:"symbol,omitempty"`
+	// 未配置且未能查询到精度的代币不返回
+	Decimals int `json:"decimals,omitempty"`
+	// 期望金额（最小单位）及换算后的金额
+	Amount          string `json:"amount,omitempty"`
+	AmountFormatted string `json:"amount_formatted,omitempty"`
+	// EIP-681 / BIP21 / Solana Pay 支付 URI
+	Uri string `json:"uri"`
+	// data URI 形式的二维码
+	QrCode   string `json:"qr_code"`
+	QrFormat string `json:"qr_format"`
+	// Solana Pay reference 公钥，用于在链上定位该笔付款
+	Reference string `json:"reference,omitempty"`
+	// 指定金额且该链支持收款匹配时创建的收款请求
+	RequestId string `json:"request_id,omitempty"`
+	Status    string `json:"status,omitempty"`
+	ExpiresAt int64  `json:"expires_at,omitempty"`
+}
+
+// ReceiveStatusReq 查询收款请求状态
+type ReceiveStatusReq struct {
+	RequestId string `form:"request_id"`
+}
+
+// ReceiveStatusResp 收款请求状态
+type ReceiveStatusResp struct {
+	RequestId  string `json:"request_id"`
+	Chain      string `json:"chain"`
+	ChainId    int64  `json:"chain_id"`
+	Address    string `json:"address"`
+	Token      string `json:"token,omitempty"`
+	Amount     string `json:"amount"`
+	Uri        string `json:"uri"`
+	Status     string `json:"status"` // pending / paid / expired
+	PaidTxHash string `json:"paid_tx_hash,omitempty"`
+	PaidAmount string `json:"paid_amount,omitempty"`
+	ExpiresAt  int64  `json:"expires_at"`
+	PaidAt     int64  `json:"paid_at,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+// BatchSendItem 批量转账中的一笔付款
+type BatchSendItem struct {
+	// 调用方的付款 ID，结果按该 ID 返回；为空时使用从 0 开始的序号
+	Id string `json:"id,optional"`
+	To string `json:"to"`
+	// 代币合约地址，为空或原生币地址表示原生币
+	Token  string `json:"token,optional"`
+	Amount string `json:"amount"` // 最小单位
+}
+
+// BatchSendReq 批量转账请求（仅 EVM 链）
+type BatchSendReq struct {
+	Chain       string          `json:"chain"`
+	FromAddress string          `json:"from_address"`
+	Items       []BatchSendItem `json:"items"`
+	// 手续费档位，同 TransactionReq
+	FeeLevel             string `json:"fee_level,optional"`
+	MaxFeePerGas         string `json:"max_fee_per_gas,optional"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,optional"`
+}
+
+// BatchSendTx 批量转账发出的一笔交易
+type BatchSendTx struct {
+	TxHash      string   `json:"tx_hash"`
+	Kind        string   `json:"kind"` // approve / disperse / send
+	Token       string   `json:"token,omitempty"`
+	ItemIds     []string `json:"item_ids,omitempty"`
+	ExplorerUrl string   `json:"explorer_url"`
+}
+
+// BatchSendResult 单笔付款的结果
+type BatchSendResult struct {
+	Id     string `json:"id"`
+	To     string `json:"to"`
+	Token  string `json:"token,omitempty"`
+	Amount string `json:"amount"`
+	Status string `json:"status"` // submitted / failed
+	TxHash string `json:"tx_hash,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchSendResp 批量转账响应
+type BatchSendResp struct {
+	Chain        string            `json:"chain"`
+	Mode         string            `json:"mode"`   // disperse / sequential
+	Status       string            `json:"status"` // pending / partial / failed
+	Submitted    int               `json:"submitted"`
+	Failed       int               `json:"failed"`
+	Transactions []BatchSendTx     `json:"transactions"`
+	Results      []BatchSendResult `json:"results"`
+}
+
+// UserOperationStatusReq 查询智能账户 UserOperation 的打包结果
+type UserOperationStatusReq struct {
+	Chain      string `json:"chain"`
+	UserOpHash string `json:"user_op_hash"`
+}
+
+// UserOperationStatusResp UserOperation 状态，打包后给出链上交易
+type UserOperationStatusResp struct {
+	Chain      string `json:"chain"`
+	UserOpHash string `json:"user_op_hash"`
+	Status     string `json:"status"` // pending / confirmed / failed
+	Sender     string `json:"sender,omitempty"`
+	TxHash     string `json:"tx_hash,omitempty"`
+	// 实际支付的手续费（wei），由 paymaster 代付时为 paymaster 支付的金额
+	ActualGasCost string `json:"actual_gas_cost,omitempty"`
+	Reason        string `json:"reason,omitempty"` // 执行失败的 revert 原因
+	ExplorerUrl   string `json:"explorer_url,omitempty"`
+}
@@ -6,9 +6,9 @@ type WalletInitReq struct {
 	// A user-defined name for the wallet.
 	Name string `json:"name"`
 	// The user's phone number (optional).
-	PhoneNumber string `json:"phone_number,omitempty"`
+	PhoneNumber string `json:"phone_number,optional"`
 	// The user's email address (optional).
-	Email string `json:"email,omitempty" validate:"omitempty,email"`
+	Email string `json:"email,optional" validate:"omitempty,email"`
 }
 
 // WalletAddress 单个链的钱包地址信息
@@ -30,3 +30,121 @@ type WalletInitResp struct {
 	// 失败的链（如果有）
 	FailedChains []string `json:"failed_chains,omitempty"`
 }
+
+// WalletBalancesReq 查询钱包余额请求，address 与 user_id 二选一
+type WalletBalancesReq struct {
+	// 单个钱包地址
+	Address string `json:"address,optional"`
+	// 查询该用户名下所有钱包
+	UserId string `json:"user_id,optional"`
+	// 只查询这些链（配置中的链名，如 BSC、Solana、BTC），为空表示全部已配置的链
+	Chains []string `json:"chains,optional"`
+	// 是否返回余额为 0 的代币
+	IncludeZero bool `json:"include_zero,optional"`
+}
+
+// TokenBalance 单个资产余额
+type TokenBalance struct {
+	Chain string `json:"chain"`
+	// 代币合约地址 / SPL mint，原生币为空
+	Token    string `json:"token"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+	// 最小单位余额（wei / lamports / satoshi）
+	Amount string `json:"amount"`
+	// 按 decimals 换算后的余额
+	Formatted string `json:"formatted"`
+	// BTC 未确认余额（mempool 中的 UTXO）
+	UnconfirmedAmount    string `json:"unconfirmed_amount,omitempty"`
+	UnconfirmedFormatted string `json:"unconfirmed_formatted,omitempty"`
+}
+
+// NftHolding 单个 NFT 集合的持仓
+type NftHolding struct {
+	Chain    string `json:"chain"`
+	Contract string `json:"contract"`
+	Name     string `json:"name,omitempty"`
+	Standard string `json:"standard"` // erc721 / erc1155
+	// ERC-721 为持有数量，ERC-1155 为 token_id 的持有数量
+	Balance string `json:"balance"`
+	// ERC-1155 的 token id
+	TokenId string `json:"token_id,omitempty"`
+	// ERC-721 持有的 token id（集合支持 ERC721Enumerable 时，最多返回 100 个）
+	TokenIds []string `json:"token_ids,omitempty"`
+}
+
+// WalletBalances 单个钱包的余额
+type WalletBalances struct {
+	Address   string         `json:"address"`
+	ChainType string         `json:"chain_type"`
+	Balances  []TokenBalance `json:"balances"`
+	// 链配置 Nfts 中跟踪的 NFT 持仓（仅 EVM）
+	Nfts []NftHolding `json:"nfts,omitempty"`
+	// 部分链查询失败时的错误信息，不影响其他链的结果
+	Errors []string `json:"errors,omitempty"`
+}
+
+// WalletBalancesResp 查询钱包余额响应
+type WalletBalancesResp struct {
+	Wallets []WalletBalances `json:"wallets"`
+}
+
+// SignMessageReq 消息签名请求
+type SignMessageReq struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+	// personal_sign / eip712（EVM）、bip322（BTC）、solana；为空时按链族默认
+	Type string `json:"type,optional"`
+	// 待签名的文本，personal_sign 时 0x 开头按十六进制字节处理
+	Message string `json:"message,optional"`
+	// EIP-712 结构化数据（eth_signTypedData_v4 的 types / primaryType / domain / message）
+	TypedData map[string]interface{} `json:"typed_data,optional"`
+	// 只解析并校验策略，返回待签名内容和摘要，不签名
+	Preview bool `json:"preview,optional"`
+}
+
+// TypedDataField EIP-712 字段的可读展示，嵌套结构的 Value 为 []TypedDataField
+type TypedDataField struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// SignMessageResp 消息签名响应
+type SignMessageResp struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	// 签名：EVM 为 65 字节 hex（v 为 27/28），BIP-322 为 base64，Solana 为 base58；预览时为空
+	Signature string `json:"signature,omitempty"`
+	// BIP-322 签名格式 simple（隔离见证地址）/ full（P2PKH 地址）
+	Format string `json:"format,omitempty"`
+	// 被签名的摘要（EVM 为 EIP-191 / EIP-712 哈希，BIP-322 为消息 tagged hash）
+	Hash string `json:"hash,omitempty"`
+	// EIP-712 解析结果：domain 与 primaryType 两项
+	TypedData []TypedDataField `json:"typed_data,omitempty"`
+	Warnings  []string         `json:"warnings,omitempty"`
+	Message   string           `json:"message"`
+}
+
+// SmartAccountReq 创建 ERC-4337 智能账户（SimpleAccount）
+type SmartAccountReq struct {
+	Chain string `json:"chain"`
+	// 所有者：本服务托管的 EVM 钱包地址，负责签名 UserOperation
+	Owner string `json:"owner"`
+	// CREATE2 salt（十进制），同一所有者可用不同 salt 创建多个账户，默认 0
+	Salt string `json:"salt,optional"`
+}
+
+// SmartAccountResp 智能账户信息，地址在部署前即可用于收款
+type SmartAccountResp struct {
+	Chain      string `json:"chain"`
+	Address    string `json:"address"`
+	Owner      string `json:"owner"`
+	Salt       string `json:"salt"`
+	Factory    string `json:"factory"`
+	EntryPoint string `json:"entry_point"`
+	// 合约是否已部署；未部署时由第一笔 UserOperation 部署
+	Deployed    bool   `json:"deployed"`
+	ExplorerUrl string `json:"explorer_url,omitempty"`
+}
@@ -0,0 +1,79 @@
+// Package units converts between integer base units (wei, lamports, satoshi)
+// and human-readable decimal strings without going through float64.
+package units
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Units an amount string can be expressed in.
+const (
+	UnitBase    = "base"    // integer base units (wei, lamports, satoshi)
+	UnitDisplay = "display" // decimal amount in whole tokens, e.g. "0.1"
+)
+
+var decimalPattern = regexp.MustCompile(`^-?(\d+\.?\d*|\.\d+)$`)
+
+// FormatUnits renders amount (in base units) as a decimal string with the
+// given number of decimals, trimming trailing zeros: 1500000 with 6 decimals
+// becomes "1.5".
+func FormatUnits(amount *big.Int, decimals int) string {
+	if amount == nil {
+		return "0"
+	}
+	if decimals <= 0 {
+		return amount.String()
+	}
+
+	negative := amount.Sign() < 0
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	intPart := digits[:len(digits)-decimals]
+	fracPart := strings.TrimRight(digits[len(digits)-decimals:], "0")
+
+	result := intPart
+	if fracPart != "" {
+		result += "." + fracPart
+	}
+	if negative {
+		result = "-" + result
+	}
+	return result
+}
+
+// FormatUnitsString is FormatUnits for a base-10 string; invalid input yields "0".
+func FormatUnitsString(amount string, decimals int) string {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return "0"
+	}
+	return FormatUnits(value, decimals)
+}
+
+// ParseUnits is the inverse of FormatUnits: it converts a decimal string such
+// as "0.1" to base units using exact rational arithmetic. Amounts with more
+// fractional digits than decimals are rejected rather than rounded.
+func ParseUnits(amount string, decimals int) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	if !decimalPattern.MatchString(amount) {
+		return nil, fmt.Errorf("invalid decimal amount: %q", amount)
+	}
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal amount: %q", amount)
+	}
+	if decimals > 0 {
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+		value.Mul(value, new(big.Rat).SetInt(scale))
+	}
+	if !value.IsInt() {
+		return nil, fmt.Errorf("amount %s has more than %d decimal places", amount, decimals)
+	}
+	return new(big.Int).Set(value.Num()), nil
+}
@@ -0,0 +1,106 @@
+package units
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int
+		want     string // 为空表示应返回错误
+	}{
+		{"1", 18, "1000000000000000000"},
+		{"0.1", 18, "100000000000000000"},
+		{"1.5", 6, "1500000"},
+		{"0.000001", 6, "1"},
+		{".5", 6, "500000"},
+		{"2.", 6, "2000000"},
+		{" 3 ", 6, "3000000"},
+		{"-1.25", 2, "-125"},
+		{"-.5", 1, "-5"},
+		{"0", 18, "0"},
+		{"123", 0, "123"},
+		{"1.0", 0, "1"},
+		{"0.0000001", 6, ""},
+		{"1.5", 0, ""},
+		{"", 6, ""},
+		{".", 6, ""},
+		{"-", 6, ""},
+		{"1e3", 6, ""},
+		{"1/2", 6, ""},
+		{"+1", 6, ""},
+		{"0x10", 6, ""},
+		{"1,5", 6, ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseUnits(tt.amount, tt.decimals)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ParseUnits(%q, %d) = %s, want an error", tt.amount, tt.decimals, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseUnits(%q, %d): %v", tt.amount, tt.decimals, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseUnits(%q, %d) = %s, want %s", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int
+		want     string
+	}{
+		{"1500000", 6, "1.5"},
+		{"1000000000000000000", 18, "1"},
+		{"1", 18, "0.000000000000000001"},
+		{"0", 18, "0"},
+		{"123", 0, "123"},
+		{"-125", 2, "-1.25"},
+		{"-5", 1, "-0.5"},
+		{"100", 2, "1"},
+		{"abc", 6, "0"},
+	}
+	for _, tt := range tests {
+		if got := FormatUnitsString(tt.amount, tt.decimals); got != tt.want {
+			t.Errorf("FormatUnitsString(%q, %d) = %q, want %q", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+	if got := FormatUnits(nil, 18); got != "0" {
+		t.Errorf("FormatUnits(nil) = %q, want \"0\"", got)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		base     string
+		decimals int
+	}{
+		{"0", 18},
+		{"1", 18},
+		{"123456789012345678901234567890", 18},
+		{"-987654321", 9},
+		{"1000", 3},
+		{"42", 0},
+		{"100000000", 8},
+	}
+	for _, tt := range tests {
+		base, _ := new(big.Int).SetString(tt.base, 10)
+		display := FormatUnits(base, tt.decimals)
+		back, err := ParseUnits(display, tt.decimals)
+		if err != nil {
+			t.Errorf("ParseUnits(%q, %d): %v", display, tt.decimals, err)
+			continue
+		}
+		if back.Cmp(base) != 0 {
+			t.Errorf("%s -> %q -> %s with %d decimals", tt.base, display, back, tt.decimals)
+		}
+	}
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"demo/internal/config"
 	"demo/internal/handler"
+	"demo/internal/logic/schedule"
+	"demo/internal/logic/sweep"
+	"demo/internal/logic/token"
+	"demo/internal/model/migrations"
 	"demo/internal/svc"
 
 	"github.com/zeromicro/go-zero/core/conf"
@@ -23,18 +29,40 @@ func main() {
 	var c config.Config
 	conf.MustLoad(*configFile, &c)
 
+	// 子命令: migrate [status]
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(c, flag.Arg(1)); err != nil {
+			fmt.Printf("❌ 数据库迁移失败: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	server := rest.MustNewServer(c.RestConf)
 	defer server.Stop()
 
 	ctx := svc.NewServiceContext(c)
 	handler.RegisterHandlers(server, ctx)
 
+	// 后台导入 LI.FI 代币列表，供代币符号解析
+	if c.TokenRegistry.SeedFromLifi {
+		go seedTokens(ctx)
+	}
+
+	// 定时归集和定时转账
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if len(c.Sweep.Rules) > 0 {
+		go sweep.StartScheduler(jobCtx, ctx)
+	}
+	go schedule.StartScheduler(jobCtx, ctx)
+
 	// 设置优雅退出
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
-	fmt.Println("🔗 BSC监控服务已集成启动")
+	fmt.Println("🔗 链上监控服务已集成启动")
 	fmt.Println("📤 TokenEvent将发送到Mock Kafka")
 
 	// 在独立的goroutine中启动服务器
@@ -46,8 +74,54 @@ func main() {
 	<-quit
 	fmt.Println("\n🛑 收到退出信号，正在优雅关闭服务...")
 
-	// 停止监控服务
+	// 停止归集、定时转账调度和监控服务
+	stopJobs()
 	ctx.StopMonitor()
 
 	fmt.Println("✅ 服务已安全退出")
 }
+
+// seedTokens 从 LI.FI 导入代币列表，失败时只影响符号解析（地址查询仍会从链上读取）
+func seedTokens(svcCtx *svc.ServiceContext) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err := token.NewSeedLogic(ctx, svcCtx).SeedFromLifi(); err != nil {
+		fmt.Printf("⚠️  导入 LI.FI 代币列表失败: %v\n", err)
+	}
+}
+
+// runMigrate 执行版本化 SQL 迁移；action 为 "status" 时只列出待执行的迁移
+func runMigrate(c config.Config, action string) error {
+	db, err := svc.InitDB(c.Database.Driver, c.DatabaseDSN())
+	if err != nil {
+		return err
+	}
+
+	if action == "status" {
+		pending, err := migrations.Pending(db, c.Database.Driver)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			fmt.Println("✅ 数据库已是最新版本")
+			return nil
+		}
+		fmt.Printf("📋 待执行迁移 %d 个:\n", len(pending))
+		for _, m := range pending {
+			fmt.Printf("   - %s\n", m.Name)
+		}
+		return nil
+	}
+
+	applied, err := migrations.Up(db, c.Database.Driver)
+	for _, m := range applied {
+		fmt.Printf("✅ 已执行迁移: %s\n", m.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("✅ 数据库已是最新版本，无需迁移")
+	}
+	return nil
+}